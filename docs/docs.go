@@ -15,29 +15,16 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/auth/login": {
-            "post": {
-                "consumes": [
-                    "application/json"
-                ],
+        "/.well-known/jwks.json": {
+            "get": {
+                "description": "JSON Web Key Set con todas las claves de verificación vigentes (campo kid del header JWT)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "auth"
                 ],
-                "summary": "Iniciar sesión",
-                "parameters": [
-                    {
-                        "description": "Credenciales",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/usecase.LoginInput"
-                        }
-                    }
-                ],
+                "summary": "Claves públicas para verificar los tokens",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -45,39 +32,18 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
                     }
                 }
             }
         },
-        "/auth/register": {
+        "/admin/invites": {
             "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Quien se registre con el código obtiene directamente su rol (host por defecto)",
                 "consumes": [
                     "application/json"
                 ],
@@ -85,60 +51,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Registrar usuario",
+                "summary": "Crear código de invitación",
                 "parameters": [
                     {
-                        "description": "Datos de registro",
+                        "description": "Configuración de la invitación",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/usecase.RegisterInput"
-                        }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/usecase.CreateInviteInput"
                         }
                     }
-                }
-            }
-        },
-        "/rooms": {
-            "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "rooms"
                 ],
-                "summary": "Crear sala",
                 "responses": {
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.InviteCode"
                         }
                     },
                     "400": {
@@ -150,15 +81,6 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
@@ -171,7 +93,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/rooms/{code}": {
+        "/admin/users": {
             "get": {
                 "security": [
                     {
@@ -182,28 +104,47 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "rooms"
+                    "admin"
                 ],
-                "summary": "Obtener sala por código",
+                "summary": "Listar usuarios",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Código de sala",
-                        "name": "code",
-                        "in": "path",
-                        "required": true
+                        "description": "Filtrar por rol (admin, host, participant)",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Solo los que pidieron ser host",
+                        "name": "pending",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Máximo de resultados (50 por defecto, máx. 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Desplazamiento",
+                        "name": "offset",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.User"
+                            }
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -211,8 +152,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -223,13 +164,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/rooms/{code}/answer": {
-            "post": {
+        "/admin/users/{id}/role": {
+            "patch": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "Aprobar a quien pidió ser host es cambiar su rol a host.\nEl rol nuevo rige desde su próxima petición, sin esperar a que venza el token.",
                 "consumes": [
                     "application/json"
                 ],
@@ -237,24 +179,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "questions"
+                    "admin"
                 ],
-                "summary": "Participante envía su respuesta",
+                "summary": "Ascender o degradar a un usuario",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Código de sala",
-                        "name": "code",
+                        "type": "integer",
+                        "description": "ID de usuario",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Respuesta",
+                        "description": "Rol nuevo",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/usecase.SubmitAnswerInput"
+                            "$ref": "#/definitions/usecase.ChangeRoleInput"
                         }
                     }
                 ],
@@ -262,7 +204,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/usecase.SubmitAnswerOutput"
+                            "$ref": "#/definitions/domain.User"
                         }
                     },
                     "400": {
@@ -273,31 +215,40 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
             }
         },
-        "/rooms/{code}/end": {
-            "patch": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
+        "/auth/login": {
+            "post": {
+                "consumes": [
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "rooms"
+                    "auth"
                 ],
-                "summary": "Finalizar sesión de sala",
+                "summary": "Iniciar sesión",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Código de sala",
-                        "name": "code",
-                        "in": "path",
-                        "required": true
+                        "description": "Credenciales",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/usecase.LoginInput"
+                        }
                     }
                 ],
                 "responses": {
@@ -305,9 +256,7 @@ const docTemplate = `{
                         "description": "OK",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "additionalProperties": true
                         }
                     },
                     "400": {
@@ -328,8 +277,17 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -340,26 +298,29 @@ const docTemplate = `{
                 }
             }
         },
-        "/rooms/{code}/join": {
-            "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
+        "/auth/oidc/callback": {
+            "get": {
+                "description": "Vincula o crea el usuario y emite el JWT de QuickScore.\nCon Accept: application/json responde {token, user}; si no,\nredirige a APP_URL/auth/callback#token=... (o #error=...).",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "rooms"
+                    "auth"
                 ],
-                "summary": "Unirse a sala",
+                "summary": "Callback del proveedor de identidad (OIDC)",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Código de sala",
+                        "description": "Código de autorización",
                         "name": "code",
-                        "in": "path",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "State enviado al proveedor",
+                        "name": "state",
+                        "in": "query",
                         "required": true
                     }
                 ],
@@ -368,11 +329,12 @@ const docTemplate = `{
                         "description": "OK",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "additionalProperties": true
                         }
                     },
+                    "302": {
+                        "description": "Found"
+                    },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
@@ -381,9 +343,23 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    }
+                }
+            }
+        },
+        "/auth/oidc/login": {
+            "get": {
+                "description": "Redirige al proveedor con authorization code + PKCE",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Iniciar sesión con el proveedor de identidad (OIDC)",
+                "responses": {
+                    "302": {
+                        "description": "Found"
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -394,13 +370,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/rooms/{code}/kick": {
+        "/auth/password/forgot": {
             "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
+                "description": "Siempre responde 200 para no revelar qué emails están registrados",
                 "consumes": [
                     "application/json"
                 ],
@@ -408,27 +380,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "rooms"
+                    "auth"
                 ],
-                "summary": "Host expulsa a un participante",
+                "summary": "Pedir enlace para recuperar la contraseña",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Código de sala",
-                        "name": "code",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "user_id del participante a expulsar",
+                        "description": "Email de la cuenta",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "integer"
-                            }
+                            "$ref": "#/definitions/usecase.ForgotPasswordInput"
                         }
                     }
                 ],
@@ -451,8 +413,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
+                    "429": {
+                        "description": "Too Many Requests",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -463,85 +425,96 @@ const docTemplate = `{
                 }
             }
         },
-        "/rooms/{code}/online": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "produces": [
+        "/auth/password/reset": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "rooms"
+                    "auth"
                 ],
-                "summary": "Ver quién está conectado ahora mismo en la sala (vía WS)",
+                "summary": "Elegir contraseña nueva con el token recibido por correo",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Código de sala",
-                        "name": "code",
-                        "in": "path",
-                        "required": true
+                        "description": "Token y contraseña nueva",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/usecase.ResetPasswordInput"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/websocket.ClientInfo"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     }
                 }
             }
         },
-        "/rooms/{code}/participants": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
+        "/auth/register": {
+            "post": {
+                "consumes": [
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "rooms"
+                    "auth"
                 ],
-                "summary": "Listar participantes de una sala",
+                "summary": "Registrar usuario",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Código de sala",
-                        "name": "code",
-                        "in": "path",
-                        "required": true
+                        "description": "Datos de registro",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/usecase.RegisterInput"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/domain.ParticipantWithUser"
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     }
                 }
             }
         },
-        "/rooms/{code}/questions": {
+        "/auth/verify": {
             "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
                 "consumes": [
                     "application/json"
                 ],
@@ -549,45 +522,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "questions"
+                    "auth"
                 ],
-                "summary": "Host lanza una pregunta a la sala",
+                "summary": "Verificar email con el token recibido por correo",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Código de sala",
-                        "name": "code",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Datos de la pregunta",
+                        "description": "Token de verificación",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/usecase.LaunchQuestionInput"
+                            "$ref": "#/definitions/usecase.VerifyEmailInput"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/usecase.LaunchQuestionOutput"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -595,8 +546,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -607,8 +558,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/rooms/{code}/questions/current": {
-            "get": {
+        "/auth/verify/resend": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
@@ -618,32 +569,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "questions"
-                ],
-                "summary": "Obtener la pregunta activa de una sala",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Código de sala",
-                        "name": "code",
-                        "in": "path",
-                        "required": true
-                    }
+                    "auth"
                 ],
+                "summary": "Reenviar el correo de verificación al usuario autenticado",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/usecase.LaunchQuestionOutput"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
-                    "204": {
-                        "description": "Sin pregunta activa"
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
             }
         },
-        "/rooms/{code}/questions/{question_id}/answers": {
+        "/badges": {
             "get": {
                 "security": [
                     {
@@ -654,68 +605,40 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "questions"
-                ],
-                "summary": "Host obtiene todas las respuestas de una pregunta",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Código de sala",
-                        "name": "code",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "ID de pregunta",
-                        "name": "question_id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "badges"
                 ],
+                "summary": "Catálogo de medallas",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/domain.Answer"
+                                "$ref": "#/definitions/domain.Badge"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     }
                 }
             }
         },
-        "/rooms/{code}/questions/{question_id}/close": {
-            "patch": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
+        "/healthz": {
+            "get": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "questions"
-                ],
-                "summary": "Host cierra la pregunta activa",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Código de sala",
-                        "name": "code",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "ID de pregunta",
-                        "name": "question_id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "health"
                 ],
+                "summary": "Liveness: el proceso está vivo",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -729,7 +652,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/rooms/{code}/ranking": {
+        "/leaderboards": {
             "get": {
                 "security": [
                     {
@@ -740,40 +663,21 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "scores"
-                ],
-                "summary": "Obtener ranking de sala",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Código de sala",
-                        "name": "code",
-                        "in": "path",
-                        "required": true
-                    }
+                    "leaderboards"
                 ],
+                "summary": "Listar mis leaderboards (host)",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "type": "object",
-                                "additionalProperties": true
-                            }
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
+                                "$ref": "#/definitions/domain.Leaderboard"
                             }
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -782,15 +686,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/rooms/{code}/score": {
+            },
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "Suma los puntos de varias salas propias. starts_at y ends_at (RFC 3339) limitan qué cambios de puntos cuentan; ends_at es exclusivo.",
                 "consumes": [
                     "application/json"
                 ],
@@ -798,35 +701,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "scores"
+                    "leaderboards"
                 ],
-                "summary": "Agregar puntos a participante",
+                "summary": "Crear leaderboard (host)",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Código de sala",
-                        "name": "code",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Datos de puntaje",
+                        "description": "Nombre, salas y rango de fechas",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/usecase.AddPointsInput"
+                            "$ref": "#/definitions/usecase.CreateLeaderboardInput"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain.Leaderboard"
                         }
                     },
                     "400": {
@@ -838,17 +731,94 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
-                    },
-                    "403": {
-                        "description": "Forbidden",
+                    }
+                }
+            }
+        },
+        "/leaderboards/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lo ven su host, los admins y quienes participan en alguna de sus salas.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "leaderboards"
+                ],
+                "summary": "Ver un leaderboard",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del leaderboard",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Leaderboard"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "leaderboards"
+                ],
+                "summary": "Borrar un leaderboard propio (host)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del leaderboard",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -859,13 +829,90 @@ const docTemplate = `{
                 }
             }
         },
-        "/rooms/{code}/score/reset": {
+        "/leaderboards/{id}/ranking": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Posiciones del periodo (semana ISO o mes en UTC) que contiene la fecha at, o el acumulado total, con el puesto de quien consulta en me.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "leaderboards"
+                ],
+                "summary": "Ranking de un leaderboard",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del leaderboard",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "all_time (por defecto), monthly o weekly",
+                        "name": "window",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Fecha dentro del periodo (YYYY-MM-DD); hoy por defecto",
+                        "name": "at",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Máximo de posiciones (50 por defecto, máx. 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Desplazamiento",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.LeaderboardRanking"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/leaderboards/{id}/rooms": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "Los puntos que la sala ya tiene en el historial se suman en el momento.",
                 "consumes": [
                     "application/json"
                 ],
@@ -873,30 +920,36 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "scores"
+                    "leaderboards"
                 ],
-                "summary": "Host resetea los puntos de un participante específico",
+                "summary": "Agregar una sala al leaderboard (host)",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Código de sala",
-                        "name": "code",
+                        "type": "integer",
+                        "description": "ID del leaderboard",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "user_id del participante",
+                        "description": "Código de sala",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/usecase.ResetUserPointsInput"
+                            "$ref": "#/definitions/usecase.LeaderboardRoomInput"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Leaderboard"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -907,21 +960,29 @@ const docTemplate = `{
                 }
             }
         },
-        "/rooms/{code}/score/reset-all": {
-            "post": {
+        "/leaderboards/{id}/rooms/{code}": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "Resta lo que la sala había aportado.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "scores"
+                    "leaderboards"
                 ],
-                "summary": "Host resetea los puntos de todos los participantes",
+                "summary": "Quitar una sala del leaderboard (host)",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del leaderboard",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "string",
                         "description": "Código de sala",
@@ -939,12 +1000,21 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
             }
         },
-        "/rooms/{code}/start": {
-            "patch": {
+        "/me": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
@@ -954,16 +1024,62 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "rooms"
+                    "me"
                 ],
-                "summary": "Iniciar sesión de sala",
+                "summary": "Ver mi cuenta",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.User"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Anonimiza nombre, email y contraseña. Las respuestas y puntos\nse conservan a nombre de \"Usuario eliminado\".",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "me"
+                ],
+                "summary": "Eliminar mi cuenta",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Código de sala",
-                        "name": "code",
-                        "in": "path",
-                        "required": true
+                        "description": "Contraseña para confirmar",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/usecase.DeleteAccountInput"
+                        }
                     }
                 ],
                 "responses": {
@@ -993,9 +1109,54 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "me"
+                ],
+                "summary": "Cambiar mi nombre",
+                "parameters": [
+                    {
+                        "description": "Nombre nuevo",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/usecase.UpdateProfileInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     },
-                    "403": {
-                        "description": "Forbidden",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1005,25 +1166,2559 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "domain.Answer": {
+        },
+        "/me/api-keys": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Incluye las revocadas; nunca devuelve la key completa",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "api-keys"
+                ],
+                "summary": "Listar mis API keys",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.APIKey"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "La key completa solo se devuelve en esta respuesta. Scopes:\nrooms:read, rooms:write, questions:read, questions:write, scores:read, scores:write",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "api-keys"
+                ],
+                "summary": "Crear API key",
+                "parameters": [
+                    {
+                        "description": "Nombre y scopes",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/usecase.CreateAPIKeyInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/usecase.CreatedAPIKey"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/me/api-keys/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "api-keys"
+                ],
+                "summary": "Revocar API key",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la API key",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/me/badges": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cada medalla se gana como mucho una vez por sala; las más recientes primero.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "me"
+                ],
+                "summary": "Mis medallas",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.UserBadge"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/me/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cada sala a la que me uní con puesto final, puntos, precisión y tiempo medio de respuesta, más el resumen y la tendencia de las últimas sesiones.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "me"
+                ],
+                "summary": "Mi historial de sesiones",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.History"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/me/password": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Requiere la contraseña actual",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "me"
+                ],
+                "summary": "Cambiar mi contraseña",
+                "parameters": [
+                    {
+                        "description": "Contraseña actual y nueva",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/usecase.ChangePasswordInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness: la base de datos responde y no se está apagando",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "El cuerpo es opcional; ranking_mode define cómo se numeran los empates (competition por defecto) y streak el premio por racha (none por defecto).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Crear sala",
+                "parameters": [
+                    {
+                        "description": "Modo de ranking",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/usecase.CreateRoomInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/usecase.CreateRoomOutput"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{code}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Obtener sala por código",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Código de sala",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{code}/answer": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "questions"
+                ],
+                "summary": "Participante envía su respuesta",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Código de sala",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Respuesta",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/usecase.SubmitAnswerInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/usecase.SubmitAnswerOutput"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{code}/end": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Finalizar sesión de sala",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Código de sala",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{code}/events": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Emite los mismos mensajes que /ws en el campo data, para redes que bloquean WebSocket o pantallas que solo reciben.\nEventSource no permite headers: el JWT puede ir en ?token=. Al reconectar, el navegador envía Last-Event-ID y se reenvían los eventos perdidos que sigan en el historial de la instancia; si el id no está, llega room_state con resync: true.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Eventos de la sala por Server-Sent Events (alternativa a /ws)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Código de sala",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Nombre a mostrar en la presencia",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "JWT, si no se puede enviar el header Authorization",
+                        "name": "token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Último id recibido",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "stream text/event-stream",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{code}/join": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Unirse a sala",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Código de sala",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{code}/kick": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Host expulsa a un participante",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Código de sala",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "user_id del participante a expulsar",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{code}/online": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Ver quién está conectado ahora mismo en la sala (vía WS)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Código de sala",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/websocket.ClientInfo"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{code}/participants": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Listar participantes de una sala",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Código de sala",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.ParticipantWithUser"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{code}/questions": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "questions"
+                ],
+                "summary": "Host lanza una pregunta a la sala",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Código de sala",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Datos de la pregunta",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/usecase.LaunchQuestionInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/usecase.LaunchQuestionOutput"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{code}/questions/current": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "questions"
+                ],
+                "summary": "Obtener la pregunta activa de una sala",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Código de sala",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/usecase.LaunchQuestionOutput"
+                        }
+                    },
+                    "204": {
+                        "description": "Sin pregunta activa"
+                    }
+                }
+            }
+        },
+        "/rooms/{code}/questions/{question_id}/answers": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "questions"
+                ],
+                "summary": "Host obtiene todas las respuestas de una pregunta",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Código de sala",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID de pregunta",
+                        "name": "question_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Answer"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{code}/questions/{question_id}/close": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "questions"
+                ],
+                "summary": "Host cierra la pregunta activa",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Código de sala",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID de pregunta",
+                        "name": "question_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{code}/questions/{question_id}/stats": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Respondieron / sin responder, porcentaje de aciertos, distribución de respuestas, incorrectas más comunes y percentiles del tiempo de respuesta.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "questions"
+                ],
+                "summary": "Host obtiene las estadísticas de una pregunta",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Código de sala",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID de pregunta",
+                        "name": "question_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.QuestionStats"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{code}/ranking": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ordenado por puesto; dentro de un empate, por menor tiempo total de respuesta, la última respuesta correcta más temprana y el ingreso a la sala más temprano.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scores"
+                ],
+                "summary": "Obtener ranking de sala",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Código de sala",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Máximo de posiciones (sin límite por defecto)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Desplazamiento",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.RankingEntry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{code}/ranking-mode": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "competition: 1, 2, 2, 4. dense: 1, 2, 2, 3. Emite score_update con el ranking renumerado.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scores"
+                ],
+                "summary": "Host cambia cómo se numeran los empates del ranking",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Código de sala",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Modo de ranking",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/usecase.RankingModeInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{code}/ranking/me": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scores"
+                ],
+                "summary": "Mi puesto en el ranking de la sala",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Código de sala",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.RankingEntry"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{code}/score": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scores"
+                ],
+                "summary": "Agregar puntos a participante",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Código de sala",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Datos de puntaje",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/usecase.AddPointsInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{code}/score/events": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cada cambio (manual, por respuesta, reset o undo) con quién lo hizo, el motivo y el delta, del más nuevo al más viejo.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scores"
+                ],
+                "summary": "Host lista el historial de cambios de puntos de la sala",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Código de sala",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Solo los de este participante",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Máximo de resultados (50 por defecto, máx. 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Desplazamiento",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.ScoreEvent"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{code}/score/events/{id}/undo": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Registra un evento undo con el delta opuesto y emite score_update. Cada evento se deshace una sola vez; un undo o un opening no se pueden deshacer.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scores"
+                ],
+                "summary": "Host deshace un cambio de puntos",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Código de sala",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID del evento",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Motivo (opcional)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/usecase.ReasonInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ScoreEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{code}/score/reconcile": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Iguala cada score a la suma de sus eventos y devuelve los que estaban desfasados.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scores"
+                ],
+                "summary": "Host recalcula los puntos de la sala desde el historial",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Código de sala",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/usecase.ReconcileOutput"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{code}/score/reset": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scores"
+                ],
+                "summary": "Host resetea los puntos de un participante específico",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Código de sala",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "user_id del participante",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/usecase.ResetUserPointsInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{code}/score/reset-all": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scores"
+                ],
+                "summary": "Host resetea los puntos de todos los participantes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Código de sala",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Motivo (opcional)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/usecase.ReasonInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{code}/start": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Iniciar sesión de sala",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Código de sala",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{code}/streak": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "none: sin premio. bonus: step puntos extra por cada acierto previo de la racha. multiplier: step % extra por cada acierto previo. max limita la racha que cuenta (0 = sin tope). Vale para las respuestas siguientes.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scores"
+                ],
+                "summary": "Host cambia el premio por racha de la sala",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Código de sala",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Premio por racha",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.StreakConfig"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.StreakConfig"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/badges": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Un host solo ve las ganadas en salas que creó; un admin ve todas.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Medallas de un usuario (host)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de usuario",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.UserBadge"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Un host solo ve las salas que creó; un admin ve todas.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Historial de sesiones de un usuario (host)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de usuario",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.History"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "domain.APIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "description": "inicio de la key, para reconocerla en el listado",
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Scope"
+                    }
+                }
+            }
+        },
+        "domain.Answer": {
+            "type": "object",
+            "properties": {
+                "answer": {
+                    "type": "string"
+                },
+                "answered_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_correct": {
+                    "type": "boolean"
+                },
+                "question_id": {
+                    "type": "integer"
+                },
+                "response_ms": {
+                    "description": "milisegundos desde que se lanzó la pregunta",
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.AnswerCount": {
+            "type": "object",
+            "properties": {
+                "answer": {
+                    "type": "string"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "is_correct": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.Badge": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "$ref": "#/definitions/domain.BadgeCode"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.BadgeCode": {
+            "type": "string",
+            "enum": [
+                "first_correct",
+                "streak_5",
+                "fastest_answer",
+                "perfect_session",
+                "comeback"
+            ],
+            "x-enum-comments": {
+                "BadgeComeback": "gana la sesión tras haber estado en la mitad de abajo",
+                "BadgeFastestAnswer": "la primera respuesta correcta de una pregunta",
+                "BadgeFirstCorrect": "primera respuesta correcta de su historia",
+                "BadgePerfectSession": "todas las preguntas de la sesión respondidas bien",
+                "BadgeStreak5": "5 respuestas correctas seguidas en una sala"
+            },
+            "x-enum-descriptions": [
+                "primera respuesta correcta de su historia",
+                "5 respuestas correctas seguidas en una sala",
+                "la primera respuesta correcta de una pregunta",
+                "todas las preguntas de la sesión respondidas bien",
+                "gana la sesión tras haber estado en la mitad de abajo"
+            ],
+            "x-enum-varnames": [
+                "BadgeFirstCorrect",
+                "BadgeStreak5",
+                "BadgeFastestAnswer",
+                "BadgePerfectSession",
+                "BadgeComeback"
+            ]
+        },
+        "domain.History": {
+            "type": "object",
+            "properties": {
+                "sessions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.SessionHistory"
+                    }
+                },
+                "summary": {
+                    "$ref": "#/definitions/domain.HistorySummary"
+                },
+                "trend": {
+                    "$ref": "#/definitions/domain.HistoryTrend"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.HistorySummary": {
+            "type": "object",
+            "properties": {
+                "accuracy": {
+                    "type": "number"
+                },
+                "answered": {
+                    "type": "integer"
+                },
+                "avg_points": {
+                    "type": "number"
+                },
+                "avg_rank": {
+                    "type": "number"
+                },
+                "avg_response_ms": {
+                    "type": "integer"
+                },
+                "best_rank": {
+                    "description": "0 si no hay sesiones",
+                    "type": "integer"
+                },
+                "correct": {
+                    "type": "integer"
+                },
+                "sessions": {
+                    "type": "integer"
+                },
+                "total_points": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.HistoryTrend": {
+            "type": "object",
+            "properties": {
+                "accuracy_change": {
+                    "type": "number"
+                },
+                "avg_rank_change": {
+                    "type": "number"
+                },
+                "response_ms_change": {
+                    "type": "integer"
+                },
+                "window": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.InviteCode": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "role": {
+                    "$ref": "#/definitions/domain.Role"
+                },
+                "uses_left": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.Leaderboard": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "ends_at": {
+                    "type": "string"
+                },
+                "host_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "rooms": {
+                    "description": "códigos de las salas incluidas",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "starts_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.LeaderboardEntry": {
+            "type": "object",
+            "properties": {
+                "points": {
+                    "type": "integer"
+                },
+                "position": {
+                    "description": "los empatados comparten puesto (1, 2, 2, 4)",
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "user_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.LeaderboardRanking": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.LeaderboardEntry"
+                    }
+                },
+                "leaderboard_id": {
+                    "type": "integer"
+                },
+                "me": {
+                    "description": "null si no tiene puntos en el periodo",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.LeaderboardEntry"
+                        }
+                    ]
+                },
+                "name": {
+                    "type": "string"
+                },
+                "period": {
+                    "description": "\"all\", \"2026-10\" o \"2026-W42\"",
+                    "type": "string"
+                },
+                "window": {
+                    "$ref": "#/definitions/domain.LeaderboardWindow"
+                }
+            }
+        },
+        "domain.LeaderboardWindow": {
+            "type": "string",
+            "enum": [
+                "all_time",
+                "monthly",
+                "weekly"
+            ],
+            "x-enum-varnames": [
+                "LeaderboardAllTime",
+                "LeaderboardMonthly",
+                "LeaderboardWeekly"
+            ]
+        },
+        "domain.ParticipantWithUser": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "joined_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "user_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.QuestionStats": {
+            "type": "object",
+            "properties": {
+                "answered": {
+                    "type": "integer"
+                },
+                "correct": {
+                    "type": "integer"
+                },
+                "distribution": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.AnswerCount"
+                    }
+                },
+                "not_answered": {
+                    "description": "participantes de la sala sin respuesta",
+                    "type": "integer"
+                },
+                "participants": {
+                    "type": "integer"
+                },
+                "percent_correct": {
+                    "type": "number"
+                },
+                "question_id": {
+                    "type": "integer"
+                },
+                "response_times": {
+                    "$ref": "#/definitions/domain.ResponseTimes"
+                },
+                "top_wrong": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.AnswerCount"
+                    }
+                }
+            }
+        },
+        "domain.QuestionStatus": {
+            "type": "string",
+            "enum": [
+                "open",
+                "closed"
+            ],
+            "x-enum-varnames": [
+                "QuestionStatusOpen",
+                "QuestionStatusClosed"
+            ]
+        },
+        "domain.RankingEntry": {
+            "type": "object",
+            "properties": {
+                "points": {
+                    "type": "integer"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "streak": {
+                    "description": "respuestas correctas seguidas",
+                    "type": "integer"
+                },
+                "total_response_ms": {
+                    "description": "suma de sus tiempos de respuesta en la sala",
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "user_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.RankingMode": {
+            "type": "string",
+            "enum": [
+                "competition",
+                "dense"
+            ],
+            "x-enum-comments": {
+                "RankingCompetition": "1, 2, 2, 4",
+                "RankingDense": "1, 2, 2, 3"
+            },
+            "x-enum-descriptions": [
+                "1, 2, 2, 4",
+                "1, 2, 2, 3"
+            ],
+            "x-enum-varnames": [
+                "RankingCompetition",
+                "RankingDense"
+            ]
+        },
+        "domain.ResponseTimes": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "median_ms": {
+                    "type": "integer"
+                },
+                "p75_ms": {
+                    "type": "integer"
+                },
+                "p90_ms": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.Role": {
+            "type": "string",
+            "enum": [
+                "admin",
+                "host",
+                "participant"
+            ],
+            "x-enum-varnames": [
+                "RoleAdmin",
+                "RoleHost",
+                "RoleParticipant"
+            ]
+        },
+        "domain.RoomStatus": {
+            "type": "string",
+            "enum": [
+                "waiting",
+                "active",
+                "finished"
+            ],
+            "x-enum-varnames": [
+                "RoomStatusWaiting",
+                "RoomStatusActive",
+                "RoomStatusFinished"
+            ]
+        },
+        "domain.Scope": {
+            "type": "string",
+            "enum": [
+                "rooms:read",
+                "rooms:write",
+                "questions:read",
+                "questions:write",
+                "scores:read",
+                "scores:write"
+            ],
+            "x-enum-varnames": [
+                "ScopeRoomsRead",
+                "ScopeRoomsWrite",
+                "ScopeQuestionsRead",
+                "ScopeQuestionsWrite",
+                "ScopeScoresRead",
+                "ScopeScoresWrite"
+            ]
+        },
+        "domain.ScoreDrift": {
+            "type": "object",
+            "properties": {
+                "ledger": {
+                    "description": "la suma de sus eventos, que pasa a ser el valor",
+                    "type": "integer"
+                },
+                "points": {
+                    "description": "lo que tenía scores",
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.ScoreEvent": {
+            "type": "object",
+            "properties": {
+                "actor_id": {
+                    "description": "0 si el actor ya no existe",
+                    "type": "integer"
+                },
+                "actor_name": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delta": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "question_id": {
+                    "description": "source = answer",
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "reverted_by": {
+                    "description": "evento undo que lo deshizo, si hay",
+                    "type": "integer"
+                },
+                "reverts_id": {
+                    "description": "source = undo: evento que deshace",
+                    "type": "integer"
+                },
+                "room_id": {
+                    "type": "integer"
+                },
+                "source": {
+                    "$ref": "#/definitions/domain.ScoreSource"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "user_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ScoreSource": {
+            "type": "string",
+            "enum": [
+                "manual",
+                "answer",
+                "reset",
+                "undo",
+                "opening"
+            ],
+            "x-enum-comments": {
+                "ScoreSourceAnswer": "respuesta correcta",
+                "ScoreSourceManual": "el host sumó o restó puntos",
+                "ScoreSourceOpening": "puntos previos al ledger, registrados al migrar",
+                "ScoreSourceReset": "reset de un participante, de la sala o expulsión",
+                "ScoreSourceUndo": "reversión de otro evento"
+            },
+            "x-enum-descriptions": [
+                "el host sumó o restó puntos",
+                "respuesta correcta",
+                "reset de un participante, de la sala o expulsión",
+                "reversión de otro evento",
+                "puntos previos al ledger, registrados al migrar"
+            ],
+            "x-enum-varnames": [
+                "ScoreSourceManual",
+                "ScoreSourceAnswer",
+                "ScoreSourceReset",
+                "ScoreSourceUndo",
+                "ScoreSourceOpening"
+            ]
+        },
+        "domain.SessionHistory": {
+            "type": "object",
+            "properties": {
+                "accuracy": {
+                    "description": "% de respuestas correctas",
+                    "type": "number"
+                },
+                "answered": {
+                    "type": "integer"
+                },
+                "avg_response_ms": {
+                    "description": "0 si no hay tiempos registrados",
+                    "type": "integer"
+                },
+                "correct": {
+                    "type": "integer"
+                },
+                "joined_at": {
+                    "type": "string"
+                },
+                "participants": {
+                    "type": "integer"
+                },
+                "points": {
+                    "type": "integer"
+                },
+                "questions": {
+                    "type": "integer"
+                },
+                "rank": {
+                    "description": "puesto por puntos según el ranking_mode de la sala",
+                    "type": "integer"
+                },
+                "room_code": {
+                    "type": "string"
+                },
+                "room_id": {
+                    "type": "integer"
+                },
+                "room_status": {
+                    "$ref": "#/definitions/domain.RoomStatus"
+                }
+            }
+        },
+        "domain.StreakConfig": {
             "type": "object",
             "properties": {
-                "answer": {
+                "max": {
+                    "description": "racha a partir de la cual el premio deja de crecer; 0 = sin tope",
+                    "type": "integer"
+                },
+                "mode": {
+                    "$ref": "#/definitions/domain.StreakMode"
+                },
+                "step": {
+                    "description": "puntos (bonus) o porcentaje (multiplier) por nivel",
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.StreakMode": {
+            "type": "string",
+            "enum": [
+                "none",
+                "bonus",
+                "multiplier"
+            ],
+            "x-enum-comments": {
+                "StreakBonus": "Step puntos extra por cada acierto previo de la racha",
+                "StreakMultiplier": "Step % extra por cada acierto previo de la racha",
+                "StreakNone": "sin premio"
+            },
+            "x-enum-descriptions": [
+                "sin premio",
+                "Step puntos extra por cada acierto previo de la racha",
+                "Step % extra por cada acierto previo de la racha"
+            ],
+            "x-enum-varnames": [
+                "StreakNone",
+                "StreakBonus",
+                "StreakMultiplier"
+            ]
+        },
+        "domain.User": {
+            "type": "object",
+            "properties": {
+                "created_at": {
                     "type": "string"
                 },
-                "answered_at": {
+                "email": {
                     "type": "string"
                 },
+                "email_verified": {
+                    "type": "boolean"
+                },
+                "host_requested": {
+                    "description": "pidió ser host al registrarse y espera aprobación",
+                    "type": "boolean"
+                },
                 "id": {
                     "type": "integer"
                 },
-                "is_correct": {
-                    "type": "boolean"
+                "name": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/domain.Role"
+                }
+            }
+        },
+        "domain.UserBadge": {
+            "type": "object",
+            "properties": {
+                "badge": {
+                    "$ref": "#/definitions/domain.BadgeCode"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "earned_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
                 },
                 "question_id": {
+                    "description": "la que la otorgó, en las medallas por respuesta",
+                    "type": "integer"
+                },
+                "room_code": {
+                    "type": "string"
+                },
+                "room_id": {
                     "type": "integer"
                 },
                 "user_id": {
@@ -1031,57 +3726,179 @@ const docTemplate = `{
                 }
             }
         },
-        "domain.ParticipantWithUser": {
+        "usecase.AddPointsInput": {
             "type": "object",
             "properties": {
-                "email": {
+                "delta": {
+                    "description": "positivo o negativo",
+                    "type": "integer"
+                },
+                "reason": {
+                    "description": "queda en el historial de puntos",
                     "type": "string"
                 },
-                "joined_at": {
+                "room_code": {
                     "type": "string"
                 },
-                "user_id": {
+                "target_user_id": {
                     "type": "integer"
+                }
+            }
+        },
+        "usecase.ChangePasswordInput": {
+            "type": "object",
+            "properties": {
+                "current_password": {
+                    "type": "string"
                 },
-                "user_name": {
+                "new_password": {
                     "type": "string"
                 }
             }
         },
-        "domain.QuestionStatus": {
-            "type": "string",
-            "enum": [
-                "open",
-                "closed"
-            ],
-            "x-enum-varnames": [
-                "QuestionStatusOpen",
-                "QuestionStatusClosed"
-            ]
+        "usecase.ChangeRoleInput": {
+            "type": "object",
+            "properties": {
+                "role": {
+                    "$ref": "#/definitions/domain.Role"
+                }
+            }
         },
-        "domain.Role": {
-            "type": "string",
-            "enum": [
-                "host",
-                "participant"
-            ],
-            "x-enum-varnames": [
-                "RoleHost",
-                "RoleParticipant"
-            ]
+        "usecase.CreateAPIKeyInput": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Scope"
+                    }
+                }
+            }
         },
-        "usecase.AddPointsInput": {
+        "usecase.CreateInviteInput": {
             "type": "object",
             "properties": {
-                "delta": {
-                    "description": "positivo o negativo",
+                "expires_in_hours": {
                     "type": "integer"
                 },
-                "room_code": {
+                "max_uses": {
+                    "type": "integer"
+                },
+                "role": {
+                    "$ref": "#/definitions/domain.Role"
+                }
+            }
+        },
+        "usecase.CreateLeaderboardInput": {
+            "type": "object",
+            "properties": {
+                "ends_at": {
+                    "description": "RFC 3339, exclusivo; sin él no termina",
                     "type": "string"
                 },
-                "target_user_id": {
+                "name": {
+                    "type": "string"
+                },
+                "rooms": {
+                    "description": "códigos de sala propios",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "starts_at": {
+                    "description": "RFC 3339; sin él cuenta desde siempre",
+                    "type": "string"
+                }
+            }
+        },
+        "usecase.CreateRoomInput": {
+            "type": "object",
+            "properties": {
+                "ranking_mode": {
+                    "description": "competition (por defecto) o dense",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.RankingMode"
+                        }
+                    ]
+                },
+                "streak": {
+                    "description": "premio por racha; sin él, none",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.StreakConfig"
+                        }
+                    ]
+                }
+            }
+        },
+        "usecase.CreateRoomOutput": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "ranking_mode": {
+                    "$ref": "#/definitions/domain.RankingMode"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.RoomStatus"
+                },
+                "streak": {
+                    "$ref": "#/definitions/domain.StreakConfig"
+                }
+            }
+        },
+        "usecase.CreatedAPIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
                     "type": "integer"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "description": "inicio de la key, para reconocerla en el listado",
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Scope"
+                    }
+                }
+            }
+        },
+        "usecase.DeleteAccountInput": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "usecase.ForgotPasswordInput": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
                 }
             }
         },
@@ -1119,6 +3936,14 @@ const docTemplate = `{
                 }
             }
         },
+        "usecase.LeaderboardRoomInput": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
         "usecase.LoginInput": {
             "type": "object",
             "properties": {
@@ -1130,12 +3955,42 @@ const docTemplate = `{
                 }
             }
         },
+        "usecase.RankingModeInput": {
+            "type": "object",
+            "properties": {
+                "ranking_mode": {
+                    "$ref": "#/definitions/domain.RankingMode"
+                }
+            }
+        },
+        "usecase.ReasonInput": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "usecase.ReconcileOutput": {
+            "type": "object",
+            "properties": {
+                "fixed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ScoreDrift"
+                    }
+                }
+            }
+        },
         "usecase.RegisterInput": {
             "type": "object",
             "properties": {
                 "email": {
                     "type": "string"
                 },
+                "invite_code": {
+                    "type": "string"
+                },
                 "name": {
                     "type": "string"
                 },
@@ -1147,9 +4002,23 @@ const docTemplate = `{
                 }
             }
         },
+        "usecase.ResetPasswordInput": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
         "usecase.ResetUserPointsInput": {
             "type": "object",
             "properties": {
+                "reason": {
+                    "type": "string"
+                },
                 "room_code": {
                     "type": "string"
                 },
@@ -1179,10 +4048,35 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "points_earned": {
+                    "description": "con el premio por racha incluido",
+                    "type": "integer"
+                },
+                "streak": {
+                    "description": "respuestas correctas seguidas en la sala",
+                    "type": "integer"
+                },
+                "streak_bonus": {
+                    "description": "la parte de points_earned que aporta la racha",
                     "type": "integer"
                 }
             }
         },
+        "usecase.UpdateProfileInput": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "usecase.VerifyEmailInput": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
         "websocket.ClientInfo": {
             "type": "object",
             "properties": {
@@ -1192,6 +4086,10 @@ const docTemplate = `{
                 "role": {
                     "type": "string"
                 },
+                "status": {
+                    "description": "online, idle o disconnected",
+                    "type": "string"
+                },
                 "user_id": {
                     "type": "integer"
                 }
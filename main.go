@@ -1,16 +1,30 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
 	"apiGolan/src/applications/usecase"
 	"apiGolan/src/core"
+	"apiGolan/src/domain"
+	"apiGolan/src/infrastructure/broker"
 	infradb "apiGolan/src/infrastructure/db"
 	"apiGolan/src/infrastructure/http/handler"
 	"apiGolan/src/infrastructure/http/middleware"
 	"apiGolan/src/infrastructure/http/router"
+	jwtutil "apiGolan/src/infrastructure/jwt"
+	"apiGolan/src/infrastructure/logger"
+	"apiGolan/src/infrastructure/mailer"
+	"apiGolan/src/infrastructure/metrics"
+	"apiGolan/src/infrastructure/oidc"
+	"apiGolan/src/infrastructure/ratelimit"
 	"apiGolan/src/infrastructure/repository"
 	"apiGolan/src/infrastructure/websocket"
 )
@@ -25,12 +39,20 @@ import (
 // @in header
 // @name Authorization
 func main() {
+	logger.Setup()
+
+	// Claves JWT: fuera de APP_ENV=development es obligatorio JWT_KEYS_DIR
+	if err := jwtutil.Setup(); err != nil {
+		slog.Error("No se pudieron cargar las claves JWT", "error", err)
+		os.Exit(1)
+	}
+
 	db, err := infradb.Connect()
 	if err != nil {
-		log.Fatal("No se pudo conectar a la base de datos:", err)
+		slog.Error("No se pudo conectar a la base de datos", "error", err)
+		os.Exit(1)
 	}
-	defer db.Close()
-	log.Println("Conexión a MySQL exitosa")
+	slog.Info("Conexión a la base de datos exitosa", "driver", db.Driver)
 
 	// Repositorios
 	userRepo := repository.NewUserRepo(db)
@@ -39,35 +61,193 @@ func main() {
 	scoreRepo := repository.NewScoreRepo(db)
 	questionRepo := repository.NewQuestionRepo(db)
 	answerRepo := repository.NewAnswerRepo(db)
+	tokenRepo := repository.NewTokenRepo(db)
+	inviteRepo := repository.NewInviteRepo(db)
+	identityRepo := repository.NewIdentityRepo(db)
+	apiKeyRepo := repository.NewAPIKeyRepo(db)
+	historyRepo := repository.NewHistoryRepo(db)
+	leaderboardRepo := repository.NewLeaderboardRepo(db)
+	badgeRepo := repository.NewBadgeRepo(db)
+	transactor := repository.NewTransactor(db)
+
+	// Correo saliente
+	mail, err := mailer.New()
+	if err != nil {
+		slog.Error("No se pudo configurar el correo", "error", err)
+		os.Exit(1)
+	}
+
+	// SSO con OpenID Connect (opcional, OIDC_ISSUER)
+	oidcProvider, err := oidc.New(context.Background())
+	if err != nil {
+		slog.Error("No se pudo configurar el proveedor OIDC", "error", err)
+		os.Exit(1)
+	}
+	oidcDefaultRole := domain.Role(getEnv("OIDC_DEFAULT_ROLE", string(domain.RoleParticipant)))
+	if oidcDefaultRole != domain.RoleParticipant && oidcDefaultRole != domain.RoleHost {
+		slog.Error("OIDC_DEFAULT_ROLE debe ser participant o host", "role", oidcDefaultRole)
+		os.Exit(1)
+	}
 
 	// Servicios (core)
-	userService := core.NewUserService(userRepo)
+	appURL := getEnv("APP_URL", "http://localhost:5173")
+	userService := core.NewUserService(userRepo, tokenRepo, inviteRepo, transactor, mail, appURL, core.DefaultLoginLockout)
 	roomService := core.NewRoomService(roomRepo, participantRepo, scoreRepo)
-	scoreService := core.NewScoreService(scoreRepo, roomRepo)
-	questionService := core.NewQuestionService(questionRepo, answerRepo, scoreRepo, roomRepo)
+	// RANKING_BROADCAST_SIZE: posiciones que viajan en score_update y room_state (0 = todas)
+	rankingTop := 20
+	if n, err := strconv.Atoi(os.Getenv("RANKING_BROADCAST_SIZE")); err == nil && n >= 0 {
+		rankingTop = n
+	}
+	scoreService := core.NewScoreService(scoreRepo, roomRepo, rankingTop)
+	questionService := core.NewQuestionService(questionRepo, answerRepo, scoreRepo, roomRepo, transactor)
+	apiKeyService := core.NewAPIKeyService(apiKeyRepo, userRepo)
+	historyService := core.NewHistoryService(historyRepo, userRepo)
+	leaderboardService := core.NewLeaderboardService(leaderboardRepo, roomRepo, transactor)
+	badgeService := core.NewBadgeService(badgeRepo, roomRepo, userRepo)
+
+	// Admin inicial: ADMIN_EMAIL se crea (con ADMIN_PASSWORD) o se asciende al arrancar
+	if adminEmail := os.Getenv("ADMIN_EMAIL"); adminEmail != "" {
+		if err := userService.EnsureAdmin(context.Background(), adminEmail, os.Getenv("ADMIN_PASSWORD")); err != nil {
+			slog.Error("No se pudo preparar el admin inicial", "error", err)
+			os.Exit(1)
+		}
+	}
 
 	// Casos de uso (application)
 	authUC := usecase.NewAuthUseCase(userService)
+	userUC := usecase.NewUserUseCase(userService)
+	adminUC := usecase.NewAdminUseCase(userService)
+	apiKeyUC := usecase.NewAPIKeyUseCase(apiKeyService)
+	historyUC := usecase.NewHistoryUseCase(historyService)
+	leaderboardUC := usecase.NewLeaderboardUseCase(leaderboardService)
+	badgeUC := usecase.NewBadgeUseCase(badgeService)
 	roomUC := usecase.NewRoomUseCase(roomService)
 	scoreUC := usecase.NewScoreUseCase(scoreService)
 	questionUC := usecase.NewQuestionUseCase(questionService)
+	roomStateUC := usecase.NewRoomStateUseCase(roomService, questionService, scoreService)
 
 	// WebSocket Hub
-	hub := websocket.NewHub()
+	wsConfig := websocket.DefaultConfig()
+	wsConfig.PingInterval = getDuration("WS_PING_INTERVAL", wsConfig.PingInterval)
+	wsConfig.PongWait = getDuration("WS_PONG_TIMEOUT", wsConfig.PongWait)
+	wsConfig.IdleAfter = getDuration("WS_IDLE_AFTER", wsConfig.IdleAfter)
+	wsConfig.DisconnectGrace = getDuration("WS_DISCONNECT_GRACE", wsConfig.DisconnectGrace)
+	if n, err := strconv.ParseInt(os.Getenv("WS_MAX_MESSAGE_SIZE"), 10, 64); err == nil && n > 0 {
+		wsConfig.MaxMessageSize = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("WS_SEND_BUFFER")); err == nil && n > 0 {
+		wsConfig.SendBuffer = n
+	}
+	if v := os.Getenv("WS_SLOW_CLIENT_POLICY"); v != "" {
+		policy, err := websocket.ParsePolicy(v)
+		if err != nil {
+			slog.Error("configuración de websocket inválida", "error", err)
+			os.Exit(1)
+		}
+		wsConfig.SlowClientPolicy = policy
+	}
+	if wsConfig.PingInterval >= wsConfig.PongWait {
+		slog.Error("WS_PING_INTERVAL debe ser menor que WS_PONG_TIMEOUT")
+		os.Exit(1)
+	}
+
+	// Con REDIS_URL los broadcasts y la presencia se comparten entre instancias
+	var wsBroker websocket.Broker = websocket.NewLocalBroker()
+	if redisURL := os.Getenv("REDIS_URL"); redisURL != "" {
+		redisBroker, err := broker.NewRedis(context.Background(), redisURL)
+		if err != nil {
+			slog.Error("error al conectar el broker de websocket", "error", err)
+			os.Exit(1)
+		}
+		wsBroker = redisBroker
+		slog.Info("WebSocket multi-instancia con Redis")
+	}
+	hub := websocket.NewHub(wsConfig, wsBroker)
+	hub.SetRoomState(func(ctx context.Context, code string, userID int) (interface{}, error) {
+		return roomStateUC.GetRoomState(ctx, code, userID)
+	})
+
+	// Rate limiting: RATE_LIMIT_BACKEND=memory (token bucket, por defecto) o
+	// store (ventana fija sobre un Store con la interfaz de Redis; hoy MemoryStore)
+	rateLimitBackend := getEnv("RATE_LIMIT_BACKEND", "memory")
+	rateLimitStore := ratelimit.NewMemoryStore()
+	newLimiter := func(name string, limit int, per time.Duration) ratelimit.Limiter {
+		if rateLimitBackend == "store" {
+			return ratelimit.NewStoreLimiter(rateLimitStore, name, limit, per)
+		}
+		return ratelimit.NewTokenBucket(limit, per)
+	}
+	limits := router.Limits{
+		Login:    newLimiter("login_ip", 20, time.Minute),
+		Register: newLimiter("register_ip", 5, time.Minute),
+		Forgot:   newLimiter("forgot_ip", 5, time.Minute),
+		Answer:   newLimiter("answer_user", 10, 10*time.Second),
+	}
 
 	// Handlers HTTP
-	authHandler := handler.NewAuthHandler(authUC)
-	roomHandler := handler.NewRoomHandler(roomUC, hub)
+	authHandler := handler.NewAuthHandler(authUC, newLimiter("login_email", 5, time.Minute))
+	userHandler := handler.NewUserHandler(userUC)
+	roomHandler := handler.NewRoomHandler(roomUC, badgeUC, hub)
 	scoreHandler := handler.NewScoreHandler(scoreUC, hub)
-	questionHandler := handler.NewQuestionHandler(questionUC, hub)
+	questionHandler := handler.NewQuestionHandler(questionUC, badgeUC, hub)
+	healthHandler := handler.NewHealthHandler(db, hub)
+	adminHandler := handler.NewAdminHandler(adminUC)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyUC)
+	historyHandler := handler.NewHistoryHandler(historyUC)
+	leaderboardHandler := handler.NewLeaderboardHandler(leaderboardUC)
+	badgeHandler := handler.NewBadgeHandler(badgeUC)
+	var oidcHandler *handler.OIDCHandler
+	if oidcProvider != nil {
+		ssoService := core.NewSSOService(userRepo, identityRepo, oidcProvider, oidcDefaultRole)
+		oidcHandler = handler.NewOIDCHandler(usecase.NewSSOUseCase(ssoService), appURL)
+		slog.Info("SSO OIDC activado", "issuer", os.Getenv("OIDC_ISSUER"))
+	}
+
+	// Métricas
+	metrics.Register(db.DB, hub)
 
 	// Router
-	mux := router.Setup(authHandler, roomHandler, scoreHandler, questionHandler, hub)
-	handlerWithCORS := middleware.CORS(mux)
+	mux := router.Setup(
+		authHandler, userHandler, roomHandler, scoreHandler, questionHandler,
+		healthHandler, adminHandler, oidcHandler, apiKeyHandler, historyHandler,
+		leaderboardHandler, badgeHandler, apiKeyService, userService, hub, limits,
+	)
+	handlerWithCORS := middleware.CORS(
+		middleware.RequestID(middleware.AccessLog(middleware.Metrics(mux))),
+	)
 
 	port := getEnv("PORT", "8080")
-	log.Printf("API corriendo en http://localhost:%s", port)
-	log.Fatal(http.ListenAndServe(":"+port, handlerWithCORS))
+	srv := &http.Server{
+		Addr:    ":" + port,
+		Handler: handlerWithCORS,
+	}
+
+	go func() {
+		slog.Info("API corriendo", "addr", "http://localhost:"+port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("error del servidor HTTP", "error", err)
+			os.Exit(1)
+		}
+	}()
+
+	// Esperar SIGINT/SIGTERM y apagar en orden:
+	// WebSockets → peticiones HTTP en curso → base de datos
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	<-ctx.Done()
+	stop()
+	slog.Info("Apagando servidor...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), getDuration("SHUTDOWN_TIMEOUT", 15*time.Second))
+	defer cancel()
+
+	hub.Shutdown(shutdownCtx, getDuration("SHUTDOWN_RETRY_AFTER", 5*time.Second))
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("error al cerrar el servidor HTTP", "error", err)
+	}
+	if err := db.Close(); err != nil {
+		slog.Error("error al cerrar la base de datos", "error", err)
+	}
+	slog.Info("Servidor detenido")
 }
 
 func getEnv(key, def string) string {
@@ -76,3 +256,12 @@ func getEnv(key, def string) string {
 	}
 	return def
 }
+
+func getDuration(key string, def time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return def
+}
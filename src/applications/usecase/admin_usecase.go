@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"apiGolan/src/core"
+	"apiGolan/src/domain"
+)
+
+// AdminUseCase agrupa las operaciones del panel de administración
+type AdminUseCase struct {
+	userService *core.UserService
+}
+
+func NewAdminUseCase(userService *core.UserService) *AdminUseCase {
+	return &AdminUseCase{userService: userService}
+}
+
+type ChangeRoleInput struct {
+	Role domain.Role `json:"role"`
+}
+
+// CreateInviteInput configura un código de invitación.
+// ExpiresInHours 0 = no vence; MaxUses 0 = un solo uso.
+type CreateInviteInput struct {
+	Role           domain.Role `json:"role"`
+	MaxUses        int         `json:"max_uses"`
+	ExpiresInHours int         `json:"expires_in_hours"`
+}
+
+func (uc *AdminUseCase) ListUsers(ctx context.Context, filter domain.UserFilter) ([]domain.User, error) {
+	return uc.userService.ListUsers(ctx, filter)
+}
+
+func (uc *AdminUseCase) ChangeRole(ctx context.Context, adminID, userID int, input ChangeRoleInput) (*domain.User, error) {
+	return uc.userService.ChangeRole(ctx, adminID, userID, input.Role)
+}
+
+func (uc *AdminUseCase) CreateInvite(ctx context.Context, adminID int, input CreateInviteInput) (*domain.InviteCode, error) {
+	ttl := time.Duration(input.ExpiresInHours) * time.Hour
+	return uc.userService.CreateInvite(ctx, adminID, input.Role, input.MaxUses, ttl)
+}
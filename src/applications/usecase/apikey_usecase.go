@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	"context"
+
+	"apiGolan/src/core"
+	"apiGolan/src/domain"
+)
+
+type APIKeyUseCase struct {
+	apiKeyService *core.APIKeyService
+}
+
+func NewAPIKeyUseCase(apiKeyService *core.APIKeyService) *APIKeyUseCase {
+	return &APIKeyUseCase{apiKeyService: apiKeyService}
+}
+
+type CreateAPIKeyInput struct {
+	Name   string         `json:"name"`
+	Scopes []domain.Scope `json:"scopes"`
+}
+
+// CreatedAPIKey es la key recién creada; Key solo se devuelve esta vez
+type CreatedAPIKey struct {
+	Key string `json:"key"`
+	*domain.APIKey
+}
+
+func (uc *APIKeyUseCase) Create(ctx context.Context, userID int, input CreateAPIKeyInput) (*CreatedAPIKey, error) {
+	secret, key, err := uc.apiKeyService.Create(ctx, userID, input.Name, input.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	return &CreatedAPIKey{Key: secret, APIKey: key}, nil
+}
+
+func (uc *APIKeyUseCase) List(ctx context.Context, userID int) ([]domain.APIKey, error) {
+	return uc.apiKeyService.List(ctx, userID)
+}
+
+func (uc *APIKeyUseCase) Revoke(ctx context.Context, userID, keyID int) error {
+	return uc.apiKeyService.Revoke(ctx, userID, keyID)
+}
@@ -1,8 +1,10 @@
 package usecase
 
 import (
-"apiGolan/src/core"
-"apiGolan/src/domain"
+	"context"
+
+	"apiGolan/src/core"
+	"apiGolan/src/domain"
 )
 
 type AuthUseCase struct {
@@ -13,11 +15,14 @@ func NewAuthUseCase(userService *core.UserService) *AuthUseCase {
 	return &AuthUseCase{userService: userService}
 }
 
+// RegisterInput son los datos de registro. Role "host" sin InviteCode crea
+// un participante pendiente de aprobación; con InviteCode se usa el rol de la invitación.
 type RegisterInput struct {
-	Name     string      `json:"name"`
-	Email    string      `json:"email"`
-	Password string      `json:"password"`
-	Role     domain.Role `json:"role"`
+	Name       string      `json:"name"`
+	Email      string      `json:"email"`
+	Password   string      `json:"password"`
+	Role       domain.Role `json:"role"`
+	InviteCode string      `json:"invite_code,omitempty"`
 }
 
 type LoginInput struct {
@@ -25,13 +30,42 @@ type LoginInput struct {
 	Password string `json:"password"`
 }
 
-func (uc *AuthUseCase) Register(input RegisterInput) (*domain.User, error) {
-	if input.Role == "" {
-		input.Role = domain.RoleParticipant
-	}
-	return uc.userService.Register(input.Name, input.Email, input.Password, input.Role)
+func (uc *AuthUseCase) Register(ctx context.Context, input RegisterInput) (*domain.User, error) {
+	return uc.userService.Register(ctx, input.Name, input.Email, input.Password, input.Role, input.InviteCode)
+}
+
+func (uc *AuthUseCase) Login(ctx context.Context, input LoginInput) (*domain.User, error) {
+	return uc.userService.Login(ctx, input.Email, input.Password)
+}
+
+// ForgotPasswordInput es el email de la cuenta que quiere recuperar la contraseña
+type ForgotPasswordInput struct {
+	Email string `json:"email"`
+}
+
+// ResetPasswordInput es el token recibido por correo y la contraseña nueva
+type ResetPasswordInput struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// VerifyEmailInput es el token de verificación recibido por correo
+type VerifyEmailInput struct {
+	Token string `json:"token"`
+}
+
+func (uc *AuthUseCase) ForgotPassword(ctx context.Context, input ForgotPasswordInput) error {
+	return uc.userService.RequestPasswordReset(ctx, input.Email)
+}
+
+func (uc *AuthUseCase) ResetPassword(ctx context.Context, input ResetPasswordInput) error {
+	return uc.userService.ResetPassword(ctx, input.Token, input.Password)
+}
+
+func (uc *AuthUseCase) VerifyEmail(ctx context.Context, input VerifyEmailInput) error {
+	return uc.userService.VerifyEmail(ctx, input.Token)
 }
 
-func (uc *AuthUseCase) Login(input LoginInput) (*domain.User, error) {
-	return uc.userService.Login(input.Email, input.Password)
+func (uc *AuthUseCase) ResendVerification(ctx context.Context, userID int) error {
+	return uc.userService.ResendVerification(ctx, userID)
 }
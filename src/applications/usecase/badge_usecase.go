@@ -0,0 +1,46 @@
+package usecase
+
+import (
+	"context"
+
+	"apiGolan/src/core"
+	"apiGolan/src/domain"
+)
+
+// BadgeUseCase orquesta la evaluación y consulta de medallas
+type BadgeUseCase struct {
+	badgeService *core.BadgeService
+}
+
+func NewBadgeUseCase(badgeService *core.BadgeService) *BadgeUseCase {
+	return &BadgeUseCase{badgeService: badgeService}
+}
+
+// OnAnswer se llama después de registrar una respuesta; devuelve las medallas ganadas
+func (uc *BadgeUseCase) OnAnswer(ctx context.Context, input SubmitAnswerInput, output *SubmitAnswerOutput) ([]domain.UserBadge, error) {
+	return uc.badgeService.OnAnswer(ctx, input.RoomCode, domain.AnswerEvent{
+		UserID:     input.UserID,
+		QuestionID: input.QuestionID,
+		IsCorrect:  output.IsCorrect,
+		Streak:     output.Streak,
+	})
+}
+
+// OnSessionEnd se llama después de terminar la sesión; devuelve las medallas ganadas
+func (uc *BadgeUseCase) OnSessionEnd(ctx context.Context, code string) ([]domain.UserBadge, error) {
+	return uc.badgeService.OnSessionEnd(ctx, code)
+}
+
+func (uc *BadgeUseCase) Catalog() []domain.Badge {
+	return domain.Badges
+}
+
+func (uc *BadgeUseCase) GetMyBadges(ctx context.Context, userID int) ([]domain.UserBadge, error) {
+	return uc.badgeService.GetMyBadges(ctx, userID)
+}
+
+// GetUserBadges devuelve las medallas de userID vistas por requesterID;
+// los admins ven todas y los hosts solo las ganadas en sus salas
+func (uc *BadgeUseCase) GetUserBadges(ctx context.Context, requesterID int, requesterRole domain.Role, userID int) ([]domain.UserBadge, error) {
+	return uc.badgeService.GetUserBadges(ctx, requesterID, userID, requesterRole == domain.RoleAdmin)
+}
@@ -0,0 +1,27 @@
+package usecase
+
+import (
+	"context"
+
+	"apiGolan/src/core"
+	"apiGolan/src/domain"
+)
+
+// HistoryUseCase orquesta las consultas de historial de sesiones
+type HistoryUseCase struct {
+	historyService *core.HistoryService
+}
+
+func NewHistoryUseCase(historyService *core.HistoryService) *HistoryUseCase {
+	return &HistoryUseCase{historyService: historyService}
+}
+
+func (uc *HistoryUseCase) GetMyHistory(ctx context.Context, userID int) (*domain.History, error) {
+	return uc.historyService.GetMyHistory(ctx, userID)
+}
+
+// GetUserHistory devuelve el historial de userID visto por requesterID;
+// los admins ven todas las salas y los hosts solo las suyas
+func (uc *HistoryUseCase) GetUserHistory(ctx context.Context, requesterID int, requesterRole domain.Role, userID int) (*domain.History, error) {
+	return uc.historyService.GetUserHistory(ctx, requesterID, userID, requesterRole == domain.RoleAdmin)
+}
@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"apiGolan/src/core"
+	"apiGolan/src/domain"
+)
+
+// LeaderboardUseCase orquesta los leaderboards entre salas
+type LeaderboardUseCase struct {
+	leaderboardService *core.LeaderboardService
+}
+
+func NewLeaderboardUseCase(leaderboardService *core.LeaderboardService) *LeaderboardUseCase {
+	return &LeaderboardUseCase{leaderboardService: leaderboardService}
+}
+
+// CreateLeaderboardInput define un leaderboard: nombre, salas y rango de fechas opcional
+type CreateLeaderboardInput struct {
+	Name     string     `json:"name"`
+	Rooms    []string   `json:"rooms"`               // códigos de sala propios
+	StartsAt *time.Time `json:"starts_at,omitempty"` // RFC 3339; sin él cuenta desde siempre
+	EndsAt   *time.Time `json:"ends_at,omitempty"`   // RFC 3339, exclusivo; sin él no termina
+}
+
+// LeaderboardRoomInput es la sala a agregar
+type LeaderboardRoomInput struct {
+	Code string `json:"code"`
+}
+
+// LeaderboardRankingInput elige la página y el periodo del ranking
+type LeaderboardRankingInput struct {
+	Window domain.LeaderboardWindow
+	At     time.Time // fecha dentro del periodo; ahora si es cero
+	Limit  int
+	Offset int
+}
+
+func (uc *LeaderboardUseCase) Create(ctx context.Context, hostID int, input CreateLeaderboardInput) (*domain.Leaderboard, error) {
+	return uc.leaderboardService.Create(ctx, hostID, input.Name, input.StartsAt, input.EndsAt, input.Rooms)
+}
+
+func (uc *LeaderboardUseCase) List(ctx context.Context, hostID int) ([]domain.Leaderboard, error) {
+	return uc.leaderboardService.List(ctx, hostID)
+}
+
+// Get devuelve el leaderboard visto por userID; los admins ven todos
+func (uc *LeaderboardUseCase) Get(ctx context.Context, id, userID int, role domain.Role) (*domain.Leaderboard, error) {
+	return uc.leaderboardService.Get(ctx, id, userID, role == domain.RoleAdmin)
+}
+
+func (uc *LeaderboardUseCase) Delete(ctx context.Context, id, hostID int) error {
+	return uc.leaderboardService.Delete(ctx, id, hostID)
+}
+
+func (uc *LeaderboardUseCase) AddRoom(ctx context.Context, id, hostID int, input LeaderboardRoomInput) (*domain.Leaderboard, error) {
+	return uc.leaderboardService.AddRoom(ctx, id, hostID, input.Code)
+}
+
+func (uc *LeaderboardUseCase) RemoveRoom(ctx context.Context, id, hostID int, code string) error {
+	return uc.leaderboardService.RemoveRoom(ctx, id, hostID, code)
+}
+
+func (uc *LeaderboardUseCase) GetRanking(ctx context.Context, id, userID int, role domain.Role, input LeaderboardRankingInput) (*domain.LeaderboardRanking, error) {
+	at := input.At
+	if at.IsZero() {
+		at = time.Now()
+	}
+	return uc.leaderboardService.GetRanking(ctx, id, userID, role == domain.RoleAdmin, input.Window, at, input.Limit, input.Offset)
+}
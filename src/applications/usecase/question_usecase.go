@@ -1,6 +1,8 @@
 package usecase
 
 import (
+	"context"
+
 	"apiGolan/src/core"
 	"apiGolan/src/domain"
 )
@@ -26,11 +28,11 @@ type LaunchQuestionInput struct {
 // LaunchQuestionOutput es lo que se devuelve al lanzar una pregunta
 // No incluye la respuesta correcta para no exponerla al cliente
 type LaunchQuestionOutput struct {
-	ID       int                    `json:"id"`
-	RoomID   int                    `json:"room_id"`
-	Text     string                 `json:"text"`
-	Points   int                    `json:"points"`
-	Status   domain.QuestionStatus  `json:"status"`
+	ID     int                   `json:"id"`
+	RoomID int                   `json:"room_id"`
+	Text   string                `json:"text"`
+	Points int                   `json:"points"`
+	Status domain.QuestionStatus `json:"status"`
 }
 
 // SubmitAnswerInput son los datos que envía un participante al responder
@@ -44,12 +46,20 @@ type SubmitAnswerInput struct {
 // SubmitAnswerOutput es el resultado de evaluar la respuesta
 type SubmitAnswerOutput struct {
 	IsCorrect    bool   `json:"is_correct"`
-	PointsEarned int    `json:"points_earned"`
+	PointsEarned int    `json:"points_earned"` // con el premio por racha incluido
+	StreakBonus  int    `json:"streak_bonus"`  // la parte de points_earned que aporta la racha
+	Streak       int    `json:"streak"`        // respuestas correctas seguidas en la sala
 	Message      string `json:"message"`
 }
 
-func (uc *QuestionUseCase) LaunchQuestion(input LaunchQuestionInput) (*LaunchQuestionOutput, error) {
-	q, err := uc.questionService.LaunchQuestion(input.RoomCode, input.HostID, input.Text, input.CorrectAnswer, input.Points)
+// AnswerCountsOutput resume las respuestas recibidas por una pregunta
+type AnswerCountsOutput struct {
+	Answers int `json:"answers"`
+	Correct int `json:"correct"`
+}
+
+func (uc *QuestionUseCase) LaunchQuestion(ctx context.Context, input LaunchQuestionInput) (*LaunchQuestionOutput, error) {
+	q, err := uc.questionService.LaunchQuestion(ctx, input.RoomCode, input.HostID, input.Text, input.CorrectAnswer, input.Points)
 	if err != nil {
 		return nil, err
 	}
@@ -62,30 +72,32 @@ func (uc *QuestionUseCase) LaunchQuestion(input LaunchQuestionInput) (*LaunchQue
 	}, nil
 }
 
-func (uc *QuestionUseCase) CloseQuestion(roomCode string, hostID, questionID int) error {
-	return uc.questionService.CloseQuestion(roomCode, hostID, questionID)
+func (uc *QuestionUseCase) CloseQuestion(ctx context.Context, roomCode string, hostID, questionID int) error {
+	return uc.questionService.CloseQuestion(ctx, roomCode, hostID, questionID)
 }
 
-func (uc *QuestionUseCase) SubmitAnswer(input SubmitAnswerInput) (*SubmitAnswerOutput, error) {
-	isCorrect, points, err := uc.questionService.SubmitAnswer(input.RoomCode, input.UserID, input.QuestionID, input.Answer)
+func (uc *QuestionUseCase) SubmitAnswer(ctx context.Context, input SubmitAnswerInput) (*SubmitAnswerOutput, error) {
+	result, err := uc.questionService.SubmitAnswer(ctx, input.RoomCode, input.UserID, input.QuestionID, input.Answer)
 	if err != nil {
 		return nil, err
 	}
 
 	msg := "Respuesta incorrecta"
-	if isCorrect {
+	if result.IsCorrect {
 		msg = "¡Correcto! Ganaste puntos"
 	}
 
 	return &SubmitAnswerOutput{
-		IsCorrect:    isCorrect,
-		PointsEarned: points,
+		IsCorrect:    result.IsCorrect,
+		PointsEarned: result.Points,
+		StreakBonus:  result.Bonus,
+		Streak:       result.Streak,
 		Message:      msg,
 	}, nil
 }
 
-func (uc *QuestionUseCase) GetCurrentQuestion(roomCode string) (*LaunchQuestionOutput, error) {
-	q, err := uc.questionService.GetCurrentQuestion(roomCode)
+func (uc *QuestionUseCase) GetCurrentQuestion(ctx context.Context, roomCode string) (*LaunchQuestionOutput, error) {
+	q, err := uc.questionService.GetCurrentQuestion(ctx, roomCode)
 	if err != nil {
 		return nil, err
 	}
@@ -101,6 +113,26 @@ func (uc *QuestionUseCase) GetCurrentQuestion(roomCode string) (*LaunchQuestionO
 	}, nil
 }
 
-func (uc *QuestionUseCase) GetAnswers(roomCode string, hostID, questionID int) ([]domain.Answer, error) {
-	return uc.questionService.GetAnswers(roomCode, hostID, questionID)
+func (uc *QuestionUseCase) GetAnswers(ctx context.Context, roomCode string, hostID, questionID int) ([]domain.Answer, error) {
+	return uc.questionService.GetAnswers(ctx, roomCode, hostID, questionID)
+}
+
+func (uc *QuestionUseCase) CountAnswers(ctx context.Context, questionID int) (*AnswerCountsOutput, error) {
+	total, correct, err := uc.questionService.CountAnswers(ctx, questionID)
+	if err != nil {
+		return nil, err
+	}
+	return &AnswerCountsOutput{Answers: total, Correct: correct}, nil
+}
+
+func (uc *QuestionUseCase) RoomHostID(ctx context.Context, roomCode string) (int, error) {
+	return uc.questionService.RoomHostID(ctx, roomCode)
+}
+
+func (uc *QuestionUseCase) GetQuestionStats(ctx context.Context, roomCode string, hostID, questionID int) (*domain.QuestionStats, error) {
+	return uc.questionService.GetQuestionStats(ctx, roomCode, hostID, questionID)
+}
+
+func (uc *QuestionUseCase) QuestionStats(ctx context.Context, roomCode string, questionID int) (*domain.QuestionStats, error) {
+	return uc.questionService.QuestionStats(ctx, roomCode, questionID)
 }
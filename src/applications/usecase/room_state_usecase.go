@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"context"
+
+	"apiGolan/src/core"
+	"apiGolan/src/domain"
+)
+
+// RoomStateUseCase arma una foto completa de la sala para un cliente que se conecta
+type RoomStateUseCase struct {
+	roomService     *core.RoomService
+	questionService *core.QuestionService
+	scoreService    *core.ScoreService
+}
+
+func NewRoomStateUseCase(
+	roomService *core.RoomService,
+	questionService *core.QuestionService,
+	scoreService *core.ScoreService,
+) *RoomStateUseCase {
+	return &RoomStateUseCase{
+		roomService:     roomService,
+		questionService: questionService,
+		scoreService:    scoreService,
+	}
+}
+
+// RoomStateOutput es el estado de la sala visto por un usuario
+type RoomStateOutput struct {
+	Code            string                `json:"code"`
+	Status          domain.RoomStatus     `json:"status"`
+	HostID          int                   `json:"host_id"`
+	CurrentQuestion *LaunchQuestionOutput `json:"current_question"` // null si no hay pregunta abierta
+	Answered        bool                  `json:"answered"`         // si el usuario ya respondió la pregunta abierta
+	Ranking         []domain.RankingEntry `json:"ranking"`          // primeras posiciones (RANKING_BROADCAST_SIZE)
+	Me              *domain.RankingEntry  `json:"me"`               // su puesto aunque no esté entre las primeras; null si no tiene puntos
+}
+
+func (uc *RoomStateUseCase) GetRoomState(ctx context.Context, code string, userID int) (*RoomStateOutput, error) {
+	room, err := uc.roomService.GetRoom(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	ranking, err := uc.scoreService.TopRanking(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	me, err := uc.scoreService.GetPosition(ctx, code, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &RoomStateOutput{
+		Code:    room.Code,
+		Status:  room.Status,
+		HostID:  room.HostID,
+		Ranking: ranking,
+		Me:      me,
+	}
+
+	q, err := uc.questionService.GetCurrentQuestion(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if q != nil {
+		state.CurrentQuestion = &LaunchQuestionOutput{
+			ID:     q.ID,
+			RoomID: q.RoomID,
+			Text:   q.Text,
+			Points: q.Points,
+			Status: q.Status,
+		}
+		if state.Answered, err = uc.questionService.HasAnswered(ctx, q.ID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	return state, nil
+}
@@ -0,0 +1,199 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"apiGolan/src/applications/usecase"
+	"apiGolan/src/core"
+	"apiGolan/src/domain"
+	infradb "apiGolan/src/infrastructure/db"
+	"apiGolan/src/infrastructure/repository"
+)
+
+// roomStateFixture es una sala ABC123 con un host y dos participantes, ana y beto
+type roomStateFixture struct {
+	uc        *usecase.RoomStateUseCase
+	questions *core.QuestionService
+	scores    *core.ScoreService
+	host      *domain.User
+	ana, beto *domain.User
+}
+
+func newRoomStateFixture(t *testing.T) *roomStateFixture {
+	t.Helper()
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DB_PATH", ":memory:")
+	db, err := infradb.Connect()
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	ctx := context.Background()
+
+	users := repository.NewUserRepo(db)
+	rooms := repository.NewRoomRepo(db)
+	participants := repository.NewParticipantRepo(db)
+	scores := repository.NewScoreRepo(db)
+	newUser := func(name string, role domain.Role) *domain.User {
+		u := &domain.User{Name: name, Email: name + "@x.com", Password: "x", Role: role}
+		if err := users.Create(ctx, u); err != nil {
+			t.Fatal(err)
+		}
+		return u
+	}
+
+	f := &roomStateFixture{host: newUser("host", domain.RoleHost)}
+	room := &domain.Room{
+		Code:        "ABC123",
+		HostID:      f.host.ID,
+		Status:      domain.RoomStatusActive,
+		RankingMode: domain.RankingCompetition,
+		Streak:      domain.StreakConfig{Mode: domain.StreakNone},
+	}
+	if err := rooms.Create(ctx, room); err != nil {
+		t.Fatal(err)
+	}
+	f.ana, f.beto = newUser("ana", domain.RoleParticipant), newUser("beto", domain.RoleParticipant)
+	for _, u := range []*domain.User{f.ana, f.beto} {
+		if err := participants.Add(ctx, &domain.Participant{RoomID: room.ID, UserID: u.ID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f.scores = core.NewScoreService(scores, rooms, 1)
+	f.questions = core.NewQuestionService(repository.NewQuestionRepo(db), repository.NewAnswerRepo(db), scores, rooms, repository.NewTransactor(db))
+	f.uc = usecase.NewRoomStateUseCase(core.NewRoomService(rooms, participants, scores), f.questions, f.scores)
+	return f
+}
+
+func TestGetRoomState(t *testing.T) {
+	// setup prepara la sala y devuelve el usuario que se conecta
+	tests := []struct {
+		name         string
+		setup        func(t *testing.T, f *roomStateFixture) *domain.User
+		wantQuestion bool
+		answered     bool
+		ranking      []string // nombres en el ranking (RANKING_BROADCAST_SIZE = 1)
+		me           int      // puesto del usuario; 0 si no tiene puntos
+	}{
+		{"sin pregunta abierta ni puntos", func(t *testing.T, f *roomStateFixture) *domain.User {
+			return f.ana
+		}, false, false, nil, 0},
+
+		{"pregunta abierta sin responder", func(t *testing.T, f *roomStateFixture) *domain.User {
+			launch(t, f)
+			return f.ana
+		}, true, false, nil, 0},
+
+		{"pregunta abierta ya respondida", func(t *testing.T, f *roomStateFixture) *domain.User {
+			q := launch(t, f)
+			answer(t, f, f.ana, q, "4")
+			return f.ana
+		}, true, true, []string{"ana"}, 1},
+
+		{"respondió otro", func(t *testing.T, f *roomStateFixture) *domain.User {
+			q := launch(t, f)
+			answer(t, f, f.ana, q, "4")
+			return f.beto
+		}, true, false, []string{"ana"}, 0},
+
+		{"fuera del top sigue viendo su puesto", func(t *testing.T, f *roomStateFixture) *domain.User {
+			addPoints(t, f, f.ana, 20)
+			addPoints(t, f, f.beto, 5)
+			return f.beto
+		}, false, false, []string{"ana"}, 2},
+
+		{"pregunta cerrada", func(t *testing.T, f *roomStateFixture) *domain.User {
+			q := launch(t, f)
+			answer(t, f, f.ana, q, "3")
+			if err := f.questions.CloseQuestion(context.Background(), "ABC123", f.host.ID, q.ID); err != nil {
+				t.Fatal(err)
+			}
+			return f.ana
+		}, false, false, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newRoomStateFixture(t)
+			user := tt.setup(t, f)
+
+			state, err := f.uc.GetRoomState(context.Background(), "ABC123", user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if state.Code != "ABC123" || state.Status != domain.RoomStatusActive || state.HostID != f.host.ID {
+				t.Errorf("sala = %s/%s/%d", state.Code, state.Status, state.HostID)
+			}
+			if (state.CurrentQuestion != nil) != tt.wantQuestion {
+				t.Errorf("current_question = %+v, se esperaba pregunta: %v", state.CurrentQuestion, tt.wantQuestion)
+			}
+			if state.Answered != tt.answered {
+				t.Errorf("answered = %v, se esperaba %v", state.Answered, tt.answered)
+			}
+			var names []string
+			for _, e := range state.Ranking {
+				names = append(names, e.UserName)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.ranking, ",") {
+				t.Errorf("ranking = %v, se esperaba %v", names, tt.ranking)
+			}
+			switch {
+			case tt.me == 0 && state.Me != nil:
+				t.Errorf("me = %+v, se esperaba null", state.Me)
+			case tt.me != 0 && (state.Me == nil || state.Me.Position != tt.me):
+				t.Errorf("me = %+v, se esperaba el puesto %d", state.Me, tt.me)
+			}
+		})
+	}
+}
+
+func TestGetRoomStateHidesAnswer(t *testing.T) {
+	f := newRoomStateFixture(t)
+	launch(t, f)
+
+	state, err := f.uc.GetRoomState(context.Background(), "ABC123", f.ana.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := json.Marshal(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(body), "correct_answer") || strings.Contains(string(body), `"4"`) {
+		t.Errorf("room_state filtra la respuesta: %s", body)
+	}
+}
+
+func TestGetRoomStateUnknownRoom(t *testing.T) {
+	f := newRoomStateFixture(t)
+	if _, err := f.uc.GetRoomState(context.Background(), "NOPE00", f.ana.ID); err == nil {
+		t.Error("se esperaba error para una sala inexistente")
+	}
+}
+
+// launch abre la pregunta "¿2+2?" (respuesta "4", 10 puntos)
+func launch(t *testing.T, f *roomStateFixture) *domain.Question {
+	t.Helper()
+	q, err := f.questions.LaunchQuestion(context.Background(), "ABC123", f.host.ID, "¿2+2?", "4", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return q
+}
+
+func answer(t *testing.T, f *roomStateFixture, u *domain.User, q *domain.Question, text string) {
+	t.Helper()
+	if _, err := f.questions.SubmitAnswer(context.Background(), "ABC123", u.ID, q.ID, text); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func addPoints(t *testing.T, f *roomStateFixture, u *domain.User, delta int) {
+	t.Helper()
+	if err := f.scores.AddPoints(context.Background(), "ABC123", f.host.ID, u.ID, delta, "bonus"); err != nil {
+		t.Fatal(err)
+	}
+}
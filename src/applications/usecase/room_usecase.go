@@ -1,6 +1,8 @@
 package usecase
 
 import (
+	"context"
+
 	"apiGolan/src/core"
 	"apiGolan/src/domain"
 )
@@ -14,40 +16,48 @@ func NewRoomUseCase(roomService *core.RoomService) *RoomUseCase {
 	return &RoomUseCase{roomService: roomService}
 }
 
+// CreateRoomInput es el cuerpo opcional al crear una sala
+type CreateRoomInput struct {
+	RankingMode domain.RankingMode  `json:"ranking_mode,omitempty"` // competition (por defecto) o dense
+	Streak      domain.StreakConfig `json:"streak"`                 // premio por racha; sin él, none
+}
+
 // CreateRoomOutput es lo que se devuelve al crear una sala
 type CreateRoomOutput struct {
-	Code   string            `json:"code"`
-	Status domain.RoomStatus `json:"status"`
+	Code        string              `json:"code"`
+	Status      domain.RoomStatus   `json:"status"`
+	RankingMode domain.RankingMode  `json:"ranking_mode"`
+	Streak      domain.StreakConfig `json:"streak"`
 }
 
-func (uc *RoomUseCase) CreateRoom(hostID int) (*CreateRoomOutput, error) {
-	room, err := uc.roomService.CreateRoom(hostID)
+func (uc *RoomUseCase) CreateRoom(ctx context.Context, hostID int, input CreateRoomInput) (*CreateRoomOutput, error) {
+	room, err := uc.roomService.CreateRoom(ctx, hostID, input.RankingMode, input.Streak)
 	if err != nil {
 		return nil, err
 	}
-	return &CreateRoomOutput{Code: room.Code, Status: room.Status}, nil
+	return &CreateRoomOutput{Code: room.Code, Status: room.Status, RankingMode: room.RankingMode, Streak: room.Streak}, nil
 }
 
-func (uc *RoomUseCase) JoinRoom(code string, userID int) error {
-	return uc.roomService.JoinRoom(code, userID)
+func (uc *RoomUseCase) JoinRoom(ctx context.Context, code string, userID int) error {
+	return uc.roomService.JoinRoom(ctx, code, userID)
 }
 
-func (uc *RoomUseCase) StartSession(code string, hostID int) error {
-	return uc.roomService.StartSession(code, hostID)
+func (uc *RoomUseCase) StartSession(ctx context.Context, code string, hostID int) error {
+	return uc.roomService.StartSession(ctx, code, hostID)
 }
 
-func (uc *RoomUseCase) EndSession(code string, hostID int) error {
-	return uc.roomService.EndSession(code, hostID)
+func (uc *RoomUseCase) EndSession(ctx context.Context, code string, hostID int) error {
+	return uc.roomService.EndSession(ctx, code, hostID)
 }
 
-func (uc *RoomUseCase) GetRoom(code string) (*domain.Room, error) {
-	return uc.roomService.GetRoom(code)
+func (uc *RoomUseCase) GetRoom(ctx context.Context, code string) (*domain.Room, error) {
+	return uc.roomService.GetRoom(ctx, code)
 }
 
-func (uc *RoomUseCase) GetParticipants(code string) ([]domain.ParticipantWithUser, error) {
-	return uc.roomService.GetParticipants(code)
+func (uc *RoomUseCase) GetParticipants(ctx context.Context, code string) ([]domain.ParticipantWithUser, error) {
+	return uc.roomService.GetParticipants(ctx, code)
 }
 
-func (uc *RoomUseCase) KickParticipant(code string, hostID, targetUserID int) error {
-	return uc.roomService.KickParticipant(code, hostID, targetUserID)
+func (uc *RoomUseCase) KickParticipant(ctx context.Context, code string, hostID, targetUserID int) error {
+	return uc.roomService.KickParticipant(ctx, code, hostID, targetUserID)
 }
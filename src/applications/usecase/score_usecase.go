@@ -1,6 +1,8 @@
 package usecase
 
 import (
+	"context"
+
 	"apiGolan/src/core"
 	"apiGolan/src/domain"
 )
@@ -18,29 +20,80 @@ func NewScoreUseCase(scoreService *core.ScoreService) *ScoreUseCase {
 type AddPointsInput struct {
 	RoomCode    string `json:"room_code"`
 	TargetID    int    `json:"target_user_id"`
-	Delta       int    `json:"delta"` // positivo o negativo
-	RequesterID int    `json:"-"`     // se toma del token, no del body
+	Delta       int    `json:"delta"`            // positivo o negativo
+	Reason      string `json:"reason,omitempty"` // queda en el historial de puntos
+	RequesterID int    `json:"-"`                // se toma del token, no del body
+}
+
+func (uc *ScoreUseCase) AddPoints(ctx context.Context, input AddPointsInput) error {
+	return uc.scoreService.AddPoints(ctx, input.RoomCode, input.RequesterID, input.TargetID, input.Delta, input.Reason)
+}
+
+func (uc *ScoreUseCase) GetRanking(ctx context.Context, code string, limit, offset int) ([]domain.RankingEntry, error) {
+	return uc.scoreService.GetRanking(ctx, code, limit, offset)
+}
+
+// TopRanking son las primeras posiciones que viajan en score_update
+func (uc *ScoreUseCase) TopRanking(ctx context.Context, code string) ([]domain.RankingEntry, error) {
+	return uc.scoreService.TopRanking(ctx, code)
+}
+
+func (uc *ScoreUseCase) GetPosition(ctx context.Context, code string, userID int) (*domain.RankingEntry, error) {
+	return uc.scoreService.GetPosition(ctx, code, userID)
+}
+
+// RankingModeInput cambia la numeración de empates de la sala
+type RankingModeInput struct {
+	RankingMode domain.RankingMode `json:"ranking_mode"`
 }
 
-func (uc *ScoreUseCase) AddPoints(input AddPointsInput) error {
-	return uc.scoreService.AddPoints(input.RoomCode, input.RequesterID, input.TargetID, input.Delta)
+func (uc *ScoreUseCase) SetRankingMode(ctx context.Context, code string, hostID int, input RankingModeInput) error {
+	return uc.scoreService.SetRankingMode(ctx, code, hostID, input.RankingMode)
 }
 
-func (uc *ScoreUseCase) GetRanking(code string) ([]domain.RankingEntry, error) {
-	return uc.scoreService.GetRanking(code)
+// SetStreak cambia el premio por racha de la sala; devuelve cómo quedó
+func (uc *ScoreUseCase) SetStreak(ctx context.Context, code string, hostID int, input domain.StreakConfig) (*domain.StreakConfig, error) {
+	return uc.scoreService.SetStreak(ctx, code, hostID, input)
 }
 
 // ResetUserPointsInput es la entrada para resetear puntos de un usuario
 type ResetUserPointsInput struct {
 	RoomCode    string `json:"room_code"`
 	TargetID    int    `json:"target_user_id"`
+	Reason      string `json:"reason,omitempty"`
 	RequesterID int    `json:"-"`
 }
 
-func (uc *ScoreUseCase) ResetUserPoints(input ResetUserPointsInput) error {
-	return uc.scoreService.ResetUserPoints(input.RoomCode, input.RequesterID, input.TargetID)
+func (uc *ScoreUseCase) ResetUserPoints(ctx context.Context, input ResetUserPointsInput) error {
+	return uc.scoreService.ResetUserPoints(ctx, input.RoomCode, input.RequesterID, input.TargetID, input.Reason)
+}
+
+// ReasonInput es el cuerpo opcional de reset-all y undo
+type ReasonInput struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+func (uc *ScoreUseCase) ResetAllPoints(ctx context.Context, code string, hostID int, reason string) error {
+	return uc.scoreService.ResetAllPoints(ctx, code, hostID, reason)
+}
+
+func (uc *ScoreUseCase) ListScoreEvents(ctx context.Context, code string, hostID int, filter domain.ScoreEventFilter) ([]domain.ScoreEvent, error) {
+	return uc.scoreService.ListScoreEvents(ctx, code, hostID, filter)
+}
+
+func (uc *ScoreUseCase) UndoScoreEvent(ctx context.Context, code string, hostID, eventID int, reason string) (*domain.ScoreEvent, error) {
+	return uc.scoreService.UndoScoreEvent(ctx, code, hostID, eventID, reason)
+}
+
+// ReconcileOutput lista los participantes cuyos puntos se corrigieron
+type ReconcileOutput struct {
+	Fixed []domain.ScoreDrift `json:"fixed"`
 }
 
-func (uc *ScoreUseCase) ResetAllPoints(code string, hostID int) error {
-	return uc.scoreService.ResetAllPoints(code, hostID)
+func (uc *ScoreUseCase) ReconcileScores(ctx context.Context, code string, hostID int) (*ReconcileOutput, error) {
+	fixed, err := uc.scoreService.ReconcileScores(ctx, code, hostID)
+	if err != nil {
+		return nil, err
+	}
+	return &ReconcileOutput{Fixed: fixed}, nil
 }
@@ -0,0 +1,31 @@
+package usecase
+
+import (
+	"context"
+
+	"apiGolan/src/core"
+	"apiGolan/src/domain"
+)
+
+type SSOUseCase struct {
+	ssoService *core.SSOService
+}
+
+func NewSSOUseCase(ssoService *core.SSOService) *SSOUseCase {
+	return &SSOUseCase{ssoService: ssoService}
+}
+
+// SSOCallbackInput junta lo que llega en el callback con lo guardado al iniciar el login
+type SSOCallbackInput struct {
+	Code     string
+	Verifier string
+	Nonce    string
+}
+
+func (uc *SSOUseCase) Begin() (*core.SSOLogin, error) {
+	return uc.ssoService.Begin()
+}
+
+func (uc *SSOUseCase) Complete(ctx context.Context, input SSOCallbackInput) (*domain.User, error) {
+	return uc.ssoService.Complete(ctx, input.Code, input.Verifier, input.Nonce)
+}
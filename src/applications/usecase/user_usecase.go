@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"context"
+
+	"apiGolan/src/core"
+	"apiGolan/src/domain"
+)
+
+// UserUseCase agrupa lo que el usuario autenticado puede hacer con su propia cuenta
+type UserUseCase struct {
+	userService *core.UserService
+}
+
+func NewUserUseCase(userService *core.UserService) *UserUseCase {
+	return &UserUseCase{userService: userService}
+}
+
+type UpdateProfileInput struct {
+	Name string `json:"name"`
+}
+
+type ChangePasswordInput struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// DeleteAccountInput pide la contraseña para confirmar el borrado
+type DeleteAccountInput struct {
+	Password string `json:"password"`
+}
+
+func (uc *UserUseCase) GetProfile(ctx context.Context, userID int) (*domain.User, error) {
+	return uc.userService.GetProfile(ctx, userID)
+}
+
+func (uc *UserUseCase) UpdateProfile(ctx context.Context, userID int, input UpdateProfileInput) (*domain.User, error) {
+	return uc.userService.UpdateName(ctx, userID, input.Name)
+}
+
+func (uc *UserUseCase) ChangePassword(ctx context.Context, userID int, input ChangePasswordInput) error {
+	return uc.userService.ChangePassword(ctx, userID, input.CurrentPassword, input.NewPassword)
+}
+
+func (uc *UserUseCase) DeleteAccount(ctx context.Context, userID int, input DeleteAccountInput) error {
+	return uc.userService.DeleteAccount(ctx, userID, input.Password)
+}
@@ -0,0 +1,130 @@
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"apiGolan/src/domain"
+)
+
+const (
+	apiKeyPrefix       = "qs_" // permite distinguir una API key de un JWT en el header
+	maxAPIKeysPerUser  = 20
+	apiKeyTouchEvery   = time.Minute // last_used_at no se escribe en cada petición
+	apiKeyDisplayChars = 8
+)
+
+// APIKeyService contiene la lógica de las API keys personales.
+type APIKeyService struct {
+	repo  domain.APIKeyRepository
+	users domain.UserRepository
+}
+
+func NewAPIKeyService(repo domain.APIKeyRepository, users domain.UserRepository) *APIKeyService {
+	return &APIKeyService{repo: repo, users: users}
+}
+
+// IsAPIKey indica si el token del header Authorization es una API key
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, apiKeyPrefix)
+}
+
+// Create genera una key nueva y devuelve la key en claro (única vez que se ve)
+func (s *APIKeyService) Create(ctx context.Context, userID int, name string, scopes []domain.Scope) (string, *domain.APIKey, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 100 {
+		return "", nil, errors.New("el nombre es requerido (máx. 100 caracteres)")
+	}
+	if len(scopes) == 0 {
+		return "", nil, errors.New("se requiere al menos un scope")
+	}
+	seen := map[domain.Scope]bool{}
+	unique := make([]domain.Scope, 0, len(scopes))
+	for _, sc := range scopes {
+		if !sc.Valid() {
+			return "", nil, errors.New("scope inválido: " + string(sc))
+		}
+		if !seen[sc] {
+			seen[sc] = true
+			unique = append(unique, sc)
+		}
+	}
+
+	n, err := s.repo.CountActive(ctx, userID)
+	if err != nil {
+		return "", nil, err
+	}
+	if n >= maxAPIKeysPerUser {
+		return "", nil, errors.New("alcanzaste el máximo de API keys activas; revoca alguna")
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, err
+	}
+	secret := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(raw)
+
+	key := &domain.APIKey{
+		UserID:  userID,
+		Name:    name,
+		Prefix:  secret[:len(apiKeyPrefix)+apiKeyDisplayChars],
+		KeyHash: hashToken(secret),
+		Scopes:  unique,
+	}
+	if err := s.repo.Create(ctx, key); err != nil {
+		return "", nil, err
+	}
+	return secret, key, nil
+}
+
+func (s *APIKeyService) List(ctx context.Context, userID int) ([]domain.APIKey, error) {
+	return s.repo.ListByUser(ctx, userID)
+}
+
+func (s *APIKeyService) Revoke(ctx context.Context, userID, keyID int) error {
+	ok, err := s.repo.Revoke(ctx, userID, keyID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("API key no encontrada")
+	}
+	return nil
+}
+
+// Authenticate valida una API key y devuelve la key y su dueño.
+// El rol es el actual del usuario, no el que tenía al crear la key.
+func (s *APIKeyService) Authenticate(ctx context.Context, secret string) (*domain.APIKey, *domain.User, error) {
+	invalid := errors.New("API key inválida o revocada")
+	if !IsAPIKey(secret) {
+		return nil, nil, invalid
+	}
+
+	key, err := s.repo.FindByHash(ctx, hashToken(secret))
+	if err != nil {
+		return nil, nil, err
+	}
+	if key == nil || key.RevokedAt != nil {
+		return nil, nil, invalid
+	}
+
+	user, err := s.users.FindByID(ctx, key.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user == nil || user.DeletedAt != nil {
+		return nil, nil, invalid
+	}
+
+	now := time.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > apiKeyTouchEvery {
+		// Si falla solo se pierde el dato de uso; la petición sigue
+		_ = s.repo.TouchLastUsed(ctx, key.ID, now)
+		key.LastUsedAt = &now
+	}
+	return key, user, nil
+}
@@ -0,0 +1,123 @@
+package core_test
+
+import (
+	"context"
+	"testing"
+
+	"apiGolan/src/core"
+	"apiGolan/src/domain"
+	"apiGolan/src/infrastructure/repository"
+)
+
+func TestAPIKeyCreate(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+	users := repository.NewUserRepo(db)
+	svc := core.NewAPIKeyService(repository.NewAPIKeyRepo(db), users)
+
+	host := &domain.User{Name: "host", Email: "host@x.com", Password: "x", Role: domain.RoleHost}
+	if err := users.Create(ctx, host); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		keyName string
+		scopes  []domain.Scope
+		want    []domain.Scope // nil si debe fallar
+	}{
+		{"un scope", "script", []domain.Scope{domain.ScopeScoresRead}, []domain.Scope{domain.ScopeScoresRead}},
+		{"scopes repetidos", "panel", []domain.Scope{domain.ScopeRoomsRead, domain.ScopeRoomsRead, domain.ScopeScoresWrite},
+			[]domain.Scope{domain.ScopeRoomsRead, domain.ScopeScoresWrite}},
+		{"sin scopes", "script", nil, nil},
+		{"scope inexistente", "script", []domain.Scope{"rooms:delete"}, nil},
+		{"sin nombre", "   ", []domain.Scope{domain.ScopeScoresRead}, nil},
+	}
+	for _, tt := range tests {
+		secret, key, err := svc.Create(ctx, host.ID, tt.keyName, tt.scopes)
+		if tt.want == nil {
+			if err == nil {
+				t.Errorf("%s: se creó la key %+v", tt.name, key)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !core.IsAPIKey(secret) || secret[:len(key.Prefix)] != key.Prefix || key.KeyHash == secret {
+			t.Errorf("%s: secret %q con prefix %q y hash %q", tt.name, secret, key.Prefix, key.KeyHash)
+		}
+		if len(key.Scopes) != len(tt.want) {
+			t.Errorf("%s: scopes = %v, se esperaba %v", tt.name, key.Scopes, tt.want)
+			continue
+		}
+		for i := range tt.want {
+			if key.Scopes[i] != tt.want[i] {
+				t.Errorf("%s: scopes = %v, se esperaba %v", tt.name, key.Scopes, tt.want)
+			}
+		}
+	}
+}
+
+func TestAPIKeyAuthenticate(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+	users := repository.NewUserRepo(db)
+	svc := core.NewAPIKeyService(repository.NewAPIKeyRepo(db), users)
+
+	newKey := func(name string) (*domain.User, string, *domain.APIKey) {
+		t.Helper()
+		u := &domain.User{Name: name, Email: name + "@x.com", Password: "x", Role: domain.RoleHost}
+		if err := users.Create(ctx, u); err != nil {
+			t.Fatal(err)
+		}
+		secret, key, err := svc.Create(ctx, u.ID, "script", []domain.Scope{domain.ScopeScoresRead})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u, secret, key
+	}
+	ana, active, _ := newKey("ana")
+	beto, revoked, betoKey := newKey("beto")
+	if err := svc.Revoke(ctx, beto.ID, betoKey.ID); err != nil {
+		t.Fatal(err)
+	}
+	caro, deleted, _ := newKey("caro")
+	if err := users.Anonymize(ctx, caro.ID); err != nil {
+		t.Fatal(err)
+	}
+	// la key sigue la cuenta: si ana pasa a participante la key ya no es de un host
+	if err := users.UpdateRole(ctx, ana.ID, domain.RoleParticipant); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		secret   string
+		wantUser int // 0 si debe rechazarse
+		wantRole domain.Role
+	}{
+		{"key activa", active, ana.ID, domain.RoleParticipant},
+		{"key revocada", revoked, 0, ""},
+		{"cuenta eliminada", deleted, 0, ""},
+		{"key desconocida", "qs_no-existe", 0, ""},
+		{"no es una API key", "eyJhbGciOi", 0, ""},
+	}
+	for _, tt := range tests {
+		key, user, err := svc.Authenticate(ctx, tt.secret)
+		if tt.wantUser == 0 {
+			if err == nil {
+				t.Errorf("%s: se aceptó la key de %+v", tt.name, user)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if user.ID != tt.wantUser || user.Role != tt.wantRole || key.LastUsedAt == nil {
+			t.Errorf("%s: usuario %d (%s), last_used_at %v", tt.name, user.ID, user.Role, key.LastUsedAt)
+		}
+	}
+}
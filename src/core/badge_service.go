@@ -0,0 +1,250 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"apiGolan/src/domain"
+)
+
+const (
+	streakLength         = 5 // respuestas correctas seguidas para streak_5
+	perfectMinQuestions  = 3 // una sesión de 1 o 2 preguntas no cuenta como perfecta
+	comebackParticipants = 3 // con menos no hay "mitad de abajo"
+)
+
+// answerRule decide si la respuesta recién registrada gana la medalla
+type answerRule struct {
+	badge  domain.BadgeCode
+	earned func(ctx context.Context, repo domain.BadgeRepository, ev domain.AnswerEvent) (bool, error)
+}
+
+// sessionRule devuelve quiénes ganan la medalla al terminar la sesión
+type sessionRule struct {
+	badge   domain.BadgeCode
+	winners func(ctx context.Context, repo domain.BadgeRepository, roomID int) ([]int, error)
+}
+
+// answerRules se evalúan después de cada SubmitAnswer
+var answerRules = []answerRule{
+	{domain.BadgeFirstCorrect, firstCorrect},
+	{domain.BadgeStreak5, streak},
+	{domain.BadgeFastestAnswer, fastestAnswer},
+}
+
+// sessionRules se evalúan al terminar la sesión (EndSession)
+var sessionRules = []sessionRule{
+	{domain.BadgePerfectSession, perfectSession},
+	{domain.BadgeComeback, comeback},
+}
+
+// BadgeService evalúa las reglas de medallas y guarda las ganadas
+type BadgeService struct {
+	badgeRepo domain.BadgeRepository
+	roomRepo  domain.RoomRepository
+	userRepo  domain.UserRepository
+}
+
+func NewBadgeService(badgeRepo domain.BadgeRepository, roomRepo domain.RoomRepository, userRepo domain.UserRepository) *BadgeService {
+	return &BadgeService{badgeRepo: badgeRepo, roomRepo: roomRepo, userRepo: userRepo}
+}
+
+// OnAnswer evalúa las reglas por respuesta y devuelve las medallas nuevas
+func (s *BadgeService) OnAnswer(ctx context.Context, code string, ev domain.AnswerEvent) ([]domain.UserBadge, error) {
+	room, err := s.roomRepo.FindByCode(ctx, code)
+	if err != nil || room == nil {
+		return nil, errors.New("sala no encontrada")
+	}
+	ev.RoomID = room.ID
+
+	var earned []domain.UserBadge
+	for _, rule := range answerRules {
+		ok, err := rule.earned(ctx, s.badgeRepo, ev)
+		if err != nil {
+			return earned, err
+		}
+		if !ok {
+			continue
+		}
+		b, err := s.award(ctx, room, ev.UserID, rule.badge, ev.QuestionID)
+		if err != nil {
+			return earned, err
+		}
+		if b != nil {
+			earned = append(earned, *b)
+		}
+	}
+	return earned, nil
+}
+
+// OnSessionEnd evalúa las reglas de fin de sesión y devuelve las medallas nuevas
+func (s *BadgeService) OnSessionEnd(ctx context.Context, code string) ([]domain.UserBadge, error) {
+	room, err := s.roomRepo.FindByCode(ctx, code)
+	if err != nil || room == nil {
+		return nil, errors.New("sala no encontrada")
+	}
+
+	var earned []domain.UserBadge
+	for _, rule := range sessionRules {
+		users, err := rule.winners(ctx, s.badgeRepo, room.ID)
+		if err != nil {
+			return earned, err
+		}
+		for _, userID := range users {
+			b, err := s.award(ctx, room, userID, rule.badge, 0)
+			if err != nil {
+				return earned, err
+			}
+			if b != nil {
+				earned = append(earned, *b)
+			}
+		}
+	}
+	return earned, nil
+}
+
+// award guarda la medalla; nil si el usuario ya la tenía en la sala
+func (s *BadgeService) award(ctx context.Context, room *domain.Room, userID int, code domain.BadgeCode, questionID int) (*domain.UserBadge, error) {
+	b := &domain.UserBadge{UserID: userID, Badge: code, RoomID: room.ID, RoomCode: room.Code, QuestionID: questionID}
+	ok, err := s.badgeRepo.Award(ctx, b)
+	if err != nil || !ok {
+		return nil, err
+	}
+	describe(b)
+	return b, nil
+}
+
+// GetMyBadges devuelve todas las medallas del usuario
+func (s *BadgeService) GetMyBadges(ctx context.Context, userID int) ([]domain.UserBadge, error) {
+	return s.list(ctx, userID, 0)
+}
+
+// GetUserBadges devuelve las medallas de otro usuario. Un host solo ve las
+// ganadas en sus salas; un admin (all = true) ve todas.
+func (s *BadgeService) GetUserBadges(ctx context.Context, requesterID, userID int, all bool) ([]domain.UserBadge, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil || user == nil {
+		return nil, errors.New("usuario no encontrado")
+	}
+	hostID := requesterID
+	if all {
+		hostID = 0
+	}
+	return s.list(ctx, userID, hostID)
+}
+
+func (s *BadgeService) list(ctx context.Context, userID, hostID int) ([]domain.UserBadge, error) {
+	badges, err := s.badgeRepo.ListByUser(ctx, userID, hostID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range badges {
+		describe(&badges[i])
+	}
+	return badges, nil
+}
+
+// describe completa nombre y descripción desde el catálogo
+func describe(b *domain.UserBadge) {
+	if def := domain.FindBadge(b.Badge); def != nil {
+		b.Name = def.Name
+		b.Description = def.Description
+	}
+}
+
+// firstCorrect: la respuesta es la primera correcta del usuario
+func firstCorrect(ctx context.Context, repo domain.BadgeRepository, ev domain.AnswerEvent) (bool, error) {
+	if !ev.IsCorrect {
+		return false, nil
+	}
+	questionID, err := repo.FirstCorrectQuestion(ctx, ev.UserID)
+	return questionID == ev.QuestionID, err
+}
+
+// streak: la respuesta lleva su racha en la sala a streakLength aciertos
+func streak(ctx context.Context, repo domain.BadgeRepository, ev domain.AnswerEvent) (bool, error) {
+	return ev.IsCorrect && ev.Streak >= streakLength, nil
+}
+
+// fastestAnswer: fue el primero en responder bien la pregunta
+func fastestAnswer(ctx context.Context, repo domain.BadgeRepository, ev domain.AnswerEvent) (bool, error) {
+	if !ev.IsCorrect {
+		return false, nil
+	}
+	userID, err := repo.FirstCorrectUser(ctx, ev.QuestionID)
+	return userID == ev.UserID, err
+}
+
+// perfectSession: respondió bien todas las preguntas de la sala
+func perfectSession(ctx context.Context, repo domain.BadgeRepository, roomID int) ([]int, error) {
+	questions, results, err := repo.SessionResults(ctx, roomID)
+	if err != nil || questions < perfectMinQuestions {
+		return nil, err
+	}
+	var winners []int
+	for _, res := range results {
+		if res.Correct == questions {
+			winners = append(winners, res.UserID)
+		}
+	}
+	return winners, nil
+}
+
+// comeback: termina primero (con puntos) y en algún momento de la sesión
+// estuvo en la mitad de abajo del ranking. Se reconstruye el ranking
+// reproduciendo el ledger evento por evento.
+func comeback(ctx context.Context, repo domain.BadgeRepository, roomID int) ([]int, error) {
+	_, results, err := repo.SessionResults(ctx, roomID)
+	if err != nil || len(results) < comebackParticipants {
+		return nil, err
+	}
+	events, err := repo.ScoreTimeline(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make(map[int]int, len(results))
+	for _, res := range results {
+		points[res.UserID] = 0
+	}
+	bottom := len(points) - len(points)/2 // puestos mayores a este son la mitad de abajo
+	wasBottom := map[int]bool{}
+	for _, ev := range events {
+		if _, ok := points[ev.UserID]; !ok {
+			continue // ya no participa (expulsado)
+		}
+		points[ev.UserID] += ev.Delta
+		positions := rank(points)
+		for userID, pos := range positions {
+			if pos > bottom {
+				wasBottom[userID] = true
+			}
+		}
+	}
+
+	var winners []int
+	for userID, pos := range rank(points) {
+		if pos == 1 && points[userID] > 0 && wasBottom[userID] {
+			winners = append(winners, userID)
+		}
+	}
+	sort.Ints(winners)
+	return winners, nil
+}
+
+// rank numera a los usuarios por puntos con el criterio competition (1, 2, 2, 4)
+func rank(points map[int]int) map[int]int {
+	desc := make([]int, 0, len(points))
+	for _, p := range points {
+		desc = append(desc, p)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(desc)))
+
+	positions := make(map[int]int, len(points))
+	for userID, p := range points {
+		// puesto = 1 + cuántos tienen más puntos
+		positions[userID] = 1 + sort.Search(len(desc), func(i int) bool { return desc[i] <= p })
+	}
+	return positions
+}
@@ -0,0 +1,141 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"apiGolan/src/domain"
+)
+
+// fakeBadgeRepo devuelve resultados de sesión y ledger fijos
+type fakeBadgeRepo struct {
+	domain.BadgeRepository
+	questions int
+	results   []domain.SessionResult
+	timeline  []domain.ScoreEvent
+	err       error
+}
+
+func (f fakeBadgeRepo) SessionResults(ctx context.Context, roomID int) (int, []domain.SessionResult, error) {
+	return f.questions, f.results, f.err
+}
+
+func (f fakeBadgeRepo) ScoreTimeline(ctx context.Context, roomID int) ([]domain.ScoreEvent, error) {
+	return f.timeline, nil
+}
+
+// players arma resultados de sesión para los usuarios dados, sin respuestas
+func players(ids ...int) []domain.SessionResult {
+	results := make([]domain.SessionResult, 0, len(ids))
+	for _, id := range ids {
+		results = append(results, domain.SessionResult{UserID: id})
+	}
+	return results
+}
+
+// timeline arma el ledger a partir de pares usuario, delta
+func timeline(pairs ...int) []domain.ScoreEvent {
+	events := make([]domain.ScoreEvent, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		events = append(events, domain.ScoreEvent{UserID: pairs[i], Delta: pairs[i+1]})
+	}
+	return events
+}
+
+func TestRank(t *testing.T) {
+	tests := []struct {
+		name   string
+		points map[int]int
+		want   map[int]int
+	}{
+		{"vacío", map[int]int{}, map[int]int{}},
+		{"uno solo", map[int]int{1: 10}, map[int]int{1: 1}},
+		{"empate en el medio", map[int]int{1: 30, 2: 20, 3: 20, 4: 10}, map[int]int{1: 1, 2: 2, 3: 2, 4: 4}},
+		{"todos empatados", map[int]int{1: 0, 2: 0, 3: 0}, map[int]int{1: 1, 2: 1, 3: 1}},
+		{"empate arriba", map[int]int{1: 5, 2: 5, 3: 1}, map[int]int{1: 1, 2: 1, 3: 3}},
+		{"negativos", map[int]int{1: -5, 2: 0, 3: -10}, map[int]int{1: 2, 2: 1, 3: 3}},
+	}
+	for _, tt := range tests {
+		if got := rank(tt.points); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: rank = %v, se esperaba %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPerfectSession(t *testing.T) {
+	results := []domain.SessionResult{
+		{UserID: 1, Answered: 3, Correct: 3},
+		{UserID: 2, Answered: 3, Correct: 2},
+		{UserID: 3, Answered: 3, Correct: 3},
+		{UserID: 4, Answered: 1, Correct: 1},
+	}
+	tests := []struct {
+		name string
+		repo fakeBadgeRepo
+		want []int
+	}{
+		{"todas bien", fakeBadgeRepo{questions: 3, results: results}, []int{1, 3}},
+		{"menos preguntas que el mínimo", fakeBadgeRepo{questions: 2, results: []domain.SessionResult{{UserID: 1, Answered: 2, Correct: 2}}}, nil},
+		{"nadie perfecto", fakeBadgeRepo{questions: 4, results: results}, nil},
+		{"sin participantes", fakeBadgeRepo{questions: 5}, nil},
+	}
+	for _, tt := range tests {
+		got, err := perfectSession(context.Background(), tt.repo, 1)
+		if err != nil || fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: perfectSession = %v, %v; se esperaba %v", tt.name, got, err, tt.want)
+		}
+	}
+
+	if _, err := perfectSession(context.Background(), fakeBadgeRepo{err: errors.New("db caída")}, 1); err == nil {
+		t.Error("perfectSession no devolvió el error del repositorio")
+	}
+}
+
+func TestComeback(t *testing.T) {
+	tests := []struct {
+		name string
+		repo fakeBadgeRepo
+		want []int
+	}{
+		{
+			// 4 participantes: la mitad de abajo son los puestos 3 y 4
+			"remonta desde abajo",
+			fakeBadgeRepo{results: players(1, 2, 3, 4), timeline: timeline(1, 10, 2, 10, 3, 30)},
+			[]int{3},
+		},
+		{
+			"el líder nunca estuvo abajo",
+			fakeBadgeRepo{results: players(1, 2, 3), timeline: timeline(1, 10, 2, 5, 3, 3)},
+			nil,
+		},
+		{
+			"termina primero sin puntos",
+			fakeBadgeRepo{results: players(1, 2, 3), timeline: timeline(1, -5, 2, -10, 3, -10)},
+			nil,
+		},
+		{
+			"dos empatados en el primer puesto",
+			fakeBadgeRepo{results: players(1, 2, 3, 4), timeline: timeline(1, 10, 4, 10, 2, 20, 3, 20)},
+			[]int{2, 3},
+		},
+		{
+			// los eventos de un expulsado no cuentan para el ranking
+			"ignora a quien ya no participa",
+			fakeBadgeRepo{results: players(1, 2, 3), timeline: timeline(9, 100, 1, 10, 2, 5, 3, 20)},
+			[]int{3},
+		},
+		{
+			"menos de 3 participantes",
+			fakeBadgeRepo{results: players(1, 2), timeline: timeline(1, 10, 2, 30)},
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		got, err := comeback(context.Background(), tt.repo, 1)
+		if err != nil || fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: comeback = %v, %v; se esperaba %v", tt.name, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"apiGolan/src/domain"
+)
+
+// TrendWindow es cuántas sesiones recientes se comparan con las anteriores
+const TrendWindow = 5
+
+// HistoryService arma el progreso de un usuario a lo largo de sus sesiones
+type HistoryService struct {
+	historyRepo domain.HistoryRepository
+	userRepo    domain.UserRepository
+}
+
+func NewHistoryService(historyRepo domain.HistoryRepository, userRepo domain.UserRepository) *HistoryService {
+	return &HistoryService{historyRepo: historyRepo, userRepo: userRepo}
+}
+
+// GetMyHistory devuelve el historial completo del usuario
+func (s *HistoryService) GetMyHistory(ctx context.Context, userID int) (*domain.History, error) {
+	return s.history(ctx, userID, 0)
+}
+
+// GetUserHistory devuelve el historial de otro usuario. Un host solo ve las
+// salas que creó; un admin (all = true) ve todas.
+func (s *HistoryService) GetUserHistory(ctx context.Context, requesterID, userID int, all bool) (*domain.History, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil || user == nil {
+		return nil, errors.New("usuario no encontrado")
+	}
+	hostID := requesterID
+	if all {
+		hostID = 0
+	}
+	return s.history(ctx, userID, hostID)
+}
+
+func (s *HistoryService) history(ctx context.Context, userID, hostID int) (*domain.History, error) {
+	sessions, err := s.historyRepo.FindByUser(ctx, userID, hostID)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.History{
+		UserID:   userID,
+		Summary:  summarize(sessions),
+		Trend:    trend(sessions),
+		Sessions: sessions,
+	}, nil
+}
+
+// summarize acumula las sesiones; la precisión y el tiempo de respuesta se
+// ponderan por respuestas, no por sesión
+func summarize(sessions []domain.SessionHistory) domain.HistorySummary {
+	sum := domain.HistorySummary{Sessions: len(sessions)}
+	if len(sessions) == 0 {
+		return sum
+	}
+
+	var rankTotal, timed int
+	var responseTotal int64
+	for _, h := range sessions {
+		sum.TotalPoints += h.Points
+		sum.Answered += h.Answered
+		sum.Correct += h.Correct
+		rankTotal += h.Rank
+		if sum.BestRank == 0 || h.Rank < sum.BestRank {
+			sum.BestRank = h.Rank
+		}
+		timed += h.TimedAnswers
+		responseTotal += h.AvgResponseMS * int64(h.TimedAnswers)
+	}
+	sum.AvgPoints = round1(float64(sum.TotalPoints) / float64(len(sessions)))
+	sum.AvgRank = round1(float64(rankTotal) / float64(len(sessions)))
+	if sum.Answered > 0 {
+		sum.Accuracy = round1(float64(sum.Correct) * 100 / float64(sum.Answered))
+	}
+	if timed > 0 {
+		sum.AvgResponseMS = responseTotal / int64(timed)
+	}
+	return sum
+}
+
+// trend compara las últimas TrendWindow sesiones (sessions viene de la más
+// reciente a la más antigua) con las TrendWindow anteriores
+func trend(sessions []domain.SessionHistory) domain.HistoryTrend {
+	t := domain.HistoryTrend{Window: TrendWindow}
+	if len(sessions) <= TrendWindow {
+		return t
+	}
+
+	recent := summarize(sessions[:TrendWindow])
+	previous := summarize(sessions[TrendWindow:min(len(sessions), 2*TrendWindow)])
+
+	if recent.Answered > 0 && previous.Answered > 0 {
+		accuracy := round1(recent.Accuracy - previous.Accuracy)
+		t.AccuracyChange = &accuracy
+	}
+	rank := round1(recent.AvgRank - previous.AvgRank)
+	t.AvgRankChange = &rank
+	if recent.AvgResponseMS > 0 && previous.AvgResponseMS > 0 {
+		response := recent.AvgResponseMS - previous.AvgResponseMS
+		t.ResponseMSChange = &response
+	}
+	return t
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
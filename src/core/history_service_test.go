@@ -0,0 +1,132 @@
+package core_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"apiGolan/src/core"
+	"apiGolan/src/domain"
+	"apiGolan/src/infrastructure/repository"
+)
+
+// fakeHistory devuelve sesiones fijas y anota el hostID con que se consultó
+type fakeHistory struct {
+	sessions []domain.SessionHistory
+	hostID   int
+}
+
+func (f *fakeHistory) FindByUser(ctx context.Context, userID, hostID int) ([]domain.SessionHistory, error) {
+	f.hostID = hostID
+	return f.sessions, nil
+}
+
+// sessions repite n veces una sesión con el puesto, las respuestas y el tiempo indicados
+func sessions(n, points, rank, answered, correct int, avgMS int64) []domain.SessionHistory {
+	out := make([]domain.SessionHistory, n)
+	for i := range out {
+		timed := 0
+		if avgMS > 0 {
+			timed = answered
+		}
+		out[i] = domain.SessionHistory{Points: points, Rank: rank, Answered: answered, Correct: correct,
+			AvgResponseMS: avgMS, TimedAnswers: timed}
+	}
+	return out
+}
+
+func TestHistorySummaryAndTrend(t *testing.T) {
+	// las sesiones van de la más reciente a la más antigua
+	tests := []struct {
+		name     string
+		sessions []domain.SessionHistory
+		summary  domain.HistorySummary
+		trend    string // "accuracy rank response"; "-" si el cambio es nil
+	}{
+		{"sin sesiones", nil,
+			domain.HistorySummary{}, "- - -"},
+		{"pondera por respuestas, no por sesión",
+			append(sessions(1, 10, 3, 4, 2, 100), sessions(1, 20, 1, 1, 1, 400)...),
+			domain.HistorySummary{Sessions: 2, TotalPoints: 30, AvgPoints: 15, BestRank: 1, AvgRank: 2,
+				Answered: 5, Correct: 3, Accuracy: 60, AvgResponseMS: 160}, "- - -"},
+		{"sin tiempos registrados",
+			sessions(2, 5, 2, 3, 1, 0),
+			domain.HistorySummary{Sessions: 2, TotalPoints: 10, AvgPoints: 5, BestRank: 2, AvgRank: 2,
+				Answered: 6, Correct: 2, Accuracy: 33.3}, "- - -"},
+		{"mejora respecto de las anteriores",
+			append(sessions(5, 30, 1, 2, 2, 100), sessions(2, 10, 3, 2, 1, 300)...),
+			domain.HistorySummary{Sessions: 7, TotalPoints: 170, AvgPoints: 24.3, BestRank: 1, AvgRank: 1.6,
+				Answered: 14, Correct: 12, Accuracy: 85.7, AvgResponseMS: 157}, "50 -2 -200"},
+		{"solo compara las TrendWindow anteriores",
+			append(append(sessions(5, 0, 2, 1, 1, 0), sessions(5, 0, 2, 1, 0, 0)...), sessions(3, 0, 9, 1, 1, 0)...),
+			domain.HistorySummary{Sessions: 13, BestRank: 2, AvgRank: 3.6, Answered: 13, Correct: 8, Accuracy: 61.5}, "100 0 -"},
+		{"las anteriores no respondieron nada",
+			append(sessions(5, 10, 2, 1, 1, 50), sessions(1, 0, 4, 0, 0, 0)...),
+			domain.HistorySummary{Sessions: 6, TotalPoints: 50, AvgPoints: 8.3, BestRank: 2, AvgRank: 2.3,
+				Answered: 5, Correct: 5, Accuracy: 100, AvgResponseMS: 50}, "- -2 -"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := core.NewHistoryService(&fakeHistory{sessions: tt.sessions}, nil)
+			h, err := svc.GetMyHistory(context.Background(), 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if h.Summary != tt.summary {
+				t.Errorf("summary = %+v\nse esperaba %+v", h.Summary, tt.summary)
+			}
+			if h.Trend.Window != core.TrendWindow {
+				t.Errorf("window = %d, se esperaba %d", h.Trend.Window, core.TrendWindow)
+			}
+			if got := fmt.Sprintf("%s %s %s", deref(h.Trend.AccuracyChange), deref(h.Trend.AvgRankChange), deref(h.Trend.ResponseMSChange)); got != tt.trend {
+				t.Errorf("trend = %s, se esperaba %s", got, tt.trend)
+			}
+		})
+	}
+}
+
+// deref muestra el valor de un cambio de tendencia, o "-" si es nil
+func deref[T any](v *T) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprint(*v)
+}
+
+func TestGetUserHistoryScope(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+	host, _, players := seedGame(t, db, "ana")
+	repo := &fakeHistory{}
+	svc := core.NewHistoryService(repo, repository.NewUserRepo(db))
+
+	tests := []struct {
+		name       string
+		userID     int
+		all        bool
+		wantHostID int
+		wantErr    bool
+	}{
+		{"un host solo ve sus salas", players[0].ID, false, host.ID, false},
+		{"un admin ve todas", players[0].ID, true, 0, false},
+		{"usuario inexistente", 9999, true, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo.hostID = -1
+			h, err := svc.GetUserHistory(ctx, host.ID, tt.userID, tt.all)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("se esperaba error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if h.UserID != tt.userID || repo.hostID != tt.wantHostID {
+				t.Errorf("historial de %d consultado con hostID %d; se esperaba %d con hostID %d", h.UserID, repo.hostID, tt.userID, tt.wantHostID)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"apiGolan/src/domain"
+)
+
+const (
+	maxLeaderboardName  = 100
+	maxLeaderboardRooms = 200
+)
+
+// errLeaderboardNotFound se devuelve también cuando el usuario no puede verlo,
+// así no se revela qué ids existen
+var errLeaderboardNotFound = errors.New("leaderboard no encontrado")
+
+// LeaderboardService contiene la lógica de los leaderboards entre salas
+type LeaderboardService struct {
+	leaderboardRepo domain.LeaderboardRepository
+	roomRepo        domain.RoomRepository
+	tx              domain.Transactor
+}
+
+func NewLeaderboardService(leaderboardRepo domain.LeaderboardRepository, roomRepo domain.RoomRepository, tx domain.Transactor) *LeaderboardService {
+	return &LeaderboardService{leaderboardRepo: leaderboardRepo, roomRepo: roomRepo, tx: tx}
+}
+
+// Create crea un leaderboard con las salas dadas. Solo se pueden incluir salas
+// propias; los puntos que ya tienen se suman al agregarlas.
+func (s *LeaderboardService) Create(ctx context.Context, hostID int, name string, startsAt, endsAt *time.Time, codes []string) (*domain.Leaderboard, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxLeaderboardName {
+		return nil, errors.New("el nombre es requerido (máx. 100 caracteres)")
+	}
+	if startsAt != nil && endsAt != nil && !endsAt.After(*startsAt) {
+		return nil, errors.New("ends_at debe ser posterior a starts_at")
+	}
+	if len(codes) > maxLeaderboardRooms {
+		return nil, errors.New("un leaderboard admite como máximo 200 salas")
+	}
+
+	rooms := make([]*domain.Room, 0, len(codes))
+	seen := map[string]bool{}
+	for _, code := range codes {
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+		room, err := s.ownRoom(ctx, hostID, code)
+		if err != nil {
+			return nil, err
+		}
+		rooms = append(rooms, room)
+	}
+
+	// Si falla alguna sala no queda un leaderboard a medio armar
+	lb := &domain.Leaderboard{HostID: hostID, Name: name, StartsAt: startsAt, EndsAt: endsAt}
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.leaderboardRepo.Create(ctx, lb); err != nil {
+			return err
+		}
+		for _, room := range rooms {
+			if _, err := s.leaderboardRepo.AddRoom(ctx, lb, room.ID); err != nil {
+				return err
+			}
+			lb.Rooms = append(lb.Rooms, room.Code)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return lb, nil
+}
+
+// List devuelve los leaderboards del host
+func (s *LeaderboardService) List(ctx context.Context, hostID int) ([]domain.Leaderboard, error) {
+	return s.leaderboardRepo.ListByHost(ctx, hostID)
+}
+
+// Get devuelve el leaderboard si el usuario puede verlo: su host, un admin
+// (all = true) o quien participa en alguna de sus salas
+func (s *LeaderboardService) Get(ctx context.Context, id, userID int, all bool) (*domain.Leaderboard, error) {
+	lb, err := s.leaderboardRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if lb == nil {
+		return nil, errLeaderboardNotFound
+	}
+	if all || lb.HostID == userID {
+		return lb, nil
+	}
+	member, err := s.leaderboardRepo.IsMember(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !member {
+		return nil, errLeaderboardNotFound
+	}
+	return lb, nil
+}
+
+// Delete borra un leaderboard propio
+func (s *LeaderboardService) Delete(ctx context.Context, id, hostID int) error {
+	if _, err := s.owned(ctx, id, hostID); err != nil {
+		return err
+	}
+	return s.leaderboardRepo.Delete(ctx, id)
+}
+
+// AddRoom incluye una sala propia y suma sus puntos
+func (s *LeaderboardService) AddRoom(ctx context.Context, id, hostID int, code string) (*domain.Leaderboard, error) {
+	lb, err := s.owned(ctx, id, hostID)
+	if err != nil {
+		return nil, err
+	}
+	if len(lb.Rooms) >= maxLeaderboardRooms {
+		return nil, errors.New("un leaderboard admite como máximo 200 salas")
+	}
+	room, err := s.ownRoom(ctx, hostID, code)
+	if err != nil {
+		return nil, err
+	}
+	added, err := s.leaderboardRepo.AddRoom(ctx, lb, room.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !added {
+		return nil, errors.New("la sala ya está en el leaderboard")
+	}
+	lb.Rooms = append(lb.Rooms, room.Code)
+	return lb, nil
+}
+
+// RemoveRoom quita una sala y resta lo que aportó
+func (s *LeaderboardService) RemoveRoom(ctx context.Context, id, hostID int, code string) error {
+	lb, err := s.owned(ctx, id, hostID)
+	if err != nil {
+		return err
+	}
+	room, err := s.roomRepo.FindByCode(ctx, code)
+	if err != nil || room == nil {
+		return errors.New("sala no encontrada")
+	}
+	removed, err := s.leaderboardRepo.RemoveRoom(ctx, lb, room.ID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return errors.New("la sala no está en el leaderboard")
+	}
+	return nil
+}
+
+// GetRanking devuelve una página del periodo de la ventana que contiene at y
+// el puesto de userID en él
+func (s *LeaderboardService) GetRanking(ctx context.Context, id, userID int, all bool, window domain.LeaderboardWindow, at time.Time, limit, offset int) (*domain.LeaderboardRanking, error) {
+	if window == "" {
+		window = domain.LeaderboardAllTime
+	}
+	if !window.Valid() {
+		return nil, errors.New("window debe ser all_time, monthly o weekly")
+	}
+	lb, err := s.Get(ctx, id, userID, all)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	q := domain.LeaderboardQuery{LeaderboardID: id, Period: window.Period(at), Limit: limit, Offset: offset}
+
+	entries, err := s.leaderboardRepo.Ranking(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	me, err := s.leaderboardRepo.Entry(ctx, q, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.LeaderboardRanking{
+		LeaderboardID: lb.ID,
+		Name:          lb.Name,
+		Window:        window,
+		Period:        q.Period,
+		Entries:       entries,
+		Me:            me,
+	}, nil
+}
+
+// owned devuelve el leaderboard si es del host
+func (s *LeaderboardService) owned(ctx context.Context, id, hostID int) (*domain.Leaderboard, error) {
+	lb, err := s.leaderboardRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if lb == nil || lb.HostID != hostID {
+		return nil, errLeaderboardNotFound
+	}
+	return lb, nil
+}
+
+// ownRoom busca una sala y comprueba que la creó el host
+func (s *LeaderboardService) ownRoom(ctx context.Context, hostID int, code string) (*domain.Room, error) {
+	room, err := s.roomRepo.FindByCode(ctx, code)
+	if err != nil || room == nil {
+		return nil, errors.New("sala no encontrada: " + code)
+	}
+	if room.HostID != hostID {
+		return nil, errors.New("solo puedes agregar salas que creaste: " + code)
+	}
+	return room, nil
+}
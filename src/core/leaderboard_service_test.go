@@ -0,0 +1,61 @@
+package core_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"apiGolan/src/core"
+	"apiGolan/src/domain"
+	"apiGolan/src/infrastructure/repository"
+)
+
+// failingAddRoom falla al agregar la sala roomID, después de crear el leaderboard
+type failingAddRoom struct {
+	domain.LeaderboardRepository
+	roomID int
+}
+
+func (f failingAddRoom) AddRoom(ctx context.Context, lb *domain.Leaderboard, roomID int) (bool, error) {
+	if roomID == f.roomID {
+		return false, errors.New("fallo al agregar la sala")
+	}
+	return f.LeaderboardRepository.AddRoom(ctx, lb, roomID)
+}
+
+func TestCreateLeaderboardIsAtomic(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+	host, room, _ := seedGame(t, db, "ana")
+	rooms := repository.NewRoomRepo(db)
+	other := &domain.Room{
+		Code: "XYZ789", HostID: host.ID, Status: domain.RoomStatusActive,
+		RankingMode: domain.RankingCompetition, Streak: domain.StreakConfig{Mode: domain.StreakNone},
+	}
+	if err := rooms.Create(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+
+	boards := repository.NewLeaderboardRepo(db)
+	tx := repository.NewTransactor(db)
+	failing := core.NewLeaderboardService(failingAddRoom{boards, other.ID}, rooms, tx)
+	if _, err := failing.Create(ctx, host.ID, "liga", nil, nil, []string{room.Code, other.Code}); err == nil {
+		t.Fatal("se esperaba el error de AddRoom")
+	}
+	if list, err := boards.ListByHost(ctx, host.ID); err != nil || len(list) != 0 {
+		t.Fatalf("quedó un leaderboard a medio crear: %+v, %v", list, err)
+	}
+	var n int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM leaderboard_rooms`).Scan(&n); err != nil || n != 0 {
+		t.Errorf("leaderboard_rooms tiene %d filas, %v", n, err)
+	}
+
+	svc := core.NewLeaderboardService(boards, rooms, tx)
+	lb, err := svc.Create(ctx, host.ID, "liga", nil, nil, []string{room.Code, other.Code, room.Code})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lb.Rooms) != 2 || lb.Rooms[0] != room.Code || lb.Rooms[1] != other.Code {
+		t.Errorf("salas = %v", lb.Rooms)
+	}
+}
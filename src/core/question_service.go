@@ -1,8 +1,11 @@
 package core
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"strings"
+	"time"
 
 	"apiGolan/src/domain"
 )
@@ -13,6 +16,7 @@ type QuestionService struct {
 	answerRepo   domain.AnswerRepository
 	scoreRepo    domain.ScoreRepository
 	roomRepo     domain.RoomRepository
+	tx           domain.Transactor
 }
 
 func NewQuestionService(
@@ -20,18 +24,20 @@ func NewQuestionService(
 	answerRepo domain.AnswerRepository,
 	scoreRepo domain.ScoreRepository,
 	roomRepo domain.RoomRepository,
+	tx domain.Transactor,
 ) *QuestionService {
 	return &QuestionService{
 		questionRepo: questionRepo,
 		answerRepo:   answerRepo,
 		scoreRepo:    scoreRepo,
 		roomRepo:     roomRepo,
+		tx:           tx,
 	}
 }
 
 // LaunchQuestion crea una nueva pregunta y la lanza a la sala (solo host)
-func (s *QuestionService) LaunchQuestion(roomCode string, hostID int, text, correctAnswer string, points int) (*domain.Question, error) {
-	room, err := s.roomRepo.FindByCode(roomCode)
+func (s *QuestionService) LaunchQuestion(ctx context.Context, roomCode string, hostID int, text, correctAnswer string, points int) (*domain.Question, error) {
+	room, err := s.roomRepo.FindByCode(ctx, roomCode)
 	if err != nil || room == nil {
 		return nil, errors.New("sala no encontrada")
 	}
@@ -49,9 +55,11 @@ func (s *QuestionService) LaunchQuestion(roomCode string, hostID int, text, corr
 	}
 
 	// Cerrar pregunta abierta anterior si existe
-	existing, _ := s.questionRepo.FindOpenByRoom(room.ID)
+	existing, _ := s.questionRepo.FindOpenByRoom(ctx, room.ID)
 	if existing != nil {
-		_ = s.questionRepo.CloseQuestion(existing.ID)
+		if err := s.closeQuestion(ctx, room.ID, existing.ID); err != nil {
+			return nil, err
+		}
 	}
 
 	q := &domain.Question{
@@ -61,50 +69,69 @@ func (s *QuestionService) LaunchQuestion(roomCode string, hostID int, text, corr
 		Points:        points,
 		Status:        domain.QuestionStatusOpen,
 	}
-	if err := s.questionRepo.Create(q); err != nil {
+	if err := s.questionRepo.Create(ctx, q); err != nil {
 		return nil, err
 	}
 	return q, nil
 }
 
 // CloseQuestion cierra la pregunta activa (solo host)
-func (s *QuestionService) CloseQuestion(roomCode string, hostID, questionID int) error {
-	room, err := s.roomRepo.FindByCode(roomCode)
+func (s *QuestionService) CloseQuestion(ctx context.Context, roomCode string, hostID, questionID int) error {
+	room, err := s.roomRepo.FindByCode(ctx, roomCode)
 	if err != nil || room == nil {
 		return errors.New("sala no encontrada")
 	}
 	if room.HostID != hostID {
 		return errors.New("solo el host puede cerrar preguntas")
 	}
-	return s.questionRepo.CloseQuestion(questionID)
+	q, err := s.questionRepo.FindByID(ctx, questionID)
+	if err != nil || q == nil || q.RoomID != room.ID {
+		return errors.New("pregunta no encontrada")
+	}
+	if q.Status != domain.QuestionStatusOpen {
+		return nil // ya cerrada: las rachas ya se cortaron entonces
+	}
+	return s.closeQuestion(ctx, room.ID, questionID)
+}
+
+// closeQuestion cierra la pregunta y corta la racha de quienes no la
+// respondieron en una sola transacción, igual que SubmitAnswer: si algo falla
+// no queda la pregunta cerrada con las rachas sin cortar
+func (s *QuestionService) closeQuestion(ctx context.Context, roomID, questionID int) error {
+	return s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.questionRepo.CloseQuestion(ctx, questionID); err != nil {
+			return err
+		}
+		return s.scoreRepo.ResetMissedStreaks(ctx, roomID, questionID)
+	})
 }
 
 // SubmitAnswer procesa la respuesta de un participante
-// Devuelve si fue correcta y los puntos ganados
-func (s *QuestionService) SubmitAnswer(roomCode string, userID int, questionID int, answerText string) (bool, int, error) {
-	room, err := s.roomRepo.FindByCode(roomCode)
+// Devuelve si fue correcta, los puntos ganados y cómo queda su racha
+func (s *QuestionService) SubmitAnswer(ctx context.Context, roomCode string, userID int, questionID int, answerText string) (*domain.AnswerResult, error) {
+	room, err := s.roomRepo.FindByCode(ctx, roomCode)
 	if err != nil || room == nil {
-		return false, 0, errors.New("sala no encontrada")
+		return nil, errors.New("sala no encontrada")
 	}
 	if room.Status != domain.RoomStatusActive {
-		return false, 0, errors.New("la sesión no está activa")
+		return nil, errors.New("la sesión no está activa")
 	}
 
-	question, err := s.questionRepo.FindByID(questionID)
+	question, err := s.questionRepo.FindByID(ctx, questionID)
 	if err != nil || question == nil {
-		return false, 0, errors.New("pregunta no encontrada")
+		return nil, errors.New("pregunta no encontrada")
 	}
 	if question.RoomID != room.ID {
-		return false, 0, errors.New("la pregunta no pertenece a esta sala")
+		return nil, errors.New("la pregunta no pertenece a esta sala")
 	}
 	if question.Status != domain.QuestionStatusOpen {
-		return false, 0, errors.New("la pregunta ya está cerrada")
+		return nil, errors.New("la pregunta ya está cerrada")
 	}
 
 	// Verificar que no haya respondido ya
-	already, _ := s.answerRepo.HasAnswered(questionID, userID)
+	already, _ := s.answerRepo.HasAnswered(ctx, questionID, userID)
 	if already {
-		return false, 0, errors.New("ya respondiste esta pregunta")
+		return nil, errors.New("ya respondiste esta pregunta")
 	}
 
 	// Evaluar respuesta (case-insensitive, trimmed)
@@ -118,29 +145,53 @@ func (s *QuestionService) SubmitAnswer(roomCode string, userID int, questionID i
 		UserID:     userID,
 		Text:       answerText,
 		IsCorrect:  isCorrect,
+		ResponseMS: time.Since(question.CreatedAt).Milliseconds(),
 	}
-	if err := s.answerRepo.Create(answer); err != nil {
-		return false, 0, err
-	}
-
-	pointsEarned := 0
-	if isCorrect {
-		pointsEarned = question.Points
-		if err := s.scoreRepo.AddPoints(room.ID, userID, pointsEarned); err != nil {
-			return true, 0, err
+	// La respuesta, la racha y los puntos se guardan en la misma transacción:
+	// si algo falla no queda una respuesta sin puntos que impida reintentar
+	result := &domain.AnswerResult{IsCorrect: isCorrect}
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.answerRepo.Create(ctx, answer); err != nil {
+			return err
+		}
+		streak, err := s.scoreRepo.UpdateStreak(ctx, room.ID, userID, isCorrect)
+		if err != nil {
+			return err
 		}
+		result.Streak = streak
+		if !isCorrect {
+			return nil
+		}
+		result.Points = room.Streak.Points(question.Points, streak)
+		result.Bonus = result.Points - question.Points
+		reason := "respuesta correcta"
+		if result.Bonus > 0 {
+			reason = fmt.Sprintf("respuesta correcta (racha de %d)", streak)
+		}
+		ev := &domain.ScoreEvent{
+			RoomID:     room.ID,
+			UserID:     userID,
+			ActorID:    userID,
+			Source:     domain.ScoreSourceAnswer,
+			Delta:      result.Points,
+			Reason:     reason,
+			QuestionID: questionID,
+		}
+		return s.scoreRepo.AddPoints(ctx, ev)
+	})
+	if err != nil {
+		return nil, err
 	}
-
-	return isCorrect, pointsEarned, nil
+	return result, nil
 }
 
 // GetCurrentQuestion devuelve la pregunta actualmente abierta en una sala
-func (s *QuestionService) GetCurrentQuestion(roomCode string) (*domain.Question, error) {
-	room, err := s.roomRepo.FindByCode(roomCode)
+func (s *QuestionService) GetCurrentQuestion(ctx context.Context, roomCode string) (*domain.Question, error) {
+	room, err := s.roomRepo.FindByCode(ctx, roomCode)
 	if err != nil || room == nil {
 		return nil, errors.New("sala no encontrada")
 	}
-	q, err := s.questionRepo.FindOpenByRoom(room.ID)
+	q, err := s.questionRepo.FindOpenByRoom(ctx, room.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -148,13 +199,63 @@ func (s *QuestionService) GetCurrentQuestion(roomCode string) (*domain.Question,
 }
 
 // GetRoomService agrega ResetPoints al scoreService (host)
-func (s *QuestionService) GetAnswers(roomCode string, hostID, questionID int) ([]domain.Answer, error) {
-	room, err := s.roomRepo.FindByCode(roomCode)
+func (s *QuestionService) GetAnswers(ctx context.Context, roomCode string, hostID, questionID int) ([]domain.Answer, error) {
+	room, err := s.roomRepo.FindByCode(ctx, roomCode)
 	if err != nil || room == nil {
 		return nil, errors.New("sala no encontrada")
 	}
 	if room.HostID != hostID {
 		return nil, errors.New("solo el host puede ver las respuestas")
 	}
-	return s.answerRepo.FindByQuestion(questionID)
+	return s.answerRepo.FindByQuestion(ctx, questionID)
+}
+
+// CountAnswers devuelve cuántas respuestas recibió una pregunta y cuántas fueron correctas
+func (s *QuestionService) CountAnswers(ctx context.Context, questionID int) (total, correct int, err error) {
+	return s.answerRepo.CountByQuestion(ctx, questionID)
+}
+
+// RoomHostID devuelve el id del host de la sala, el único que recibe en vivo
+// las respuestas de los participantes
+func (s *QuestionService) RoomHostID(ctx context.Context, roomCode string) (int, error) {
+	room, err := s.roomRepo.FindByCode(ctx, roomCode)
+	if err != nil || room == nil {
+		return 0, errors.New("sala no encontrada")
+	}
+	return room.HostID, nil
+}
+
+// GetQuestionStats devuelve las estadísticas de una pregunta de la sala (solo host)
+func (s *QuestionService) GetQuestionStats(ctx context.Context, roomCode string, hostID, questionID int) (*domain.QuestionStats, error) {
+	room, err := s.roomRepo.FindByCode(ctx, roomCode)
+	if err != nil || room == nil {
+		return nil, errors.New("sala no encontrada")
+	}
+	if room.HostID != hostID {
+		return nil, errors.New("solo el host puede ver las estadísticas")
+	}
+	return s.questionStats(ctx, room, questionID)
+}
+
+// QuestionStats devuelve las estadísticas de una pregunta sin verificar quién
+// las pide; es para el evento en vivo que solo recibe el host de la sala
+func (s *QuestionService) QuestionStats(ctx context.Context, roomCode string, questionID int) (*domain.QuestionStats, error) {
+	room, err := s.roomRepo.FindByCode(ctx, roomCode)
+	if err != nil || room == nil {
+		return nil, errors.New("sala no encontrada")
+	}
+	return s.questionStats(ctx, room, questionID)
+}
+
+func (s *QuestionService) questionStats(ctx context.Context, room *domain.Room, questionID int) (*domain.QuestionStats, error) {
+	question, err := s.questionRepo.FindByID(ctx, questionID)
+	if err != nil || question == nil || question.RoomID != room.ID {
+		return nil, errors.New("pregunta no encontrada")
+	}
+	return s.answerRepo.StatsByQuestion(ctx, question.ID, room.ID)
+}
+
+// HasAnswered indica si el usuario ya respondió la pregunta
+func (s *QuestionService) HasAnswered(ctx context.Context, questionID, userID int) (bool, error) {
+	return s.answerRepo.HasAnswered(ctx, questionID, userID)
 }
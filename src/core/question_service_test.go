@@ -0,0 +1,182 @@
+package core_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"apiGolan/src/core"
+	"apiGolan/src/domain"
+	"apiGolan/src/infrastructure/repository"
+)
+
+// failingAddPoints falla al sumar puntos, después de guardar la respuesta y la racha
+type failingAddPoints struct {
+	domain.ScoreRepository
+}
+
+func (failingAddPoints) AddPoints(ctx context.Context, ev *domain.ScoreEvent) error {
+	return errors.New("fallo al sumar puntos")
+}
+
+func TestSubmitAnswerIsAtomic(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+	host, room, players := seedGame(t, db, "ana")
+	ana := players[0]
+	questions := repository.NewQuestionRepo(db)
+	answers := repository.NewAnswerRepo(db)
+	scores := repository.NewScoreRepo(db)
+	rooms := repository.NewRoomRepo(db)
+	tx := repository.NewTransactor(db)
+
+	svc := core.NewQuestionService(questions, answers, scores, rooms, tx)
+	q, err := svc.LaunchQuestion(ctx, room.Code, host.ID, "¿2+2?", "4", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failing := core.NewQuestionService(questions, answers, failingAddPoints{scores}, rooms, tx)
+	if _, err := failing.SubmitAnswer(ctx, room.Code, ana.ID, q.ID, "4"); err == nil {
+		t.Fatal("se esperaba el error de AddPoints")
+	}
+	if answered, err := answers.HasAnswered(ctx, q.ID, ana.ID); err != nil || answered {
+		t.Fatalf("quedó la respuesta sin sus puntos: %v, %v", answered, err)
+	}
+	// la racha tampoco se guardó (ni siquiera la fila del puntaje)
+	entry, err := scores.GetRankingEntry(ctx, domain.RankingQuery{RoomID: room.ID}, ana.ID)
+	if err != nil || entry != nil && (entry.Points != 0 || entry.Streak != 0) {
+		t.Fatalf("puntaje tras el fallo = %+v, %v", entry, err)
+	}
+
+	// el participante puede reintentar y la respuesta cuenta normalmente
+	result, err := svc.SubmitAnswer(ctx, room.Code, ana.ID, q.ID, " 4 ")
+	if err != nil {
+		t.Fatalf("reintento: %v", err)
+	}
+	if !result.IsCorrect || result.Points != 10 || result.Streak != 1 {
+		t.Errorf("resultado = %+v", result)
+	}
+	entry, err = scores.GetRankingEntry(ctx, domain.RankingQuery{RoomID: room.ID}, ana.ID)
+	if err != nil || entry == nil || entry.Points != 10 || entry.Streak != 1 {
+		t.Errorf("puntaje tras el reintento = %+v, %v", entry, err)
+	}
+}
+
+// failingResetStreaks falla al cortar las rachas, después de cerrar la pregunta
+type failingResetStreaks struct {
+	domain.ScoreRepository
+}
+
+func (failingResetStreaks) ResetMissedStreaks(ctx context.Context, roomID, questionID int) error {
+	return errors.New("fallo al cortar las rachas")
+}
+
+func TestCloseQuestionIsAtomic(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+	host, room, _ := seedGame(t, db, "ana")
+	questions := repository.NewQuestionRepo(db)
+	answers := repository.NewAnswerRepo(db)
+	scores := repository.NewScoreRepo(db)
+	rooms := repository.NewRoomRepo(db)
+	tx := repository.NewTransactor(db)
+
+	svc := core.NewQuestionService(questions, answers, scores, rooms, tx)
+	q, err := svc.LaunchQuestion(ctx, room.Code, host.ID, "¿2+2?", "4", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failing := core.NewQuestionService(questions, answers, failingResetStreaks{scores}, rooms, tx)
+	if err := failing.CloseQuestion(ctx, room.Code, host.ID, q.ID); err == nil {
+		t.Fatal("se esperaba el error de ResetMissedStreaks")
+	}
+	if current, err := svc.GetCurrentQuestion(ctx, room.Code); err != nil || current == nil || current.ID != q.ID {
+		t.Fatalf("la pregunta quedó cerrada sin cortar las rachas: %+v, %v", current, err)
+	}
+
+	// lanzar otra pregunta cierra la anterior con la misma garantía
+	if _, err := failing.LaunchQuestion(ctx, room.Code, host.ID, "¿3+3?", "6", 10); err == nil {
+		t.Fatal("se esperaba el error al cerrar la pregunta anterior")
+	}
+	if current, _ := svc.GetCurrentQuestion(ctx, room.Code); current == nil || current.ID != q.ID {
+		t.Errorf("pregunta abierta = %+v, se esperaba la %d", current, q.ID)
+	}
+
+	if err := svc.CloseQuestion(ctx, room.Code, host.ID, q.ID); err != nil {
+		t.Fatalf("reintento: %v", err)
+	}
+	if current, err := svc.GetCurrentQuestion(ctx, room.Code); err != nil || current != nil {
+		t.Errorf("pregunta abierta tras cerrar = %+v, %v", current, err)
+	}
+}
+
+func TestGetQuestionStats(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+	host, room, players := seedGame(t, db, "ana", "beto", "carla")
+	rooms := repository.NewRoomRepo(db)
+	scores := repository.NewScoreRepo(db)
+	svc := core.NewQuestionService(repository.NewQuestionRepo(db), repository.NewAnswerRepo(db), scores, rooms, repository.NewTransactor(db))
+
+	q, err := svc.LaunchQuestion(ctx, room.Code, host.ID, "¿2+2?", "4", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, text := range []string{"4", "5"} {
+		if _, err := svc.SubmitAnswer(ctx, room.Code, players[i].ID, q.ID, text); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// una pregunta de otra sala del mismo host no se ve desde ABC123
+	other := &domain.Room{Code: "XYZ789", HostID: host.ID, Status: domain.RoomStatusActive,
+		RankingMode: domain.RankingCompetition, Streak: domain.StreakConfig{Mode: domain.StreakNone}}
+	if err := rooms.Create(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+	foreign, err := svc.LaunchQuestion(ctx, other.Code, host.ID, "¿3+3?", "6", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		code        string
+		requesterID int
+		questionID  int
+		wantErr     string
+	}{
+		{"host de la sala", room.Code, host.ID, q.ID, ""},
+		{"un participante", room.Code, players[0].ID, q.ID, "solo el host puede ver las estadísticas"},
+		{"pregunta de otra sala", room.Code, host.ID, foreign.ID, "pregunta no encontrada"},
+		{"pregunta inexistente", room.Code, host.ID, 9999, "pregunta no encontrada"},
+		{"sala inexistente", "NOPE00", host.ID, q.ID, "sala no encontrada"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats, err := svc.GetQuestionStats(ctx, tt.code, tt.requesterID, tt.questionID)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, se esperaba %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stats.Participants != 3 || stats.Answered != 2 || stats.NotAnswered != 1 || stats.Correct != 1 || stats.PercentCorrect != 50 {
+				t.Errorf("stats = %+v", stats)
+			}
+			if len(stats.TopWrong) != 1 || stats.TopWrong[0].Answer != "5" {
+				t.Errorf("top_wrong = %+v, se esperaba solo \"5\"", stats.TopWrong)
+			}
+		})
+	}
+
+	// el evento en vivo usa QuestionStats, que no mira quién pide
+	if live, err := svc.QuestionStats(ctx, room.Code, q.ID); err != nil || live.Answered != 2 {
+		t.Errorf("QuestionStats = %+v, %v", live, err)
+	}
+}
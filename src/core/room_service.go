@@ -1,6 +1,7 @@
 package core
 
 import (
+	"context"
 	"errors"
 	"math/rand"
 	"strings"
@@ -28,25 +29,38 @@ func NewRoomService(
 	}
 }
 
-// CreateRoom genera un código único y crea la sala
-func (s *RoomService) CreateRoom(hostID int) (*domain.Room, error) {
+// CreateRoom genera un código único y crea la sala. mode vacío = competition;
+// streak vacío = sin premio por racha.
+func (s *RoomService) CreateRoom(ctx context.Context, hostID int, mode domain.RankingMode, streak domain.StreakConfig) (*domain.Room, error) {
+	if mode == "" {
+		mode = domain.RankingCompetition
+	}
+	if !mode.Valid() {
+		return nil, errors.New("ranking_mode debe ser competition o dense")
+	}
+	if err := validateStreak(&streak); err != nil {
+		return nil, err
+	}
+
 	code := generateCode()
 
 	room := &domain.Room{
-		Code:   code,
-		HostID: hostID,
-		Status: domain.RoomStatusWaiting,
+		Code:        code,
+		HostID:      hostID,
+		Status:      domain.RoomStatusWaiting,
+		RankingMode: mode,
+		Streak:      streak,
 	}
 
-	if err := s.roomRepo.Create(room); err != nil {
+	if err := s.roomRepo.Create(ctx, room); err != nil {
 		return nil, err
 	}
 
 	return room, nil
 }
 
-func (s *RoomService) JoinRoom(code string, userID int) error {
-	room, err := s.roomRepo.FindByCode(code)
+func (s *RoomService) JoinRoom(ctx context.Context, code string, userID int) error {
+	room, err := s.roomRepo.FindByCode(ctx, code)
 	if err != nil || room == nil {
 		return errors.New("sala no encontrada")
 	}
@@ -55,7 +69,7 @@ func (s *RoomService) JoinRoom(code string, userID int) error {
 		return errors.New("la sala ya terminó")
 	}
 
-	exists, _ := s.participantRepo.ExistsInRoom(room.ID, userID)
+	exists, _ := s.participantRepo.ExistsInRoom(ctx, room.ID, userID)
 	if exists {
 		return errors.New("ya estás en esta sala")
 	}
@@ -65,26 +79,26 @@ func (s *RoomService) JoinRoom(code string, userID int) error {
 		UserID: userID,
 	}
 
-	if err := s.participantRepo.Add(participant); err != nil {
+	if err := s.participantRepo.Add(ctx, participant); err != nil {
 		return err
 	}
 
 	// Inicializar el score en 0 al unirse
-	return s.scoreRepo.Upsert(room.ID, userID, 0)
+	return s.scoreRepo.Upsert(ctx, room.ID, userID, 0)
 }
 
 // StartSession marca la sala como activa (solo el host puede hacer esto)
-func (s *RoomService) StartSession(code string, requesterID int) error {
-	return s.changeStatus(code, requesterID, domain.RoomStatusWaiting, domain.RoomStatusActive)
+func (s *RoomService) StartSession(ctx context.Context, code string, requesterID int) error {
+	return s.changeStatus(ctx, code, requesterID, domain.RoomStatusWaiting, domain.RoomStatusActive)
 }
 
 // EndSession marca la sala como finalizada
-func (s *RoomService) EndSession(code string, requesterID int) error {
-	return s.changeStatus(code, requesterID, domain.RoomStatusActive, domain.RoomStatusFinished)
+func (s *RoomService) EndSession(ctx context.Context, code string, requesterID int) error {
+	return s.changeStatus(ctx, code, requesterID, domain.RoomStatusActive, domain.RoomStatusFinished)
 }
 
-func (s *RoomService) changeStatus(code string, requesterID int, from, to domain.RoomStatus) error {
-	room, err := s.roomRepo.FindByCode(code)
+func (s *RoomService) changeStatus(ctx context.Context, code string, requesterID int, from, to domain.RoomStatus) error {
+	room, err := s.roomRepo.FindByCode(ctx, code)
 	if err != nil || room == nil {
 		return errors.New("sala no encontrada")
 	}
@@ -98,12 +112,12 @@ func (s *RoomService) changeStatus(code string, requesterID int, from, to domain
 		return errors.New("la sala no está en el estado correcto para esta acción")
 	}
 
-	return s.roomRepo.UpdateStatus(code, to)
+	return s.roomRepo.UpdateStatus(ctx, code, to)
 }
 
 // GetRoom devuelve una sala por código
-func (s *RoomService) GetRoom(code string) (*domain.Room, error) {
-	room, err := s.roomRepo.FindByCode(code)
+func (s *RoomService) GetRoom(ctx context.Context, code string) (*domain.Room, error) {
+	room, err := s.roomRepo.FindByCode(ctx, code)
 	if err != nil || room == nil {
 		return nil, errors.New("sala no encontrada")
 	}
@@ -111,17 +125,17 @@ func (s *RoomService) GetRoom(code string) (*domain.Room, error) {
 }
 
 // GetParticipants lista los participantes de una sala con sus datos de usuario
-func (s *RoomService) GetParticipants(code string) ([]domain.ParticipantWithUser, error) {
-	room, err := s.roomRepo.FindByCode(code)
+func (s *RoomService) GetParticipants(ctx context.Context, code string) ([]domain.ParticipantWithUser, error) {
+	room, err := s.roomRepo.FindByCode(ctx, code)
 	if err != nil || room == nil {
 		return nil, errors.New("sala no encontrada")
 	}
-	return s.participantRepo.FindByRoomWithUsers(room.ID)
+	return s.participantRepo.FindByRoomWithUsers(ctx, room.ID)
 }
 
 // KickParticipant expulsa a un participante (solo el host puede hacerlo)
-func (s *RoomService) KickParticipant(code string, hostID, targetUserID int) error {
-	room, err := s.roomRepo.FindByCode(code)
+func (s *RoomService) KickParticipant(ctx context.Context, code string, hostID, targetUserID int) error {
+	room, err := s.roomRepo.FindByCode(ctx, code)
 	if err != nil || room == nil {
 		return errors.New("sala no encontrada")
 	}
@@ -132,16 +146,21 @@ func (s *RoomService) KickParticipant(code string, hostID, targetUserID int) err
 		return errors.New("no puedes expulsarte a ti mismo")
 	}
 
-	exists, _ := s.participantRepo.ExistsInRoom(room.ID, targetUserID)
+	exists, _ := s.participantRepo.ExistsInRoom(ctx, room.ID, targetUserID)
 	if !exists {
 		return errors.New("el usuario no está en esta sala")
 	}
 
 	// Eliminar de participantes y también su score
-	if err := s.participantRepo.Remove(room.ID, targetUserID); err != nil {
+	if err := s.participantRepo.Remove(ctx, room.ID, targetUserID); err != nil {
 		return err
 	}
-	return s.scoreRepo.ResetPoints(room.ID, targetUserID)
+	return s.scoreRepo.ResetPoints(ctx, domain.ScoreEvent{
+		RoomID:  room.ID,
+		UserID:  targetUserID,
+		ActorID: hostID,
+		Reason:  "participante expulsado",
+	})
 }
 
 // generateCode genera un código aleatorio de 6 caracteres tipo ABC123
@@ -154,3 +173,31 @@ func generateCode() string {
 	}
 	return sb.String()
 }
+
+// Límites del premio por racha
+const (
+	maxStreakStep = 1000 // puntos o % por nivel
+	maxStreakCap  = 100
+)
+
+// validateStreak completa el modo por defecto y revisa los límites. Sin
+// premio, step y max no se usan y quedan en 0.
+func validateStreak(c *domain.StreakConfig) error {
+	if c.Mode == "" {
+		c.Mode = domain.StreakNone
+	}
+	if !c.Mode.Valid() {
+		return errors.New("el modo de racha debe ser none, bonus o multiplier")
+	}
+	if c.Mode == domain.StreakNone {
+		c.Step, c.Max = 0, 0
+		return nil
+	}
+	if c.Step <= 0 || c.Step > maxStreakStep {
+		return errors.New("step debe estar entre 1 y 1000")
+	}
+	if c.Max < 0 || c.Max > maxStreakCap {
+		return errors.New("max debe estar entre 0 y 100")
+	}
+	return nil
+}
@@ -1,7 +1,9 @@
 package core
 
 import (
+	"context"
 	"errors"
+	"unicode/utf8"
 
 	"apiGolan/src/domain"
 )
@@ -10,16 +12,29 @@ import (
 type ScoreService struct {
 	scoreRepo domain.ScoreRepository
 	roomRepo  domain.RoomRepository
+	topSize   int // posiciones que incluye TopRanking (0 = todas)
 }
 
-func NewScoreService(scoreRepo domain.ScoreRepository, roomRepo domain.RoomRepository) *ScoreService {
-	return &ScoreService{scoreRepo: scoreRepo, roomRepo: roomRepo}
+// NewScoreService crea el servicio; topSize es cuántas posiciones viajan en
+// score_update y room_state (0 = el ranking completo)
+func NewScoreService(scoreRepo domain.ScoreRepository, roomRepo domain.RoomRepository, topSize int) *ScoreService {
+	return &ScoreService{scoreRepo: scoreRepo, roomRepo: roomRepo, topSize: topSize}
 }
 
+// maxReasonLength es el largo máximo del motivo de un cambio de puntos
+const maxReasonLength = 255
+
 // AddPoints suma o resta puntos a un participante (solo el host puede hacerlo)
-// delta puede ser positivo (+10) o negativo (-5)
-func (s *ScoreService) AddPoints(code string, requesterID, targetUserID, delta int) error {
-	room, err := s.roomRepo.FindByCode(code)
+// delta puede ser positivo (+10) o negativo (-5); reason queda en el ledger
+func (s *ScoreService) AddPoints(ctx context.Context, code string, requesterID, targetUserID, delta int, reason string) error {
+	if delta == 0 {
+		return errors.New("el delta no puede ser 0")
+	}
+	if err := validateReason(reason); err != nil {
+		return err
+	}
+
+	room, err := s.roomRepo.FindByCode(ctx, code)
 	if err != nil || room == nil {
 		return errors.New("sala no encontrada")
 	}
@@ -34,39 +49,207 @@ func (s *ScoreService) AddPoints(code string, requesterID, targetUserID, delta i
 		return errors.New("la sesión no está activa")
 	}
 
-	return s.scoreRepo.AddPoints(room.ID, targetUserID, delta)
+	return s.scoreRepo.AddPoints(ctx, &domain.ScoreEvent{
+		RoomID:  room.ID,
+		UserID:  targetUserID,
+		ActorID: requesterID,
+		Source:  domain.ScoreSourceManual,
+		Delta:   delta,
+		Reason:  reason,
+	})
 }
 
-// GetRanking devuelve el ranking ordenado de una sala
-func (s *ScoreService) GetRanking(code string) ([]domain.RankingEntry, error) {
-	room, err := s.roomRepo.FindByCode(code)
+// GetRanking devuelve una página del ranking de una sala (limit 0 = completo)
+func (s *ScoreService) GetRanking(ctx context.Context, code string, limit, offset int) ([]domain.RankingEntry, error) {
+	room, err := s.roomRepo.FindByCode(ctx, code)
 	if err != nil || room == nil {
 		return nil, errors.New("sala no encontrada")
 	}
+	if limit < 0 {
+		limit = 0
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	return s.scoreRepo.GetRanking(ctx, domain.RankingQuery{
+		RoomID: room.ID,
+		Mode:   room.RankingMode,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+// TopRanking devuelve las primeras posiciones, las que se envían a toda la sala
+func (s *ScoreService) TopRanking(ctx context.Context, code string) ([]domain.RankingEntry, error) {
+	return s.GetRanking(ctx, code, s.topSize, 0)
+}
 
-	return s.scoreRepo.GetRanking(room.ID)
+// GetPosition devuelve el puesto de un usuario en la sala; nil si no tiene puntos
+func (s *ScoreService) GetPosition(ctx context.Context, code string, userID int) (*domain.RankingEntry, error) {
+	room, err := s.roomRepo.FindByCode(ctx, code)
+	if err != nil || room == nil {
+		return nil, errors.New("sala no encontrada")
+	}
+	return s.scoreRepo.GetRankingEntry(ctx, domain.RankingQuery{RoomID: room.ID, Mode: room.RankingMode}, userID)
+}
+
+// SetRankingMode cambia cómo se numeran los empates en la sala (solo host)
+func (s *ScoreService) SetRankingMode(ctx context.Context, code string, hostID int, mode domain.RankingMode) error {
+	if !mode.Valid() {
+		return errors.New("ranking_mode debe ser competition o dense")
+	}
+	room, err := s.roomRepo.FindByCode(ctx, code)
+	if err != nil || room == nil {
+		return errors.New("sala no encontrada")
+	}
+	if room.HostID != hostID {
+		return errors.New("solo el host puede cambiar el ranking")
+	}
+	return s.roomRepo.UpdateRankingMode(ctx, code, mode)
+}
+
+// SetStreak cambia el premio por racha de la sala (solo host). Vale para las
+// respuestas siguientes; las rachas en curso se mantienen.
+func (s *ScoreService) SetStreak(ctx context.Context, code string, hostID int, streak domain.StreakConfig) (*domain.StreakConfig, error) {
+	if err := validateStreak(&streak); err != nil {
+		return nil, err
+	}
+	room, err := s.roomRepo.FindByCode(ctx, code)
+	if err != nil || room == nil {
+		return nil, errors.New("sala no encontrada")
+	}
+	if room.HostID != hostID {
+		return nil, errors.New("solo el host puede cambiar el premio por racha")
+	}
+	if err := s.roomRepo.UpdateStreak(ctx, code, streak); err != nil {
+		return nil, err
+	}
+	return &streak, nil
 }
 
 // ResetUserPoints resetea los puntos de un participante específico (solo host)
-func (s *ScoreService) ResetUserPoints(code string, hostID, targetUserID int) error {
-	room, err := s.roomRepo.FindByCode(code)
+func (s *ScoreService) ResetUserPoints(ctx context.Context, code string, hostID, targetUserID int, reason string) error {
+	if err := validateReason(reason); err != nil {
+		return err
+	}
+	room, err := s.roomRepo.FindByCode(ctx, code)
 	if err != nil || room == nil {
 		return errors.New("sala no encontrada")
 	}
 	if room.HostID != hostID {
 		return errors.New("solo el host puede resetear puntos")
 	}
-	return s.scoreRepo.ResetPoints(room.ID, targetUserID)
+	return s.scoreRepo.ResetPoints(ctx, domain.ScoreEvent{
+		RoomID:  room.ID,
+		UserID:  targetUserID,
+		ActorID: hostID,
+		Reason:  reason,
+	})
 }
 
 // ResetAllPoints resetea los puntos de todos en la sala (solo host)
-func (s *ScoreService) ResetAllPoints(code string, hostID int) error {
-	room, err := s.roomRepo.FindByCode(code)
+func (s *ScoreService) ResetAllPoints(ctx context.Context, code string, hostID int, reason string) error {
+	if err := validateReason(reason); err != nil {
+		return err
+	}
+	room, err := s.roomRepo.FindByCode(ctx, code)
 	if err != nil || room == nil {
 		return errors.New("sala no encontrada")
 	}
 	if room.HostID != hostID {
 		return errors.New("solo el host puede resetear puntos")
 	}
-	return s.scoreRepo.ResetAllPoints(room.ID)
+	return s.scoreRepo.ResetAllPoints(ctx, room.ID, hostID, reason)
+}
+
+// ListScoreEvents devuelve el ledger de cambios de puntos de la sala (solo host)
+func (s *ScoreService) ListScoreEvents(ctx context.Context, code string, hostID int, filter domain.ScoreEventFilter) ([]domain.ScoreEvent, error) {
+	room, err := s.roomRepo.FindByCode(ctx, code)
+	if err != nil || room == nil {
+		return nil, errors.New("sala no encontrada")
+	}
+	if room.HostID != hostID {
+		return nil, errors.New("solo el host puede ver el historial de puntos")
+	}
+
+	filter.RoomID = room.ID
+	if filter.Limit <= 0 || filter.Limit > 100 {
+		filter.Limit = 50
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	return s.scoreRepo.ListEvents(ctx, filter)
+}
+
+// UndoScoreEvent revierte un evento del ledger registrando otro con el delta
+// opuesto (solo host). Un evento se deshace una sola vez y las reversiones no
+// se pueden deshacer.
+func (s *ScoreService) UndoScoreEvent(ctx context.Context, code string, hostID, eventID int, reason string) (*domain.ScoreEvent, error) {
+	if err := validateReason(reason); err != nil {
+		return nil, err
+	}
+	room, err := s.roomRepo.FindByCode(ctx, code)
+	if err != nil || room == nil {
+		return nil, errors.New("sala no encontrada")
+	}
+	if room.HostID != hostID {
+		return nil, errors.New("solo el host puede deshacer cambios de puntos")
+	}
+
+	ev, err := s.scoreRepo.FindEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if ev == nil || ev.RoomID != room.ID {
+		return nil, errors.New("evento no encontrado")
+	}
+	if ev.Source == domain.ScoreSourceUndo {
+		return nil, errors.New("no se puede deshacer una reversión")
+	}
+	if ev.Source == domain.ScoreSourceOpening {
+		return nil, errors.New("no se puede deshacer el saldo previo al historial")
+	}
+	if ev.RevertedBy != 0 {
+		return nil, errors.New("el evento ya fue deshecho")
+	}
+
+	undo := &domain.ScoreEvent{
+		RoomID:    room.ID,
+		UserID:    ev.UserID,
+		ActorID:   hostID,
+		Source:    domain.ScoreSourceUndo,
+		Delta:     -ev.Delta,
+		Reason:    reason,
+		RevertsID: ev.ID,
+	}
+	ok, err := s.scoreRepo.Revert(ctx, undo)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("el evento ya fue deshecho")
+	}
+	return undo, nil
+}
+
+// ReconcileScores iguala los puntos de la sala a la suma de su ledger (solo host)
+// y devuelve los participantes que estaban desfasados
+func (s *ScoreService) ReconcileScores(ctx context.Context, code string, hostID int) ([]domain.ScoreDrift, error) {
+	room, err := s.roomRepo.FindByCode(ctx, code)
+	if err != nil || room == nil {
+		return nil, errors.New("sala no encontrada")
+	}
+	if room.HostID != hostID {
+		return nil, errors.New("solo el host puede reconciliar los puntos")
+	}
+	return s.scoreRepo.Reconcile(ctx, room.ID)
+}
+
+func validateReason(reason string) error {
+	if utf8.RuneCountInString(reason) > maxReasonLength {
+		return errors.New("el motivo no puede superar 255 caracteres")
+	}
+	return nil
 }
@@ -0,0 +1,141 @@
+package core_test
+
+import (
+	"context"
+	"testing"
+
+	"apiGolan/src/core"
+	"apiGolan/src/domain"
+	infradb "apiGolan/src/infrastructure/db"
+	"apiGolan/src/infrastructure/repository"
+)
+
+// seedGame crea un host y una sala activa con los participantes indicados
+func seedGame(t *testing.T, db *infradb.DB, names ...string) (*domain.User, *domain.Room, []*domain.User) {
+	t.Helper()
+	ctx := context.Background()
+	users := repository.NewUserRepo(db)
+
+	host := &domain.User{Name: "host", Email: "host@x.com", Password: "x", Role: domain.RoleHost}
+	if err := users.Create(ctx, host); err != nil {
+		t.Fatal(err)
+	}
+	room := &domain.Room{
+		Code:        "ABC123",
+		HostID:      host.ID,
+		Status:      domain.RoomStatusActive,
+		RankingMode: domain.RankingCompetition,
+		Streak:      domain.StreakConfig{Mode: domain.StreakNone},
+	}
+	if err := repository.NewRoomRepo(db).Create(ctx, room); err != nil {
+		t.Fatal(err)
+	}
+
+	participants := make([]*domain.User, 0, len(names))
+	for _, name := range names {
+		u := &domain.User{Name: name, Email: name + "@x.com", Password: "x", Role: domain.RoleParticipant}
+		if err := users.Create(ctx, u); err != nil {
+			t.Fatal(err)
+		}
+		if err := repository.NewParticipantRepo(db).Add(ctx, &domain.Participant{RoomID: room.ID, UserID: u.ID}); err != nil {
+			t.Fatal(err)
+		}
+		participants = append(participants, u)
+	}
+	return host, room, participants
+}
+
+func TestUndoScoreEvent(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+	host, room, players := seedGame(t, db, "ana")
+	ana := players[0]
+	scores := repository.NewScoreRepo(db)
+	svc := core.NewScoreService(scores, repository.NewRoomRepo(db), 10)
+
+	if err := svc.AddPoints(ctx, room.Code, host.ID, ana.ID, 10, "bonus"); err != nil {
+		t.Fatal(err)
+	}
+	events, err := svc.ListScoreEvents(ctx, room.Code, host.ID, domain.ScoreEventFilter{})
+	if err != nil || len(events) != 1 {
+		t.Fatalf("ListScoreEvents = %+v, %v", events, err)
+	}
+	original := events[0].ID
+
+	undo, err := svc.UndoScoreEvent(ctx, room.Code, host.ID, original, "error de tipeo")
+	if err != nil {
+		t.Fatalf("UndoScoreEvent: %v", err)
+	}
+	if undo.Delta != -10 || undo.RevertsID != original || undo.Source != domain.ScoreSourceUndo {
+		t.Errorf("undo = %+v", undo)
+	}
+
+	tests := []struct {
+		name    string
+		eventID int
+		want    string
+	}{
+		{"deshacer dos veces", original, "el evento ya fue deshecho"},
+		{"deshacer una reversión", undo.ID, "no se puede deshacer una reversión"},
+		{"evento inexistente", undo.ID + 100, "evento no encontrado"},
+	}
+	for _, tt := range tests {
+		_, err := svc.UndoScoreEvent(ctx, room.Code, host.ID, tt.eventID, "")
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%s: error = %v, se esperaba %q", tt.name, err, tt.want)
+		}
+	}
+
+	// ninguno de los intentos fallidos tocó los puntos ni el ledger
+	score, err := scores.GetRankingEntry(ctx, domain.RankingQuery{RoomID: room.ID}, ana.ID)
+	if err != nil || score == nil || score.Points != 0 {
+		t.Errorf("puntos de ana = %+v, %v; se esperaban 0", score, err)
+	}
+	if events, _ := svc.ListScoreEvents(ctx, room.Code, host.ID, domain.ScoreEventFilter{}); len(events) != 2 {
+		t.Errorf("el ledger tiene %d eventos, se esperaban 2", len(events))
+	}
+	if drifts, err := svc.ReconcileScores(ctx, room.Code, host.ID); err != nil || len(drifts) != 0 {
+		t.Errorf("ReconcileScores = %+v, %v; se esperaba sin desfase", drifts, err)
+	}
+}
+
+func TestUndoScoreEventOnlyHost(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+	host, room, players := seedGame(t, db, "ana")
+	svc := core.NewScoreService(repository.NewScoreRepo(db), repository.NewRoomRepo(db), 10)
+
+	if err := svc.AddPoints(ctx, room.Code, host.ID, players[0].ID, 10, ""); err != nil {
+		t.Fatal(err)
+	}
+	events, _ := svc.ListScoreEvents(ctx, room.Code, host.ID, domain.ScoreEventFilter{})
+	if _, err := svc.UndoScoreEvent(ctx, room.Code, players[0].ID, events[0].ID, ""); err == nil {
+		t.Error("un participante pudo deshacer un cambio de puntos")
+	}
+}
+
+func TestUndoScoreEventKeepsOpeningBalance(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+	host, room, players := seedGame(t, db, "ana")
+	scores := repository.NewScoreRepo(db)
+	svc := core.NewScoreService(scores, repository.NewRoomRepo(db), 10)
+
+	// como lo deja la migración: sin actor y con el motivo del saldo previo
+	opening := &domain.ScoreEvent{RoomID: room.ID, UserID: players[0].ID, Source: domain.ScoreSourceOpening, Delta: 30}
+	if err := scores.AddPoints(ctx, opening); err != nil {
+		t.Fatal(err)
+	}
+	events, err := svc.ListScoreEvents(ctx, room.Code, host.ID, domain.ScoreEventFilter{})
+	if err != nil || len(events) != 1 {
+		t.Fatalf("ListScoreEvents = %+v, %v", events, err)
+	}
+
+	if _, err := svc.UndoScoreEvent(ctx, room.Code, host.ID, events[0].ID, ""); err == nil || err.Error() != "no se puede deshacer el saldo previo al historial" {
+		t.Errorf("UndoScoreEvent del saldo previo: error = %v", err)
+	}
+	score, err := scores.GetRankingEntry(ctx, domain.RankingQuery{RoomID: room.ID}, players[0].ID)
+	if err != nil || score == nil || score.Points != 30 {
+		t.Errorf("puntos tras intentar deshacer = %+v, %v; se esperaban 30", score, err)
+	}
+}
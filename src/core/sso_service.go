@@ -0,0 +1,140 @@
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"apiGolan/src/domain"
+)
+
+// SSOLogin es lo que hay que guardar en el navegador entre el inicio del
+// login y el callback del proveedor
+type SSOLogin struct {
+	URL      string // a donde redirigir al usuario
+	State    string // protege el callback contra CSRF
+	Verifier string // PKCE: solo viaja al canjear el código
+	Nonce    string // se compara con el del ID token
+}
+
+// SSOService contiene la lógica del login con un proveedor OpenID Connect.
+type SSOService struct {
+	users       domain.UserRepository
+	identities  domain.IdentityRepository
+	provider    domain.IdentityProvider
+	defaultRole domain.Role // rol de los usuarios creados en su primer login
+}
+
+func NewSSOService(
+	users domain.UserRepository,
+	identities domain.IdentityRepository,
+	provider domain.IdentityProvider,
+	defaultRole domain.Role,
+) *SSOService {
+	return &SSOService{users: users, identities: identities, provider: provider, defaultRole: defaultRole}
+}
+
+// Begin genera state, verifier PKCE y nonce y arma la URL del proveedor
+func (s *SSOService) Begin() (*SSOLogin, error) {
+	login := &SSOLogin{}
+	for _, v := range []*string{&login.State, &login.Verifier, &login.Nonce} {
+		raw := make([]byte, 32)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, err
+		}
+		*v = base64.RawURLEncoding.EncodeToString(raw)
+	}
+	login.URL = s.provider.AuthCodeURL(login.State, login.Verifier, login.Nonce)
+	return login, nil
+}
+
+// Complete canjea el código del callback y devuelve el usuario vinculado.
+// En el primer login busca una cuenta local con el mismo email (solo si el
+// proveedor lo da por verificado) o crea una nueva con el rol por defecto.
+func (s *SSOService) Complete(ctx context.Context, code, verifier, nonce string) (*domain.User, error) {
+	if code == "" {
+		return nil, errors.New("código de autorización requerido")
+	}
+
+	profile, err := s.provider.Exchange(ctx, code, verifier, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := s.identities.FindBySubject(ctx, profile.Provider, profile.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if identity != nil {
+		user, err := s.users.FindByID(ctx, identity.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil || user.DeletedAt != nil {
+			return nil, errors.New("usuario no encontrado")
+		}
+		return user, nil
+	}
+
+	user, err := s.linkOrCreate(ctx, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.identities.Create(ctx, &domain.ExternalIdentity{
+		UserID:   user.ID,
+		Provider: profile.Provider,
+		Subject:  profile.Subject,
+		Email:    profile.Email,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *SSOService) linkOrCreate(ctx context.Context, profile *domain.ExternalProfile) (*domain.User, error) {
+	if profile.Email == "" {
+		return nil, errors.New("el proveedor no devolvió un email")
+	}
+
+	existing, err := s.users.FindByEmail(ctx, profile.Email)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		// Sin verificación del proveedor cualquiera podría apropiarse de la cuenta local
+		if !profile.EmailVerified {
+			return nil, errors.New("el email ya está registrado; inicia sesión con tu contraseña")
+		}
+		if !existing.EmailVerified {
+			if err := s.users.MarkEmailVerified(ctx, existing.ID); err != nil {
+				return nil, err
+			}
+			existing.EmailVerified = true
+		}
+		return existing, nil
+	}
+
+	name := strings.TrimSpace(profile.Name)
+	if name == "" {
+		name, _, _ = strings.Cut(profile.Email, "@")
+	}
+	if r := []rune(name); len(r) > 100 {
+		name = string(r[:100])
+	}
+
+	// Sin contraseña local: solo entra por SSO hasta que use "olvidé mi contraseña"
+	user := &domain.User{
+		Name:          name,
+		Email:         profile.Email,
+		Role:          s.defaultRole,
+		EmailVerified: profile.EmailVerified,
+	}
+	if err := s.users.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
@@ -0,0 +1,132 @@
+package core_test
+
+import (
+	"context"
+	"testing"
+
+	"apiGolan/src/core"
+	"apiGolan/src/domain"
+	"apiGolan/src/infrastructure/oidc"
+	"apiGolan/src/infrastructure/oidc/oidctest"
+	"apiGolan/src/infrastructure/repository"
+)
+
+// newSSOService arma el servicio contra el proveedor falso
+func newSSOService(t *testing.T) (*core.SSOService, domain.UserRepository, *oidctest.Issuer) {
+	t.Helper()
+	db := openDB(t)
+	iss := oidctest.NewIssuer("quickscore")
+	t.Cleanup(iss.Close)
+
+	t.Setenv("OIDC_ISSUER", iss.URL)
+	t.Setenv("OIDC_CLIENT_ID", "quickscore")
+	t.Setenv("OIDC_REDIRECT_URL", "http://app.test/auth/oidc/callback")
+	provider, err := oidc.New(context.Background())
+	if err != nil {
+		t.Fatalf("oidc.New: %v", err)
+	}
+	users := repository.NewUserRepo(db)
+	svc := core.NewSSOService(users, repository.NewIdentityRepo(db), provider, domain.RoleParticipant)
+	return svc, users, iss
+}
+
+// ssoLogin hace el login completo como lo haría el navegador
+func ssoLogin(t *testing.T, svc *core.SSOService, iss *oidctest.Issuer) (*domain.User, error) {
+	t.Helper()
+	login, err := svc.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	callback, err := iss.Authorize(login.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return svc.Complete(context.Background(), callback.Query().Get("code"), login.Verifier, login.Nonce)
+}
+
+func TestSSOCreatesUserOnFirstLogin(t *testing.T) {
+	svc, _, iss := newSSOService(t)
+	iss.SetUser(oidctest.User{Subject: "sub-1", Email: "ana@example.com", EmailVerified: true, Name: "Ana"})
+
+	user, err := ssoLogin(t, svc, iss)
+	if err != nil {
+		t.Fatalf("primer login: %v", err)
+	}
+	if user.ID == 0 || user.Name != "Ana" || user.Email != "ana@example.com" ||
+		user.Role != domain.RoleParticipant || !user.EmailVerified {
+		t.Errorf("usuario creado = %+v", user)
+	}
+
+	// el segundo login encuentra la identidad aunque cambie el email en el proveedor
+	iss.SetUser(oidctest.User{Subject: "sub-1", Email: "ana@otro.com", EmailVerified: true, Name: "Ana"})
+	again, err := ssoLogin(t, svc, iss)
+	if err != nil {
+		t.Fatalf("segundo login: %v", err)
+	}
+	if again.ID != user.ID {
+		t.Errorf("el segundo login devolvió el usuario %d, se esperaba %d", again.ID, user.ID)
+	}
+}
+
+func TestSSOCreatesUserWithoutName(t *testing.T) {
+	svc, _, iss := newSSOService(t)
+	iss.SetUser(oidctest.User{Subject: "sub-1", Email: "beto@example.com"})
+
+	user, err := ssoLogin(t, svc, iss)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Name != "beto" || user.EmailVerified {
+		t.Errorf("usuario creado = %+v, se esperaba nombre \"beto\" sin email verificado", user)
+	}
+}
+
+func TestSSOLinksExistingAccountByVerifiedEmail(t *testing.T) {
+	svc, users, iss := newSSOService(t)
+	ctx := context.Background()
+	local := &domain.User{Name: "Ana", Email: "ana@example.com", Password: "x", Role: domain.RoleHost}
+	if err := users.Create(ctx, local); err != nil {
+		t.Fatal(err)
+	}
+
+	iss.SetUser(oidctest.User{Subject: "sub-1", Email: "ana@example.com", EmailVerified: true})
+	user, err := ssoLogin(t, svc, iss)
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if user.ID != local.ID || user.Role != domain.RoleHost {
+		t.Errorf("se vinculó %+v, se esperaba la cuenta %d", user, local.ID)
+	}
+
+	stored, err := users.FindByID(ctx, local.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stored.EmailVerified {
+		t.Error("el login con email verificado no marcó la cuenta como verificada")
+	}
+}
+
+func TestSSORejectsUnverifiedEmailOfExistingAccount(t *testing.T) {
+	svc, users, iss := newSSOService(t)
+	ctx := context.Background()
+	local := &domain.User{Name: "Ana", Email: "ana@example.com", Password: "x", Role: domain.RoleHost}
+	if err := users.Create(ctx, local); err != nil {
+		t.Fatal(err)
+	}
+
+	iss.SetUser(oidctest.User{Subject: "sub-atacante", Email: "ana@example.com", EmailVerified: false})
+	if user, err := ssoLogin(t, svc, iss); err == nil {
+		t.Fatalf("se vinculó la cuenta %d con un email sin verificar", user.ID)
+	}
+
+	// el intento fallido no dejó la identidad vinculada
+	iss.SetUser(oidctest.User{Subject: "sub-atacante", Email: "otra@example.com", EmailVerified: true})
+	user, err := ssoLogin(t, svc, iss)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.ID == local.ID {
+		t.Error("la identidad rechazada quedó vinculada a la cuenta local")
+	}
+}
@@ -1,28 +1,103 @@
 package core
 
 import (
-"errors"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
 
-"apiGolan/src/domain"
-"golang.org/x/crypto/bcrypt"
+	"apiGolan/src/domain"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// LoginLockout define el bloqueo progresivo por intentos fallidos:
+// a partir de MaxFailures fallos seguidos la cuenta se bloquea BaseLock,
+// y cada fallo adicional duplica el bloqueo hasta MaxLock.
+type LoginLockout struct {
+	MaxFailures int
+	BaseLock    time.Duration
+	MaxLock     time.Duration
+}
+
+// DefaultLoginLockout bloquea 1 minuto al 5º fallo, 2 al 6º, ... hasta 1 hora
+var DefaultLoginLockout = LoginLockout{MaxFailures: 5, BaseLock: time.Minute, MaxLock: time.Hour}
+
+// lockFor devuelve cuánto bloquear tras acumular failures fallos (0 = no bloquear)
+func (l LoginLockout) lockFor(failures int) time.Duration {
+	if l.MaxFailures <= 0 || failures < l.MaxFailures {
+		return 0
+	}
+	lock := l.BaseLock
+	for i := l.MaxFailures; i < failures && lock < l.MaxLock; i++ {
+		lock *= 2
+	}
+	if lock > l.MaxLock {
+		lock = l.MaxLock
+	}
+	return lock
+}
+
+// Vigencia de los tokens enviados por correo
+const (
+	passwordResetTTL     = time.Hour
+	emailVerificationTTL = 48 * time.Hour
 )
 
 // UserService contiene la lógica de negocio relacionada con usuarios.
 type UserService struct {
-	repo domain.UserRepository
+	repo       domain.UserRepository
+	tokenRepo  domain.TokenRepository
+	inviteRepo domain.InviteRepository
+	tx         domain.Transactor
+	mailer     domain.Mailer
+	appURL     string // URL del frontend para armar los enlaces de los correos
+	lockout    LoginLockout
 }
 
-func NewUserService(repo domain.UserRepository) *UserService {
-	return &UserService{repo: repo}
+func NewUserService(
+	repo domain.UserRepository,
+	tokenRepo domain.TokenRepository,
+	inviteRepo domain.InviteRepository,
+	tx domain.Transactor,
+	mailer domain.Mailer,
+	appURL string,
+	lockout LoginLockout,
+) *UserService {
+	return &UserService{
+		repo:       repo,
+		tokenRepo:  tokenRepo,
+		inviteRepo: inviteRepo,
+		tx:         tx,
+		mailer:     mailer,
+		appURL:     strings.TrimRight(appURL, "/"),
+		lockout:    lockout,
+	}
 }
 
-// Register valida los datos, hashea la contraseña y crea el usuario
-func (s *UserService) Register(name, email, password string, role domain.Role) (*domain.User, error) {
+// Register valida los datos, hashea la contraseña y crea el usuario.
+// Sin código de invitación siempre crea un participante; si pidió ser host
+// queda marcado como host_requested hasta que un admin lo apruebe.
+func (s *UserService) Register(ctx context.Context, name, email, password string, role domain.Role, inviteCode string) (*domain.User, error) {
 	if name == "" || email == "" || password == "" {
 		return nil, errors.New("nombre, email y contraseña son requeridos")
 	}
+	if role == "" {
+		role = domain.RoleParticipant
+	}
+	if !role.Valid() {
+		return nil, errors.New("rol inválido")
+	}
+	if role == domain.RoleAdmin && inviteCode == "" {
+		return nil, errors.New("no se puede registrar como admin sin invitación")
+	}
 
-	existing, _ := s.repo.FindByEmail(email)
+	existing, _ := s.repo.FindByEmail(ctx, email)
 	if existing != nil {
 		return nil, errors.New("el email ya está registrado")
 	}
@@ -36,26 +111,401 @@ func (s *UserService) Register(name, email, password string, role domain.Role) (
 		Name:     name,
 		Email:    email,
 		Password: string(hashed),
-		Role:     role,
+		Role:     domain.RoleParticipant,
+	}
+	if inviteCode == "" && role != domain.RoleParticipant {
+		user.HostRequested = true
 	}
 
-	if err := s.repo.Create(user); err != nil {
+	// La invitación se gasta en la misma transacción que crea el usuario: si
+	// el alta falla (por ejemplo un email repetido) el uso no se pierde
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		if inviteCode != "" {
+			invite, err := s.inviteRepo.Consume(ctx, inviteCode)
+			if err != nil {
+				return err
+			}
+			if invite == nil {
+				return errors.New("código de invitación inválido o vencido")
+			}
+			user.Role = invite.Role
+		}
+		return s.repo.Create(ctx, user)
+	})
+	if err != nil {
 		return nil, err
 	}
 
+	// El registro no falla si el correo no sale: se puede reenviar después
+	_ = s.sendVerification(ctx, user)
+
 	return user, nil
 }
 
 // Login valida credenciales y devuelve el usuario si son correctas
-func (s *UserService) Login(email, password string) (*domain.User, error) {
-	user, err := s.repo.FindByEmail(email)
+func (s *UserService) Login(ctx context.Context, email, password string) (*domain.User, error) {
+	user, err := s.repo.FindByEmail(ctx, email)
 	if err != nil || user == nil {
 		return nil, errors.New("credenciales inválidas")
 	}
 
+	// Si está bloqueada no se llega a bcrypt
+	if user.LockedUntil != nil {
+		if wait := time.Until(*user.LockedUntil); wait > 0 {
+			return nil, &domain.AccountLockedError{RetryAfter: wait}
+		}
+	}
+
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		var lockedUntil *time.Time
+		if lock := s.lockout.lockFor(user.FailedLogins + 1); lock > 0 {
+			until := time.Now().Add(lock)
+			lockedUntil = &until
+		}
+		if err := s.repo.RecordLoginFailure(ctx, user.ID, lockedUntil); err != nil {
+			return nil, err
+		}
+		if lockedUntil != nil {
+			return nil, &domain.AccountLockedError{RetryAfter: time.Until(*lockedUntil)}
+		}
 		return nil, errors.New("credenciales inválidas")
 	}
 
+	if user.FailedLogins > 0 || user.LockedUntil != nil {
+		if err := s.repo.ResetLoginFailures(ctx, user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return user, nil
+}
+
+// RequestPasswordReset envía un enlace para elegir una contraseña nueva.
+// Si el email no existe no hace nada, para no revelar qué cuentas existen.
+func (s *UserService) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.repo.FindByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if user == nil || user.DeletedAt != nil {
+		return nil
+	}
+
+	// Solo el último enlace enviado sirve
+	if err := s.tokenRepo.InvalidateAll(ctx, user.ID, domain.TokenPasswordReset); err != nil {
+		return err
+	}
+	token, err := s.issueToken(ctx, user.ID, domain.TokenPasswordReset, passwordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	body := "Hola " + user.Name + ",\n\n" +
+		"Para elegir una contraseña nueva entra a:\n" +
+		s.appURL + "/reset-password?token=" + token + "\n\n" +
+		"El enlace vence en 1 hora. Si no lo pediste, ignora este correo."
+	return s.mailer.Send(ctx, user.Email, "Recupera tu contraseña de QuickScore", body)
+}
+
+// ResetPassword cambia la contraseña usando un token de recuperación válido
+func (s *UserService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	if token == "" || newPassword == "" {
+		return errors.New("token y contraseña son requeridos")
+	}
+
+	t, err := s.consumeToken(ctx, domain.TokenPasswordReset, token)
+	if err != nil {
+		return err
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return errors.New("error al procesar la contraseña")
+	}
+	return s.repo.UpdatePassword(ctx, t.UserID, string(hashed))
+}
+
+// VerifyEmail marca el email del usuario como verificado
+func (s *UserService) VerifyEmail(ctx context.Context, token string) error {
+	if token == "" {
+		return errors.New("token requerido")
+	}
+
+	t, err := s.consumeToken(ctx, domain.TokenEmailVerification, token)
+	if err != nil {
+		return err
+	}
+	return s.repo.MarkEmailVerified(ctx, t.UserID)
+}
+
+// ResendVerification vuelve a enviar el correo de verificación
+func (s *UserService) ResendVerification(ctx context.Context, userID int) error {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil || user == nil {
+		return errors.New("usuario no encontrado")
+	}
+	if user.EmailVerified {
+		return errors.New("el email ya está verificado")
+	}
+	if err := s.tokenRepo.InvalidateAll(ctx, user.ID, domain.TokenEmailVerification); err != nil {
+		return err
+	}
+	return s.sendVerification(ctx, user)
+}
+
+// GetProfile devuelve la cuenta del usuario autenticado
+func (s *UserService) GetProfile(ctx context.Context, userID int) (*domain.User, error) {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	// Una cuenta eliminada se trata como inexistente aunque su JWT siga vigente
+	if user == nil || user.DeletedAt != nil {
+		return nil, errors.New("usuario no encontrado")
+	}
 	return user, nil
 }
+
+// UpdateName cambia el nombre visible del usuario
+func (s *UserService) UpdateName(ctx context.Context, userID int, name string) (*domain.User, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("el nombre es requerido")
+	}
+	if len(name) > 100 {
+		return nil, errors.New("el nombre no puede superar 100 caracteres")
+	}
+
+	user, err := s.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateName(ctx, userID, name); err != nil {
+		return nil, err
+	}
+	user.Name = name
+	return user, nil
+}
+
+// ChangePassword cambia la contraseña comprobando antes la actual
+func (s *UserService) ChangePassword(ctx context.Context, userID int, current, newPassword string) error {
+	if current == "" || newPassword == "" {
+		return errors.New("contraseña actual y nueva son requeridas")
+	}
+
+	user, err := s.GetProfile(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(current)); err != nil {
+		return errors.New("la contraseña actual no es correcta")
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return errors.New("error al procesar la contraseña")
+	}
+	if err := s.repo.UpdatePassword(ctx, userID, string(hashed)); err != nil {
+		return err
+	}
+	// Un enlace de recuperación pendiente ya no debe poder pisar la contraseña nueva
+	return s.tokenRepo.InvalidateAll(ctx, userID, domain.TokenPasswordReset)
+}
+
+// DeleteAccount elimina la cuenta tras confirmar la contraseña.
+// Los datos personales se anonimizan; respuestas y puntos se conservan
+// para que los rankings y el historial de las salas no cambien.
+func (s *UserService) DeleteAccount(ctx context.Context, userID int, password string) error {
+	user, err := s.GetProfile(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return errors.New("la contraseña no es correcta")
+	}
+
+	for _, purpose := range []domain.TokenPurpose{domain.TokenPasswordReset, domain.TokenEmailVerification} {
+		if err := s.tokenRepo.InvalidateAll(ctx, userID, purpose); err != nil {
+			return err
+		}
+	}
+	return s.repo.Anonymize(ctx, userID)
+}
+
+// ActiveRole devuelve el rol actual de la cuenta, o "" si no existe o fue
+// eliminada. Auth lo consulta en cada petición con JWT: el token sigue siendo
+// válido hasta que expira aunque la cuenta se elimine o cambie de rol, así que
+// no basta con validar la firma ni con el rol que trae el token.
+func (s *UserService) ActiveRole(ctx context.Context, userID int) (domain.Role, error) {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if user == nil || user.DeletedAt != nil {
+		return "", nil
+	}
+	return user.Role, nil
+}
+
+// ListUsers devuelve los usuarios para el panel de administración
+func (s *UserService) ListUsers(ctx context.Context, filter domain.UserFilter) ([]domain.User, error) {
+	if filter.Role != "" && !filter.Role.Valid() {
+		return nil, errors.New("rol inválido")
+	}
+	if filter.Limit <= 0 || filter.Limit > 100 {
+		filter.Limit = 50
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	return s.repo.List(ctx, filter)
+}
+
+// ChangeRole asciende o degrada a un usuario. Un admin no puede cambiar su
+// propio rol, así siempre queda al menos el admin que hace el cambio.
+// El rol nuevo rige desde la próxima petición: Auth usa el rol actual de la
+// cuenta (ver ActiveRole), no el que trae el JWT vigente.
+func (s *UserService) ChangeRole(ctx context.Context, adminID, userID int, role domain.Role) (*domain.User, error) {
+	if !role.Valid() {
+		return nil, errors.New("rol inválido")
+	}
+	if adminID == userID {
+		return nil, errors.New("no puedes cambiar tu propio rol")
+	}
+
+	user, err := s.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateRole(ctx, userID, role); err != nil {
+		return nil, err
+	}
+	user.Role = role
+	user.HostRequested = false
+	return user, nil
+}
+
+// CreateInvite genera un código para registrarse directamente con el rol indicado
+func (s *UserService) CreateInvite(ctx context.Context, adminID int, role domain.Role, uses int, ttl time.Duration) (*domain.InviteCode, error) {
+	if role == "" {
+		role = domain.RoleHost
+	}
+	if role != domain.RoleHost && role != domain.RoleAdmin {
+		return nil, errors.New("las invitaciones solo pueden ser para host o admin")
+	}
+	if uses <= 0 {
+		uses = 1
+	}
+	if uses > 1000 {
+		return nil, errors.New("una invitación admite como máximo 1000 usos")
+	}
+
+	raw := make([]byte, 10)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	invite := &domain.InviteCode{
+		Code:      base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(raw),
+		Role:      role,
+		UsesLeft:  uses,
+		CreatedBy: adminID,
+	}
+	if ttl > 0 {
+		expires := time.Now().Add(ttl)
+		invite.ExpiresAt = &expires
+	}
+	if err := s.inviteRepo.Create(ctx, invite); err != nil {
+		return nil, err
+	}
+	return invite, nil
+}
+
+// EnsureAdmin garantiza que exista el admin inicial (ADMIN_EMAIL al arrancar):
+// lo crea si no existe o lo asciende si ya estaba registrado.
+func (s *UserService) EnsureAdmin(ctx context.Context, email, password string) error {
+	user, err := s.repo.FindByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if user != nil {
+		if user.Role == domain.RoleAdmin {
+			return nil
+		}
+		return s.repo.UpdateRole(ctx, user.ID, domain.RoleAdmin)
+	}
+
+	if password == "" {
+		return errors.New("ADMIN_PASSWORD es requerido para crear el admin inicial")
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return errors.New("error al procesar la contraseña")
+	}
+	return s.repo.Create(ctx, &domain.User{
+		Name:          "Admin",
+		Email:         email,
+		Password:      string(hashed),
+		Role:          domain.RoleAdmin,
+		EmailVerified: true,
+	})
+}
+
+func (s *UserService) sendVerification(ctx context.Context, user *domain.User) error {
+	token, err := s.issueToken(ctx, user.ID, domain.TokenEmailVerification, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	body := "Hola " + user.Name + ",\n\n" +
+		"Confirma tu email entrando a:\n" +
+		s.appURL + "/verify-email?token=" + token + "\n\n" +
+		"El enlace vence en 48 horas."
+	return s.mailer.Send(ctx, user.Email, "Confirma tu email en QuickScore", body)
+}
+
+// issueToken genera un token aleatorio, guarda su hash y devuelve el token en claro
+func (s *UserService) issueToken(ctx context.Context, userID int, purpose domain.TokenPurpose, ttl time.Duration) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	t := &domain.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := s.tokenRepo.Create(ctx, t); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// consumeToken valida el token y lo marca como usado (un solo uso)
+func (s *UserService) consumeToken(ctx context.Context, purpose domain.TokenPurpose, token string) (*domain.UserToken, error) {
+	invalid := errors.New("token inválido o expirado")
+
+	t, err := s.tokenRepo.FindByHash(ctx, purpose, hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if t == nil || t.UsedAt != nil || time.Now().After(t.ExpiresAt) {
+		return nil, invalid
+	}
+
+	ok, err := s.tokenRepo.MarkUsed(ctx, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, invalid
+	}
+	return t, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,109 @@
+package core_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"apiGolan/src/core"
+	"apiGolan/src/domain"
+	infradb "apiGolan/src/infrastructure/db"
+	"apiGolan/src/infrastructure/repository"
+)
+
+// openDB abre una base SQLite en memoria con el esquema aplicado
+func openDB(t *testing.T) *infradb.DB {
+	t.Helper()
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DB_PATH", ":memory:")
+	db, err := infradb.Connect()
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+type nopMailer struct{}
+
+func (nopMailer) Send(ctx context.Context, to, subject, body string) error { return nil }
+
+// failingCreate simula que el alta del usuario falla después de gastar la invitación
+type failingCreate struct {
+	domain.UserRepository
+}
+
+func (failingCreate) Create(ctx context.Context, user *domain.User) error {
+	return errors.New("fallo al insertar")
+}
+
+func newUserService(db *infradb.DB, users domain.UserRepository) *core.UserService {
+	return core.NewUserService(users, repository.NewTokenRepo(db), repository.NewInviteRepo(db),
+		repository.NewTransactor(db), nopMailer{}, "http://app", core.DefaultLoginLockout)
+}
+
+func TestRegisterKeepsInviteWhenCreateFails(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+	users := repository.NewUserRepo(db)
+	svc := newUserService(db, users)
+
+	admin := &domain.User{Name: "admin", Email: "admin@x.com", Password: "x", Role: domain.RoleAdmin}
+	if err := users.Create(ctx, admin); err != nil {
+		t.Fatal(err)
+	}
+	invite, err := svc.CreateInvite(ctx, admin.ID, domain.RoleHost, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failing := newUserService(db, failingCreate{users})
+	if _, err := failing.Register(ctx, "Ana", "ana@x.com", "secret123", domain.RoleHost, invite.Code); err == nil {
+		t.Fatal("se esperaba el error del alta")
+	}
+
+	// el uso no se gastó: la invitación sigue sirviendo
+	user, err := svc.Register(ctx, "Ana", "ana@x.com", "secret123", domain.RoleHost, invite.Code)
+	if err != nil {
+		t.Fatalf("Register con la invitación intacta: %v", err)
+	}
+	if user.Role != domain.RoleHost || user.HostRequested {
+		t.Errorf("usuario = %+v, se esperaba host sin solicitud pendiente", user)
+	}
+
+	// y ahora sí está agotada
+	if _, err := svc.Register(ctx, "Beto", "beto@x.com", "secret123", domain.RoleHost, invite.Code); err == nil {
+		t.Error("la invitación de un uso sirvió dos veces")
+	}
+	if u, _ := users.FindByEmail(ctx, "beto@x.com"); u != nil {
+		t.Error("se creó el usuario con una invitación agotada")
+	}
+}
+
+func TestActiveRoleFollowsChangeRole(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+	users := repository.NewUserRepo(db)
+	svc := newUserService(db, users)
+
+	admin := &domain.User{Name: "admin", Email: "admin@x.com", Password: "x", Role: domain.RoleAdmin}
+	host := &domain.User{Name: "host", Email: "host@x.com", Password: "x", Role: domain.RoleHost}
+	for _, u := range []*domain.User{admin, host} {
+		if err := users.Create(ctx, u); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if role, err := svc.ActiveRole(ctx, host.ID); err != nil || role != domain.RoleHost {
+		t.Fatalf("ActiveRole = %q, %v; se esperaba host", role, err)
+	}
+	if _, err := svc.ChangeRole(ctx, admin.ID, host.ID, domain.RoleParticipant); err != nil {
+		t.Fatal(err)
+	}
+	if role, err := svc.ActiveRole(ctx, host.ID); err != nil || role != domain.RoleParticipant {
+		t.Errorf("ActiveRole tras degradar = %q, %v; se esperaba participant", role, err)
+	}
+	if role, err := svc.ActiveRole(ctx, host.ID+100); err != nil || role != "" {
+		t.Errorf("ActiveRole de una cuenta inexistente = %q, %v", role, err)
+	}
+}
@@ -0,0 +1,56 @@
+package domain
+
+import "time"
+
+// Scope limita qué puede hacer una API key
+type Scope string
+
+const (
+	ScopeRoomsRead      Scope = "rooms:read"
+	ScopeRoomsWrite     Scope = "rooms:write"
+	ScopeQuestionsRead  Scope = "questions:read"
+	ScopeQuestionsWrite Scope = "questions:write"
+	ScopeScoresRead     Scope = "scores:read"
+	ScopeScoresWrite    Scope = "scores:write"
+)
+
+// Scopes son todos los scopes que se pueden asignar a una API key
+var Scopes = []Scope{
+	ScopeRoomsRead, ScopeRoomsWrite,
+	ScopeQuestionsRead, ScopeQuestionsWrite,
+	ScopeScoresRead, ScopeScoresWrite,
+}
+
+// Valid indica si el scope existe
+func (s Scope) Valid() bool {
+	for _, v := range Scopes {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKey es una credencial personal para scripts e integraciones.
+// Solo se guarda el hash; la key completa se muestra una única vez al crearla.
+type APIKey struct {
+	ID         int        `json:"id"`
+	UserID     int        `json:"-"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"` // inicio de la key, para reconocerla en el listado
+	KeyHash    string     `json:"-"`
+	Scopes     []Scope    `json:"scopes"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// HasScope indica si la key tiene el scope pedido
+func (k *APIKey) HasScope(scope Scope) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
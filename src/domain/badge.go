@@ -0,0 +1,70 @@
+package domain
+
+import "time"
+
+// BadgeCode identifica una medalla
+type BadgeCode string
+
+const (
+	BadgeFirstCorrect   BadgeCode = "first_correct"   // primera respuesta correcta de su historia
+	BadgeStreak5        BadgeCode = "streak_5"        // 5 respuestas correctas seguidas en una sala
+	BadgeFastestAnswer  BadgeCode = "fastest_answer"  // la primera respuesta correcta de una pregunta
+	BadgePerfectSession BadgeCode = "perfect_session" // todas las preguntas de la sesión respondidas bien
+	BadgeComeback       BadgeCode = "comeback"        // gana la sesión tras haber estado en la mitad de abajo
+)
+
+// Badge describe una medalla del catálogo
+type Badge struct {
+	Code        BadgeCode `json:"code"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+}
+
+// Badges es el catálogo de medallas, en el orden en que se muestran
+var Badges = []Badge{
+	{BadgeFirstCorrect, "Primer acierto", "Responder bien una pregunta por primera vez"},
+	{BadgeStreak5, "Racha de 5", "Cinco respuestas correctas seguidas en una sala"},
+	{BadgeFastestAnswer, "Más rápido", "Ser el primero en responder bien una pregunta"},
+	{BadgePerfectSession, "Sesión perfecta", "Responder bien todas las preguntas de una sesión (mínimo 3)"},
+	{BadgeComeback, "Remontada", "Ganar una sesión después de haber estado en la mitad de abajo del ranking"},
+}
+
+// FindBadge devuelve la medalla del catálogo; nil si el código no existe
+func FindBadge(code BadgeCode) *Badge {
+	for i := range Badges {
+		if Badges[i].Code == code {
+			return &Badges[i]
+		}
+	}
+	return nil
+}
+
+// UserBadge es una medalla ganada por un usuario. Cada medalla se gana como
+// mucho una vez por sala.
+type UserBadge struct {
+	ID          int       `json:"id"`
+	UserID      int       `json:"user_id"`
+	Badge       BadgeCode `json:"badge"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	RoomID      int       `json:"room_id"`
+	RoomCode    string    `json:"room_code"`
+	QuestionID  int       `json:"question_id,omitempty"` // la que la otorgó, en las medallas por respuesta
+	EarnedAt    time.Time `json:"earned_at"`
+}
+
+// AnswerEvent es una respuesta ya registrada, lo que evalúan las reglas por respuesta
+type AnswerEvent struct {
+	RoomID     int
+	UserID     int
+	QuestionID int
+	IsCorrect  bool
+	Streak     int // respuestas correctas seguidas en la sala, contando esta
+}
+
+// SessionResult resume cómo respondió un participante en una sala
+type SessionResult struct {
+	UserID   int
+	Answered int
+	Correct  int
+}
@@ -0,0 +1,52 @@
+package domain
+
+import "time"
+
+// SessionHistory es el resultado de un usuario en una sala a la que se unió
+type SessionHistory struct {
+	RoomID        int        `json:"room_id"`
+	RoomCode      string     `json:"room_code"`
+	RoomStatus    RoomStatus `json:"room_status"`
+	JoinedAt      time.Time  `json:"joined_at"`
+	Points        int        `json:"points"`
+	Rank          int        `json:"rank"` // puesto por puntos según el ranking_mode de la sala
+	Participants  int        `json:"participants"`
+	Questions     int        `json:"questions"`
+	Answered      int        `json:"answered"`
+	Correct       int        `json:"correct"`
+	Accuracy      float64    `json:"accuracy"`        // % de respuestas correctas
+	AvgResponseMS int64      `json:"avg_response_ms"` // 0 si no hay tiempos registrados
+	TimedAnswers  int        `json:"-"`               // respuestas con tiempo, para promediar entre sesiones
+}
+
+// HistorySummary acumula todas las sesiones del historial
+type HistorySummary struct {
+	Sessions      int     `json:"sessions"`
+	TotalPoints   int     `json:"total_points"`
+	AvgPoints     float64 `json:"avg_points"`
+	BestRank      int     `json:"best_rank"` // 0 si no hay sesiones
+	AvgRank       float64 `json:"avg_rank"`
+	Answered      int     `json:"answered"`
+	Correct       int     `json:"correct"`
+	Accuracy      float64 `json:"accuracy"`
+	AvgResponseMS int64   `json:"avg_response_ms"`
+}
+
+// HistoryTrend compara las últimas Window sesiones con las Window anteriores.
+// Cada cambio es (últimas − anteriores): accuracy sube y rank/response bajan
+// cuando el usuario mejora. Son nil si todavía no hay sesiones anteriores.
+type HistoryTrend struct {
+	Window           int      `json:"window"`
+	AccuracyChange   *float64 `json:"accuracy_change"`
+	AvgRankChange    *float64 `json:"avg_rank_change"`
+	ResponseMSChange *int64   `json:"response_ms_change"`
+}
+
+// History es el progreso de un usuario a lo largo de sus sesiones, de la más
+// reciente a la más antigua
+type History struct {
+	UserID   int              `json:"user_id"`
+	Summary  HistorySummary   `json:"summary"`
+	Trend    HistoryTrend     `json:"trend"`
+	Sessions []SessionHistory `json:"sessions"`
+}
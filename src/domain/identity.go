@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// ExternalIdentity vincula un usuario con su cuenta en un proveedor externo
+// (OpenID Connect). El par Provider + Subject es único.
+type ExternalIdentity struct {
+	ID        int
+	UserID    int
+	Provider  string // issuer del proveedor
+	Subject   string // claim sub del ID token
+	Email     string
+	CreatedAt time.Time
+}
+
+// ExternalProfile son los datos del usuario que devuelve el proveedor tras el login
+type ExternalProfile struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// IdentityProvider es un proveedor OpenID Connect (flujo authorization code + PKCE).
+// La implementación concreta está en infrastructure.
+type IdentityProvider interface {
+	// AuthCodeURL arma la URL de login del proveedor; verifier se envía como challenge S256
+	AuthCodeURL(state, verifier, nonce string) string
+	// Exchange canjea el código, valida el ID token (firma, audiencia y nonce) y devuelve el perfil
+	Exchange(ctx context.Context, code, verifier, nonce string) (*ExternalProfile, error)
+}
@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Leaderboard es una tabla de posiciones de un host que suma los puntos de
+// varias salas. Si StartsAt o EndsAt están definidos, solo cuentan los
+// puntos ganados dentro de ese rango.
+type Leaderboard struct {
+	ID        int        `json:"id"`
+	HostID    int        `json:"host_id"`
+	Name      string     `json:"name"`
+	StartsAt  *time.Time `json:"starts_at"`
+	EndsAt    *time.Time `json:"ends_at"`
+	Rooms     []string   `json:"rooms"` // códigos de las salas incluidas
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Covers indica si un cambio de puntos hecho en t entra en el rango de fechas
+func (l *Leaderboard) Covers(t time.Time) bool {
+	if l.StartsAt != nil && t.Before(*l.StartsAt) {
+		return false
+	}
+	if l.EndsAt != nil && !t.Before(*l.EndsAt) {
+		return false
+	}
+	return true
+}
+
+// LeaderboardWindow es el periodo sobre el que se acumulan los puntos
+type LeaderboardWindow string
+
+const (
+	LeaderboardAllTime LeaderboardWindow = "all_time"
+	LeaderboardMonthly LeaderboardWindow = "monthly"
+	LeaderboardWeekly  LeaderboardWindow = "weekly"
+)
+
+// LeaderboardWindows son las ventanas que se mantienen para cada leaderboard
+var LeaderboardWindows = []LeaderboardWindow{LeaderboardAllTime, LeaderboardMonthly, LeaderboardWeekly}
+
+func (w LeaderboardWindow) Valid() bool {
+	switch w {
+	case LeaderboardAllTime, LeaderboardMonthly, LeaderboardWeekly:
+		return true
+	}
+	return false
+}
+
+// Period devuelve la clave del periodo de la ventana que contiene t (en UTC):
+// "all", el mes ("2026-10") o la semana ISO ("2026-W42")
+func (w LeaderboardWindow) Period(t time.Time) string {
+	t = t.UTC()
+	switch w {
+	case LeaderboardMonthly:
+		return t.Format("2006-01")
+	case LeaderboardWeekly:
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	}
+	return "all"
+}
+
+// LeaderboardEntry es la posición de un usuario en un periodo del leaderboard
+type LeaderboardEntry struct {
+	UserID   int    `json:"user_id"`
+	UserName string `json:"user_name"`
+	Points   int    `json:"points"`
+	Position int    `json:"position"` // los empatados comparten puesto (1, 2, 2, 4)
+}
+
+// LeaderboardQuery pide una página de un periodo del leaderboard
+type LeaderboardQuery struct {
+	LeaderboardID int
+	Period        string
+	Limit         int
+	Offset        int
+}
+
+// LeaderboardRanking es una página de un periodo del leaderboard junto con
+// el puesto de quien la pide
+type LeaderboardRanking struct {
+	LeaderboardID int                `json:"leaderboard_id"`
+	Name          string             `json:"name"`
+	Window        LeaderboardWindow  `json:"window"`
+	Period        string             `json:"period"` // "all", "2026-10" o "2026-W42"
+	Entries       []LeaderboardEntry `json:"entries"`
+	Me            *LeaderboardEntry  `json:"me"` // null si no tiene puntos en el periodo
+}
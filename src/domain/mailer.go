@@ -0,0 +1,9 @@
+package domain
+
+import "context"
+
+// Mailer envía correos salientes. La implementación concreta (SMTP, log)
+// está en infrastructure.
+type Mailer interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // QuestionStatus indica si una pregunta está abierta o cerrada para respuestas
 type QuestionStatus string
@@ -15,8 +18,8 @@ type Question struct {
 	ID            int            `json:"id"`
 	RoomID        int            `json:"room_id"`
 	Text          string         `json:"text"`
-	CorrectAnswer string         `json:"-"`          // nunca se expone al cliente
-	Points        int            `json:"points"`     // puntos que vale la pregunta
+	CorrectAnswer string         `json:"-"`      // nunca se expone al cliente
+	Points        int            `json:"points"` // puntos que vale la pregunta
 	Status        QuestionStatus `json:"status"`
 	CreatedAt     time.Time      `json:"created_at"`
 }
@@ -28,21 +31,62 @@ type Answer struct {
 	UserID     int       `json:"user_id"`
 	Text       string    `json:"answer"`
 	IsCorrect  bool      `json:"is_correct"`
+	ResponseMS int64     `json:"response_ms,omitempty"` // milisegundos desde que se lanzó la pregunta
 	AnsweredAt time.Time `json:"answered_at"`
 }
 
+// AnswerResult es el resultado de evaluar una respuesta
+type AnswerResult struct {
+	IsCorrect bool
+	Points    int // puntos ganados, con el premio por racha incluido
+	Bonus     int // la parte de Points que aporta la racha
+	Streak    int // respuestas correctas seguidas en la sala, contando esta
+}
+
+// AnswerCount agrupa las respuestas con el mismo texto (sin distinguir
+// mayúsculas ni espacios alrededor)
+type AnswerCount struct {
+	Answer    string `json:"answer"`
+	Count     int    `json:"count"`
+	IsCorrect bool   `json:"is_correct"`
+}
+
+// ResponseTimes son percentiles del tiempo de respuesta en milisegundos.
+// Count es cuántas respuestas tienen tiempo registrado.
+type ResponseTimes struct {
+	Count    int   `json:"count"`
+	MedianMS int64 `json:"median_ms"`
+	P75MS    int64 `json:"p75_ms"`
+	P90MS    int64 `json:"p90_ms"`
+}
+
+// QuestionStats resume las respuestas de una pregunta para el host
+type QuestionStats struct {
+	QuestionID     int           `json:"question_id"`
+	Participants   int           `json:"participants"`
+	Answered       int           `json:"answered"`
+	NotAnswered    int           `json:"not_answered"` // participantes de la sala sin respuesta
+	Correct        int           `json:"correct"`
+	PercentCorrect float64       `json:"percent_correct"`
+	Distribution   []AnswerCount `json:"distribution"`
+	TopWrong       []AnswerCount `json:"top_wrong"`
+	ResponseTimes  ResponseTimes `json:"response_times"`
+}
+
 // QuestionRepository define las operaciones de persistencia para preguntas
 type QuestionRepository interface {
-	Create(q *Question) error
-	FindByID(id int) (*Question, error)
-	FindOpenByRoom(roomID int) (*Question, error) // pregunta actualmente abierta
-	CloseQuestion(id int) error
-	FindByRoom(roomID int) ([]Question, error)
+	Create(ctx context.Context, q *Question) error
+	FindByID(ctx context.Context, id int) (*Question, error)
+	FindOpenByRoom(ctx context.Context, roomID int) (*Question, error) // pregunta actualmente abierta
+	CloseQuestion(ctx context.Context, id int) error
+	FindByRoom(ctx context.Context, roomID int) ([]Question, error)
 }
 
 // AnswerRepository define las operaciones de persistencia para respuestas
 type AnswerRepository interface {
-	Create(a *Answer) error
-	HasAnswered(questionID, userID int) (bool, error)
-	FindByQuestion(questionID int) ([]Answer, error)
+	Create(ctx context.Context, a *Answer) error
+	HasAnswered(ctx context.Context, questionID, userID int) (bool, error)
+	FindByQuestion(ctx context.Context, questionID int) ([]Answer, error)
+	CountByQuestion(ctx context.Context, questionID int) (total, correct int, err error)
+	StatsByQuestion(ctx context.Context, questionID, roomID int) (*QuestionStats, error)
 }
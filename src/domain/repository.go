@@ -1,36 +1,142 @@
 package domain
 
+import (
+	"context"
+	"time"
+)
+
+// Transactor agrupa varias operaciones de repositorio en una transacción.
+// Los repositorios llamados con el ctx que recibe fn escriben dentro de ella;
+// si fn devuelve error no queda ningún cambio.
+type Transactor interface {
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 // UserRepository define las operaciones de persistencia para usuarios.
 // Esta interfaz vive en el dominio; la implementación concreta está en infrastructure.
 type UserRepository interface {
-	Create(user *User) error
-	FindByEmail(email string) (*User, error)
-	FindByID(id int) (*User, error)
+	Create(ctx context.Context, user *User) error
+	FindByEmail(ctx context.Context, email string) (*User, error)
+	FindByID(ctx context.Context, id int) (*User, error)
+	RecordLoginFailure(ctx context.Context, id int, lockedUntil *time.Time) error // suma un intento fallido
+	ResetLoginFailures(ctx context.Context, id int) error                         // login correcto: limpia el bloqueo
+	UpdatePassword(ctx context.Context, id int, hashed string) error
+	MarkEmailVerified(ctx context.Context, id int) error
+	UpdateName(ctx context.Context, id int, name string) error
+	Anonymize(ctx context.Context, id int) error // borra los datos personales y conserva respuestas y puntos
+	List(ctx context.Context, filter UserFilter) ([]User, error)
+	UpdateRole(ctx context.Context, id int, role Role) error // también limpia host_requested
+}
+
+// IdentityRepository define las operaciones de persistencia para identidades externas.
+type IdentityRepository interface {
+	FindBySubject(ctx context.Context, provider, subject string) (*ExternalIdentity, error)
+	Create(ctx context.Context, identity *ExternalIdentity) error
+}
+
+// APIKeyRepository define las operaciones de persistencia para API keys.
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *APIKey) error
+	FindByHash(ctx context.Context, hash string) (*APIKey, error)
+	ListByUser(ctx context.Context, userID int) ([]APIKey, error)
+	CountActive(ctx context.Context, userID int) (int, error)
+	Revoke(ctx context.Context, userID, id int) (bool, error) // false si no existe o no es suya
+	TouchLastUsed(ctx context.Context, id int, at time.Time) error
+}
+
+// InviteRepository define las operaciones de persistencia para códigos de invitación.
+type InviteRepository interface {
+	Create(ctx context.Context, invite *InviteCode) error
+	Consume(ctx context.Context, code string) (*InviteCode, error) // gasta un uso; nil si no es válido
+}
+
+// TokenRepository define las operaciones de persistencia para tokens de un solo uso.
+type TokenRepository interface {
+	Create(ctx context.Context, token *UserToken) error
+	FindByHash(ctx context.Context, purpose TokenPurpose, hash string) (*UserToken, error)
+	MarkUsed(ctx context.Context, id int) (bool, error)                        // false si ya estaba usado
+	InvalidateAll(ctx context.Context, userID int, purpose TokenPurpose) error // marca como usados los pendientes
 }
 
 // RoomRepository define las operaciones de persistencia para salas.
 type RoomRepository interface {
-	Create(room *Room) error
-	FindByCode(code string) (*Room, error)
-	UpdateStatus(code string, status RoomStatus) error
+	Create(ctx context.Context, room *Room) error
+	FindByCode(ctx context.Context, code string) (*Room, error)
+	UpdateStatus(ctx context.Context, code string, status RoomStatus) error
+	UpdateRankingMode(ctx context.Context, code string, mode RankingMode) error
+	UpdateStreak(ctx context.Context, code string, streak StreakConfig) error
 }
 
 // ParticipantRepository define las operaciones de persistencia para participantes.
 type ParticipantRepository interface {
-	Add(participant *Participant) error
-	ExistsInRoom(roomID, userID int) (bool, error)
-	FindByRoom(roomID int) ([]Participant, error)
-	FindByRoomWithUsers(roomID int) ([]ParticipantWithUser, error) // con datos de usuario
-	Remove(roomID, userID int) error                               // expulsar participante
+	Add(ctx context.Context, participant *Participant) error
+	ExistsInRoom(ctx context.Context, roomID, userID int) (bool, error)
+	FindByRoom(ctx context.Context, roomID int) ([]Participant, error)
+	FindByRoomWithUsers(ctx context.Context, roomID int) ([]ParticipantWithUser, error) // con datos de usuario
+	Remove(ctx context.Context, roomID, userID int) error                               // expulsar participante
 }
 
 // ScoreRepository define las operaciones de persistencia para puntos.
+// Todo cambio de puntos queda en el ledger score_events en la misma
+// transacción que actualiza scores y los leaderboards que incluyen la sala.
 type ScoreRepository interface {
-	Upsert(roomID, userID, points int) error          // crea o actualiza el score
-	AddPoints(roomID, userID, delta int) error         // suma o resta puntos
-	GetRanking(roomID int) ([]RankingEntry, error)     // ranking ordenado por puntos
-	ResetPoints(roomID, userID int) error              // resetear puntos de un participante
-	ResetAllPoints(roomID int) error                   // resetear todos los puntos de la sala
+	Upsert(ctx context.Context, roomID, userID, points int) error           // crea o actualiza el score
+	AddPoints(ctx context.Context, ev *ScoreEvent) error                    // registra el evento y suma su Delta
+	GetRanking(ctx context.Context, q RankingQuery) ([]RankingEntry, error) // ranking ordenado por puesto y desempates
+	// GetRankingEntry devuelve el puesto de un participante; nil si no tiene score en la sala
+	GetRankingEntry(ctx context.Context, q RankingQuery, userID int) (*RankingEntry, error)
+	// ResetPoints deja en 0 a ev.UserID registrando un evento reset por lo que tenía
+	ResetPoints(ctx context.Context, ev ScoreEvent) error
+	// ResetAllPoints deja en 0 a toda la sala, con un evento reset por participante
+	ResetAllPoints(ctx context.Context, roomID, actorID int, reason string) error
+	ListEvents(ctx context.Context, filter ScoreEventFilter) ([]ScoreEvent, error) // del más nuevo al más viejo
+	FindEvent(ctx context.Context, id int) (*ScoreEvent, error)
+	// Revert registra undo (con RevertsID) y aplica su Delta; false si el evento ya estaba deshecho
+	Revert(ctx context.Context, undo *ScoreEvent) (bool, error)
+	// Reconcile iguala scores.points a la suma del ledger y devuelve lo que corrigió
+	Reconcile(ctx context.Context, roomID int) ([]ScoreDrift, error)
+	// UpdateStreak suma 1 a la racha del participante si acertó o la vuelve a 0, y devuelve la nueva
+	UpdateStreak(ctx context.Context, roomID, userID int, correct bool) (int, error)
+	// ResetMissedStreaks vuelve a 0 la racha de quienes no respondieron la pregunta
+	ResetMissedStreaks(ctx context.Context, roomID, questionID int) error
+}
+
+// HistoryRepository consulta el resultado de un usuario en cada sala.
+type HistoryRepository interface {
+	// FindByUser devuelve las salas a las que se unió el usuario, de la más
+	// reciente a la más antigua; si hostID no es 0, solo las de ese host
+	FindByUser(ctx context.Context, userID, hostID int) ([]SessionHistory, error)
+}
+
+// LeaderboardRepository define las operaciones de persistencia para
+// leaderboards. Los puntos por periodo se mantienen al registrar cada
+// score_event (ver ScoreRepository); agregar o quitar una sala suma o resta
+// lo que ya tenía en el ledger.
+type LeaderboardRepository interface {
+	Create(ctx context.Context, lb *Leaderboard) error
+	FindByID(ctx context.Context, id int) (*Leaderboard, error) // nil si no existe
+	ListByHost(ctx context.Context, hostID int) ([]Leaderboard, error)
+	Delete(ctx context.Context, id int) error
+	AddRoom(ctx context.Context, lb *Leaderboard, roomID int) (bool, error)    // false si ya estaba
+	RemoveRoom(ctx context.Context, lb *Leaderboard, roomID int) (bool, error) // false si no estaba
+	Ranking(ctx context.Context, q LeaderboardQuery) ([]LeaderboardEntry, error)
+	// Entry devuelve el puesto de un usuario en el periodo; nil si no tiene puntos
+	Entry(ctx context.Context, q LeaderboardQuery, userID int) (*LeaderboardEntry, error)
+	// IsMember indica si el usuario participa en alguna sala del leaderboard
+	IsMember(ctx context.Context, id, userID int) (bool, error)
+}
+
+// BadgeRepository guarda las medallas ganadas y responde las consultas que
+// usan las reglas para decidir si se ganó una.
+type BadgeRepository interface {
+	Award(ctx context.Context, b *UserBadge) (bool, error) // false si ya la tenía en esa sala
+	// ListByUser devuelve las medallas del usuario, de la más nueva a la más
+	// vieja; si hostID no es 0, solo las ganadas en salas de ese host
+	ListByUser(ctx context.Context, userID, hostID int) ([]UserBadge, error)
+	FirstCorrectQuestion(ctx context.Context, userID int) (int, error)            // pregunta de su primera respuesta correcta
+	FirstCorrectUser(ctx context.Context, questionID int) (int, error)            // quién respondió bien primero
+	SessionResults(ctx context.Context, roomID int) (int, []SessionResult, error) // preguntas de la sala y resultado por participante
+	ScoreTimeline(ctx context.Context, roomID int) ([]ScoreEvent, error)          // UserID y Delta del ledger, en orden
 }
 
 // ParticipantWithUser combina participante y datos del usuario para listados
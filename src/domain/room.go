@@ -11,11 +11,70 @@ const (
 	RoomStatusFinished RoomStatus = "finished"
 )
 
+// RankingMode decide cómo se numeran los puestos cuando hay empates de puntos
+type RankingMode string
+
+const (
+	RankingCompetition RankingMode = "competition" // 1, 2, 2, 4
+	RankingDense       RankingMode = "dense"       // 1, 2, 2, 3
+)
+
+// Valid indica si el modo existe
+func (m RankingMode) Valid() bool {
+	return m == RankingCompetition || m == RankingDense
+}
+
+// StreakMode decide cómo premia la sala las respuestas correctas seguidas
+type StreakMode string
+
+const (
+	StreakNone       StreakMode = "none"       // sin premio
+	StreakBonus      StreakMode = "bonus"      // Step puntos extra por cada acierto previo de la racha
+	StreakMultiplier StreakMode = "multiplier" // Step % extra por cada acierto previo de la racha
+)
+
+// Valid indica si el modo existe
+func (m StreakMode) Valid() bool {
+	return m == StreakNone || m == StreakBonus || m == StreakMultiplier
+}
+
+// StreakConfig es el premio por racha de una sala. La racha cuenta las
+// respuestas correctas seguidas de un participante; se corta con una
+// respuesta incorrecta o al cerrarse una pregunta que no respondió.
+type StreakConfig struct {
+	Mode StreakMode `json:"mode"`
+	Step int        `json:"step"` // puntos (bonus) o porcentaje (multiplier) por nivel
+	Max  int        `json:"max"`  // racha a partir de la cual el premio deja de crecer; 0 = sin tope
+}
+
+// Points devuelve los puntos de una respuesta correcta que vale base y deja
+// la racha en streak (contándola). El primer acierto vale base; cada acierto
+// previo de la racha suma un nivel.
+func (c StreakConfig) Points(base, streak int) int {
+	if c.Max > 0 && streak > c.Max {
+		streak = c.Max
+	}
+	level := streak - 1
+	if level <= 0 {
+		return base
+	}
+	switch c.Mode {
+	case StreakBonus:
+		return base + c.Step*level
+	case StreakMultiplier:
+		// redondeo al entero más cercano
+		return (base*(100+c.Step*level) + 50) / 100
+	}
+	return base
+}
+
 // Room representa una sala de competencia creada por un host
 type Room struct {
-	ID        int        `json:"id"`
-	Code      string     `json:"code"`       // código corto tipo ABC123
-	HostID    int        `json:"host_id"`
-	Status    RoomStatus `json:"status"`
-	CreatedAt time.Time  `json:"created_at"`
+	ID          int          `json:"id"`
+	Code        string       `json:"code"`       // código corto tipo ABC123
+	HostID      int          `json:"host_id"`
+	Status      RoomStatus   `json:"status"`
+	RankingMode RankingMode  `json:"ranking_mode"`
+	Streak      StreakConfig `json:"streak"`
+	CreatedAt   time.Time    `json:"created_at"`
 }
@@ -0,0 +1,29 @@
+package domain
+
+import "testing"
+
+func TestStreakConfigPoints(t *testing.T) {
+	tests := []struct {
+		name   string
+		config StreakConfig
+		base   int
+		streak int
+		want   int
+	}{
+		{"sin premio", StreakConfig{Mode: StreakNone, Step: 5}, 10, 4, 10},
+		{"bonus primer acierto", StreakConfig{Mode: StreakBonus, Step: 5}, 10, 1, 10},
+		{"bonus racha de 3", StreakConfig{Mode: StreakBonus, Step: 5}, 10, 3, 20},
+		{"bonus con tope", StreakConfig{Mode: StreakBonus, Step: 5, Max: 2}, 10, 6, 15},
+		{"bonus sin racha", StreakConfig{Mode: StreakBonus, Step: 5}, 10, 0, 10},
+		{"multiplicador racha de 2", StreakConfig{Mode: StreakMultiplier, Step: 50}, 10, 2, 15},
+		{"multiplicador racha de 3", StreakConfig{Mode: StreakMultiplier, Step: 50}, 10, 3, 20},
+		{"multiplicador redondea hacia arriba", StreakConfig{Mode: StreakMultiplier, Step: 25}, 7, 2, 9},
+		{"multiplicador redondea hacia abajo", StreakConfig{Mode: StreakMultiplier, Step: 5}, 7, 2, 7},
+		{"multiplicador con tope", StreakConfig{Mode: StreakMultiplier, Step: 10, Max: 3}, 10, 10, 12},
+	}
+	for _, tt := range tests {
+		if got := tt.config.Points(tt.base, tt.streak); got != tt.want {
+			t.Errorf("%s: Points(%d, %d) = %d, se esperaba %d", tt.name, tt.base, tt.streak, got, tt.want)
+		}
+	}
+}
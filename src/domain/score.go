@@ -19,10 +19,69 @@ type Score struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
-// RankingEntry representa una entrada del ranking con los datos del usuario
+// RankingEntry representa una entrada del ranking con los datos del usuario.
+// Los empatados en puntos comparten Position (según el RankingMode de la sala);
+// dentro del empate el orden se decide, en este orden, por menor
+// TotalResponseMS, por la última respuesta correcta más temprana, por quién se
+// unió antes y por user_id.
 type RankingEntry struct {
-	UserID   int    `json:"user_id"`
-	UserName string `json:"user_name"`
-	Points   int    `json:"points"`
-	Position int    `json:"position"`
+	UserID          int    `json:"user_id"`
+	UserName        string `json:"user_name"`
+	Points          int    `json:"points"`
+	Position        int    `json:"position"`
+	TotalResponseMS int64  `json:"total_response_ms"` // suma de sus tiempos de respuesta en la sala
+	Streak          int    `json:"streak"`            // respuestas correctas seguidas
+}
+
+// RankingQuery pide una página del ranking de una sala
+type RankingQuery struct {
+	RoomID int
+	Mode   RankingMode
+	Limit  int // 0 = todos
+	Offset int
+}
+
+// ScoreSource indica qué originó un cambio de puntos
+type ScoreSource string
+
+const (
+	ScoreSourceManual  ScoreSource = "manual"  // el host sumó o restó puntos
+	ScoreSourceAnswer  ScoreSource = "answer"  // respuesta correcta
+	ScoreSourceReset   ScoreSource = "reset"   // reset de un participante, de la sala o expulsión
+	ScoreSourceUndo    ScoreSource = "undo"    // reversión de otro evento
+	ScoreSourceOpening ScoreSource = "opening" // puntos previos al ledger, registrados al migrar
+)
+
+// ScoreEvent es una entrada del ledger score_events: un cambio de puntos con
+// quién lo hizo y por qué. Los puntos de un participante en la sala son la
+// suma de los Delta de sus eventos.
+type ScoreEvent struct {
+	ID         int         `json:"id"`
+	RoomID     int         `json:"room_id"`
+	UserID     int         `json:"user_id"`
+	UserName   string      `json:"user_name,omitempty"`
+	ActorID    int         `json:"actor_id,omitempty"` // 0 si el actor ya no existe
+	ActorName  string      `json:"actor_name,omitempty"`
+	Source     ScoreSource `json:"source"`
+	Delta      int         `json:"delta"`
+	Reason     string      `json:"reason,omitempty"`
+	QuestionID int         `json:"question_id,omitempty"` // source = answer
+	RevertsID  int         `json:"reverts_id,omitempty"`  // source = undo: evento que deshace
+	RevertedBy int         `json:"reverted_by,omitempty"` // evento undo que lo deshizo, si hay
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+// ScoreEventFilter acota el listado del ledger de una sala
+type ScoreEventFilter struct {
+	RoomID int
+	UserID int // 0 = todos
+	Limit  int
+	Offset int
+}
+
+// ScoreDrift es un participante cuyo scores.points no coincide con su ledger
+type ScoreDrift struct {
+	UserID int `json:"user_id"`
+	Points int `json:"points"` // lo que tenía scores
+	Ledger int `json:"ledger"` // la suma de sus eventos, que pasa a ser el valor
 }
@@ -6,11 +6,30 @@ import "time"
 type Role string
 
 const (
-RoleHost        Role = "host"
-RoleParticipant Role = "participant"
+	RoleAdmin       Role = "admin"
+	RoleHost        Role = "host"
+	RoleParticipant Role = "participant"
 )
 
-// User representa a un usuario del sistema (host o participante)
+// roleLevels ordena los roles: cada uno puede hacer todo lo de los inferiores
+var roleLevels = map[Role]int{
+	RoleParticipant: 1,
+	RoleHost:        2,
+	RoleAdmin:       3,
+}
+
+// Valid indica si el rol existe
+func (r Role) Valid() bool {
+	_, ok := roleLevels[r]
+	return ok
+}
+
+// AtLeast indica si el rol alcanza el nivel de min (admin ⊇ host ⊇ participant)
+func (r Role) AtLeast(min Role) bool {
+	return r.Valid() && roleLevels[r] >= roleLevels[min]
+}
+
+// User representa a un usuario del sistema (admin, host o participante)
 type User struct {
 	ID        int       `json:"id"`
 	Name      string    `json:"name"`
@@ -18,4 +37,61 @@ type User struct {
 	Password  string    `json:"-"` // nunca se expone en JSON
 	Role      Role      `json:"role"`
 	CreatedAt time.Time `json:"created_at"`
+
+	EmailVerified bool `json:"email_verified"`
+	HostRequested bool `json:"host_requested"` // pidió ser host al registrarse y espera aprobación
+
+	FailedLogins int        `json:"-"` // intentos fallidos seguidos
+	LockedUntil  *time.Time `json:"-"` // bloqueo temporal por intentos fallidos
+
+	DeletedAt *time.Time `json:"-"` // cuenta eliminada: los datos personales ya se anonimizaron
+}
+
+// AccountLockedError indica que la cuenta está bloqueada temporalmente
+// por demasiados intentos de login fallidos
+type AccountLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *AccountLockedError) Error() string {
+	return "cuenta bloqueada temporalmente por intentos fallidos"
+}
+
+// TokenPurpose indica para qué sirve un token de un solo uso
+type TokenPurpose string
+
+const (
+	TokenPasswordReset     TokenPurpose = "password_reset"
+	TokenEmailVerification TokenPurpose = "email_verification"
+)
+
+// UserToken es un token de un solo uso enviado por correo.
+// Solo se guarda el hash; el token en claro únicamente viaja en el correo.
+type UserToken struct {
+	ID        int
+	UserID    int
+	Purpose   TokenPurpose
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// InviteCode permite registrarse directamente con un rol distinto de participante
+type InviteCode struct {
+	ID        int        `json:"id"`
+	Code      string     `json:"code"`
+	Role      Role       `json:"role"`
+	UsesLeft  int        `json:"uses_left"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	CreatedBy int        `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// UserFilter acota el listado de usuarios del panel de administración
+type UserFilter struct {
+	Role          Role // vacío = todos
+	HostRequested bool // solo los que esperan aprobación como host
+	Limit         int
+	Offset        int
 }
@@ -0,0 +1,195 @@
+package broker
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	ws "apiGolan/src/infrastructure/websocket"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// channel es el canal de pub/sub por donde viajan los mensajes de todas las salas
+const channel = "quickscore:ws"
+
+// presenceTTL es cuánto vive la presencia de una instancia sin refrescarse.
+// El Hub la refresca cada PresenceRefresh; tres vueltas perdidas = caída.
+const presenceTTL = 3 * ws.PresenceRefresh
+
+// Redis reparte los mensajes del Hub entre instancias con Redis pub/sub y
+// guarda la presencia de cada instancia en un hash con expiración:
+//
+//	quickscore:ws:presence:<sala>            set de instancias con usuarios en la sala
+//	quickscore:ws:presence:<sala>:<instancia> hash userID → ClientInfo (JSON)
+type Redis struct {
+	client   *redis.Client
+	pubsub   *redis.PubSub
+	instance string // id aleatorio de esta instancia
+
+	mu    sync.Mutex
+	rooms map[string]bool // salas donde esta instancia publicó presencia
+}
+
+// NewRedis conecta con la URL dada (redis://[:clave@]host:puerto/db) y se suscribe al canal
+func NewRedis(ctx context.Context, url string) (*Redis, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("REDIS_URL inválida: %w", err)
+	}
+	client := redis.NewClient(opts)
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("error al conectar con Redis: %w", err)
+	}
+
+	// Receive confirma la suscripción antes de aceptar conexiones
+	pubsub := client.Subscribe(ctx, channel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		client.Close()
+		return nil, fmt.Errorf("error al suscribirse en Redis: %w", err)
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		pubsub.Close()
+		client.Close()
+		return nil, err
+	}
+
+	return &Redis{
+		client:   client,
+		pubsub:   pubsub,
+		instance: hex.EncodeToString(id),
+		rooms:    make(map[string]bool),
+	}, nil
+}
+
+func (b *Redis) Publish(ctx context.Context, ev ws.Event) error {
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	return b.client.Publish(ctx, channel, payload).Err()
+}
+
+// Subscribe entrega cada mensaje recibido. go-redis reconecta la suscripción
+// sola si se corta la conexión; lo publicado mientras tanto se pierde.
+func (b *Redis) Subscribe(deliver func(ev ws.Event)) {
+	go func() {
+		for msg := range b.pubsub.Channel() {
+			var ev ws.Event
+			if err := json.Unmarshal([]byte(msg.Payload), &ev); err != nil {
+				slog.Error("mensaje inválido en el broker", "error", err)
+				continue
+			}
+			deliver(ev)
+		}
+	}()
+}
+
+func (b *Redis) SetPresence(ctx context.Context, room string, users []ws.ClientInfo) error {
+	roomKey := presenceKey(room)
+	key := roomKey + ":" + b.instance
+
+	if len(users) == 0 {
+		b.mu.Lock()
+		delete(b.rooms, room)
+		b.mu.Unlock()
+
+		_, err := b.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Del(ctx, key)
+			pipe.SRem(ctx, roomKey, b.instance)
+			return nil
+		})
+		return err
+	}
+
+	fields := make([]interface{}, 0, len(users)*2)
+	for _, info := range users {
+		data, err := json.Marshal(info)
+		if err != nil {
+			return err
+		}
+		fields = append(fields, strconv.Itoa(info.UserID), data)
+	}
+
+	b.mu.Lock()
+	b.rooms[room] = true
+	b.mu.Unlock()
+
+	_, err := b.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key)
+		pipe.HSet(ctx, key, fields...)
+		pipe.Expire(ctx, key, presenceTTL)
+		pipe.SAdd(ctx, roomKey, b.instance)
+		pipe.Expire(ctx, roomKey, presenceTTL)
+		return nil
+	})
+	return err
+}
+
+func (b *Redis) RemotePresence(ctx context.Context, room string) ([]ws.ClientInfo, error) {
+	roomKey := presenceKey(room)
+	instances, err := b.client.SMembers(ctx, roomKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var users []ws.ClientInfo
+	for _, instance := range instances {
+		if instance == b.instance {
+			continue
+		}
+		entries, err := b.client.HGetAll(ctx, roomKey+":"+instance).Result()
+		if err != nil {
+			return nil, err
+		}
+		if len(entries) == 0 {
+			// la instancia dejó de refrescar su presencia: se la quita del set
+			b.client.SRem(ctx, roomKey, instance)
+			continue
+		}
+		for _, data := range entries {
+			var info ws.ClientInfo
+			if err := json.Unmarshal([]byte(data), &info); err != nil {
+				continue
+			}
+			users = append(users, info)
+		}
+	}
+	return users, nil
+}
+
+// Close retira la presencia de esta instancia y cierra la conexión
+func (b *Redis) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	b.mu.Lock()
+	rooms := make([]string, 0, len(b.rooms))
+	for room := range b.rooms {
+		rooms = append(rooms, room)
+	}
+	b.mu.Unlock()
+
+	for _, room := range rooms {
+		if err := b.SetPresence(ctx, room, nil); err != nil {
+			slog.Error("error al retirar la presencia", "room", room, "error", err)
+		}
+	}
+
+	b.pubsub.Close()
+	return b.client.Close()
+}
+
+func presenceKey(room string) string {
+	return "quickscore:ws:presence:" + room
+}
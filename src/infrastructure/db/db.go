@@ -0,0 +1,294 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Driver identifica el motor de base de datos configurado con DB_DRIVER
+type Driver string
+
+const (
+	DriverMySQL    Driver = "mysql"
+	DriverPostgres Driver = "postgres"
+	DriverSQLite   Driver = "sqlite"
+)
+
+// DB envuelve *sql.DB y conoce el dialecto SQL del motor configurado.
+// Los repositorios escriben sus consultas con placeholders "?" y DB se
+// encarga de adaptarlas al motor (por ejemplo "$1, $2" en PostgreSQL).
+// Las variantes *Context además aplican QueryTimeout a cada consulta, así que
+// los repositorios deben usar siempre esas y no los métodos sin contexto.
+type DB struct {
+	*sql.DB
+	Driver       Driver
+	QueryTimeout time.Duration // plazo máximo por consulta (DB_QUERY_TIMEOUT)
+}
+
+// Connect abre la conexión con el motor indicado en DB_DRIVER (mysql por defecto)
+// y, salvo DB_MIGRATE=false, aplica el esquema y las migraciones pendientes
+func Connect() (*DB, error) {
+	driver := Driver(strings.ToLower(getEnv("DB_DRIVER", string(DriverMySQL))))
+
+	var (
+		conn *sql.DB
+		err  error
+	)
+	switch driver {
+	case DriverMySQL:
+		conn, err = connectMySQL()
+	case DriverPostgres:
+		conn, err = connectPostgres()
+	case DriverSQLite:
+		conn, err = connectSQLite()
+	default:
+		return nil, fmt.Errorf("DB_DRIVER desconocido: %q", driver)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	timeout, err := time.ParseDuration(getEnv("DB_QUERY_TIMEOUT", "5s"))
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("DB_QUERY_TIMEOUT inválido: %w", err)
+	}
+
+	d := &DB{DB: conn, Driver: driver, QueryTimeout: timeout}
+	if getEnv("DB_MIGRATE", "true") != "false" {
+		if err := d.Migrate(context.Background()); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("error al migrar la base de datos: %w", err)
+		}
+	}
+	return d, nil
+}
+
+// Rebind convierte los placeholders "?" al formato del motor
+func (d *DB) Rebind(query string) string {
+	if d.Driver != DriverPostgres {
+		return query
+	}
+
+	var sb strings.Builder
+	n := 0
+	for i := 0; i < len(query); i++ {
+		if query[i] == '?' {
+			n++
+			sb.WriteByte('$')
+			sb.WriteString(strconv.Itoa(n))
+			continue
+		}
+		sb.WriteByte(query[i])
+	}
+	return sb.String()
+}
+
+// withTimeout limita el contexto al plazo configurado para una consulta
+func (d *DB) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if d.QueryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d.QueryTimeout)
+}
+
+// txKey guarda en el contexto la transacción abierta por InTx
+type txKey struct{}
+
+// conn devuelve la transacción del contexto, si hay una, o la base de datos
+func (d *DB) conn(ctx context.Context) interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+} {
+	if tx, ok := ctx.Value(txKey{}).(*Tx); ok && tx.db == d {
+		return tx.Tx
+	}
+	return d.DB
+}
+
+// ExecContext ejecuta una sentencia adaptando los placeholders al motor.
+// Dentro de InTx (ctx de fn) corre en la transacción.
+func (d *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, cancel := d.withTimeout(ctx)
+	defer cancel()
+	return d.conn(ctx).ExecContext(ctx, d.Rebind(query), args...)
+}
+
+// QueryContext ejecuta una consulta adaptando los placeholders al motor.
+// El plazo se libera al cerrar las filas.
+func (d *DB) QueryContext(ctx context.Context, query string, args ...interface{}) (*Rows, error) {
+	ctx, cancel := d.withTimeout(ctx)
+	rows, err := d.conn(ctx).QueryContext(ctx, d.Rebind(query), args...)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &Rows{Rows: rows, cancel: cancel}, nil
+}
+
+// QueryRowContext ejecuta una consulta de una fila adaptando los placeholders al motor.
+// El plazo se libera al hacer Scan.
+func (d *DB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *Row {
+	ctx, cancel := d.withTimeout(ctx)
+	return &Row{Row: d.conn(ctx).QueryRowContext(ctx, d.Rebind(query), args...), cancel: cancel}
+}
+
+// Insert ejecuta un INSERT y devuelve el id generado.
+// PostgreSQL no soporta LastInsertId, así que ahí se usa RETURNING id.
+func (d *DB) Insert(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	if d.Driver == DriverPostgres {
+		var id int64
+		err := d.QueryRowContext(ctx, query+" RETURNING id", args...).Scan(&id)
+		return id, err
+	}
+
+	result, err := d.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// Tx es una transacción que, igual que DB, adapta los placeholders al motor
+type Tx struct {
+	*sql.Tx
+	db *DB
+}
+
+// Upsert es DB.Upsert para el motor de la transacción
+func (t *Tx) Upsert(conflict, set string) string {
+	return t.db.Upsert(conflict, set)
+}
+
+// InTx ejecuta fn dentro de una transacción: la confirma si fn no devuelve
+// error y la deshace si sí. QueryTimeout se aplica a la transacción completa.
+// El ctx que recibe fn lleva la transacción: los métodos de DB llamados con
+// ese ctx corren dentro de ella, y un InTx anidado se une a la de afuera en
+// vez de abrir otra (solo la más externa confirma o deshace).
+// En SQLite conviene que la primera sentencia de fn sea una escritura, así la
+// transacción toma el lock de escritura de entrada (esperando busy_timeout)
+// en vez de fallar al pasar de lectura a escritura.
+func (d *DB) InTx(ctx context.Context, fn func(ctx context.Context, tx *Tx) error) error {
+	if tx, ok := ctx.Value(txKey{}).(*Tx); ok && tx.db == d {
+		return fn(ctx, tx)
+	}
+
+	ctx, cancel := d.withTimeout(ctx)
+	defer cancel()
+
+	sqlTx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	tx := &Tx{Tx: sqlTx, db: d}
+	if err := fn(context.WithValue(ctx, txKey{}, tx), tx); err != nil {
+		sqlTx.Rollback()
+		return err
+	}
+	return sqlTx.Commit()
+}
+
+func (t *Tx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return t.Tx.ExecContext(ctx, t.db.Rebind(query), args...)
+}
+
+func (t *Tx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return t.Tx.QueryContext(ctx, t.db.Rebind(query), args...)
+}
+
+func (t *Tx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return t.Tx.QueryRowContext(ctx, t.db.Rebind(query), args...)
+}
+
+// Insert es como DB.Insert pero dentro de la transacción
+func (t *Tx) Insert(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	if t.db.Driver == DriverPostgres {
+		var id int64
+		err := t.QueryRowContext(ctx, query+" RETURNING id", args...).Scan(&id)
+		return id, err
+	}
+
+	result, err := t.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// Rows son filas de una consulta cuyo plazo se libera al cerrarlas
+type Rows struct {
+	*sql.Rows
+	cancel context.CancelFunc
+}
+
+func (r *Rows) Close() error {
+	err := r.Rows.Close()
+	r.cancel()
+	return err
+}
+
+// Row es una fila cuyo plazo se libera al leerla
+type Row struct {
+	*sql.Row
+	cancel context.CancelFunc
+}
+
+func (r *Row) Scan(dest ...interface{}) error {
+	defer r.cancel()
+	return r.Row.Scan(dest...)
+}
+
+// Upsert devuelve la cláusula que resuelve un conflicto de clave única.
+// conflict son las columnas de la clave y set las asignaciones, escritas con
+// excluded.<col> para referirse al valor que se intentó insertar.
+func (d *DB) Upsert(conflict, set string) string {
+	if d.Driver == DriverMySQL {
+		// MySQL no tiene excluded; usa VALUES(col)
+		return "ON DUPLICATE KEY UPDATE " + mysqlExcluded(set)
+	}
+	return fmt.Sprintf("ON CONFLICT (%s) DO UPDATE SET %s", conflict, set)
+}
+
+// Ignore devuelve la cláusula que descarta la fila si choca con la clave
+// única conflict. En ese caso RowsAffected es 0, así se sabe si se insertó.
+func (d *DB) Ignore(conflict string) string {
+	if d.Driver == DriverMySQL {
+		// asignar una columna a sí misma no cambia la fila: 0 filas afectadas
+		col := strings.TrimSpace(strings.Split(conflict, ",")[0])
+		return "ON DUPLICATE KEY UPDATE " + col + " = " + col
+	}
+	return fmt.Sprintf("ON CONFLICT (%s) DO NOTHING", conflict)
+}
+
+// mysqlExcluded traduce excluded.col → VALUES(col)
+func mysqlExcluded(set string) string {
+	var sb strings.Builder
+	for {
+		i := strings.Index(set, "excluded.")
+		if i < 0 {
+			sb.WriteString(set)
+			return sb.String()
+		}
+		sb.WriteString(set[:i])
+		set = set[i+len("excluded."):]
+		j := 0
+		for j < len(set) && (set[j] == '_' || set[j] >= 'a' && set[j] <= 'z' || set[j] >= '0' && set[j] <= '9') {
+			j++
+		}
+		sb.WriteString("VALUES(" + set[:j] + ")")
+		set = set[j:]
+	}
+}
+
+func getEnv(key, defaultVal string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return defaultVal
+}
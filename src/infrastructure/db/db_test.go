@@ -0,0 +1,256 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRebind(t *testing.T) {
+	tests := []struct {
+		driver Driver
+		query  string
+		want   string
+	}{
+		{DriverPostgres, "SELECT * FROM users WHERE id = ?", "SELECT * FROM users WHERE id = $1"},
+		{DriverPostgres, "INSERT INTO t (a, b, c) VALUES (?, ?, ?)", "INSERT INTO t (a, b, c) VALUES ($1, $2, $3)"},
+		{DriverPostgres, "SELECT 1", "SELECT 1"},
+		{DriverMySQL, "SELECT * FROM users WHERE id = ?", "SELECT * FROM users WHERE id = ?"},
+		{DriverSQLite, "UPDATE t SET a = ? WHERE b = ?", "UPDATE t SET a = ? WHERE b = ?"},
+	}
+	for _, tt := range tests {
+		d := &DB{Driver: tt.driver}
+		if got := d.Rebind(tt.query); got != tt.want {
+			t.Errorf("%s Rebind(%q) = %q, se esperaba %q", tt.driver, tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestUpsert(t *testing.T) {
+	tests := []struct {
+		driver Driver
+		want   string
+	}{
+		{DriverMySQL, "ON DUPLICATE KEY UPDATE points = VALUES(points), updated_at = CURRENT_TIMESTAMP"},
+		{DriverPostgres, "ON CONFLICT (room_id, user_id) DO UPDATE SET points = excluded.points, updated_at = CURRENT_TIMESTAMP"},
+		{DriverSQLite, "ON CONFLICT (room_id, user_id) DO UPDATE SET points = excluded.points, updated_at = CURRENT_TIMESTAMP"},
+	}
+	for _, tt := range tests {
+		d := &DB{Driver: tt.driver}
+		got := d.Upsert("room_id, user_id", "points = excluded.points, updated_at = CURRENT_TIMESTAMP")
+		if got != tt.want {
+			t.Errorf("%s Upsert = %q, se esperaba %q", tt.driver, got, tt.want)
+		}
+	}
+}
+
+func TestIgnore(t *testing.T) {
+	tests := []struct {
+		driver Driver
+		want   string
+	}{
+		{DriverMySQL, "ON DUPLICATE KEY UPDATE user_id = user_id"},
+		{DriverPostgres, "ON CONFLICT (user_id, badge, room_id) DO NOTHING"},
+		{DriverSQLite, "ON CONFLICT (user_id, badge, room_id) DO NOTHING"},
+	}
+	for _, tt := range tests {
+		d := &DB{Driver: tt.driver}
+		if got := d.Ignore("user_id, badge, room_id"); got != tt.want {
+			t.Errorf("%s Ignore = %q, se esperaba %q", tt.driver, got, tt.want)
+		}
+	}
+}
+
+func TestMysqlExcluded(t *testing.T) {
+	tests := []struct {
+		set  string
+		want string
+	}{
+		{"points = excluded.points", "points = VALUES(points)"},
+		{"points = points + excluded.points", "points = points + VALUES(points)"},
+		{"a = excluded.a, b_2 = excluded.b_2", "a = VALUES(a), b_2 = VALUES(b_2)"},
+		{"points = excluded.points+1", "points = VALUES(points)+1"},
+		{"updated_at = CURRENT_TIMESTAMP", "updated_at = CURRENT_TIMESTAMP"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := mysqlExcluded(tt.set); got != tt.want {
+			t.Errorf("mysqlExcluded(%q) = %q, se esperaba %q", tt.set, got, tt.want)
+		}
+	}
+}
+
+// openMemory abre una base SQLite en memoria con el esquema embebido
+func openMemory(t *testing.T) *DB {
+	t.Helper()
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DB_PATH", ":memory:")
+	d, err := Connect()
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	t.Cleanup(func() { d.Close() })
+	return d
+}
+
+func TestInTxRollback(t *testing.T) {
+	d := openMemory(t)
+	ctx := context.Background()
+
+	err := d.InTx(ctx, func(ctx context.Context, tx *Tx) error {
+		if _, err := tx.Insert(ctx, `INSERT INTO users (name, email, password, role) VALUES (?, ?, ?, ?)`,
+			"Ana", "ana@x.com", "x", "host"); err != nil {
+			return err
+		}
+		return context.Canceled
+	})
+	if err != context.Canceled {
+		t.Fatalf("InTx devolvió %v, se esperaba el error de fn", err)
+	}
+
+	var n int
+	if err := d.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Errorf("la transacción fallida dejó %d usuarios", n)
+	}
+}
+
+func TestInTxContextJoinsTransaction(t *testing.T) {
+	d := openMemory(t)
+	ctx := context.Background()
+
+	err := d.InTx(ctx, func(ctx context.Context, _ *Tx) error {
+		// DB.Insert con el ctx de fn corre dentro de la transacción
+		if _, err := d.Insert(ctx, `INSERT INTO users (name, email, password, role) VALUES (?, ?, ?, ?)`,
+			"Ana", "ana@x.com", "x", "host"); err != nil {
+			return err
+		}
+		// un InTx anidado se une a la transacción de afuera
+		if err := d.InTx(ctx, func(ctx context.Context, tx *Tx) error {
+			_, err := tx.ExecContext(ctx, `INSERT INTO users (name, email, password, role) VALUES (?, ?, ?, ?)`,
+				"Beto", "beto@x.com", "x", "participant")
+			return err
+		}); err != nil {
+			return err
+		}
+
+		var n int
+		if err := d.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`).Scan(&n); err != nil {
+			return err
+		}
+		if n != 2 {
+			t.Errorf("dentro de la transacción se ven %d usuarios, se esperaban 2", n)
+		}
+		return context.Canceled
+	})
+	if err != context.Canceled {
+		t.Fatalf("InTx devolvió %v", err)
+	}
+
+	var n int
+	if err := d.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Errorf("el rollback dejó %d usuarios", n)
+	}
+}
+
+// slowQuery tarda varios segundos en SQLite: cuenta hasta cien millones
+const slowQuery = `WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c WHERE x < 100000000) SELECT COUNT(*) FROM c`
+
+func TestQueryTimeout(t *testing.T) {
+	d := openMemory(t)
+
+	tests := []struct {
+		name  string
+		query func(ctx context.Context) error
+	}{
+		{"QueryRowContext", func(ctx context.Context) error {
+			var n int
+			return d.QueryRowContext(ctx, slowQuery).Scan(&n)
+		}},
+		{"QueryContext", func(ctx context.Context) error {
+			rows, err := d.QueryContext(ctx, slowQuery)
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+			for rows.Next() {
+			}
+			return rows.Err()
+		}},
+		{"ExecContext", func(ctx context.Context) error {
+			_, err := d.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS t AS `+slowQuery)
+			return err
+		}},
+		{"InTx", func(ctx context.Context) error {
+			return d.InTx(ctx, func(ctx context.Context, tx *Tx) error {
+				var n int
+				return tx.QueryRowContext(ctx, slowQuery).Scan(&n)
+			})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d.QueryTimeout = 50 * time.Millisecond
+			start := time.Now()
+			err := tt.query(context.Background())
+			if err == nil {
+				t.Fatal("la consulta lenta terminó sin error")
+			}
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("la consulta se cortó a los %v, se esperaba cerca de QueryTimeout", elapsed)
+			}
+
+			// el plazo de una consulta no afecta a las siguientes
+			var n int
+			if err := d.QueryRowContext(context.Background(), `SELECT 1`).Scan(&n); err != nil || n != 1 {
+				t.Errorf("la consulta siguiente = %d, %v", n, err)
+			}
+
+			// un contexto ya cancelado corta aunque no haya QueryTimeout
+			d.QueryTimeout = 0
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			if err := tt.query(ctx); err == nil {
+				t.Error("la consulta con el contexto cancelado terminó sin error")
+			}
+		})
+	}
+}
+
+func TestConnectQueryTimeout(t *testing.T) {
+	tests := []struct {
+		env     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 5 * time.Second, false},
+		{"250ms", 250 * time.Millisecond, false},
+		{"0", 0, false},
+		{"cinco", 0, true},
+	}
+	for _, tt := range tests {
+		t.Setenv("DB_DRIVER", "sqlite")
+		t.Setenv("DB_PATH", ":memory:")
+		t.Setenv("DB_QUERY_TIMEOUT", tt.env)
+		d, err := Connect()
+		if tt.wantErr {
+			if err == nil {
+				d.Close()
+				t.Errorf("DB_QUERY_TIMEOUT=%q no dio error", tt.env)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("DB_QUERY_TIMEOUT=%q: %v", tt.env, err)
+		}
+		if d.QueryTimeout != tt.want {
+			t.Errorf("DB_QUERY_TIMEOUT=%q → %v, se esperaba %v", tt.env, d.QueryTimeout, tt.want)
+		}
+		d.Close()
+	}
+}
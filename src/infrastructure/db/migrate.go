@@ -0,0 +1,405 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// migration es un cambio versionado sobre tablas que ya existen. El esquema
+// embebido de cada motor crea las tablas que falten con todas sus columnas,
+// pero no modifica las existentes: lo que se agrega a una tabla ya creada
+// (columnas, índices, valores de un ENUM o CHECK) va además en una migración.
+// Cada paso comprueba si ya está aplicado, así en una base nueva, creada con
+// el esquema completo, la migración solo queda registrada.
+type migration struct {
+	version int
+	name    string
+	up      func(ctx context.Context, m *migrator) error
+}
+
+// migrations se aplican en orden; nunca se edita una ya publicada, se agrega otra
+var migrations = []migration{
+	{1, "users_login_lock", func(ctx context.Context, m *migrator) error {
+		if err := m.addColumn(ctx, "users", "failed_logins", "INT NOT NULL DEFAULT 0"); err != nil {
+			return err
+		}
+		return m.addColumn(ctx, "users", "locked_until", m.pick("TIMESTAMP NULL DEFAULT NULL", "TIMESTAMP NULL", "DATETIME"))
+	}},
+	{2, "users_email_verified", func(ctx context.Context, m *migrator) error {
+		// las cuentas existentes quedan sin verificar: nadie comprobó su email
+		return m.addColumn(ctx, "users", "email_verified", "BOOLEAN NOT NULL DEFAULT FALSE")
+	}},
+	{3, "users_deleted_at", func(ctx context.Context, m *migrator) error {
+		return m.addColumn(ctx, "users", "deleted_at", m.pick("TIMESTAMP NULL DEFAULT NULL", "TIMESTAMP NULL", "DATETIME"))
+	}},
+	{4, "users_admin_role", func(ctx context.Context, m *migrator) error {
+		if err := m.addColumn(ctx, "users", "host_requested", "BOOLEAN NOT NULL DEFAULT FALSE"); err != nil {
+			return err
+		}
+		switch m.db.Driver {
+		case DriverMySQL:
+			return m.exec(ctx, `ALTER TABLE users MODIFY role ENUM('admin','host','participant') NOT NULL DEFAULT 'participant'`)
+		case DriverPostgres:
+			if err := m.exec(ctx, `ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check`); err != nil {
+				return err
+			}
+			return m.exec(ctx, `ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('admin','host','participant'))`)
+		default:
+			// SQLite no permite cambiar un CHECK: hay que recrear la tabla
+			var ddl string
+			if err := m.queryRow(ctx, `SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'users'`).Scan(&ddl); err != nil {
+				return err
+			}
+			if strings.Contains(ddl, "'admin'") {
+				return nil
+			}
+			return m.rebuildSQLiteTable(ctx, "users")
+		}
+	}},
+	{5, "answers_response_ms", func(ctx context.Context, m *migrator) error {
+		if err := m.addColumn(ctx, "answers", "response_ms", m.pick("INT NULL", "INT", "INTEGER")); err != nil {
+			return err
+		}
+		if m.db.Driver == DriverMySQL {
+			// milisegundos para medir tiempos de respuesta (PostgreSQL y SQLite ya los guardan)
+			return m.exec(ctx, `ALTER TABLE questions MODIFY created_at TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3)`)
+		}
+		return nil
+	}},
+	{6, "history_indexes", func(ctx context.Context, m *migrator) error {
+		if err := m.addIndex(ctx, "participants", "idx_participants_user", "user_id, joined_at"); err != nil {
+			return err
+		}
+		if err := m.addIndex(ctx, "scores", "idx_scores_room_points", "room_id, points"); err != nil {
+			return err
+		}
+		return m.addIndex(ctx, "questions", "idx_questions_room", "room_id")
+	}},
+	{7, "rooms_ranking_mode", func(ctx context.Context, m *migrator) error {
+		return m.addColumn(ctx, "rooms", "ranking_mode", m.pick(
+			"ENUM('competition','dense') NOT NULL DEFAULT 'competition'",
+			"VARCHAR(20) NOT NULL DEFAULT 'competition' CHECK (ranking_mode IN ('competition','dense'))",
+			"TEXT NOT NULL DEFAULT 'competition' CHECK (ranking_mode IN ('competition','dense'))",
+		))
+	}},
+	{8, "streaks", func(ctx context.Context, m *migrator) error {
+		if err := m.addColumn(ctx, "rooms", "streak_mode", m.pick(
+			"ENUM('none','bonus','multiplier') NOT NULL DEFAULT 'none'",
+			"VARCHAR(20) NOT NULL DEFAULT 'none' CHECK (streak_mode IN ('none','bonus','multiplier'))",
+			"TEXT NOT NULL DEFAULT 'none' CHECK (streak_mode IN ('none','bonus','multiplier'))",
+		)); err != nil {
+			return err
+		}
+		for _, col := range []struct{ table, name string }{
+			{"rooms", "streak_step"}, {"rooms", "streak_max"}, {"scores", "streak"},
+		} {
+			if err := m.addColumn(ctx, col.table, col.name, "INT NOT NULL DEFAULT 0"); err != nil {
+				return err
+			}
+		}
+		return nil
+	}},
+	{9, "score_events_opening_balance", func(ctx context.Context, m *migrator) error {
+		// Los puntos sumados antes de que existiera el ledger no tienen
+		// eventos: sin este saldo inicial Reconcile los dejaría en 0. Lo que
+		// falte en el ledger al migrar es justamente ese saldo previo. Va con
+		// source opening, sin actor: no es un cambio del host y no se deshace.
+		switch m.db.Driver {
+		case DriverMySQL:
+			if err := m.exec(ctx, `ALTER TABLE score_events MODIFY source ENUM('manual','answer','reset','undo','opening') NOT NULL`); err != nil {
+				return err
+			}
+		case DriverPostgres:
+			if err := m.exec(ctx, `ALTER TABLE score_events DROP CONSTRAINT IF EXISTS score_events_source_check`); err != nil {
+				return err
+			}
+			if err := m.exec(ctx, `ALTER TABLE score_events ADD CONSTRAINT score_events_source_check CHECK (source IN ('manual','answer','reset','undo','opening'))`); err != nil {
+				return err
+			}
+		default:
+			var ddl string
+			if err := m.queryRow(ctx, `SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'score_events'`).Scan(&ddl); err != nil {
+				return err
+			}
+			if !strings.Contains(ddl, "'opening'") {
+				if err := m.rebuildSQLiteTable(ctx, "score_events"); err != nil {
+					return err
+				}
+			}
+		}
+		return m.exec(ctx, `
+			INSERT INTO score_events (room_id, user_id, source, delta, reason)
+			SELECT s.room_id, s.user_id, ?, s.points - COALESCE(l.total, 0), ?
+			FROM scores s
+			LEFT JOIN (
+				SELECT room_id, user_id, SUM(delta) AS total
+				FROM score_events
+				GROUP BY room_id, user_id
+			) l ON l.room_id = s.room_id AND l.user_id = s.user_id
+			WHERE s.points <> COALESCE(l.total, 0)`, openingSource, openingBalanceReason)
+	}},
+}
+
+// openingSource y openingBalanceReason marcan los eventos que registran los
+// puntos que cada participante tenía antes de que existiera el ledger.
+// openingSource es el valor de domain.ScoreSourceOpening.
+const (
+	openingSource        = "opening"
+	openingBalanceReason = "saldo previo al historial de puntos"
+)
+
+// schemaMigrationsTable registra las migraciones aplicadas (igual en los tres motores)
+const schemaMigrationsTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version    INT          NOT NULL PRIMARY KEY,
+	name       VARCHAR(100) NOT NULL,
+	applied_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+)`
+
+// Lock de migraciones: nombre en MySQL (GET_LOCK) y clave en PostgreSQL (pg_advisory_lock)
+const (
+	mysqlMigrationLock    = "quickscore_migrations"
+	postgresMigrationLock = 7240513
+)
+
+// Migrate aplica el esquema del motor (crea las tablas que falten) y luego
+// las migraciones pendientes. Todo corre en una sola conexión: en MySQL y
+// PostgreSQL toma un lock de sesión para que dos instancias que arrancan a la
+// vez no migren al mismo tiempo.
+func (d *DB) Migrate(ctx context.Context) error {
+	conn, err := d.DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	m := &migrator{conn: conn, db: d}
+
+	unlock, err := m.lock(ctx)
+	if err != nil {
+		return fmt.Errorf("no se pudo tomar el lock de migraciones: %w", err)
+	}
+	defer unlock()
+
+	if err := m.applySchema(ctx); err != nil {
+		return fmt.Errorf("error al aplicar el esquema: %w", err)
+	}
+	if err := m.exec(ctx, schemaMigrationsTable); err != nil {
+		return err
+	}
+
+	applied := map[int]bool{}
+	rows, err := conn.QueryContext(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var v int
+		if err := rows.Scan(&v); err != nil {
+			rows.Close()
+			return err
+		}
+		applied[v] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, mig := range migrations {
+		if applied[mig.version] {
+			continue
+		}
+		if err := mig.up(ctx, m); err != nil {
+			return fmt.Errorf("migración %d (%s): %w", mig.version, mig.name, err)
+		}
+		if err := m.exec(ctx, `INSERT INTO schema_migrations (version, name) VALUES (?, ?)`, mig.version, mig.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrator ejecuta los pasos de las migraciones sobre la conexión de Migrate
+type migrator struct {
+	conn *sql.Conn
+	db   *DB
+}
+
+func (m *migrator) exec(ctx context.Context, query string, args ...interface{}) error {
+	_, err := m.conn.ExecContext(ctx, m.db.Rebind(query), args...)
+	return err
+}
+
+func (m *migrator) queryRow(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return m.conn.QueryRowContext(ctx, m.db.Rebind(query), args...)
+}
+
+// pick elige la definición que corresponde al motor
+func (m *migrator) pick(mysql, postgres, sqlite string) string {
+	switch m.db.Driver {
+	case DriverMySQL:
+		return mysql
+	case DriverPostgres:
+		return postgres
+	default:
+		return sqlite
+	}
+}
+
+// lock toma el lock de migraciones y devuelve la función que lo libera
+func (m *migrator) lock(ctx context.Context) (func(), error) {
+	var ok sql.NullInt64
+	switch m.db.Driver {
+	case DriverMySQL:
+		if err := m.queryRow(ctx, `SELECT GET_LOCK(?, 60)`, mysqlMigrationLock).Scan(&ok); err != nil {
+			return nil, err
+		}
+		if ok.Int64 != 1 {
+			return nil, fmt.Errorf("otra instancia está migrando")
+		}
+		return func() {
+			m.queryRow(context.Background(), `SELECT RELEASE_LOCK(?)`, mysqlMigrationLock).Scan(&ok)
+		}, nil
+	case DriverPostgres:
+		if err := m.exec(ctx, `SELECT pg_advisory_lock(?)`, postgresMigrationLock); err != nil {
+			return nil, err
+		}
+		return func() {
+			m.exec(context.Background(), `SELECT pg_advisory_unlock(?)`, postgresMigrationLock)
+		}, nil
+	default:
+		// SQLite serializa las escrituras con el lock del archivo
+		return func() {}, nil
+	}
+}
+
+// schema devuelve el esquema embebido del motor
+func (m *migrator) schema() string {
+	return m.pick(mysqlSchema, postgresSchema, sqliteSchema)
+}
+
+// applySchema ejecuta una por una las sentencias del esquema (todas IF NOT EXISTS)
+func (m *migrator) applySchema(ctx context.Context) error {
+	for _, stmt := range splitStatements(m.schema()) {
+		if err := m.exec(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitStatements separa un script SQL en sentencias. Quita antes los
+// comentarios "--", que pueden llevar ";"; los esquemas no usan "--" ni ";"
+// dentro de literales.
+func splitStatements(script string) []string {
+	lines := strings.Split(script, "\n")
+	for i, line := range lines {
+		if j := strings.Index(line, "--"); j >= 0 {
+			lines[i] = line[:j]
+		}
+	}
+
+	var stmts []string
+	for _, stmt := range strings.Split(strings.Join(lines, "\n"), ";") {
+		if stmt = strings.TrimSpace(stmt); stmt != "" {
+			stmts = append(stmts, stmt)
+		}
+	}
+	return stmts
+}
+
+// hasColumn indica si table ya tiene la columna
+func (m *migrator) hasColumn(ctx context.Context, table, column string) (bool, error) {
+	query := m.pick(
+		`SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ? AND column_name = ?`,
+		`SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = ? AND column_name = ?`,
+		`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`,
+	)
+	var n int
+	err := m.queryRow(ctx, query, table, column).Scan(&n)
+	return n > 0, err
+}
+
+// addColumn agrega la columna si table todavía no la tiene
+func (m *migrator) addColumn(ctx context.Context, table, column, def string) error {
+	exists, err := m.hasColumn(ctx, table, column)
+	if err != nil || exists {
+		return err
+	}
+	return m.exec(ctx, fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, def))
+}
+
+// addIndex crea el índice si no existe. MySQL no soporta CREATE INDEX IF NOT
+// EXISTS, así que ahí se consulta information_schema.
+func (m *migrator) addIndex(ctx context.Context, table, name, columns string) error {
+	if m.db.Driver != DriverMySQL {
+		return m.exec(ctx, fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s ON %s (%s)`, name, table, columns))
+	}
+	var n int
+	query := `SELECT COUNT(*) FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name = ? AND index_name = ?`
+	if err := m.queryRow(ctx, query, table, name).Scan(&n); err != nil || n > 0 {
+		return err
+	}
+	return m.exec(ctx, fmt.Sprintf(`CREATE INDEX %s ON %s (%s)`, name, table, columns))
+}
+
+// rebuildSQLiteTable recrea table con su definición actual del esquema,
+// conservando las filas. Sigue el procedimiento de la documentación de
+// SQLite para cambios que ALTER TABLE no soporta: sin foreign keys, copiar a
+// una tabla nueva, borrar la vieja y renombrar la nueva.
+func (m *migrator) rebuildSQLiteTable(ctx context.Context, table string) error {
+	prefix := "CREATE TABLE IF NOT EXISTS " + table + " ("
+	create := ""
+	for _, stmt := range splitStatements(sqliteSchema) {
+		if strings.HasPrefix(stmt, prefix) {
+			create = "CREATE TABLE " + table + "_new (" + stmt[len(prefix):]
+		}
+	}
+	if create == "" {
+		return fmt.Errorf("la tabla %s no está en el esquema", table)
+	}
+
+	rows, err := m.conn.QueryContext(ctx, `SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return err
+	}
+	var columns []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		columns = append(columns, name)
+	}
+	rows.Close()
+	list := strings.Join(columns, ", ")
+
+	// foreign_keys no se puede cambiar dentro de una transacción
+	if err := m.exec(ctx, `PRAGMA foreign_keys = OFF`); err != nil {
+		return err
+	}
+	defer m.exec(context.Background(), `PRAGMA foreign_keys = ON`)
+
+	tx, err := m.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	for _, stmt := range []string{
+		create,
+		fmt.Sprintf(`INSERT INTO %s_new (%s) SELECT %s FROM %s`, table, list, list, table),
+		fmt.Sprintf(`DROP TABLE %s`, table),
+		fmt.Sprintf(`ALTER TABLE %s_new RENAME TO %s`, table, table),
+	} {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	// los índices de la tabla se borraron con ella
+	return m.applySchema(ctx)
+}
@@ -0,0 +1,229 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	script := `
+-- encabezado; con punto y coma
+CREATE TABLE a (
+    id INT, -- comentario; también
+    b  INT
+);
+
+CREATE INDEX IF NOT EXISTS idx_a ON a (b);
+`
+	got := splitStatements(script)
+	if len(got) != 2 {
+		t.Fatalf("se esperaban 2 sentencias, hay %d: %q", len(got), got)
+	}
+	if got[1] != "CREATE INDEX IF NOT EXISTS idx_a ON a (b)" {
+		t.Errorf("segunda sentencia = %q", got[1])
+	}
+}
+
+// legacySQLiteSchema es el esquema SQLite de la primera versión con soporte
+// para varios motores: sin las columnas, tablas ni roles agregados después
+const legacySQLiteSchema = `
+CREATE TABLE users (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    name       TEXT     NOT NULL,
+    email      TEXT     NOT NULL UNIQUE,
+    password   TEXT     NOT NULL,
+    role       TEXT     NOT NULL DEFAULT 'participant' CHECK (role IN ('host','participant')),
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE rooms (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    code       TEXT     NOT NULL UNIQUE,
+    host_id    INTEGER  NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status     TEXT     NOT NULL DEFAULT 'waiting' CHECK (status IN ('waiting','active','finished')),
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE participants (
+    id        INTEGER PRIMARY KEY AUTOINCREMENT,
+    room_id   INTEGER  NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    user_id   INTEGER  NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    joined_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (room_id, user_id)
+);
+CREATE TABLE scores (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    room_id    INTEGER  NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    user_id    INTEGER  NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    points     INTEGER  NOT NULL DEFAULT 0,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (room_id, user_id)
+);
+CREATE TABLE questions (
+    id             INTEGER PRIMARY KEY AUTOINCREMENT,
+    room_id        INTEGER  NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    text           TEXT     NOT NULL,
+    correct_answer TEXT     NOT NULL,
+    points         INTEGER  NOT NULL DEFAULT 10,
+    status         TEXT     NOT NULL DEFAULT 'open' CHECK (status IN ('open','closed')),
+    created_at     DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE answers (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    question_id INTEGER  NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    user_id     INTEGER  NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    text        TEXT     NOT NULL,
+    is_correct  BOOLEAN  NOT NULL DEFAULT 0,
+    answered_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (question_id, user_id)
+);
+INSERT INTO users (name, email, password, role) VALUES ('Host', 'host@x.com', 'h', 'host'), ('Ana', 'ana@x.com', 'h', 'participant');
+INSERT INTO rooms (code, host_id, status) VALUES ('ABC123', 1, 'active');
+INSERT INTO participants (room_id, user_id) VALUES (1, 2);
+INSERT INTO scores (room_id, user_id, points) VALUES (1, 2, 30);
+`
+
+func TestMigrateLegacySQLite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "legacy.db")
+	legacy, err := sql.Open("sqlite", "file:"+path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := legacy.Exec(legacySQLiteSchema); err != nil {
+		t.Fatal(err)
+	}
+	legacy.Close()
+
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DB_PATH", path)
+	d, err := Connect()
+	if err != nil {
+		t.Fatalf("Connect sobre una base vieja: %v", err)
+	}
+	defer d.Close()
+	ctx := context.Background()
+
+	// las filas existentes sobreviven y reciben los valores por defecto
+	var role, rankingMode, streakMode string
+	var points, streak int
+	err = d.QueryRowContext(ctx, `
+		SELECT u.role, r.ranking_mode, r.streak_mode, s.points, s.streak
+		FROM scores s JOIN users u ON u.id = s.user_id JOIN rooms r ON r.id = s.room_id`).Scan(&role, &rankingMode, &streakMode, &points, &streak)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if role != "participant" || rankingMode != "competition" || streakMode != "none" || points != 30 || streak != 0 {
+		t.Errorf("fila migrada = %s %s %s %d %d", role, rankingMode, streakMode, points, streak)
+	}
+
+	// los puntos previos al ledger quedan registrados como saldo inicial
+	var source, reason string
+	var delta int
+	err = d.QueryRowContext(ctx, `SELECT source, delta, reason FROM score_events WHERE room_id = 1 AND user_id = 2`).Scan(&source, &delta, &reason)
+	if err != nil {
+		t.Fatalf("saldo inicial: %v", err)
+	}
+	if source != "opening" || delta != 30 || reason != openingBalanceReason {
+		t.Errorf("saldo inicial = %s %d %q", source, delta, reason)
+	}
+
+	// el CHECK de users acepta admin después de recrear la tabla
+	if _, err := d.ExecContext(ctx, `INSERT INTO users (name, email, password, role, host_requested) VALUES ('Admin', 'admin@x.com', 'h', 'admin', TRUE)`); err != nil {
+		t.Errorf("no se pudo crear un admin: %v", err)
+	}
+
+	// las foreign keys hacia users siguen funcionando (ON DELETE CASCADE)
+	if _, err := d.ExecContext(ctx, `DELETE FROM users WHERE id = 2`); err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	if err := d.QueryRowContext(ctx, `SELECT COUNT(*) FROM participants`).Scan(&n); err != nil || n != 0 {
+		t.Errorf("participantes tras borrar el usuario = %d, %v; se esperaba 0", n, err)
+	}
+
+	// las tablas nuevas existen y todas las migraciones quedaron registradas
+	if _, err := d.ExecContext(ctx, `INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes) VALUES (1, 'k', 'qs_', 'x', 'rooms:read')`); err != nil {
+		t.Errorf("tabla nueva: %v", err)
+	}
+	if err := d.QueryRowContext(ctx, `SELECT COUNT(*) FROM schema_migrations`).Scan(&n); err != nil || n != len(migrations) {
+		t.Errorf("migraciones registradas = %d, %v; se esperaban %d", n, err, len(migrations))
+	}
+	d.Close()
+
+	// volver a conectar no repite nada
+	again, err := Connect()
+	if err != nil {
+		t.Fatalf("segunda conexión: %v", err)
+	}
+	again.Close()
+}
+
+func TestMigrateOpeningBalanceSource(t *testing.T) {
+	// una base al día hasta la migración 8: score_events sin el source opening
+	path := filepath.Join(t.TempDir(), "ledger.db")
+	old, err := sql.Open("sqlite", "file:"+path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := strings.Replace(sqliteSchema, "'undo','opening'", "'undo'", 1)
+	if _, err := old.Exec(schema + `
+		CREATE TABLE schema_migrations (version INT NOT NULL PRIMARY KEY, name VARCHAR(100) NOT NULL, applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP);
+		INSERT INTO schema_migrations (version, name) VALUES (1,'a'),(2,'b'),(3,'c'),(4,'d'),(5,'e'),(6,'f'),(7,'g'),(8,'h');
+		INSERT INTO users (name, email, password, role) VALUES ('Host', 'host@x.com', 'h', 'host'), ('Ana', 'ana@x.com', 'h', 'participant');
+		INSERT INTO rooms (code, host_id, status) VALUES ('ABC123', 1, 'active');
+		INSERT INTO scores (room_id, user_id, points) VALUES (1, 2, 30);
+		INSERT INTO score_events (room_id, user_id, actor_id, source, delta) VALUES (1, 2, 1, 'manual', 10);
+	`); err != nil {
+		t.Fatal(err)
+	}
+	old.Close()
+
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DB_PATH", path)
+	d, err := Connect()
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer d.Close()
+	ctx := context.Background()
+
+	// el ledger ya tenía 10 de los 30: el saldo previo es 20, sin actor
+	var source string
+	var delta int
+	var actor sql.NullInt64
+	err = d.QueryRowContext(ctx, `SELECT source, delta, actor_id FROM score_events WHERE id = 2`).Scan(&source, &delta, &actor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source != "opening" || delta != 20 || actor.Valid {
+		t.Errorf("saldo inicial = %s %d %v", source, delta, actor)
+	}
+
+	// la tabla recreada conserva el evento previo, el índice y la FK de reverts_id
+	var n int
+	if err := d.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name = 'idx_score_events_room_user'`).Scan(&n); err != nil || n != 1 {
+		t.Errorf("índice tras recrear score_events = %d, %v", n, err)
+	}
+	if _, err := d.ExecContext(ctx, `INSERT INTO score_events (room_id, user_id, source, delta, reverts_id) VALUES (1, 2, 'undo', -10, 1)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.ExecContext(ctx, `DELETE FROM score_events WHERE id = 1`); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.QueryRowContext(ctx, `SELECT COUNT(*) FROM score_events`).Scan(&n); err != nil || n != 1 {
+		t.Errorf("eventos tras borrar el revertido = %d, %v; se esperaba que el undo se borrara en cascada", n, err)
+	}
+}
+
+func TestMigrateFreshSQLiteIsNoop(t *testing.T) {
+	d := openMemory(t)
+	var n int
+	if err := d.QueryRowContext(context.Background(), `SELECT COUNT(*) FROM schema_migrations`).Scan(&n); err != nil || n != len(migrations) {
+		t.Errorf("migraciones registradas = %d, %v; se esperaban %d", n, err, len(migrations))
+	}
+	// una segunda pasada sobre el esquema completo no falla
+	if err := d.Migrate(context.Background()); err != nil {
+		t.Errorf("Migrate repetido: %v", err)
+	}
+}
@@ -1,21 +1,27 @@
 package db
 
 import (
-"database/sql"
-"fmt"
-"os"
+	"database/sql"
+	_ "embed"
+	"fmt"
 
-_ "github.com/go-sql-driver/mysql"
+	_ "github.com/go-sql-driver/mysql"
 )
 
-func Connect() (*sql.DB, error) {
+// mysqlSchema se aplica al conectar (ver Migrate)
+//
+//go:embed schema_mysql.sql
+var mysqlSchema string
+
+// connectMySQL abre la conexión con MySQL usando las variables DB_*
+func connectMySQL() (*sql.DB, error) {
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true",
-getEnv("DB_USER", "apiuser"),
-getEnv("DB_PASSWORD", "apipassword"),
-getEnv("DB_HOST", "localhost"),
-getEnv("DB_PORT", "3306"),
-getEnv("DB_NAME", "apidb"),
-)
+		getEnv("DB_USER", "apiuser"),
+		getEnv("DB_PASSWORD", "apipassword"),
+		getEnv("DB_HOST", "localhost"),
+		getEnv("DB_PORT", "3306"),
+		getEnv("DB_NAME", "apidb"),
+	)
 
 	conn, err := sql.Open("mysql", dsn)
 	if err != nil {
@@ -31,10 +37,3 @@ getEnv("DB_NAME", "apidb"),
 
 	return conn, nil
 }
-
-func getEnv(key, defaultVal string) string {
-	if val := os.Getenv(key); val != "" {
-		return val
-	}
-	return defaultVal
-}
@@ -0,0 +1,40 @@
+package db
+
+import (
+	"database/sql"
+	_ "embed"
+	"fmt"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// postgresSchema se aplica al conectar (ver Migrate)
+//
+//go:embed schema_postgres.sql
+var postgresSchema string
+
+// connectPostgres abre la conexión con PostgreSQL usando las variables DB_*
+func connectPostgres() (*sql.DB, error) {
+	dsn := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
+		getEnv("DB_USER", "apiuser"),
+		getEnv("DB_PASSWORD", "apipassword"),
+		getEnv("DB_HOST", "localhost"),
+		getEnv("DB_PORT", "5432"),
+		getEnv("DB_NAME", "apidb"),
+		getEnv("DB_SSLMODE", "disable"),
+	)
+
+	conn, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("error al abrir conexión: %w", err)
+	}
+
+	if err := conn.Ping(); err != nil {
+		return nil, fmt.Errorf("error al conectar con PostgreSQL: %w", err)
+	}
+
+	conn.SetMaxOpenConns(25)
+	conn.SetMaxIdleConns(10)
+
+	return conn, nil
+}
@@ -0,0 +1,256 @@
+-- ============================================================
+-- Esquema MySQL (DB_DRIVER=mysql)
+-- Se aplica automáticamente al conectar sobre la base DB_NAME.
+-- Las columnas e índices nuevos de tablas existentes van además
+-- en una migración de migrate.go.
+-- ============================================================
+
+-- ------------------------------------------------------------
+-- Tabla: users
+-- Almacena hosts (profesores) y participantes (alumnos)
+-- ------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS users (
+    id         INT AUTO_INCREMENT PRIMARY KEY,
+    name       VARCHAR(100)        NOT NULL,
+    email      VARCHAR(150)        NOT NULL UNIQUE,
+    password   VARCHAR(255)        NOT NULL,
+    role       ENUM('admin','host','participant') NOT NULL DEFAULT 'participant',
+    created_at TIMESTAMP           NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    failed_logins INT              NOT NULL DEFAULT 0,   -- intentos fallidos seguidos
+    locked_until  TIMESTAMP        NULL DEFAULT NULL,    -- bloqueo temporal por intentos fallidos
+    email_verified BOOLEAN         NOT NULL DEFAULT FALSE,
+    host_requested BOOLEAN         NOT NULL DEFAULT FALSE, -- pidió ser host; espera aprobación de un admin
+    deleted_at    TIMESTAMP        NULL DEFAULT NULL     -- cuenta eliminada y anonimizada
+);
+
+-- ------------------------------------------------------------
+-- Tabla: rooms
+-- Salas creadas por los hosts
+-- ------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS rooms (
+    id         INT AUTO_INCREMENT PRIMARY KEY,
+    code       VARCHAR(10)         NOT NULL UNIQUE,
+    host_id    INT                 NOT NULL,
+    status     ENUM('waiting','active','finished') NOT NULL DEFAULT 'waiting',
+    ranking_mode ENUM('competition','dense') NOT NULL DEFAULT 'competition',  -- numeración de empates en el ranking
+    streak_mode ENUM('none','bonus','multiplier') NOT NULL DEFAULT 'none',    -- premio por respuestas correctas seguidas
+    streak_step INT NOT NULL DEFAULT 0,                                       -- puntos o % por nivel de racha
+    streak_max  INT NOT NULL DEFAULT 0,                                       -- tope de la racha que cuenta; 0 = sin tope
+    created_at TIMESTAMP           NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_rooms_host FOREIGN KEY (host_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- ------------------------------------------------------------
+-- Tabla: participants
+-- Relación de qué usuarios están en qué sala
+-- ------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS participants (
+    id         INT AUTO_INCREMENT PRIMARY KEY,
+    room_id    INT NOT NULL,
+    user_id    INT NOT NULL,
+    joined_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_part_room FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE CASCADE,
+    CONSTRAINT fk_part_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY uq_room_user (room_id, user_id),
+    INDEX idx_participants_user (user_id, joined_at)  -- historial del usuario
+);
+
+-- ------------------------------------------------------------
+-- Tabla: scores
+-- Puntos de cada participante dentro de una sala
+-- ------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS scores (
+    id         INT AUTO_INCREMENT PRIMARY KEY,
+    room_id    INT NOT NULL,
+    user_id    INT NOT NULL,
+    points     INT NOT NULL DEFAULT 0,
+    streak     INT NOT NULL DEFAULT 0,  -- respuestas correctas seguidas
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    CONSTRAINT fk_score_room FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE CASCADE,
+    CONSTRAINT fk_score_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY uq_score_room_user (room_id, user_id),
+    INDEX idx_scores_room_points (room_id, points)  -- puesto en la sala
+);
+
+-- ------------------------------------------------------------
+-- Tabla: questions
+-- Preguntas lanzadas por el host durante una sesión activa
+-- ------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS questions (
+    id             INT AUTO_INCREMENT PRIMARY KEY,
+    room_id        INT NOT NULL,
+    text           TEXT NOT NULL,
+    correct_answer VARCHAR(500) NOT NULL,
+    points         INT NOT NULL DEFAULT 10,
+    status         ENUM('open','closed') NOT NULL DEFAULT 'open',
+    created_at     TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),  -- milisegundos para medir tiempos de respuesta
+    CONSTRAINT fk_question_room FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE CASCADE,
+    INDEX idx_questions_room (room_id)
+);
+
+-- ------------------------------------------------------------
+-- Tabla: answers
+-- Respuestas enviadas por los participantes a las preguntas
+-- ------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS answers (
+    id          INT AUTO_INCREMENT PRIMARY KEY,
+    question_id INT NOT NULL,
+    user_id     INT NOT NULL,
+    text        VARCHAR(500) NOT NULL,
+    is_correct  BOOLEAN NOT NULL DEFAULT FALSE,
+    response_ms INT NULL,  -- milisegundos desde que se lanzó la pregunta
+    answered_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_answer_question FOREIGN KEY (question_id) REFERENCES questions(id) ON DELETE CASCADE,
+    CONSTRAINT fk_answer_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY uq_answer_question_user (question_id, user_id)  -- un participante solo responde una vez
+);
+
+-- ------------------------------------------------------------
+-- Tabla: score_events
+-- Ledger de cambios de puntos: cada cambio en scores deja aquí su delta.
+-- scores.points de un participante es la suma de sus deltas en la sala.
+-- ------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS score_events (
+    id          INT AUTO_INCREMENT PRIMARY KEY,
+    room_id     INT NOT NULL,
+    user_id     INT NOT NULL,                -- a quién le cambiaron los puntos
+    actor_id    INT NULL,                    -- quién hizo el cambio
+    source      ENUM('manual','answer','reset','undo','opening') NOT NULL,
+    delta       INT NOT NULL,
+    reason      VARCHAR(255) NOT NULL DEFAULT '',
+    question_id INT NULL,                    -- source = answer
+    reverts_id  INT NULL UNIQUE,             -- source = undo: evento deshecho (una sola vez)
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_score_event_room FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE CASCADE,
+    CONSTRAINT fk_score_event_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_score_event_actor FOREIGN KEY (actor_id) REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT fk_score_event_question FOREIGN KEY (question_id) REFERENCES questions(id) ON DELETE SET NULL,
+    CONSTRAINT fk_score_event_reverts FOREIGN KEY (reverts_id) REFERENCES score_events(id) ON DELETE CASCADE,
+    INDEX idx_score_events_room_user (room_id, user_id)
+);
+
+-- ------------------------------------------------------------
+-- Tabla: leaderboards
+-- Tablas de posiciones de un host que suman varias salas (un curso, una
+-- serie de sesiones). Solo cuentan los puntos ganados entre starts_at y
+-- ends_at, si están definidos.
+-- ------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS leaderboards (
+    id          INT AUTO_INCREMENT PRIMARY KEY,
+    host_id     INT NOT NULL,
+    name        VARCHAR(100) NOT NULL,
+    starts_at   TIMESTAMP NULL,
+    ends_at     TIMESTAMP NULL,
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_leaderboard_host FOREIGN KEY (host_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_leaderboards_host (host_id)
+);
+
+CREATE TABLE IF NOT EXISTS leaderboard_rooms (
+    leaderboard_id INT NOT NULL,
+    room_id        INT NOT NULL,
+    added_at       TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (leaderboard_id, room_id),
+    CONSTRAINT fk_leaderboard_room_board FOREIGN KEY (leaderboard_id) REFERENCES leaderboards(id) ON DELETE CASCADE,
+    CONSTRAINT fk_leaderboard_room_room FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE CASCADE,
+    INDEX idx_leaderboard_rooms_room (room_id)
+);
+
+-- ------------------------------------------------------------
+-- Tabla: leaderboard_points
+-- Puntos acumulados por periodo: 'all', mes ('2026-10') y semana ISO
+-- ('2026-W42'). Se actualiza en la misma transacción que cada score_event.
+-- ------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS leaderboard_points (
+    leaderboard_id INT NOT NULL,
+    period         VARCHAR(10) NOT NULL,
+    user_id        INT NOT NULL,
+    points         INT NOT NULL DEFAULT 0,
+    updated_at     TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    PRIMARY KEY (leaderboard_id, period, user_id),
+    CONSTRAINT fk_leaderboard_points_board FOREIGN KEY (leaderboard_id) REFERENCES leaderboards(id) ON DELETE CASCADE,
+    CONSTRAINT fk_leaderboard_points_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_leaderboard_points_rank (leaderboard_id, period, points)
+);
+
+-- ------------------------------------------------------------
+-- Tabla: user_badges
+-- Medallas ganadas. Cada medalla se gana como mucho una vez por sala.
+-- ------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS user_badges (
+    id          INT AUTO_INCREMENT PRIMARY KEY,
+    user_id     INT NOT NULL,
+    badge       VARCHAR(30) NOT NULL,
+    room_id     INT NOT NULL,
+    question_id INT NULL,                    -- medallas por respuesta
+    earned_at   TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_user_badge_room (user_id, badge, room_id),
+    CONSTRAINT fk_user_badge_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_user_badge_room FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE CASCADE,
+    CONSTRAINT fk_user_badge_question FOREIGN KEY (question_id) REFERENCES questions(id) ON DELETE SET NULL
+);
+
+-- ------------------------------------------------------------
+-- Tabla: user_tokens
+-- Tokens de un solo uso (recuperar contraseña, verificar email).
+-- Solo se guarda el SHA-256 del token.
+-- ------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS user_tokens (
+    id          INT AUTO_INCREMENT PRIMARY KEY,
+    user_id     INT NOT NULL,
+    purpose     ENUM('password_reset','email_verification') NOT NULL,
+    token_hash  CHAR(64)  NOT NULL UNIQUE,
+    expires_at  TIMESTAMP NOT NULL,
+    used_at     TIMESTAMP NULL DEFAULT NULL,
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_token_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_token_user_purpose (user_id, purpose)
+);
+
+-- ------------------------------------------------------------
+-- Tabla: invite_codes
+-- Códigos que permiten registrarse como host sin aprobación
+-- ------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS invite_codes (
+    id          INT AUTO_INCREMENT PRIMARY KEY,
+    code        VARCHAR(32) NOT NULL UNIQUE,
+    role        ENUM('admin','host','participant') NOT NULL DEFAULT 'host',
+    uses_left   INT NOT NULL DEFAULT 1,
+    expires_at  TIMESTAMP NULL DEFAULT NULL,
+    created_by  INT NOT NULL,
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_invite_user FOREIGN KEY (created_by) REFERENCES users(id)
+);
+
+-- ------------------------------------------------------------
+-- Tabla: user_identities
+-- Cuentas externas (OpenID Connect) vinculadas a cada usuario
+-- ------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS user_identities (
+    id          INT AUTO_INCREMENT PRIMARY KEY,
+    user_id     INT NOT NULL,
+    provider    VARCHAR(255) NOT NULL,   -- issuer del proveedor
+    subject     VARCHAR(255) NOT NULL,   -- claim sub
+    email       VARCHAR(150) NOT NULL DEFAULT '',
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_identity_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY uq_identity_provider_subject (provider, subject)
+);
+
+-- ------------------------------------------------------------
+-- Tabla: api_keys
+-- Credenciales personales para scripts. Solo se guarda el SHA-256.
+-- ------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS api_keys (
+    id            INT AUTO_INCREMENT PRIMARY KEY,
+    user_id       INT NOT NULL,
+    name          VARCHAR(100) NOT NULL,
+    prefix        VARCHAR(20)  NOT NULL,
+    key_hash      CHAR(64)     NOT NULL UNIQUE,
+    scopes        VARCHAR(255) NOT NULL,   -- separados por comas
+    last_used_at  TIMESTAMP NULL DEFAULT NULL,
+    revoked_at    TIMESTAMP NULL DEFAULT NULL,
+    created_at    TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_api_key_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_api_keys_user (user_id)
+);
@@ -0,0 +1,229 @@
+-- ============================================================
+-- Esquema PostgreSQL (DB_DRIVER=postgres)
+-- Equivalente a schema_mysql.sql; se aplica automáticamente al conectar
+-- ============================================================
+
+-- ------------------------------------------------------------
+-- Tabla: users
+-- Almacena hosts (profesores) y participantes (alumnos)
+-- ------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS users (
+    id         SERIAL PRIMARY KEY,
+    name       VARCHAR(100) NOT NULL,
+    email      VARCHAR(150) NOT NULL UNIQUE,
+    password   VARCHAR(255) NOT NULL,
+    role       VARCHAR(20)  NOT NULL DEFAULT 'participant' CHECK (role IN ('admin','host','participant')),
+    created_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    failed_logins INT       NOT NULL DEFAULT 0,   -- intentos fallidos seguidos
+    locked_until  TIMESTAMP NULL,                 -- bloqueo temporal por intentos fallidos
+    email_verified BOOLEAN  NOT NULL DEFAULT FALSE,
+    host_requested BOOLEAN  NOT NULL DEFAULT FALSE, -- pidió ser host; espera aprobación de un admin
+    deleted_at    TIMESTAMP NULL                  -- cuenta eliminada y anonimizada
+);
+
+-- ------------------------------------------------------------
+-- Tabla: rooms
+-- Salas creadas por los hosts
+-- ------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS rooms (
+    id         SERIAL PRIMARY KEY,
+    code       VARCHAR(10) NOT NULL UNIQUE,
+    host_id    INT         NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status     VARCHAR(20) NOT NULL DEFAULT 'waiting' CHECK (status IN ('waiting','active','finished')),
+    ranking_mode VARCHAR(20) NOT NULL DEFAULT 'competition' CHECK (ranking_mode IN ('competition','dense')),
+    streak_mode VARCHAR(20) NOT NULL DEFAULT 'none' CHECK (streak_mode IN ('none','bonus','multiplier')),
+    streak_step INT         NOT NULL DEFAULT 0,
+    streak_max  INT         NOT NULL DEFAULT 0,
+    created_at TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- ------------------------------------------------------------
+-- Tabla: participants
+-- Relación de qué usuarios están en qué sala
+-- ------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS participants (
+    id        SERIAL PRIMARY KEY,
+    room_id   INT       NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    user_id   INT       NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    joined_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_room_user UNIQUE (room_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_participants_user ON participants (user_id, joined_at);
+
+-- ------------------------------------------------------------
+-- Tabla: scores
+-- Puntos de cada participante dentro de una sala
+-- ------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS scores (
+    id         SERIAL PRIMARY KEY,
+    room_id    INT       NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    user_id    INT       NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    points     INT       NOT NULL DEFAULT 0,
+    streak     INT       NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_score_room_user UNIQUE (room_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_scores_room_points ON scores (room_id, points);
+
+-- ------------------------------------------------------------
+-- Tabla: questions
+-- Preguntas lanzadas por el host durante una sesión activa
+-- ------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS questions (
+    id             SERIAL PRIMARY KEY,
+    room_id        INT          NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    text           TEXT         NOT NULL,
+    correct_answer VARCHAR(500) NOT NULL,
+    points         INT          NOT NULL DEFAULT 10,
+    status         VARCHAR(20)  NOT NULL DEFAULT 'open' CHECK (status IN ('open','closed')),
+    created_at     TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_questions_room ON questions (room_id);
+
+-- ------------------------------------------------------------
+-- Tabla: answers
+-- Respuestas enviadas por los participantes a las preguntas
+-- ------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS answers (
+    id          SERIAL PRIMARY KEY,
+    question_id INT          NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    user_id     INT          NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    text        VARCHAR(500) NOT NULL,
+    is_correct  BOOLEAN      NOT NULL DEFAULT FALSE,
+    response_ms INT,                                    -- milisegundos desde que se lanzó la pregunta
+    answered_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_answer_question_user UNIQUE (question_id, user_id)  -- un participante solo responde una vez
+);
+
+-- ------------------------------------------------------------
+-- Tabla: score_events
+-- Ledger de cambios de puntos: cada cambio en scores deja aquí su delta.
+-- scores.points de un participante es la suma de sus deltas en la sala.
+-- ------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS score_events (
+    id          SERIAL PRIMARY KEY,
+    room_id     INT          NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    user_id     INT          NOT NULL REFERENCES users(id) ON DELETE CASCADE,  -- a quién le cambiaron los puntos
+    actor_id    INT          REFERENCES users(id) ON DELETE SET NULL,          -- quién hizo el cambio
+    source      VARCHAR(20)  NOT NULL CHECK (source IN ('manual','answer','reset','undo','opening')),
+    delta       INT          NOT NULL,
+    reason      VARCHAR(255) NOT NULL DEFAULT '',
+    question_id INT          REFERENCES questions(id) ON DELETE SET NULL,      -- source = answer
+    reverts_id  INT          UNIQUE REFERENCES score_events(id) ON DELETE CASCADE,  -- source = undo: evento deshecho (una sola vez)
+    created_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_score_events_room_user ON score_events (room_id, user_id);
+
+-- ------------------------------------------------------------
+-- Tabla: leaderboards
+-- Tablas de posiciones de un host que suman varias salas (un curso, una
+-- serie de sesiones). Solo cuentan los puntos ganados entre starts_at y
+-- ends_at, si están definidos.
+-- ------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS leaderboards (
+    id          SERIAL PRIMARY KEY,
+    host_id     INT          NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name        VARCHAR(100) NOT NULL,
+    starts_at   TIMESTAMP    NULL,
+    ends_at     TIMESTAMP    NULL,
+    created_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_leaderboards_host ON leaderboards (host_id);
+
+CREATE TABLE IF NOT EXISTS leaderboard_rooms (
+    leaderboard_id INT       NOT NULL REFERENCES leaderboards(id) ON DELETE CASCADE,
+    room_id        INT       NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    added_at       TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (leaderboard_id, room_id)
+);
+CREATE INDEX IF NOT EXISTS idx_leaderboard_rooms_room ON leaderboard_rooms (room_id);
+
+-- ------------------------------------------------------------
+-- Tabla: leaderboard_points
+-- Puntos acumulados por periodo: 'all', mes ('2026-10') y semana ISO
+-- ('2026-W42'). Se actualiza en la misma transacción que cada score_event.
+-- ------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS leaderboard_points (
+    leaderboard_id INT         NOT NULL REFERENCES leaderboards(id) ON DELETE CASCADE,
+    period         VARCHAR(10) NOT NULL,
+    user_id        INT         NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    points         INT         NOT NULL DEFAULT 0,
+    updated_at     TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (leaderboard_id, period, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_leaderboard_points_rank ON leaderboard_points (leaderboard_id, period, points);
+
+-- ------------------------------------------------------------
+-- Tabla: user_badges
+-- Medallas ganadas. Cada medalla se gana como mucho una vez por sala.
+-- ------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS user_badges (
+    id          SERIAL PRIMARY KEY,
+    user_id     INT         NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    badge       VARCHAR(30) NOT NULL,
+    room_id     INT         NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    question_id INT         REFERENCES questions(id) ON DELETE SET NULL,  -- medallas por respuesta
+    earned_at   TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_user_badge_room UNIQUE (user_id, badge, room_id)
+);
+
+-- ------------------------------------------------------------
+-- Tabla: user_tokens
+-- Tokens de un solo uso (recuperar contraseña, verificar email).
+-- Solo se guarda el SHA-256 del token.
+-- ------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS user_tokens (
+    id          SERIAL PRIMARY KEY,
+    user_id     INT         NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose     VARCHAR(30) NOT NULL CHECK (purpose IN ('password_reset','email_verification')),
+    token_hash  CHAR(64)    NOT NULL UNIQUE,
+    expires_at  TIMESTAMP   NOT NULL,
+    used_at     TIMESTAMP   NULL,
+    created_at  TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_token_user_purpose ON user_tokens (user_id, purpose);
+
+-- ------------------------------------------------------------
+-- Tabla: invite_codes
+-- Códigos que permiten registrarse como host sin aprobación
+-- ------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS invite_codes (
+    id          SERIAL PRIMARY KEY,
+    code        VARCHAR(32) NOT NULL UNIQUE,
+    role        VARCHAR(20) NOT NULL DEFAULT 'host' CHECK (role IN ('admin','host','participant')),
+    uses_left   INT         NOT NULL DEFAULT 1,
+    expires_at  TIMESTAMP   NULL,
+    created_by  INT         NOT NULL REFERENCES users(id),
+    created_at  TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- ------------------------------------------------------------
+-- Tabla: user_identities
+-- Cuentas externas (OpenID Connect) vinculadas a cada usuario
+-- ------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS user_identities (
+    id          SERIAL PRIMARY KEY,
+    user_id     INT          NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider    VARCHAR(255) NOT NULL,   -- issuer del proveedor
+    subject     VARCHAR(255) NOT NULL,   -- claim sub
+    email       VARCHAR(150) NOT NULL DEFAULT '',
+    created_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_identity_provider_subject UNIQUE (provider, subject)
+);
+
+-- ------------------------------------------------------------
+-- Tabla: api_keys
+-- Credenciales personales para scripts. Solo se guarda el SHA-256.
+-- ------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS api_keys (
+    id            SERIAL PRIMARY KEY,
+    user_id       INT          NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name          VARCHAR(100) NOT NULL,
+    prefix        VARCHAR(20)  NOT NULL,
+    key_hash      CHAR(64)     NOT NULL UNIQUE,
+    scopes        VARCHAR(255) NOT NULL,   -- separados por comas
+    last_used_at  TIMESTAMP    NULL,
+    revoked_at    TIMESTAMP    NULL,
+    created_at    TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_api_keys_user ON api_keys (user_id);
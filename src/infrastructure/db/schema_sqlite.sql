@@ -0,0 +1,168 @@
+-- ============================================================
+-- Esquema SQLite (equivalente a schema_mysql.sql)
+-- Se aplica automáticamente al conectar con DB_DRIVER=sqlite
+-- ============================================================
+
+CREATE TABLE IF NOT EXISTS users (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    name       TEXT     NOT NULL,
+    email      TEXT     NOT NULL UNIQUE,
+    password   TEXT     NOT NULL,
+    role       TEXT     NOT NULL DEFAULT 'participant' CHECK (role IN ('admin','host','participant')),
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    failed_logins INTEGER NOT NULL DEFAULT 0,
+    locked_until  DATETIME,
+    email_verified BOOLEAN NOT NULL DEFAULT 0,
+    host_requested BOOLEAN NOT NULL DEFAULT 0,
+    deleted_at     DATETIME
+);
+
+CREATE TABLE IF NOT EXISTS rooms (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    code       TEXT     NOT NULL UNIQUE,
+    host_id    INTEGER  NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status     TEXT     NOT NULL DEFAULT 'waiting' CHECK (status IN ('waiting','active','finished')),
+    ranking_mode TEXT     NOT NULL DEFAULT 'competition' CHECK (ranking_mode IN ('competition','dense')),
+    streak_mode TEXT     NOT NULL DEFAULT 'none' CHECK (streak_mode IN ('none','bonus','multiplier')),
+    streak_step INTEGER  NOT NULL DEFAULT 0,
+    streak_max  INTEGER  NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS participants (
+    id        INTEGER PRIMARY KEY AUTOINCREMENT,
+    room_id   INTEGER  NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    user_id   INTEGER  NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    joined_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (room_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_participants_user ON participants (user_id, joined_at);
+
+CREATE TABLE IF NOT EXISTS scores (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    room_id    INTEGER  NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    user_id    INTEGER  NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    points     INTEGER  NOT NULL DEFAULT 0,
+    streak     INTEGER  NOT NULL DEFAULT 0,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (room_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_scores_room_points ON scores (room_id, points);
+
+CREATE TABLE IF NOT EXISTS questions (
+    id             INTEGER PRIMARY KEY AUTOINCREMENT,
+    room_id        INTEGER  NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    text           TEXT     NOT NULL,
+    correct_answer TEXT     NOT NULL,
+    points         INTEGER  NOT NULL DEFAULT 10,
+    status         TEXT     NOT NULL DEFAULT 'open' CHECK (status IN ('open','closed')),
+    created_at     DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_questions_room ON questions (room_id);
+
+CREATE TABLE IF NOT EXISTS answers (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    question_id INTEGER  NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    user_id     INTEGER  NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    text        TEXT     NOT NULL,
+    is_correct  BOOLEAN  NOT NULL DEFAULT 0,
+    response_ms INTEGER,
+    answered_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (question_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS score_events (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    room_id     INTEGER  NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    user_id     INTEGER  NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    actor_id    INTEGER  REFERENCES users(id) ON DELETE SET NULL,
+    source      TEXT     NOT NULL CHECK (source IN ('manual','answer','reset','undo','opening')),
+    delta       INTEGER  NOT NULL,
+    reason      TEXT     NOT NULL DEFAULT '',
+    question_id INTEGER  REFERENCES questions(id) ON DELETE SET NULL,
+    reverts_id  INTEGER  UNIQUE REFERENCES score_events(id) ON DELETE CASCADE,
+    created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_score_events_room_user ON score_events (room_id, user_id);
+
+CREATE TABLE IF NOT EXISTS leaderboards (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    host_id    INTEGER  NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name       TEXT     NOT NULL,
+    starts_at  DATETIME,
+    ends_at    DATETIME,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_leaderboards_host ON leaderboards (host_id);
+
+CREATE TABLE IF NOT EXISTS leaderboard_rooms (
+    leaderboard_id INTEGER  NOT NULL REFERENCES leaderboards(id) ON DELETE CASCADE,
+    room_id        INTEGER  NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    added_at       DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (leaderboard_id, room_id)
+);
+CREATE INDEX IF NOT EXISTS idx_leaderboard_rooms_room ON leaderboard_rooms (room_id);
+
+CREATE TABLE IF NOT EXISTS leaderboard_points (
+    leaderboard_id INTEGER  NOT NULL REFERENCES leaderboards(id) ON DELETE CASCADE,
+    period         TEXT     NOT NULL,
+    user_id        INTEGER  NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    points         INTEGER  NOT NULL DEFAULT 0,
+    updated_at     DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (leaderboard_id, period, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_leaderboard_points_rank ON leaderboard_points (leaderboard_id, period, points);
+
+CREATE TABLE IF NOT EXISTS user_badges (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id     INTEGER  NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    badge       TEXT     NOT NULL,
+    room_id     INTEGER  NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    question_id INTEGER  REFERENCES questions(id) ON DELETE SET NULL,
+    earned_at   DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, badge, room_id)
+);
+
+CREATE TABLE IF NOT EXISTS user_tokens (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id    INTEGER  NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose    TEXT     NOT NULL CHECK (purpose IN ('password_reset','email_verification')),
+    token_hash TEXT     NOT NULL UNIQUE,
+    expires_at DATETIME NOT NULL,
+    used_at    DATETIME,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_token_user_purpose ON user_tokens (user_id, purpose);
+
+CREATE TABLE IF NOT EXISTS invite_codes (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    code       TEXT     NOT NULL UNIQUE,
+    role       TEXT     NOT NULL DEFAULT 'host' CHECK (role IN ('admin','host','participant')),
+    uses_left  INTEGER  NOT NULL DEFAULT 1,
+    expires_at DATETIME,
+    created_by INTEGER  NOT NULL REFERENCES users(id),
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS user_identities (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id    INTEGER  NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider   TEXT     NOT NULL,
+    subject    TEXT     NOT NULL,
+    email      TEXT     NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (provider, subject)
+);
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id      INTEGER  NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name         TEXT     NOT NULL,
+    prefix       TEXT     NOT NULL,
+    key_hash     TEXT     NOT NULL UNIQUE,
+    scopes       TEXT     NOT NULL,
+    last_used_at DATETIME,
+    revoked_at   DATETIME,
+    created_at   DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_api_keys_user ON api_keys (user_id);
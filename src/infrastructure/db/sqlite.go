@@ -0,0 +1,32 @@
+package db
+
+import (
+	"database/sql"
+	_ "embed"
+	"fmt"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqliteSchema se aplica al conectar (ver Migrate)
+//
+//go:embed schema_sqlite.sql
+var sqliteSchema string
+
+// connectSQLite abre (o crea) el archivo indicado en DB_PATH
+func connectSQLite() (*sql.DB, error) {
+	path := getEnv("DB_PATH", "quickscore.db")
+	dsn := "file:" + path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_time_format=sqlite"
+
+	conn, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("error al abrir conexión: %w", err)
+	}
+
+	// Una base en memoria solo existe dentro de su conexión
+	if path == ":memory:" {
+		conn.SetMaxOpenConns(1)
+	}
+
+	return conn, nil
+}
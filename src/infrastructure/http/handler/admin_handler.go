@@ -0,0 +1,115 @@
+package handler
+
+import (
+    "encoding/json"
+    "net/http"
+    "strconv"
+
+    "apiGolan/src/applications/usecase"
+    "apiGolan/src/domain"
+)
+
+type AdminHandler struct {
+    uc *usecase.AdminUseCase
+}
+
+func NewAdminHandler(uc *usecase.AdminUseCase) *AdminHandler {
+    return &AdminHandler{uc: uc}
+}
+
+// ListUsers godoc
+// @Summary Listar usuarios
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param role query string false "Filtrar por rol (admin, host, participant)"
+// @Param pending query bool false "Solo los que pidieron ser host"
+// @Param limit query int false "Máximo de resultados (50 por defecto, máx. 100)"
+// @Param offset query int false "Desplazamiento"
+// @Success 200 {array} domain.User
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /admin/users [get]
+func (h *AdminHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+    q := r.URL.Query()
+    filter := domain.UserFilter{
+        Role:          domain.Role(q.Get("role")),
+        HostRequested: q.Get("pending") == "true",
+    }
+    filter.Limit, _ = strconv.Atoi(q.Get("limit"))
+    filter.Offset, _ = strconv.Atoi(q.Get("offset"))
+
+    users, err := h.uc.ListUsers(r.Context(), filter)
+    if err != nil {
+        jsonError(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    jsonResponse(w, http.StatusOK, users)
+}
+
+// ChangeRole godoc
+// @Summary Ascender o degradar a un usuario
+// @Description Aprobar a quien pidió ser host es cambiar su rol a host.
+// @Description El rol nuevo rige desde su próxima petición, sin esperar a que venza el token.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID de usuario"
+// @Param body body usecase.ChangeRoleInput true "Rol nuevo"
+// @Success 200 {object} domain.User
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /admin/users/{id}/role [patch]
+func (h *AdminHandler) ChangeRole(w http.ResponseWriter, r *http.Request) {
+    userID, err := strconv.Atoi(r.PathValue("id"))
+    if err != nil {
+        jsonError(w, "id de usuario inválido", http.StatusBadRequest)
+        return
+    }
+
+    var input usecase.ChangeRoleInput
+    if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+        jsonError(w, "cuerpo de la petición inválido", http.StatusBadRequest)
+        return
+    }
+
+    claims := getClaims(r)
+    user, err := h.uc.ChangeRole(r.Context(), claims.UserID, userID, input)
+    if err != nil {
+        jsonError(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    jsonResponse(w, http.StatusOK, user)
+}
+
+// CreateInvite godoc
+// @Summary Crear código de invitación
+// @Description Quien se registre con el código obtiene directamente su rol (host por defecto)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body usecase.CreateInviteInput true "Configuración de la invitación"
+// @Success 201 {object} domain.InviteCode
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /admin/invites [post]
+func (h *AdminHandler) CreateInvite(w http.ResponseWriter, r *http.Request) {
+    var input usecase.CreateInviteInput
+    if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+        jsonError(w, "cuerpo de la petición inválido", http.StatusBadRequest)
+        return
+    }
+
+    claims := getClaims(r)
+    invite, err := h.uc.CreateInvite(r.Context(), claims.UserID, input)
+    if err != nil {
+        jsonError(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    jsonResponse(w, http.StatusCreated, invite)
+}
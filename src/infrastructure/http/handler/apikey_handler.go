@@ -0,0 +1,92 @@
+package handler
+
+import (
+    "encoding/json"
+    "net/http"
+    "strconv"
+
+    "apiGolan/src/applications/usecase"
+)
+
+type APIKeyHandler struct {
+    uc *usecase.APIKeyUseCase
+}
+
+func NewAPIKeyHandler(uc *usecase.APIKeyUseCase) *APIKeyHandler {
+    return &APIKeyHandler{uc: uc}
+}
+
+// Create godoc
+// @Summary Crear API key
+// @Description La key completa solo se devuelve en esta respuesta. Scopes:
+// @Description rooms:read, rooms:write, questions:read, questions:write, scores:read, scores:write
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body usecase.CreateAPIKeyInput true "Nombre y scopes"
+// @Success 201 {object} usecase.CreatedAPIKey
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /me/api-keys [post]
+func (h *APIKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
+    var input usecase.CreateAPIKeyInput
+    if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+        jsonError(w, "cuerpo de la petición inválido", http.StatusBadRequest)
+        return
+    }
+
+    claims := getClaims(r)
+    created, err := h.uc.Create(r.Context(), claims.UserID, input)
+    if err != nil {
+        jsonError(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    jsonResponse(w, http.StatusCreated, created)
+}
+
+// List godoc
+// @Summary Listar mis API keys
+// @Description Incluye las revocadas; nunca devuelve la key completa
+// @Tags api-keys
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} domain.APIKey
+// @Failure 403 {object} map[string]string
+// @Router /me/api-keys [get]
+func (h *APIKeyHandler) List(w http.ResponseWriter, r *http.Request) {
+    claims := getClaims(r)
+    keys, err := h.uc.List(r.Context(), claims.UserID)
+    if err != nil {
+        jsonError(w, "error al listar API keys", http.StatusInternalServerError)
+        return
+    }
+
+    jsonResponse(w, http.StatusOK, keys)
+}
+
+// Revoke godoc
+// @Summary Revocar API key
+// @Tags api-keys
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID de la API key"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /me/api-keys/{id} [delete]
+func (h *APIKeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+    keyID, err := strconv.Atoi(r.PathValue("id"))
+    if err != nil {
+        jsonError(w, "id de API key inválido", http.StatusBadRequest)
+        return
+    }
+
+    claims := getClaims(r)
+    if err := h.uc.Revoke(r.Context(), claims.UserID, keyID); err != nil {
+        jsonError(w, err.Error(), http.StatusNotFound)
+        return
+    }
+
+    jsonResponse(w, http.StatusOK, map[string]string{"message": "API key revocada"})
+}
@@ -1,19 +1,26 @@
 package handler
 
 import (
-    "encoding/json"
-    "net/http"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
 
-    "apiGolan/src/applications/usecase"
-    jwtutil "apiGolan/src/infrastructure/jwt"
+	"apiGolan/src/applications/usecase"
+	"apiGolan/src/domain"
+	"apiGolan/src/infrastructure/http/middleware"
+	jwtutil "apiGolan/src/infrastructure/jwt"
+	"apiGolan/src/infrastructure/logger"
+	"apiGolan/src/infrastructure/ratelimit"
 )
 
 type AuthHandler struct {
-    uc *usecase.AuthUseCase
+    uc           *usecase.AuthUseCase
+    emailLimiter ratelimit.Limiter // intentos de login por email
 }
 
-func NewAuthHandler(uc *usecase.AuthUseCase) *AuthHandler {
-    return &AuthHandler{uc: uc}
+func NewAuthHandler(uc *usecase.AuthUseCase, emailLimiter ratelimit.Limiter) *AuthHandler {
+    return &AuthHandler{uc: uc, emailLimiter: emailLimiter}
 }
 
 // Register godoc
@@ -32,17 +39,19 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    user, err := h.uc.Register(input)
+    user, err := h.uc.Register(r.Context(), input)
     if err != nil {
         jsonError(w, err.Error(), http.StatusBadRequest)
         return
     }
 
     jsonResponse(w, http.StatusCreated, map[string]interface{}{
-        "id":    user.ID,
-        "name":  user.Name,
-        "email": user.Email,
-        "role":  user.Role,
+        "id":             user.ID,
+        "name":           user.Name,
+        "email":          user.Email,
+        "role":           user.Role,
+        "email_verified": user.EmailVerified,
+        "host_requested": user.HostRequested,
     })
 }
 
@@ -55,6 +64,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 429 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /auth/login [post]
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
@@ -64,7 +74,20 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    user, err := h.uc.Login(input)
+    // Límite por email además del límite por IP del router
+    emailKey := "email:" + strings.ToLower(strings.TrimSpace(input.Email))
+    if allowed, retryAfter, err := h.emailLimiter.Allow(r.Context(), emailKey); err == nil && !allowed {
+        middleware.TooManyRequests(w, retryAfter)
+        return
+    }
+
+    user, err := h.uc.Login(r.Context(), input)
+    var locked *domain.AccountLockedError
+    if errors.As(err, &locked) {
+        middleware.SetRetryAfter(w, locked.RetryAfter)
+        jsonError(w, err.Error(), http.StatusTooManyRequests)
+        return
+    }
     if err != nil {
         jsonError(w, err.Error(), http.StatusUnauthorized)
         return
@@ -76,17 +99,130 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    jsonResponse(w, http.StatusOK, map[string]interface{}{
+    jsonResponse(w, http.StatusOK, sessionResponse(token, user))
+}
+
+// sessionResponse es la respuesta de un login correcto (contraseña o SSO)
+func sessionResponse(token string, user *domain.User) map[string]interface{} {
+    return map[string]interface{}{
         "token": token,
         "user": map[string]interface{}{
-            "id":    user.ID,
-            "name":  user.Name,
-            "email": user.Email,
-            "role":  user.Role,
+            "id":             user.ID,
+            "name":           user.Name,
+            "email":          user.Email,
+            "role":           user.Role,
+            "email_verified": user.EmailVerified,
         },
+    }
+}
+
+// ForgotPassword godoc
+// @Summary Pedir enlace para recuperar la contraseña
+// @Description Siempre responde 200 para no revelar qué emails están registrados
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body usecase.ForgotPasswordInput true "Email de la cuenta"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Router /auth/password/forgot [post]
+func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+    var input usecase.ForgotPasswordInput
+    if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.Email == "" {
+        jsonError(w, "email requerido", http.StatusBadRequest)
+        return
+    }
+
+    if err := h.uc.ForgotPassword(r.Context(), input); err != nil {
+        logger.FromContext(r.Context()).Error("error al enviar recuperación de contraseña", "error", err)
+    }
+
+    jsonResponse(w, http.StatusOK, map[string]string{
+        "message": "si el email está registrado, te enviamos un enlace para recuperar la contraseña",
     })
 }
 
+// ResetPassword godoc
+// @Summary Elegir contraseña nueva con el token recibido por correo
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body usecase.ResetPasswordInput true "Token y contraseña nueva"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Router /auth/password/reset [post]
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+    var input usecase.ResetPasswordInput
+    if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+        jsonError(w, "cuerpo de la petición inválido", http.StatusBadRequest)
+        return
+    }
+
+    if err := h.uc.ResetPassword(r.Context(), input); err != nil {
+        jsonError(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    jsonResponse(w, http.StatusOK, map[string]string{"message": "contraseña actualizada"})
+}
+
+// VerifyEmail godoc
+// @Summary Verificar email con el token recibido por correo
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body usecase.VerifyEmailInput true "Token de verificación"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Router /auth/verify [post]
+func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+    var input usecase.VerifyEmailInput
+    if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+        jsonError(w, "cuerpo de la petición inválido", http.StatusBadRequest)
+        return
+    }
+
+    if err := h.uc.VerifyEmail(r.Context(), input); err != nil {
+        jsonError(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    jsonResponse(w, http.StatusOK, map[string]string{"message": "email verificado"})
+}
+
+// ResendVerification godoc
+// @Summary Reenviar el correo de verificación al usuario autenticado
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Router /auth/verify/resend [post]
+func (h *AuthHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+    claims := getClaims(r)
+
+    if err := h.uc.ResendVerification(r.Context(), claims.UserID); err != nil {
+        jsonError(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    jsonResponse(w, http.StatusOK, map[string]string{"message": "correo de verificación enviado"})
+}
+
+// JWKS godoc
+// @Summary Claves públicas para verificar los tokens
+// @Description JSON Web Key Set con todas las claves de verificación vigentes (campo kid del header JWT)
+// @Tags auth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /.well-known/jwks.json [get]
+func (h *AuthHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+    // Las claves solo cambian al reiniciar; 5 min de caché bastan para una rotación
+    w.Header().Set("Cache-Control", "public, max-age=300")
+    jsonResponse(w, http.StatusOK, map[string]interface{}{"keys": jwtutil.JWKS()})
+}
+
 // ── helpers compartidos por todos los handlers ────────────
 
 func jsonResponse(w http.ResponseWriter, status int, data interface{}) {
@@ -96,5 +232,5 @@ func jsonResponse(w http.ResponseWriter, status int, data interface{}) {
 }
 
 func jsonError(w http.ResponseWriter, msg string, status int) {
-    jsonResponse(w, status, map[string]string{"error": msg})
+    middleware.WriteError(w, msg, status)
 }
\ No newline at end of file
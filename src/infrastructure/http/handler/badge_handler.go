@@ -0,0 +1,96 @@
+package handler
+
+import (
+    "net/http"
+    "strconv"
+
+    "apiGolan/src/applications/usecase"
+    "apiGolan/src/domain"
+    "apiGolan/src/infrastructure/logger"
+    ws "apiGolan/src/infrastructure/websocket"
+)
+
+type BadgeHandler struct {
+    uc *usecase.BadgeUseCase
+}
+
+func NewBadgeHandler(uc *usecase.BadgeUseCase) *BadgeHandler {
+    return &BadgeHandler{uc: uc}
+}
+
+// Catalog godoc
+// @Summary Catálogo de medallas
+// @Tags badges
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} domain.Badge
+// @Failure 401 {object} map[string]string
+// @Router /badges [get]
+func (h *BadgeHandler) Catalog(w http.ResponseWriter, r *http.Request) {
+    jsonResponse(w, http.StatusOK, h.uc.Catalog())
+}
+
+// GetMyBadges godoc
+// @Summary Mis medallas
+// @Description Cada medalla se gana como mucho una vez por sala; las más recientes primero.
+// @Tags me
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} domain.UserBadge
+// @Failure 401 {object} map[string]string
+// @Router /me/badges [get]
+func (h *BadgeHandler) GetMyBadges(w http.ResponseWriter, r *http.Request) {
+    claims := getClaims(r)
+
+    badges, err := h.uc.GetMyBadges(r.Context(), claims.UserID)
+    if err != nil {
+        logger.FromContext(r.Context()).Error("error al obtener las medallas", "user_id", claims.UserID, "error", err)
+        jsonError(w, "error al obtener las medallas", http.StatusInternalServerError)
+        return
+    }
+    jsonResponse(w, http.StatusOK, badges)
+}
+
+// GetUserBadges godoc
+// @Summary Medallas de un usuario (host)
+// @Description Un host solo ve las ganadas en salas que creó; un admin ve todas.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID de usuario"
+// @Success 200 {array} domain.UserBadge
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /users/{id}/badges [get]
+func (h *BadgeHandler) GetUserBadges(w http.ResponseWriter, r *http.Request) {
+    userID, err := strconv.Atoi(r.PathValue("id"))
+    if err != nil {
+        jsonError(w, "id de usuario inválido", http.StatusBadRequest)
+        return
+    }
+
+    claims := getClaims(r)
+    badges, err := h.uc.GetUserBadges(r.Context(), claims.UserID, domain.Role(claims.Role), userID)
+    if err != nil {
+        jsonError(w, err.Error(), http.StatusNotFound)
+        return
+    }
+    jsonResponse(w, http.StatusOK, badges)
+}
+
+// broadcastBadges avisa a la sala de cada medalla nueva. Un error al evaluar
+// las reglas no hace fallar la petición que las disparó: se registra y se
+// anuncian las que alcanzaron a guardarse.
+func broadcastBadges(r *http.Request, hub *ws.Hub, code string, badges []domain.UserBadge, err error) {
+    if err != nil {
+        logger.FromContext(r.Context()).Error("error al evaluar medallas", "room", code, "error", err)
+    }
+    for _, b := range badges {
+        hub.Broadcast(code, ws.Message{
+            Event:    "badge_earned",
+            RoomCode: code,
+            Payload:  b,
+        })
+    }
+}
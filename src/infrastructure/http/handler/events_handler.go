@@ -0,0 +1,96 @@
+package handler
+
+import (
+    "fmt"
+    "net/http"
+    "strconv"
+    "time"
+
+    ws "apiGolan/src/infrastructure/websocket"
+)
+
+// Events godoc
+// @Summary Eventos de la sala por Server-Sent Events (alternativa a /ws)
+// @Description Emite los mismos mensajes que /ws en el campo data, para redes que bloquean WebSocket o pantallas que solo reciben.
+// @Description EventSource no permite headers: el JWT puede ir en ?token=. Al reconectar, el navegador envía Last-Event-ID y se reenvían los eventos perdidos que sigan en el historial de la instancia; si el id no está, llega room_state con resync: true.
+// @Tags rooms
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param code path string true "Código de sala"
+// @Param name query string false "Nombre a mostrar en la presencia"
+// @Param token query string false "JWT, si no se puede enviar el header Authorization"
+// @Param Last-Event-ID header string false "Último id recibido"
+// @Success 200 {string} string "stream text/event-stream"
+// @Failure 401 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /rooms/{code}/events [get]
+func (h *RoomHandler) Events(w http.ResponseWriter, r *http.Request) {
+    code := extractCode(r.URL.Path, "/rooms/", "/events")
+    claims := getClaims(r)
+
+    info := ws.ClientInfo{
+        UserID: claims.UserID,
+        Name:   r.URL.Query().Get("name"),
+        Role:   claims.Role,
+    }
+    client := h.hub.Subscribe(code, info, r.Header.Get("Last-Event-ID"))
+    if client == nil {
+        retry := int(h.hub.RetryAfter().Seconds())
+        if retry < 1 {
+            retry = 1
+        }
+        w.Header().Set("Retry-After", strconv.Itoa(retry))
+        jsonError(w, "servidor reiniciando, intenta de nuevo", http.StatusServiceUnavailable)
+        return
+    }
+    defer h.hub.Unsubscribe(client)
+
+    rc := http.NewResponseController(w)
+    w.Header().Set("Content-Type", "text/event-stream")
+    w.Header().Set("Cache-Control", "no-cache")
+    w.Header().Set("Connection", "keep-alive")
+    w.Header().Set("X-Accel-Buffering", "no") // que nginx no acumule el stream
+    w.WriteHeader(http.StatusOK)
+
+    // retry: cuánto espera EventSource para reconectar si se corta
+    fmt.Fprint(w, "retry: 3000\n\n")
+    if err := rc.Flush(); err != nil {
+        return
+    }
+
+    keepAlive := time.NewTicker(h.hub.KeepAlive())
+    defer keepAlive.Stop()
+
+    for {
+        select {
+        case <-r.Context().Done():
+            return
+        case <-keepAlive.C:
+            // un comentario SSE mantiene viva la conexión sin disparar eventos
+            fmt.Fprint(w, ": ping\n\n")
+        case <-client.Ready():
+            events, closed := client.Take()
+            for _, ev := range events {
+                writeSSE(w, ev)
+            }
+            if closed {
+                rc.Flush()
+                return
+            }
+        }
+        if err := rc.Flush(); err != nil {
+            return
+        }
+    }
+}
+
+// writeSSE escribe un evento sin campo event:, así el cliente lo recibe en
+// onmessage y puede usar el mismo switch sobre msg.event que con /ws.
+// Los mensajes propios de la conexión (online_list) no llevan id para no
+// mover el punto de reanudación.
+func writeSSE(w http.ResponseWriter, ev ws.Event) {
+    if ev.ID != 0 {
+        fmt.Fprintf(w, "id: %d\n", ev.ID)
+    }
+    fmt.Fprintf(w, "data: %s\n\n", ev.Data)
+}
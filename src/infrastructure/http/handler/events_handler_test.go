@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"apiGolan/src/infrastructure/http/middleware"
+	jwtutil "apiGolan/src/infrastructure/jwt"
+	ws "apiGolan/src/infrastructure/websocket"
+)
+
+// sseEvent es un mensaje del stream: su id (vacío si no lleva) y el evento de data
+type sseEvent struct {
+	id    string
+	event string
+}
+
+// sseStream lee los mensajes de /rooms/{code}/events
+type sseStream struct {
+	resp   *http.Response
+	reader *bufio.Reader
+}
+
+// openStream se conecta como el usuario indicado; lastEventID puede ir vacío
+func openStream(t *testing.T, srv *httptest.Server, userID int, lastEventID string) *sseStream {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/rooms/ABC123/events?user=%d", srv.URL, userID), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return &sseStream{resp: resp, reader: bufio.NewReader(resp.Body)}
+}
+
+// next devuelve el próximo mensaje con data, salteando retry:, los comentarios
+// y la presencia (cada conexión nueva se anuncia a toda la sala)
+func (s *sseStream) next(t *testing.T) sseEvent {
+	t.Helper()
+	var ev sseEvent
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("leyendo el stream: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "id: "):
+			ev.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "data: "):
+			var msg ws.Message
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &msg); err != nil {
+				t.Fatal(err)
+			}
+			ev.event = msg.Event
+		case line == "" && ev.event != "":
+			switch ev.event {
+			case "participant_connected", "presence_changed", "participant_disconnected":
+				ev = sseEvent{}
+				continue
+			}
+			return ev
+		}
+	}
+}
+
+// newEventsServer sirve el endpoint SSE; el usuario llega en ?user= en vez del JWT
+func newEventsServer(t *testing.T, hub *ws.Hub) *httptest.Server {
+	t.Helper()
+	h := NewRoomHandler(nil, nil, hub)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rooms/{code}/events", func(w http.ResponseWriter, r *http.Request) {
+		var userID int
+		fmt.Sscan(r.URL.Query().Get("user"), &userID)
+		claims := &jwtutil.Claims{UserID: userID, Role: "participant"}
+		h.Events(w, r.WithContext(context.WithValue(r.Context(), middleware.UserClaimsKey, claims)))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestEventsResume(t *testing.T) {
+	// lastEventID elige desde cuál de los dos eventos emitidos reanudar
+	tests := []struct {
+		name        string
+		lastEventID func(first, second sseEvent) string
+		want        func(first, second sseEvent) []sseEvent
+	}{
+		{"conexión nueva",
+			func(first, second sseEvent) string { return "" },
+			func(first, second sseEvent) []sseEvent { return []sseEvent{{"", "online_list"}} }},
+		{"reconecta tras el primero",
+			func(first, second sseEvent) string { return first.id },
+			func(first, second sseEvent) []sseEvent { return []sseEvent{second, {"", "online_list"}} }},
+		{"reconecta antes de ambos",
+			func(first, second sseEvent) string { return "1" },
+			func(first, second sseEvent) []sseEvent { return []sseEvent{first, second, {"", "online_list"}} }},
+		{"reconecta al día",
+			func(first, second sseEvent) string { return second.id },
+			func(first, second sseEvent) []sseEvent { return []sseEvent{{"", "online_list"}} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub := ws.NewHub(ws.DefaultConfig(), nil)
+			srv := newEventsServer(t, hub)
+			t.Cleanup(func() {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+				hub.Shutdown(ctx, time.Second)
+			})
+
+			// el host mantiene la sala abierta en esta instancia y anota los ids
+			host := openStream(t, srv, 1, "")
+			if got := host.resp.Header.Get("Content-Type"); got != "text/event-stream" {
+				t.Fatalf("Content-Type = %q", got)
+			}
+			if ev := host.next(t); ev.event != "online_list" || ev.id != "" {
+				t.Fatalf("primer mensaje = %+v, se esperaba online_list sin id", ev)
+			}
+			hub.Broadcast("ABC123", ws.Message{Event: "question_started", RoomCode: "ABC123"})
+			hub.Broadcast("ABC123", ws.Message{Event: "score_update", RoomCode: "ABC123"})
+			first, second := host.next(t), host.next(t)
+			if first.id == "" || second.id == "" || first.id == second.id {
+				t.Fatalf("ids de los eventos: %q, %q", first.id, second.id)
+			}
+
+			stream := openStream(t, srv, 2, tt.lastEventID(first, second))
+			for _, want := range tt.want(first, second) {
+				if got := stream.next(t); got != want {
+					t.Errorf("recibió %+v, se esperaba %+v", got, want)
+				}
+			}
+			// lo que se emite después llega en vivo, con su id
+			hub.Broadcast("ABC123", ws.Message{Event: "question_closed", RoomCode: "ABC123"})
+			if got := stream.next(t); got.event != "question_closed" || got.id == "" {
+				t.Errorf("en vivo recibió %+v", got)
+			}
+		})
+	}
+}
+
+func TestEventsWhileShuttingDown(t *testing.T) {
+	hub := ws.NewHub(ws.DefaultConfig(), nil)
+	srv := newEventsServer(t, hub)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	hub.Shutdown(ctx, 2500*time.Millisecond)
+
+	resp, err := http.Get(srv.URL + "/rooms/ABC123/events?user=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || resp.Header.Get("Retry-After") != "2" {
+		t.Errorf("status %d, Retry-After %q; se esperaba 503 con Retry-After 2", resp.StatusCode, resp.Header.Get("Retry-After"))
+	}
+}
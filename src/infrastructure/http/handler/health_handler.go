@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	ws "apiGolan/src/infrastructure/websocket"
+)
+
+// Pinger es lo mínimo que necesita la readiness de la base de datos
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+type HealthHandler struct {
+	db  Pinger
+	hub *ws.Hub
+}
+
+func NewHealthHandler(db Pinger, hub *ws.Hub) *HealthHandler {
+	return &HealthHandler{db: db, hub: hub}
+}
+
+// Live godoc
+// @Summary Liveness: el proceso está vivo
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Router /healthz [get]
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	jsonResponse(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Ready godoc
+// @Summary Readiness: la base de datos responde y no se está apagando
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /readyz [get]
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	if h.hub.IsShuttingDown() {
+		jsonResponse(w, http.StatusServiceUnavailable, map[string]string{"status": "shutting_down"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+	defer cancel()
+	if err := h.db.PingContext(ctx); err != nil {
+		jsonResponse(w, http.StatusServiceUnavailable, map[string]string{
+			"status": "unavailable",
+			"error":  "base de datos no disponible",
+		})
+		return
+	}
+
+	jsonResponse(w, http.StatusOK, map[string]string{"status": "ok"})
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	ws "apiGolan/src/infrastructure/websocket"
+)
+
+type fakePinger struct{ err error }
+
+func (p fakePinger) PingContext(ctx context.Context) error { return p.err }
+
+func TestHealthReady(t *testing.T) {
+	tests := []struct {
+		name         string
+		pingErr      error
+		shuttingDown bool
+		wantCode     int
+		wantStatus   string
+	}{
+		{"base de datos disponible", nil, false, http.StatusOK, "ok"},
+		{"base de datos caída", errors.New("connection refused"), false, http.StatusServiceUnavailable, "unavailable"},
+		{"apagándose", nil, true, http.StatusServiceUnavailable, "shutting_down"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub := ws.NewHub(ws.DefaultConfig(), nil)
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			if tt.shuttingDown {
+				hub.Shutdown(ctx, time.Second)
+			} else {
+				defer hub.Shutdown(ctx, time.Second)
+			}
+			h := NewHealthHandler(fakePinger{tt.pingErr}, hub)
+
+			rec := httptest.NewRecorder()
+			h.Ready(rec, httptest.NewRequest("GET", "/readyz", nil))
+			var body map[string]string
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if rec.Code != tt.wantCode || body["status"] != tt.wantStatus {
+				t.Errorf("Ready = %d %v, se esperaba %d %q", rec.Code, body, tt.wantCode, tt.wantStatus)
+			}
+
+			// la liveness no depende de la base de datos ni del apagado
+			rec = httptest.NewRecorder()
+			h.Live(rec, httptest.NewRequest("GET", "/healthz", nil))
+			if rec.Code != http.StatusOK {
+				t.Errorf("Live = %d", rec.Code)
+			}
+		})
+	}
+}
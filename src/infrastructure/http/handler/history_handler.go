@@ -0,0 +1,67 @@
+package handler
+
+import (
+    "net/http"
+    "strconv"
+
+    "apiGolan/src/applications/usecase"
+    "apiGolan/src/domain"
+    "apiGolan/src/infrastructure/logger"
+)
+
+type HistoryHandler struct {
+    uc *usecase.HistoryUseCase
+}
+
+func NewHistoryHandler(uc *usecase.HistoryUseCase) *HistoryHandler {
+    return &HistoryHandler{uc: uc}
+}
+
+// GetMyHistory godoc
+// @Summary Mi historial de sesiones
+// @Description Cada sala a la que me uní con puesto final, puntos, precisión y tiempo medio de respuesta, más el resumen y la tendencia de las últimas sesiones.
+// @Tags me
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} domain.History
+// @Failure 401 {object} map[string]string
+// @Router /me/history [get]
+func (h *HistoryHandler) GetMyHistory(w http.ResponseWriter, r *http.Request) {
+    claims := getClaims(r)
+
+    history, err := h.uc.GetMyHistory(r.Context(), claims.UserID)
+    if err != nil {
+        logger.FromContext(r.Context()).Error("error al obtener el historial", "user_id", claims.UserID, "error", err)
+        jsonError(w, "error al obtener el historial", http.StatusInternalServerError)
+        return
+    }
+    jsonResponse(w, http.StatusOK, history)
+}
+
+// GetUserHistory godoc
+// @Summary Historial de sesiones de un usuario (host)
+// @Description Un host solo ve las salas que creó; un admin ve todas.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID de usuario"
+// @Success 200 {object} domain.History
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /users/{id}/history [get]
+func (h *HistoryHandler) GetUserHistory(w http.ResponseWriter, r *http.Request) {
+    userID, err := strconv.Atoi(r.PathValue("id"))
+    if err != nil {
+        jsonError(w, "id de usuario inválido", http.StatusBadRequest)
+        return
+    }
+
+    claims := getClaims(r)
+    history, err := h.uc.GetUserHistory(r.Context(), claims.UserID, domain.Role(claims.Role), userID)
+    if err != nil {
+        jsonError(w, err.Error(), http.StatusNotFound)
+        return
+    }
+    jsonResponse(w, http.StatusOK, history)
+}
@@ -0,0 +1,228 @@
+package handler
+
+import (
+    "encoding/json"
+    "net/http"
+    "strconv"
+    "time"
+
+    "apiGolan/src/applications/usecase"
+    "apiGolan/src/domain"
+)
+
+type LeaderboardHandler struct {
+    uc *usecase.LeaderboardUseCase
+}
+
+func NewLeaderboardHandler(uc *usecase.LeaderboardUseCase) *LeaderboardHandler {
+    return &LeaderboardHandler{uc: uc}
+}
+
+// Create godoc
+// @Summary Crear leaderboard (host)
+// @Description Suma los puntos de varias salas propias. starts_at y ends_at (RFC 3339) limitan qué cambios de puntos cuentan; ends_at es exclusivo.
+// @Tags leaderboards
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body usecase.CreateLeaderboardInput true "Nombre, salas y rango de fechas"
+// @Success 201 {object} domain.Leaderboard
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /leaderboards [post]
+func (h *LeaderboardHandler) Create(w http.ResponseWriter, r *http.Request) {
+    var input usecase.CreateLeaderboardInput
+    if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+        jsonError(w, "cuerpo de la petición inválido", http.StatusBadRequest)
+        return
+    }
+
+    claims := getClaims(r)
+    lb, err := h.uc.Create(r.Context(), claims.UserID, input)
+    if err != nil {
+        jsonError(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+    jsonResponse(w, http.StatusCreated, lb)
+}
+
+// List godoc
+// @Summary Listar mis leaderboards (host)
+// @Tags leaderboards
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} domain.Leaderboard
+// @Failure 403 {object} map[string]string
+// @Router /leaderboards [get]
+func (h *LeaderboardHandler) List(w http.ResponseWriter, r *http.Request) {
+    claims := getClaims(r)
+    boards, err := h.uc.List(r.Context(), claims.UserID)
+    if err != nil {
+        jsonError(w, "error al listar los leaderboards", http.StatusInternalServerError)
+        return
+    }
+    jsonResponse(w, http.StatusOK, boards)
+}
+
+// Get godoc
+// @Summary Ver un leaderboard
+// @Description Lo ven su host, los admins y quienes participan en alguna de sus salas.
+// @Tags leaderboards
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID del leaderboard"
+// @Success 200 {object} domain.Leaderboard
+// @Failure 404 {object} map[string]string
+// @Router /leaderboards/{id} [get]
+func (h *LeaderboardHandler) Get(w http.ResponseWriter, r *http.Request) {
+    id, ok := leaderboardID(w, r)
+    if !ok {
+        return
+    }
+
+    claims := getClaims(r)
+    lb, err := h.uc.Get(r.Context(), id, claims.UserID, domain.Role(claims.Role))
+    if err != nil {
+        jsonError(w, err.Error(), http.StatusNotFound)
+        return
+    }
+    jsonResponse(w, http.StatusOK, lb)
+}
+
+// Delete godoc
+// @Summary Borrar un leaderboard propio (host)
+// @Tags leaderboards
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID del leaderboard"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /leaderboards/{id} [delete]
+func (h *LeaderboardHandler) Delete(w http.ResponseWriter, r *http.Request) {
+    id, ok := leaderboardID(w, r)
+    if !ok {
+        return
+    }
+
+    claims := getClaims(r)
+    if err := h.uc.Delete(r.Context(), id, claims.UserID); err != nil {
+        jsonError(w, err.Error(), http.StatusNotFound)
+        return
+    }
+    jsonResponse(w, http.StatusOK, map[string]string{"message": "leaderboard eliminado"})
+}
+
+// AddRoom godoc
+// @Summary Agregar una sala al leaderboard (host)
+// @Description Los puntos que la sala ya tiene en el historial se suman en el momento.
+// @Tags leaderboards
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID del leaderboard"
+// @Param body body usecase.LeaderboardRoomInput true "Código de sala"
+// @Success 200 {object} domain.Leaderboard
+// @Failure 400 {object} map[string]string
+// @Router /leaderboards/{id}/rooms [post]
+func (h *LeaderboardHandler) AddRoom(w http.ResponseWriter, r *http.Request) {
+    id, ok := leaderboardID(w, r)
+    if !ok {
+        return
+    }
+
+    var input usecase.LeaderboardRoomInput
+    if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+        jsonError(w, "cuerpo de la petición inválido", http.StatusBadRequest)
+        return
+    }
+
+    claims := getClaims(r)
+    lb, err := h.uc.AddRoom(r.Context(), id, claims.UserID, input)
+    if err != nil {
+        jsonError(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+    jsonResponse(w, http.StatusOK, lb)
+}
+
+// RemoveRoom godoc
+// @Summary Quitar una sala del leaderboard (host)
+// @Description Resta lo que la sala había aportado.
+// @Tags leaderboards
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID del leaderboard"
+// @Param code path string true "Código de sala"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Router /leaderboards/{id}/rooms/{code} [delete]
+func (h *LeaderboardHandler) RemoveRoom(w http.ResponseWriter, r *http.Request) {
+    id, ok := leaderboardID(w, r)
+    if !ok {
+        return
+    }
+
+    claims := getClaims(r)
+    if err := h.uc.RemoveRoom(r.Context(), id, claims.UserID, r.PathValue("code")); err != nil {
+        jsonError(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+    jsonResponse(w, http.StatusOK, map[string]string{"message": "sala quitada del leaderboard"})
+}
+
+// GetRanking godoc
+// @Summary Ranking de un leaderboard
+// @Description Posiciones del periodo (semana ISO o mes en UTC) que contiene la fecha at, o el acumulado total, con el puesto de quien consulta en me.
+// @Tags leaderboards
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID del leaderboard"
+// @Param window query string false "all_time (por defecto), monthly o weekly"
+// @Param at query string false "Fecha dentro del periodo (YYYY-MM-DD); hoy por defecto"
+// @Param limit query int false "Máximo de posiciones (50 por defecto, máx. 100)"
+// @Param offset query int false "Desplazamiento"
+// @Success 200 {object} domain.LeaderboardRanking
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /leaderboards/{id}/ranking [get]
+func (h *LeaderboardHandler) GetRanking(w http.ResponseWriter, r *http.Request) {
+    id, ok := leaderboardID(w, r)
+    if !ok {
+        return
+    }
+
+    q := r.URL.Query()
+    input := usecase.LeaderboardRankingInput{Window: domain.LeaderboardWindow(q.Get("window"))}
+    if at := q.Get("at"); at != "" {
+        t, err := time.Parse("2006-01-02", at)
+        if err != nil {
+            jsonError(w, "at debe tener el formato YYYY-MM-DD", http.StatusBadRequest)
+            return
+        }
+        input.At = t
+    }
+    input.Limit, _ = strconv.Atoi(q.Get("limit"))
+    input.Offset, _ = strconv.Atoi(q.Get("offset"))
+
+    claims := getClaims(r)
+    ranking, err := h.uc.GetRanking(r.Context(), id, claims.UserID, domain.Role(claims.Role), input)
+    if err != nil {
+        status := http.StatusNotFound
+        if input.Window != "" && !input.Window.Valid() {
+            status = http.StatusBadRequest
+        }
+        jsonError(w, err.Error(), status)
+        return
+    }
+    jsonResponse(w, http.StatusOK, ranking)
+}
+
+// leaderboardID lee el id de la ruta; si no es válido responde 400
+func leaderboardID(w http.ResponseWriter, r *http.Request) (int, bool) {
+    id, err := strconv.Atoi(r.PathValue("id"))
+    if err != nil {
+        jsonError(w, "id de leaderboard inválido", http.StatusBadRequest)
+        return 0, false
+    }
+    return id, true
+}
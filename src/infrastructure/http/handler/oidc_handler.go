@@ -0,0 +1,134 @@
+package handler
+
+import (
+    "net/http"
+    "net/url"
+    "strings"
+    "time"
+
+    "apiGolan/src/applications/usecase"
+    jwtutil "apiGolan/src/infrastructure/jwt"
+    "apiGolan/src/infrastructure/logger"
+)
+
+// Cookies que guardan el login en curso entre /auth/oidc/login y el callback
+const (
+    oidcStateCookie    = "qs_oidc_state"
+    oidcVerifierCookie = "qs_oidc_verifier"
+    oidcNonceCookie    = "qs_oidc_nonce"
+    oidcCookiePath     = "/auth/oidc"
+    oidcLoginTTL       = 10 * time.Minute
+)
+
+type OIDCHandler struct {
+    uc     *usecase.SSOUseCase
+    appURL string // el callback redirige a APP_URL/auth/callback con el token
+}
+
+func NewOIDCHandler(uc *usecase.SSOUseCase, appURL string) *OIDCHandler {
+    return &OIDCHandler{uc: uc, appURL: strings.TrimRight(appURL, "/")}
+}
+
+// Login godoc
+// @Summary Iniciar sesión con el proveedor de identidad (OIDC)
+// @Description Redirige al proveedor con authorization code + PKCE
+// @Tags auth
+// @Success 302
+// @Failure 500 {object} map[string]string
+// @Router /auth/oidc/login [get]
+func (h *OIDCHandler) Login(w http.ResponseWriter, r *http.Request) {
+    login, err := h.uc.Begin()
+    if err != nil {
+        jsonError(w, "no se pudo iniciar el login", http.StatusInternalServerError)
+        return
+    }
+
+    maxAge := int(oidcLoginTTL.Seconds())
+    setOIDCCookie(w, r, oidcStateCookie, login.State, maxAge)
+    setOIDCCookie(w, r, oidcVerifierCookie, login.Verifier, maxAge)
+    setOIDCCookie(w, r, oidcNonceCookie, login.Nonce, maxAge)
+    http.Redirect(w, r, login.URL, http.StatusFound)
+}
+
+// Callback godoc
+// @Summary Callback del proveedor de identidad (OIDC)
+// @Description Vincula o crea el usuario y emite el JWT de QuickScore.
+// @Description Con Accept: application/json responde {token, user}; si no,
+// @Description redirige a APP_URL/auth/callback#token=... (o #error=...).
+// @Tags auth
+// @Produce json
+// @Param code query string true "Código de autorización"
+// @Param state query string true "State enviado al proveedor"
+// @Success 200 {object} map[string]interface{}
+// @Success 302
+// @Failure 400 {object} map[string]string
+// @Router /auth/oidc/callback [get]
+func (h *OIDCHandler) Callback(w http.ResponseWriter, r *http.Request) {
+    q := r.URL.Query()
+    state, _ := r.Cookie(oidcStateCookie)
+    verifier, _ := r.Cookie(oidcVerifierCookie)
+    nonce, _ := r.Cookie(oidcNonceCookie)
+
+    // El login en curso sirve una sola vez, salga bien o mal
+    for _, name := range []string{oidcStateCookie, oidcVerifierCookie, oidcNonceCookie} {
+        setOIDCCookie(w, r, name, "", -1)
+    }
+
+    if e := q.Get("error"); e != "" {
+        h.fail(w, r, "el proveedor rechazó el login: "+e)
+        return
+    }
+    if state == nil || verifier == nil || nonce == nil || q.Get("state") != state.Value {
+        h.fail(w, r, "login expirado o state inválido, vuelve a intentarlo")
+        return
+    }
+
+    user, err := h.uc.Complete(r.Context(), usecase.SSOCallbackInput{
+        Code:     q.Get("code"),
+        Verifier: verifier.Value,
+        Nonce:    nonce.Value,
+    })
+    if err != nil {
+        logger.FromContext(r.Context()).Warn("login OIDC fallido", "error", err)
+        h.fail(w, r, err.Error())
+        return
+    }
+    logger.SetUserID(r.Context(), user.ID)
+
+    token, err := jwtutil.Generate(user.ID, string(user.Role))
+    if err != nil {
+        jsonError(w, "error al generar token", http.StatusInternalServerError)
+        return
+    }
+
+    if wantsJSON(r) {
+        jsonResponse(w, http.StatusOK, sessionResponse(token, user))
+        return
+    }
+    // En el fragmento el token no llega a logs de servidores ni a Referer
+    http.Redirect(w, r, h.appURL+"/auth/callback#token="+url.QueryEscape(token), http.StatusFound)
+}
+
+func (h *OIDCHandler) fail(w http.ResponseWriter, r *http.Request, msg string) {
+    if wantsJSON(r) {
+        jsonError(w, msg, http.StatusBadRequest)
+        return
+    }
+    http.Redirect(w, r, h.appURL+"/auth/callback#error="+url.QueryEscape(msg), http.StatusFound)
+}
+
+func wantsJSON(r *http.Request) bool {
+    return strings.Contains(r.Header.Get("Accept"), "application/json")
+}
+
+func setOIDCCookie(w http.ResponseWriter, r *http.Request, name, value string, maxAge int) {
+    http.SetCookie(w, &http.Cookie{
+        Name:     name,
+        Value:    value,
+        Path:     oidcCookiePath,
+        MaxAge:   maxAge, // -1 borra la cookie
+        HttpOnly: true,
+        Secure:   r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https",
+        SameSite: http.SameSiteLaxMode, // el callback llega como navegación GET desde el proveedor
+    })
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"apiGolan/src/applications/usecase"
+	"apiGolan/src/core"
+	"apiGolan/src/domain"
+	infradb "apiGolan/src/infrastructure/db"
+	jwtutil "apiGolan/src/infrastructure/jwt"
+	"apiGolan/src/infrastructure/oidc"
+	"apiGolan/src/infrastructure/oidc/oidctest"
+	"apiGolan/src/infrastructure/repository"
+)
+
+// newTestOIDCHandler arma el handler con SQLite en memoria y el proveedor falso
+func newTestOIDCHandler(t *testing.T) (*OIDCHandler, *oidctest.Issuer) {
+	t.Helper()
+	t.Setenv("APP_ENV", "development")
+	t.Setenv("JWT_KEYS_DIR", "")
+	if err := jwtutil.Setup(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DB_PATH", ":memory:")
+	db, err := infradb.Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	iss := oidctest.NewIssuer("quickscore")
+	t.Cleanup(iss.Close)
+	t.Setenv("OIDC_ISSUER", iss.URL)
+	t.Setenv("OIDC_CLIENT_ID", "quickscore")
+	t.Setenv("OIDC_REDIRECT_URL", "http://app.test/auth/oidc/callback")
+	provider, err := oidc.New(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svc := core.NewSSOService(repository.NewUserRepo(db), repository.NewIdentityRepo(db), provider, domain.RoleParticipant)
+	return NewOIDCHandler(usecase.NewSSOUseCase(svc), "http://app.test"), iss
+}
+
+// startLogin llama a /auth/oidc/login y devuelve las cookies y la URL del proveedor
+func startLogin(t *testing.T, h *OIDCHandler) ([]*http.Cookie, string) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h.Login(w, httptest.NewRequest("GET", "/auth/oidc/login", nil))
+	if w.Code != http.StatusFound {
+		t.Fatalf("login: status = %d", w.Code)
+	}
+	return w.Result().Cookies(), w.Header().Get("Location")
+}
+
+func callback(h *OIDCHandler, query string, cookies []*http.Cookie) *httptest.ResponseRecorder {
+	r := httptest.NewRequest("GET", "/auth/oidc/callback?"+query, nil)
+	r.Header.Set("Accept", "application/json")
+	for _, c := range cookies {
+		r.AddCookie(c)
+	}
+	w := httptest.NewRecorder()
+	h.Callback(w, r)
+	return w
+}
+
+func TestOIDCCallback(t *testing.T) {
+	h, iss := newTestOIDCHandler(t)
+	iss.SetUser(oidctest.User{Subject: "sub-1", Email: "ana@example.com", EmailVerified: true, Name: "Ana"})
+
+	cookies, authURL := startLogin(t, h)
+	redirect, err := iss.Authorize(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := callback(h, redirect.RawQuery, cookies)
+	if w.Code != http.StatusOK {
+		t.Fatalf("callback: status = %d, body = %s", w.Code, w.Body)
+	}
+	var body struct {
+		Token string `json:"token"`
+		User  struct {
+			ID    int    `json:"id"`
+			Email string `json:"email"`
+		} `json:"user"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	claims, err := jwtutil.Validate(body.Token)
+	if err != nil {
+		t.Fatalf("token inválido: %v", err)
+	}
+	if claims.UserID != body.User.ID || body.User.Email != "ana@example.com" {
+		t.Errorf("sesión = %s", w.Body)
+	}
+
+	// el callback borra las cookies del login en curso
+	for _, c := range w.Result().Cookies() {
+		if c.MaxAge >= 0 {
+			t.Errorf("la cookie %s no se borró", c.Name)
+		}
+	}
+}
+
+func TestOIDCCallbackRejectsStateMismatch(t *testing.T) {
+	h, iss := newTestOIDCHandler(t)
+	iss.SetUser(oidctest.User{Subject: "sub-1", Email: "ana@example.com", EmailVerified: true})
+
+	cookies, authURL := startLogin(t, h)
+	redirect, err := iss.Authorize(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := redirect.Query()
+	q.Set("state", "otro-state")
+
+	tests := []struct {
+		name    string
+		query   string
+		cookies []*http.Cookie
+	}{
+		{"state distinto", q.Encode(), cookies},
+		{"sin cookies", redirect.RawQuery, nil},
+	}
+	for _, tt := range tests {
+		w := callback(h, tt.query, tt.cookies)
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "state inválido") {
+			t.Errorf("%s: status = %d, body = %s", tt.name, w.Code, w.Body)
+		}
+	}
+}
+
+func TestOIDCCallbackRedirectsErrorsToApp(t *testing.T) {
+	h, _ := newTestOIDCHandler(t)
+
+	r := httptest.NewRequest("GET", "/auth/oidc/callback?error=access_denied", nil)
+	w := httptest.NewRecorder()
+	h.Callback(w, r)
+	if w.Code != http.StatusFound || !strings.HasPrefix(w.Header().Get("Location"), "http://app.test/auth/callback#error=") {
+		t.Errorf("status = %d, Location = %q", w.Code, w.Header().Get("Location"))
+	}
+}
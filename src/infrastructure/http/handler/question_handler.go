@@ -7,16 +7,19 @@ import (
 	"strings"
 
 	"apiGolan/src/applications/usecase"
+	"apiGolan/src/infrastructure/logger"
+	"apiGolan/src/infrastructure/metrics"
 	ws "apiGolan/src/infrastructure/websocket"
 )
 
 type QuestionHandler struct {
-	uc  *usecase.QuestionUseCase
-	hub *ws.Hub
+	uc      *usecase.QuestionUseCase
+	badgeUC *usecase.BadgeUseCase
+	hub     *ws.Hub
 }
 
-func NewQuestionHandler(uc *usecase.QuestionUseCase, hub *ws.Hub) *QuestionHandler {
-	return &QuestionHandler{uc: uc, hub: hub}
+func NewQuestionHandler(uc *usecase.QuestionUseCase, badgeUC *usecase.BadgeUseCase, hub *ws.Hub) *QuestionHandler {
+	return &QuestionHandler{uc: uc, badgeUC: badgeUC, hub: hub}
 }
 
 // LaunchQuestion godoc
@@ -44,7 +47,7 @@ func (h *QuestionHandler) LaunchQuestion(w http.ResponseWriter, r *http.Request)
 	input.RoomCode = code
 	input.HostID = claims.UserID
 
-	output, err := h.uc.LaunchQuestion(input)
+	output, err := h.uc.LaunchQuestion(r.Context(), input)
 	if err != nil {
 		jsonError(w, err.Error(), http.StatusBadRequest)
 		return
@@ -85,7 +88,7 @@ func (h *QuestionHandler) CloseQuestion(w http.ResponseWriter, r *http.Request)
 	}
 	claims := getClaims(r)
 
-	if err := h.uc.CloseQuestion(code, claims.UserID, questionID); err != nil {
+	if err := h.uc.CloseQuestion(r.Context(), code, claims.UserID, questionID); err != nil {
 		jsonError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -95,6 +98,8 @@ func (h *QuestionHandler) CloseQuestion(w http.ResponseWriter, r *http.Request)
 		RoomCode: code,
 		Payload:  map[string]int{"question_id": questionID},
 	})
+	// CloseQuestion ya verificó que quien cierra es el host de la sala
+	h.sendStats(r, code, claims.UserID, questionID)
 
 	jsonResponse(w, http.StatusOK, map[string]string{"message": "pregunta cerrada"})
 }
@@ -111,7 +116,7 @@ func (h *QuestionHandler) CloseQuestion(w http.ResponseWriter, r *http.Request)
 func (h *QuestionHandler) GetCurrentQuestion(w http.ResponseWriter, r *http.Request) {
 	code := extractRoomCode(r.URL.Path, "/questions/current")
 
-	q, err := h.uc.GetCurrentQuestion(code)
+	q, err := h.uc.GetCurrentQuestion(r.Context(), code)
 	if err != nil {
 		jsonError(w, err.Error(), http.StatusBadRequest)
 		return
@@ -146,28 +151,55 @@ func (h *QuestionHandler) SubmitAnswer(w http.ResponseWriter, r *http.Request) {
 	input.RoomCode = code
 	input.UserID = claims.UserID
 
-	output, err := h.uc.SubmitAnswer(input)
+	output, err := h.uc.SubmitAnswer(r.Context(), input)
 	if err != nil {
 		jsonError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	metrics.AnswerSubmitted(output.IsCorrect)
 
-	// Si fue correcta, hacer broadcast del ranking actualizado
-	if output.IsCorrect {
-		// Importar score use case sería un ciclo de dependencia,
-		// así que el broadcast del ranking lo hacemos desde el score handler
-		// via ws event genérico de "answer_correct"
-		h.hub.Broadcast(code, ws.Message{
-			Event:    "answer_correct",
+	// El resultado solo lo ve quien respondió; el host de la sala recibe el
+	// detalle con los conteos de la pregunta. Otros hosts conectados a la sala
+	// no: verían la respuesta correcta antes de que se cierre la pregunta.
+	h.hub.SendToUser(code, claims.UserID, ws.Message{
+		Event:    "answer_result",
+		RoomCode: code,
+		Payload: map[string]interface{}{
+			"question_id":   input.QuestionID,
+			"is_correct":    output.IsCorrect,
+			"points_earned": output.PointsEarned,
+			"streak_bonus":  output.StreakBonus,
+			"streak":        output.Streak,
+		},
+	})
+
+	received := map[string]interface{}{
+		"user_id":       claims.UserID,
+		"question_id":   input.QuestionID,
+		"answer":        input.Answer,
+		"is_correct":    output.IsCorrect,
+		"points_earned": output.PointsEarned,
+		"streak":        output.Streak,
+	}
+	if counts, err := h.uc.CountAnswers(r.Context(), input.QuestionID); err != nil {
+		logger.FromContext(r.Context()).Error("error al contar respuestas", "question_id", input.QuestionID, "error", err)
+	} else {
+		received["counts"] = counts
+	}
+	if hostID, err := h.uc.RoomHostID(r.Context(), code); err != nil {
+		logger.FromContext(r.Context()).Error("error al buscar el host de la sala", "room", code, "error", err)
+	} else {
+		h.hub.SendToUser(code, hostID, ws.Message{
+			Event:    "answer_received",
 			RoomCode: code,
-			Payload: map[string]interface{}{
-				"user_id":       claims.UserID,
-				"question_id":   input.QuestionID,
-				"points_earned": output.PointsEarned,
-			},
+			Payload:  received,
 		})
+		h.sendStats(r, code, hostID, input.QuestionID)
 	}
 
+	badges, err := h.badgeUC.OnAnswer(r.Context(), input, output)
+	broadcastBadges(r, h.hub, code, badges, err)
+
 	jsonResponse(w, http.StatusOK, output)
 }
 
@@ -195,7 +227,7 @@ func (h *QuestionHandler) GetAnswers(w http.ResponseWriter, r *http.Request) {
 	}
 	claims := getClaims(r)
 
-	answers, err := h.uc.GetAnswers(code, claims.UserID, questionID)
+	answers, err := h.uc.GetAnswers(r.Context(), code, claims.UserID, questionID)
 	if err != nil {
 		jsonError(w, err.Error(), http.StatusForbidden)
 		return
@@ -203,6 +235,54 @@ func (h *QuestionHandler) GetAnswers(w http.ResponseWriter, r *http.Request) {
 	jsonResponse(w, http.StatusOK, answers)
 }
 
+// GetStats godoc
+// @Summary Host obtiene las estadísticas de una pregunta
+// @Description Respondieron / sin responder, porcentaje de aciertos, distribución de respuestas, incorrectas más comunes y percentiles del tiempo de respuesta.
+// @Tags questions
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "Código de sala"
+// @Param question_id path int true "ID de pregunta"
+// @Success 200 {object} domain.QuestionStats
+// @Failure 403 {object} map[string]string
+// @Router /rooms/{code}/questions/{question_id}/stats [get]
+func (h *QuestionHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	// parts = ["rooms", code, "questions", id, "stats"]
+	if len(parts) < 5 {
+		jsonError(w, "ruta inválida", http.StatusBadRequest)
+		return
+	}
+	code := parts[1]
+	questionID, err := strconv.Atoi(parts[3])
+	if err != nil {
+		jsonError(w, "id de pregunta inválido", http.StatusBadRequest)
+		return
+	}
+	claims := getClaims(r)
+
+	stats, err := h.uc.GetQuestionStats(r.Context(), code, claims.UserID, questionID)
+	if err != nil {
+		jsonError(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	jsonResponse(w, http.StatusOK, stats)
+}
+
+// sendStats envía al host de la sala las estadísticas actualizadas de la pregunta
+func (h *QuestionHandler) sendStats(r *http.Request, code string, hostID, questionID int) {
+	stats, err := h.uc.QuestionStats(r.Context(), code, questionID)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("error al calcular estadísticas", "question_id", questionID, "error", err)
+		return
+	}
+	h.hub.SendToUser(code, hostID, ws.Message{
+		Event:    "question_stats",
+		RoomCode: code,
+		Payload:  stats,
+	})
+}
+
 // extractRoomCode extrae el código de sala de paths tipo /rooms/{code}/questions
 func extractRoomCode(path, suffix string) string {
 	s := strings.TrimPrefix(path, "/rooms/")
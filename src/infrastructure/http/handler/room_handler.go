@@ -2,6 +2,8 @@ package handler
 
 import (
     "encoding/json"
+    "errors"
+    "io"
     "net/http"
     "strings"
 
@@ -12,27 +14,38 @@ import (
 )
 
 type RoomHandler struct {
-    uc  *usecase.RoomUseCase
-    hub *ws.Hub
+    uc      *usecase.RoomUseCase
+    badgeUC *usecase.BadgeUseCase
+    hub     *ws.Hub
 }
 
-func NewRoomHandler(uc *usecase.RoomUseCase, hub *ws.Hub) *RoomHandler {
-    return &RoomHandler{uc: uc, hub: hub}
+func NewRoomHandler(uc *usecase.RoomUseCase, badgeUC *usecase.BadgeUseCase, hub *ws.Hub) *RoomHandler {
+    return &RoomHandler{uc: uc, badgeUC: badgeUC, hub: hub}
 }
 
 // CreateRoom godoc
 // @Summary Crear sala
+// @Description El cuerpo es opcional; ranking_mode define cómo se numeran los empates (competition por defecto) y streak el premio por racha (none por defecto).
 // @Tags rooms
+// @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Success 201 {object} map[string]interface{}
+// @Param body body usecase.CreateRoomInput false "Modo de ranking"
+// @Success 201 {object} usecase.CreateRoomOutput
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
 // @Failure 403 {object} map[string]string
 // @Router /rooms [post]
 func (h *RoomHandler) CreateRoom(w http.ResponseWriter, r *http.Request) {
     claims := getClaims(r)
-    room, err := h.uc.CreateRoom(claims.UserID)
+
+    var input usecase.CreateRoomInput
+    if err := json.NewDecoder(r.Body).Decode(&input); err != nil && !errors.Is(err, io.EOF) {
+        jsonError(w, "cuerpo de la petición inválido", http.StatusBadRequest)
+        return
+    }
+
+    room, err := h.uc.CreateRoom(r.Context(), claims.UserID, input)
     if err != nil {
         jsonError(w, err.Error(), http.StatusBadRequest)
         return
@@ -54,7 +67,7 @@ func (h *RoomHandler) JoinRoom(w http.ResponseWriter, r *http.Request) {
     code := extractCode(r.URL.Path, "/rooms/", "/join")
     claims := getClaims(r)
 
-    if err := h.uc.JoinRoom(code, claims.UserID); err != nil {
+    if err := h.uc.JoinRoom(r.Context(), code, claims.UserID); err != nil {
         jsonError(w, err.Error(), http.StatusBadRequest)
         return
     }
@@ -77,7 +90,7 @@ func (h *RoomHandler) StartSession(w http.ResponseWriter, r *http.Request) {
     code := extractCode(r.URL.Path, "/rooms/", "/start")
     claims := getClaims(r)
 
-    if err := h.uc.StartSession(code, claims.UserID); err != nil {
+    if err := h.uc.StartSession(r.Context(), code, claims.UserID); err != nil {
         jsonError(w, err.Error(), http.StatusBadRequest)
         return
     }
@@ -106,7 +119,7 @@ func (h *RoomHandler) EndSession(w http.ResponseWriter, r *http.Request) {
     code := extractCode(r.URL.Path, "/rooms/", "/end")
     claims := getClaims(r)
 
-    if err := h.uc.EndSession(code, claims.UserID); err != nil {
+    if err := h.uc.EndSession(r.Context(), code, claims.UserID); err != nil {
         jsonError(w, err.Error(), http.StatusBadRequest)
         return
     }
@@ -117,6 +130,9 @@ func (h *RoomHandler) EndSession(w http.ResponseWriter, r *http.Request) {
         Payload:  map[string]string{"status": "finished"},
     })
 
+    badges, err := h.badgeUC.OnSessionEnd(r.Context(), code)
+    broadcastBadges(r, h.hub, code, badges, err)
+
     jsonResponse(w, http.StatusOK, map[string]string{"message": "sesión finalizada"})
 }
 
@@ -132,7 +148,7 @@ func (h *RoomHandler) EndSession(w http.ResponseWriter, r *http.Request) {
 // @Router /rooms/{code} [get]
 func (h *RoomHandler) GetRoom(w http.ResponseWriter, r *http.Request) {
     code := strings.TrimPrefix(r.URL.Path, "/rooms/")
-    room, err := h.uc.GetRoom(code)
+    room, err := h.uc.GetRoom(r.Context(), code)
     if err != nil {
         jsonError(w, err.Error(), http.StatusNotFound)
         return
@@ -161,7 +177,7 @@ func getClaims(r *http.Request) *jwtutil.Claims {
 // @Router /rooms/{code}/participants [get]
 func (h *RoomHandler) GetParticipants(w http.ResponseWriter, r *http.Request) {
     code := extractCode(r.URL.Path, "/rooms/", "/participants")
-    participants, err := h.uc.GetParticipants(code)
+    participants, err := h.uc.GetParticipants(r.Context(), code)
     if err != nil {
         jsonError(w, err.Error(), http.StatusBadRequest)
         return
@@ -197,7 +213,7 @@ func (h *RoomHandler) KickParticipant(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    if err := h.uc.KickParticipant(code, claims.UserID, body.UserID); err != nil {
+    if err := h.uc.KickParticipant(r.Context(), code, claims.UserID, body.UserID); err != nil {
         jsonError(w, err.Error(), http.StatusBadRequest)
         return
     }
@@ -2,10 +2,13 @@ package handler
 
 import (
     "encoding/json"
+    "errors"
+    "io"
     "net/http"
-    "strings"
+    "strconv"
 
     "apiGolan/src/applications/usecase"
+    "apiGolan/src/domain"
     ws "apiGolan/src/infrastructure/websocket"
 )
 
@@ -44,38 +47,37 @@ func (h *ScoreHandler) AddPoints(w http.ResponseWriter, r *http.Request) {
     input.RoomCode = code
     input.RequesterID = claims.UserID
 
-    if err := h.uc.AddPoints(input); err != nil {
+    if err := h.uc.AddPoints(r.Context(), input); err != nil {
         jsonError(w, err.Error(), http.StatusBadRequest)
         return
     }
 
-    ranking, err := h.uc.GetRanking(code)
-    if err == nil {
-        h.hub.Broadcast(code, ws.Message{
-            Event:    "score_update",
-            RoomCode: code,
-            Payload:  ranking,
-        })
-    }
+    h.broadcastRanking(r, code)
 
     jsonResponse(w, http.StatusOK, map[string]string{"message": "puntos actualizados"})
 }
 
 // GetRanking godoc
 // @Summary Obtener ranking de sala
+// @Description Ordenado por puesto; dentro de un empate, por menor tiempo total de respuesta, la última respuesta correcta más temprana y el ingreso a la sala más temprano.
 // @Tags scores
 // @Produce json
 // @Security BearerAuth
 // @Param code path string true "Código de sala"
-// @Success 200 {array} map[string]interface{}
+// @Param limit query int false "Máximo de posiciones (sin límite por defecto)"
+// @Param offset query int false "Desplazamiento"
+// @Success 200 {array} domain.RankingEntry
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
 // @Router /rooms/{code}/ranking [get]
 func (h *ScoreHandler) GetRanking(w http.ResponseWriter, r *http.Request) {
-    code := strings.TrimPrefix(r.URL.Path, "/rooms/")
-    code = strings.TrimSuffix(code, "/ranking")
+    code := r.PathValue("code")
 
-    ranking, err := h.uc.GetRanking(code)
+    q := r.URL.Query()
+    limit, _ := strconv.Atoi(q.Get("limit"))
+    offset, _ := strconv.Atoi(q.Get("offset"))
+
+    ranking, err := h.uc.GetRanking(r.Context(), code, limit, offset)
     if err != nil {
         jsonError(w, err.Error(), http.StatusBadRequest)
         return
@@ -84,6 +86,100 @@ func (h *ScoreHandler) GetRanking(w http.ResponseWriter, r *http.Request) {
     jsonResponse(w, http.StatusOK, ranking)
 }
 
+// GetMyPosition godoc
+// @Summary Mi puesto en el ranking de la sala
+// @Tags scores
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "Código de sala"
+// @Success 200 {object} domain.RankingEntry
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /rooms/{code}/ranking/me [get]
+func (h *ScoreHandler) GetMyPosition(w http.ResponseWriter, r *http.Request) {
+    code := r.PathValue("code")
+    claims := getClaims(r)
+
+    entry, err := h.uc.GetPosition(r.Context(), code, claims.UserID)
+    if err != nil {
+        jsonError(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+    if entry == nil {
+        jsonError(w, "no estás en el ranking de esta sala", http.StatusNotFound)
+        return
+    }
+
+    jsonResponse(w, http.StatusOK, entry)
+}
+
+// SetRankingMode godoc
+// @Summary Host cambia cómo se numeran los empates del ranking
+// @Description competition: 1, 2, 2, 4. dense: 1, 2, 2, 3. Emite score_update con el ranking renumerado.
+// @Tags scores
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "Código de sala"
+// @Param body body usecase.RankingModeInput true "Modo de ranking"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /rooms/{code}/ranking-mode [patch]
+func (h *ScoreHandler) SetRankingMode(w http.ResponseWriter, r *http.Request) {
+    code := r.PathValue("code")
+    claims := getClaims(r)
+
+    var input usecase.RankingModeInput
+    if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+        jsonError(w, "cuerpo de la petición inválido", http.StatusBadRequest)
+        return
+    }
+
+    if err := h.uc.SetRankingMode(r.Context(), code, claims.UserID, input); err != nil {
+        jsonError(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    h.broadcastRanking(r, code)
+
+    jsonResponse(w, http.StatusOK, map[string]string{"message": "modo de ranking actualizado"})
+}
+
+// SetStreak godoc
+// @Summary Host cambia el premio por racha de la sala
+// @Description none: sin premio. bonus: step puntos extra por cada acierto previo de la racha. multiplier: step % extra por cada acierto previo. max limita la racha que cuenta (0 = sin tope). Vale para las respuestas siguientes.
+// @Tags scores
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "Código de sala"
+// @Param body body domain.StreakConfig true "Premio por racha"
+// @Success 200 {object} domain.StreakConfig
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /rooms/{code}/streak [patch]
+func (h *ScoreHandler) SetStreak(w http.ResponseWriter, r *http.Request) {
+    code := r.PathValue("code")
+    claims := getClaims(r)
+
+    var input domain.StreakConfig
+    if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+        jsonError(w, "cuerpo de la petición inválido", http.StatusBadRequest)
+        return
+    }
+
+    streak, err := h.uc.SetStreak(r.Context(), code, claims.UserID, input)
+    if err != nil {
+        jsonError(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+    jsonResponse(w, http.StatusOK, streak)
+}
+
 // ResetUserPoints godoc
 // @Summary Host resetea los puntos de un participante específico
 // @Tags scores
@@ -106,19 +202,12 @@ func (h *ScoreHandler) ResetUserPoints(w http.ResponseWriter, r *http.Request) {
     input.RoomCode = code
     input.RequesterID = claims.UserID
 
-    if err := h.uc.ResetUserPoints(input); err != nil {
+    if err := h.uc.ResetUserPoints(r.Context(), input); err != nil {
         jsonError(w, err.Error(), http.StatusBadRequest)
         return
     }
 
-    ranking, err := h.uc.GetRanking(code)
-    if err == nil {
-        h.hub.Broadcast(code, ws.Message{
-            Event:    "score_update",
-            RoomCode: code,
-            Payload:  ranking,
-        })
-    }
+    h.broadcastRanking(r, code)
 
     jsonResponse(w, http.StatusOK, map[string]string{"message": "puntos reseteados"})
 }
@@ -129,18 +218,125 @@ func (h *ScoreHandler) ResetUserPoints(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Security BearerAuth
 // @Param code path string true "Código de sala"
+// @Param body body usecase.ReasonInput false "Motivo (opcional)"
 // @Success 200 {object} map[string]string
 // @Router /rooms/{code}/score/reset-all [post]
 func (h *ScoreHandler) ResetAllPoints(w http.ResponseWriter, r *http.Request) {
     code := extractCode(r.URL.Path, "/rooms/", "/score/reset-all")
     claims := getClaims(r)
 
-    if err := h.uc.ResetAllPoints(code, claims.UserID); err != nil {
+    input, ok := decodeReason(w, r)
+    if !ok {
+        return
+    }
+
+    if err := h.uc.ResetAllPoints(r.Context(), code, claims.UserID, input.Reason); err != nil {
+        jsonError(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    h.broadcastRanking(r, code)
+
+    jsonResponse(w, http.StatusOK, map[string]string{"message": "todos los puntos reseteados"})
+}
+// ListEvents godoc
+// @Summary Host lista el historial de cambios de puntos de la sala
+// @Description Cada cambio (manual, por respuesta, reset o undo) con quién lo hizo, el motivo y el delta, del más nuevo al más viejo.
+// @Tags scores
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "Código de sala"
+// @Param user_id query int false "Solo los de este participante"
+// @Param limit query int false "Máximo de resultados (50 por defecto, máx. 100)"
+// @Param offset query int false "Desplazamiento"
+// @Success 200 {array} domain.ScoreEvent
+// @Failure 403 {object} map[string]string
+// @Router /rooms/{code}/score/events [get]
+func (h *ScoreHandler) ListEvents(w http.ResponseWriter, r *http.Request) {
+    code := r.PathValue("code")
+    claims := getClaims(r)
+
+    q := r.URL.Query()
+    var filter domain.ScoreEventFilter
+    filter.UserID, _ = strconv.Atoi(q.Get("user_id"))
+    filter.Limit, _ = strconv.Atoi(q.Get("limit"))
+    filter.Offset, _ = strconv.Atoi(q.Get("offset"))
+
+    events, err := h.uc.ListScoreEvents(r.Context(), code, claims.UserID, filter)
+    if err != nil {
+        jsonError(w, err.Error(), http.StatusForbidden)
+        return
+    }
+    jsonResponse(w, http.StatusOK, events)
+}
+
+// UndoEvent godoc
+// @Summary Host deshace un cambio de puntos
+// @Description Registra un evento undo con el delta opuesto y emite score_update. Cada evento se deshace una sola vez; un undo o un opening no se pueden deshacer.
+// @Tags scores
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "Código de sala"
+// @Param id path int true "ID del evento"
+// @Param body body usecase.ReasonInput false "Motivo (opcional)"
+// @Success 200 {object} domain.ScoreEvent
+// @Failure 400 {object} map[string]string
+// @Router /rooms/{code}/score/events/{id}/undo [post]
+func (h *ScoreHandler) UndoEvent(w http.ResponseWriter, r *http.Request) {
+    code := r.PathValue("code")
+    eventID, err := strconv.Atoi(r.PathValue("id"))
+    if err != nil {
+        jsonError(w, "id de evento inválido", http.StatusBadRequest)
+        return
+    }
+    claims := getClaims(r)
+
+    input, ok := decodeReason(w, r)
+    if !ok {
+        return
+    }
+
+    undo, err := h.uc.UndoScoreEvent(r.Context(), code, claims.UserID, eventID, input.Reason)
+    if err != nil {
+        jsonError(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    h.broadcastRanking(r, code)
+    jsonResponse(w, http.StatusOK, undo)
+}
+
+// Reconcile godoc
+// @Summary Host recalcula los puntos de la sala desde el historial
+// @Description Iguala cada score a la suma de sus eventos y devuelve los que estaban desfasados.
+// @Tags scores
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "Código de sala"
+// @Success 200 {object} usecase.ReconcileOutput
+// @Failure 400 {object} map[string]string
+// @Router /rooms/{code}/score/reconcile [post]
+func (h *ScoreHandler) Reconcile(w http.ResponseWriter, r *http.Request) {
+    code := r.PathValue("code")
+    claims := getClaims(r)
+
+    output, err := h.uc.ReconcileScores(r.Context(), code, claims.UserID)
+    if err != nil {
         jsonError(w, err.Error(), http.StatusBadRequest)
         return
     }
 
-    ranking, err := h.uc.GetRanking(code)
+    if len(output.Fixed) > 0 {
+        h.broadcastRanking(r, code)
+    }
+    jsonResponse(w, http.StatusOK, output)
+}
+
+// broadcastRanking envía a la sala las primeras posiciones del ranking; cada
+// cliente consulta su propio puesto con /ranking/me
+func (h *ScoreHandler) broadcastRanking(r *http.Request, code string) {
+    ranking, err := h.uc.TopRanking(r.Context(), code)
     if err == nil {
         h.hub.Broadcast(code, ws.Message{
             Event:    "score_update",
@@ -148,6 +344,14 @@ func (h *ScoreHandler) ResetAllPoints(w http.ResponseWriter, r *http.Request) {
             Payload:  ranking,
         })
     }
+}
 
-    jsonResponse(w, http.StatusOK, map[string]string{"message": "todos los puntos reseteados"})
-}
\ No newline at end of file
+// decodeReason lee el cuerpo opcional {"reason": "..."}; un cuerpo vacío vale
+func decodeReason(w http.ResponseWriter, r *http.Request) (usecase.ReasonInput, bool) {
+    var input usecase.ReasonInput
+    if err := json.NewDecoder(r.Body).Decode(&input); err != nil && !errors.Is(err, io.EOF) {
+        jsonError(w, "cuerpo de la petición inválido", http.StatusBadRequest)
+        return input, false
+    }
+    return input, true
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+    "encoding/json"
+    "net/http"
+
+    "apiGolan/src/applications/usecase"
+)
+
+type UserHandler struct {
+    uc *usecase.UserUseCase
+}
+
+func NewUserHandler(uc *usecase.UserUseCase) *UserHandler {
+    return &UserHandler{uc: uc}
+}
+
+// GetMe godoc
+// @Summary Ver mi cuenta
+// @Tags me
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} domain.User
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /me [get]
+func (h *UserHandler) GetMe(w http.ResponseWriter, r *http.Request) {
+    claims := getClaims(r)
+
+    user, err := h.uc.GetProfile(r.Context(), claims.UserID)
+    if err != nil {
+        jsonError(w, err.Error(), http.StatusNotFound)
+        return
+    }
+
+    jsonResponse(w, http.StatusOK, user)
+}
+
+// UpdateMe godoc
+// @Summary Cambiar mi nombre
+// @Tags me
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body usecase.UpdateProfileInput true "Nombre nuevo"
+// @Success 200 {object} domain.User
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /me [patch]
+func (h *UserHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+    claims := getClaims(r)
+
+    var input usecase.UpdateProfileInput
+    if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+        jsonError(w, "cuerpo de la petición inválido", http.StatusBadRequest)
+        return
+    }
+
+    user, err := h.uc.UpdateProfile(r.Context(), claims.UserID, input)
+    if err != nil {
+        jsonError(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    jsonResponse(w, http.StatusOK, user)
+}
+
+// ChangePassword godoc
+// @Summary Cambiar mi contraseña
+// @Description Requiere la contraseña actual
+// @Tags me
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body usecase.ChangePasswordInput true "Contraseña actual y nueva"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /me/password [post]
+func (h *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+    claims := getClaims(r)
+
+    var input usecase.ChangePasswordInput
+    if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+        jsonError(w, "cuerpo de la petición inválido", http.StatusBadRequest)
+        return
+    }
+
+    if err := h.uc.ChangePassword(r.Context(), claims.UserID, input); err != nil {
+        jsonError(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    jsonResponse(w, http.StatusOK, map[string]string{"message": "contraseña actualizada"})
+}
+
+// DeleteMe godoc
+// @Summary Eliminar mi cuenta
+// @Description Anonimiza nombre, email y contraseña. Las respuestas y puntos
+// @Description se conservan a nombre de "Usuario eliminado".
+// @Tags me
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body usecase.DeleteAccountInput true "Contraseña para confirmar"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /me [delete]
+func (h *UserHandler) DeleteMe(w http.ResponseWriter, r *http.Request) {
+    claims := getClaims(r)
+
+    var input usecase.DeleteAccountInput
+    if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+        jsonError(w, "cuerpo de la petición inválido", http.StatusBadRequest)
+        return
+    }
+
+    if err := h.uc.DeleteAccount(r.Context(), claims.UserID, input); err != nil {
+        jsonError(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    jsonResponse(w, http.StatusOK, map[string]string{"message": "cuenta eliminada"})
+}
@@ -5,48 +5,151 @@ import (
 	"net/http"
 	"strings"
 
+	"apiGolan/src/core"
+	"apiGolan/src/domain"
 	jwtutil "apiGolan/src/infrastructure/jwt"
+	"apiGolan/src/infrastructure/logger"
 )
 
 type contextKey string
 
-const UserClaimsKey contextKey = "user_claims"
+const (
+    UserClaimsKey contextKey = "user_claims"
+    APIKeyKey     contextKey = "api_key"      // *domain.APIKey si se autenticó con API key
+    apiScopeKey   contextKey = "api_key_scope" // scope que la ruta exige a las API keys
+)
 
-// Auth valida el token JWT del header Authorization: Bearer <token>
-func Auth(next http.Handler) http.Handler {
-    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-        // Permitir preflight CORS
-        if r.Method == http.MethodOptions {
-            w.WriteHeader(http.StatusNoContent)
-            return
-        }
+// APIKeyAuthenticator valida API keys (qs_...) y devuelve la key y su dueño
+type APIKeyAuthenticator interface {
+    Authenticate(ctx context.Context, secret string) (*domain.APIKey, *domain.User, error)
+}
 
-        authHeader := r.Header.Get("Authorization")
-        if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
-            http.Error(w, `{"error":"token requerido"}`, http.StatusUnauthorized)
-            return
-        }
+// AccountChecker devuelve el rol actual de la cuenta dueña de un JWT, o ""
+// si ya no está activa
+type AccountChecker interface {
+    ActiveRole(ctx context.Context, userID int) (domain.Role, error)
+}
 
-        tokenStr := strings.TrimPrefix(authHeader, "Bearer ")
-        claims, err := jwtutil.Validate(tokenStr)
-        if err != nil {
-            http.Error(w, `{"error":"token inválido o expirado"}`, http.StatusUnauthorized)
-            return
-        }
+// Auth valida el header Authorization: Bearer <token>, donde el token es un
+// JWT o una API key. Las API keys solo pasan en rutas marcadas con AllowAPIKey
+// y si tienen el scope de la ruta; el resto de rutas son solo para sesiones.
+// Un JWT válido de una cuenta eliminada se rechaza igual que uno expirado, y
+// el rol que llega a los handlers es el actual de la cuenta, no el del token.
+func Auth(keys APIKeyAuthenticator, accounts AccountChecker) func(http.Handler) http.Handler {
+    return func(next http.Handler) http.Handler {
+        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            // Permitir preflight CORS
+            if r.Method == http.MethodOptions {
+                w.WriteHeader(http.StatusNoContent)
+                return
+            }
+
+            authHeader := r.Header.Get("Authorization")
+            if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
+                WriteError(w, "token requerido", http.StatusUnauthorized)
+                return
+            }
+            tokenStr := strings.TrimPrefix(authHeader, "Bearer ")
+
+            if core.IsAPIKey(tokenStr) {
+                key, user, err := keys.Authenticate(r.Context(), tokenStr)
+                if err != nil {
+                    WriteError(w, "API key inválida o revocada", http.StatusUnauthorized)
+                    return
+                }
+                logger.SetUserID(r.Context(), user.ID)
+
+                scope, allowed := r.Context().Value(apiScopeKey).(domain.Scope)
+                if !allowed {
+                    WriteError(w, "esta ruta no admite API keys", http.StatusForbidden)
+                    return
+                }
+                if !key.HasScope(scope) {
+                    WriteError(w, "la API key no tiene el scope "+string(scope), http.StatusForbidden)
+                    return
+                }
 
-        ctx := context.WithValue(r.Context(), UserClaimsKey, claims)
-        next.ServeHTTP(w, r.WithContext(ctx))
+                claims := &jwtutil.Claims{UserID: user.ID, Role: string(user.Role)}
+                ctx := context.WithValue(r.Context(), UserClaimsKey, claims)
+                ctx = context.WithValue(ctx, APIKeyKey, key)
+                next.ServeHTTP(w, r.WithContext(ctx))
+                return
+            }
+
+            claims, ok := ValidateSession(w, r, accounts, tokenStr)
+            if !ok {
+                return
+            }
+
+            logger.SetUserID(r.Context(), claims.UserID)
+            ctx := context.WithValue(r.Context(), UserClaimsKey, claims)
+            next.ServeHTTP(w, r.WithContext(ctx))
+        })
+    }
+}
+
+// ValidateSession valida un JWT y comprueba que su cuenta siga activa. Los
+// claims devueltos llevan el rol actual de la cuenta: si un admin la degradó,
+// el token vigente ya no conserva los permisos anteriores.
+// Si no, escribe la respuesta de error y devuelve false.
+func ValidateSession(w http.ResponseWriter, r *http.Request, accounts AccountChecker, tokenStr string) (*jwtutil.Claims, bool) {
+    claims, err := jwtutil.Validate(tokenStr)
+    if err != nil {
+        WriteError(w, "token inválido o expirado", http.StatusUnauthorized)
+        return nil, false
+    }
+    role, err := accounts.ActiveRole(r.Context(), claims.UserID)
+    if err != nil {
+        logger.FromContext(r.Context()).Error("error al comprobar la cuenta", "error", err)
+        WriteError(w, "error al validar la sesión", http.StatusInternalServerError)
+        return nil, false
+    }
+    if role == "" {
+        WriteError(w, "token inválido o expirado", http.StatusUnauthorized)
+        return nil, false
+    }
+    claims.Role = string(role)
+    return claims, true
+}
+
+// TokenFromQuery acepta el token en ?token= cuando no viene el header
+// Authorization, para clientes que no pueden enviar headers (EventSource).
+// Va por fuera de Auth: TokenFromQuery(Auth(keys)(handler)).
+func TokenFromQuery(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if token := r.URL.Query().Get("token"); token != "" && r.Header.Get("Authorization") == "" {
+            r.Header.Set("Authorization", "Bearer "+token)
+        }
+        next.ServeHTTP(w, r)
     })
 }
 
-// OnlyHost rechaza la petición si el usuario no es host
-func OnlyHost(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		claims, ok := r.Context().Value(UserClaimsKey).(*jwtutil.Claims)
-		if !ok || claims.Role != "host" {
-			http.Error(w, `{"error":"solo el host puede realizar esta acción"}`, http.StatusForbidden)
-			return
-		}
-		next.ServeHTTP(w, r)
-	})
+// AllowAPIKey permite usar en la ruta API keys que tengan scope.
+// Va por fuera de Auth: AllowAPIKey(scope)(Auth(keys)(handler)).
+func AllowAPIKey(scope domain.Scope) func(http.Handler) http.Handler {
+    return func(next http.Handler) http.Handler {
+        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            ctx := context.WithValue(r.Context(), apiScopeKey, scope)
+            next.ServeHTTP(w, r.WithContext(ctx))
+        })
+    }
+}
+
+// RequireRole rechaza la petición si el rol del token no alcanza min.
+// Los roles son jerárquicos: un admin pasa los controles de host.
+func RequireRole(min domain.Role) func(http.Handler) http.Handler {
+	msg := "no tienes permisos para realizar esta acción"
+	if min == domain.RoleHost {
+		msg = "solo el host puede realizar esta acción"
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := r.Context().Value(UserClaimsKey).(*jwtutil.Claims)
+			if !ok || !domain.Role(claims.Role).AtLeast(min) {
+				WriteError(w, msg, http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
 }
@@ -0,0 +1,175 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"apiGolan/src/domain"
+	jwtutil "apiGolan/src/infrastructure/jwt"
+)
+
+// fakeAccounts devuelve el rol actual de las cuentas activas
+type fakeAccounts struct {
+	roles map[int]domain.Role
+	err   error
+}
+
+func (f fakeAccounts) ActiveRole(ctx context.Context, userID int) (domain.Role, error) {
+	return f.roles[userID], f.err
+}
+
+type noAPIKeys struct{}
+
+func (noAPIKeys) Authenticate(ctx context.Context, secret string) (*domain.APIKey, *domain.User, error) {
+	return nil, nil, errors.New("sin API keys")
+}
+
+// sessionToken firma un JWT de desarrollo para el usuario y rol dados
+func sessionToken(t *testing.T, userID int, role domain.Role) string {
+	t.Helper()
+	t.Setenv("APP_ENV", "development")
+	t.Setenv("JWT_KEYS_DIR", "")
+	if err := jwtutil.Setup(); err != nil {
+		t.Fatal(err)
+	}
+	token, err := jwtutil.Generate(userID, string(role))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestAuthRejectsDeletedAccounts(t *testing.T) {
+	token := sessionToken(t, 7, domain.RoleParticipant)
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	tests := []struct {
+		name     string
+		accounts fakeAccounts
+		want     int
+	}{
+		{"cuenta activa", fakeAccounts{roles: map[int]domain.Role{7: domain.RoleParticipant}}, http.StatusOK},
+		{"cuenta eliminada", fakeAccounts{roles: map[int]domain.Role{}}, http.StatusUnauthorized},
+		{"error al consultar", fakeAccounts{err: errors.New("db caída")}, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/me", nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		Auth(noAPIKeys{}, tt.accounts)(ok).ServeHTTP(w, r)
+		if w.Code != tt.want {
+			t.Errorf("%s: status = %d, se esperaba %d", tt.name, w.Code, tt.want)
+		}
+	}
+}
+
+func TestAuthUsesCurrentRole(t *testing.T) {
+	// el token se emitió cuando el usuario 7 era admin
+	token := sessionToken(t, 7, domain.RoleAdmin)
+
+	tests := []struct {
+		name    string
+		current domain.Role
+		min     domain.Role
+		want    int
+	}{
+		{"sigue siendo admin", domain.RoleAdmin, domain.RoleAdmin, http.StatusOK},
+		{"degradado a host pierde admin", domain.RoleHost, domain.RoleAdmin, http.StatusForbidden},
+		{"degradado a host conserva host", domain.RoleHost, domain.RoleHost, http.StatusOK},
+		{"degradado a participante pierde host", domain.RoleParticipant, domain.RoleHost, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		var role string
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role = r.Context().Value(UserClaimsKey).(*jwtutil.Claims).Role
+			w.WriteHeader(http.StatusOK)
+		})
+		accounts := fakeAccounts{roles: map[int]domain.Role{7: tt.current}}
+
+		r := httptest.NewRequest("GET", "/admin/users", nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		Auth(noAPIKeys{}, accounts)(RequireRole(tt.min)(handler)).ServeHTTP(w, r)
+		if w.Code != tt.want {
+			t.Errorf("%s: status = %d, se esperaba %d", tt.name, w.Code, tt.want)
+		}
+		if w.Code == http.StatusOK && role != string(tt.current) {
+			t.Errorf("%s: el handler recibió el rol %q, se esperaba %q", tt.name, role, tt.current)
+		}
+	}
+}
+
+// fakeAPIKeys acepta una sola key, de un dueño con el rol indicado
+type fakeAPIKeys struct {
+	secret string
+	key    *domain.APIKey
+	owner  *domain.User
+}
+
+func (f fakeAPIKeys) Authenticate(ctx context.Context, secret string) (*domain.APIKey, *domain.User, error) {
+	if secret != f.secret {
+		return nil, nil, errors.New("API key inválida o revocada")
+	}
+	return f.key, f.owner, nil
+}
+
+func TestAuthAPIKeyScopes(t *testing.T) {
+	keys := fakeAPIKeys{
+		secret: "qs_valida",
+		key:    &domain.APIKey{ID: 1, UserID: 9, Scopes: []domain.Scope{domain.ScopeScoresRead, domain.ScopeRoomsRead}},
+		owner:  &domain.User{ID: 9, Role: domain.RoleHost},
+	}
+	// sin cuentas: una API key no pasa por ActiveRole
+	accounts := fakeAccounts{err: errors.New("no debería consultarse")}
+
+	var gotKey *domain.APIKey
+	var gotClaims *jwtutil.Claims
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKey, _ = r.Context().Value(APIKeyKey).(*domain.APIKey)
+		gotClaims, _ = r.Context().Value(UserClaimsKey).(*jwtutil.Claims)
+		w.WriteHeader(http.StatusOK)
+	})
+	auth := Auth(keys, accounts)
+
+	tests := []struct {
+		name    string
+		secret  string
+		scope   domain.Scope // "" si la ruta no admite API keys
+		minRole domain.Role  // "" si la ruta no exige rol
+		want    int
+	}{
+		{"scope que la key tiene", "qs_valida", domain.ScopeScoresRead, "", http.StatusOK},
+		{"otro scope que la key tiene", "qs_valida", domain.ScopeRoomsRead, "", http.StatusOK},
+		{"scope que la key no tiene", "qs_valida", domain.ScopeScoresWrite, "", http.StatusForbidden},
+		{"ruta solo para sesiones", "qs_valida", "", "", http.StatusForbidden},
+		{"key revocada o desconocida", "qs_otra", domain.ScopeScoresRead, "", http.StatusUnauthorized},
+		{"rol del dueño alcanza", "qs_valida", domain.ScopeScoresRead, domain.RoleHost, http.StatusOK},
+		{"rol del dueño no alcanza", "qs_valida", domain.ScopeScoresRead, domain.RoleAdmin, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		gotKey, gotClaims = nil, nil
+		var h http.Handler = ok
+		if tt.minRole != "" {
+			h = RequireRole(tt.minRole)(h)
+		}
+		h = auth(h)
+		if tt.scope != "" {
+			h = AllowAPIKey(tt.scope)(h)
+		}
+
+		r := httptest.NewRequest("GET", "/rooms/ABC123/ranking", nil)
+		r.Header.Set("Authorization", "Bearer "+tt.secret)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != tt.want {
+			t.Errorf("%s: status = %d, se esperaba %d", tt.name, w.Code, tt.want)
+			continue
+		}
+		if tt.want == http.StatusOK && (gotKey != keys.key || gotClaims == nil || gotClaims.UserID != 9 || gotClaims.Role != "host") {
+			t.Errorf("%s: el handler recibió key %+v y claims %+v", tt.name, gotKey, gotClaims)
+		}
+	}
+}
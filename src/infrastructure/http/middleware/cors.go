@@ -7,7 +7,8 @@ func CORS(next http.Handler) http.Handler {
     return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
         w.Header().Set("Access-Control-Allow-Origin", "*")
         w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-        w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+        w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, X-Request-ID")
+        w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
         w.Header().Set("Vary", "Origin")
 
         // Responde a preflight (OPTIONS) sin pasar al siguiente handler
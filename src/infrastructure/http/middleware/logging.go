@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"apiGolan/src/infrastructure/logger"
+)
+
+// RequestIDHeader es el header por el que viaja el identificador de la petición
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID reutiliza el X-Request-ID del cliente o genera uno nuevo.
+// Se devuelve en la respuesta y queda en el contexto para los logs.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if id == "" || len(id) > 128 {
+			id = newRequestID()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+		ctx := logger.WithRequestID(r.Context(), id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// WriteError responde un error JSON incluyendo el request ID de la petición
+func WriteError(w http.ResponseWriter, msg string, status int) {
+	// Dejar el mensaje disponible para el access log
+	for rw := w; ; {
+		rec, ok := rw.(*statusRecorder)
+		if !ok {
+			break
+		}
+		rec.errMsg = msg
+		rw = rec.ResponseWriter
+	}
+
+	body := map[string]string{"error": msg}
+	if id := w.Header().Get(RequestIDHeader); id != "" {
+		body["request_id"] = id
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// AccessLog escribe una línea por petición con ruta, estado, duración y usuario.
+// Va dentro de RequestID y fuera del mux, igual que Metrics.
+func AccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(rec, r)
+
+		level := slog.LevelInfo
+		switch {
+		case rec.status >= 500:
+			level = slog.LevelError
+		case rec.status >= 400:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("route", routeOf(r)),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", rec.status),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+		}
+		if userID := logger.UserID(r.Context()); userID != 0 {
+			attrs = append(attrs, slog.Int("user_id", userID))
+		}
+		if rec.errMsg != "" {
+			attrs = append(attrs, slog.String("error", rec.errMsg))
+		}
+		logger.FromContext(r.Context()).LogAttrs(r.Context(), level, "http_request", attrs...)
+	})
+}
@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"apiGolan/src/infrastructure/logger"
+)
+
+// captureLogs redirige slog a un buffer JSON mientras dura el test
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	return &buf
+}
+
+func TestRequestLogging(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rooms/{code}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("POST /rooms/{code}/join", func(w http.ResponseWriter, r *http.Request) {
+		logger.SetUserID(r.Context(), 7) // lo que hace Auth
+		WriteError(w, "ya estás en la sala", http.StatusConflict)
+	})
+	mux.HandleFunc("GET /boom", func(w http.ResponseWriter, r *http.Request) {
+		WriteError(w, "error interno", http.StatusInternalServerError)
+	})
+	handler := RequestID(AccessLog(mux))
+	generated := regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+	tests := []struct {
+		name      string
+		method    string
+		path      string
+		requestID string // X-Request-ID que envía el cliente
+		keepID    bool   // se reutiliza el del cliente
+		status    int
+		level     string
+		userID    int
+		errMsg    string
+	}{
+		{"genera el id", "GET", "/rooms/ABC123", "", false, http.StatusOK, "INFO", 0, ""},
+		{"reutiliza el del cliente", "GET", "/rooms/ABC123", "abc-123", true, http.StatusOK, "INFO", 0, ""},
+		{"descarta ids demasiado largos", "GET", "/rooms/ABC123", strings.Repeat("x", 129), false, http.StatusOK, "INFO", 0, ""},
+		{"error del cliente con usuario", "POST", "/rooms/ABC123/join", "req-4xx", true, http.StatusConflict, "WARN", 7, "ya estás en la sala"},
+		{"error del servidor", "GET", "/boom", "", false, http.StatusInternalServerError, "ERROR", 0, "error interno"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureLogs(t)
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.requestID != "" {
+				r.Header.Set(RequestIDHeader, tt.requestID)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, r)
+
+			id := rec.Header().Get(RequestIDHeader)
+			if tt.keepID && id != tt.requestID {
+				t.Errorf("X-Request-ID = %q, se esperaba el del cliente %q", id, tt.requestID)
+			}
+			if !tt.keepID && !generated.MatchString(id) {
+				t.Errorf("X-Request-ID generado = %q", id)
+			}
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, se esperaba %d", rec.Code, tt.status)
+			}
+			if tt.errMsg != "" {
+				var body map[string]string
+				if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+					t.Fatal(err)
+				}
+				if body["error"] != tt.errMsg || body["request_id"] != id {
+					t.Errorf("cuerpo del error = %v", body)
+				}
+			}
+
+			var line struct {
+				Level     string  `json:"level"`
+				Msg       string  `json:"msg"`
+				RequestID string  `json:"request_id"`
+				Method    string  `json:"method"`
+				Route     string  `json:"route"`
+				Path      string  `json:"path"`
+				Status    int     `json:"status"`
+				Duration  float64 `json:"duration_ms"`
+				UserID    int     `json:"user_id"`
+				Error     string  `json:"error"`
+			}
+			if err := json.Unmarshal(logs.Bytes(), &line); err != nil {
+				t.Fatalf("línea de log %q: %v", logs.String(), err)
+			}
+			route := tt.path
+			if strings.HasPrefix(tt.path, "/rooms/") {
+				route = strings.Replace(tt.path, "ABC123", "{code}", 1)
+			}
+			if line.Msg != "http_request" || line.Level != tt.level || line.RequestID != id ||
+				line.Method != tt.method || line.Route != route || line.Path != tt.path ||
+				line.Status != tt.status || line.UserID != tt.userID || line.Error != tt.errMsg {
+				t.Errorf("access log = %+v", line)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"apiGolan/src/infrastructure/metrics"
+)
+
+// Metrics registra cantidad y latencia de peticiones por patrón de ruta.
+// Debe envolver al mux: el patrón (r.Pattern) lo completa el mux al enrutar.
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(rec, r)
+
+		metrics.ObserveRequest(routeOf(r), r.Method, rec.status, time.Since(start))
+	})
+}
+
+// routeOf devuelve el patrón registrado sin el método ("/rooms/{code}")
+func routeOf(r *http.Request) string {
+	if r.Pattern == "" {
+		return "unmatched"
+	}
+	if _, path, ok := strings.Cut(r.Pattern, " "); ok {
+		return path
+	}
+	return r.Pattern
+}
+
+// statusRecorder guarda el código de estado escrito por el handler.
+// Implementa Hijacker y Flusher para no romper WebSocket ni streaming.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	errMsg string // mensaje de error escrito con WriteError
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("el ResponseWriter no soporta Hijack")
+	}
+	r.status = http.StatusSwitchingProtocols
+	return h.Hijack()
+}
+
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"apiGolan/src/infrastructure/metrics"
+)
+
+// scrape devuelve el texto que sirve /metrics
+func scrape(t *testing.T) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := io.ReadAll(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestMetricsLabelsByPattern(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metricstest/{code}", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("POST /metricstest/{code}/fail", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no", http.StatusConflict)
+	})
+	handler := Metrics(mux)
+
+	tests := []struct {
+		method string
+		path   string
+		want   string // serie que debe aparecer con valor 1
+	}{
+		{"GET", "/metricstest/ABC123", `quickscore_http_requests_total{method="GET",route="/metricstest/{code}",status="200"} 1`},
+		{"POST", "/metricstest/XYZ789/fail", `quickscore_http_requests_total{method="POST",route="/metricstest/{code}/fail",status="409"} 1`},
+		{"DELETE", "/metricstest-no-existe", `quickscore_http_requests_total{method="DELETE",route="unmatched",status="404"} 1`},
+	}
+	for _, tt := range tests {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.path, nil))
+	}
+
+	body := scrape(t)
+	for _, tt := range tests {
+		if !strings.Contains(body, tt.want+"\n") {
+			t.Errorf("%s %s: falta %s", tt.method, tt.path, tt.want)
+		}
+	}
+	// la ruta lleva el patrón, no el código de sala: una serie por ruta
+	if strings.Contains(body, "ABC123") || strings.Contains(body, "XYZ789") {
+		t.Error("las métricas incluyen el path concreto en vez del patrón")
+	}
+	if !strings.Contains(body, `quickscore_http_request_duration_seconds_count{method="GET",route="/metricstest/{code}"} 1`) {
+		t.Error("falta la latencia de GET /metricstest/{code}")
+	}
+}
+
+func TestStatusRecorderHijack(t *testing.T) {
+	// un ResponseWriter sin Hijack (httptest) devuelve error en vez de entrar en pánico
+	rec := &statusRecorder{ResponseWriter: httptest.NewRecorder(), status: http.StatusOK}
+	if _, _, err := rec.Hijack(); err == nil {
+		t.Error("Hijack sobre un writer que no lo soporta no devolvió error")
+	}
+	if rec.status != http.StatusOK {
+		t.Errorf("un Hijack fallido cambió el estado a %d", rec.status)
+	}
+}
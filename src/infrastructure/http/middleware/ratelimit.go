@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	jwtutil "apiGolan/src/infrastructure/jwt"
+	"apiGolan/src/infrastructure/logger"
+	"apiGolan/src/infrastructure/ratelimit"
+)
+
+// RateLimit rechaza con 429 y Retry-After cuando key(r) supera el límite.
+// Si el limitador falla (por ejemplo Redis caído) la petición pasa.
+func RateLimit(l ratelimit.Limiter, key func(*http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			allowed, retryAfter, err := l.Allow(r.Context(), key(r))
+			if err != nil {
+				logger.FromContext(r.Context()).Error("error en rate limiter", "error", err)
+			} else if !allowed {
+				TooManyRequests(w, retryAfter)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// TooManyRequests responde 429 con el header Retry-After en segundos
+func TooManyRequests(w http.ResponseWriter, retryAfter time.Duration) {
+	SetRetryAfter(w, retryAfter)
+	WriteError(w, "demasiados intentos, intenta más tarde", http.StatusTooManyRequests)
+}
+
+// SetRetryAfter escribe Retry-After redondeando hacia arriba (mínimo 1s)
+func SetRetryAfter(w http.ResponseWriter, d time.Duration) {
+	secs := int(math.Ceil(d.Seconds()))
+	if secs < 1 {
+		secs = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(secs))
+}
+
+// ByIP usa la IP del cliente como key
+func ByIP(r *http.Request) string {
+	return "ip:" + ClientIP(r)
+}
+
+// ByUser usa el usuario autenticado como key (debe ir después de Auth)
+func ByUser(r *http.Request) string {
+	if claims, ok := r.Context().Value(UserClaimsKey).(*jwtutil.Claims); ok {
+		return "user:" + strconv.Itoa(claims.UserID)
+	}
+	return ByIP(r)
+}
+
+// trustedProxies es la cantidad de proxies propios delante de la API
+// (TRUST_PROXY: "true" equivale a 1, un número indica cuántos; vacío = ninguno)
+var trustedProxies = parseTrustProxy(os.Getenv("TRUST_PROXY"))
+
+func parseTrustProxy(val string) int {
+	if val == "true" {
+		return 1
+	}
+	n, err := strconv.Atoi(val)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// ClientIP devuelve la IP del cliente. Solo usa X-Forwarded-For si hay proxies
+// de confianza: cada proxy agrega al final la IP de quien le habló, así que se
+// toma la entrada que agregó el primero de ellos contando desde la derecha.
+// Lo que está más a la izquierda lo escribe el cliente y no es confiable.
+func ClientIP(r *http.Request) string {
+	if ip := forwardedFor(r, trustedProxies); ip != "" {
+		return ip
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// forwardedFor devuelve la entrada de X-Forwarded-For que agregó el proxy
+// número hops contando desde la derecha ("" si hops es 0 o no hay header)
+func forwardedFor(r *http.Request, hops int) string {
+	if hops <= 0 {
+		return ""
+	}
+	var entries []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		for _, entry := range strings.Split(header, ",") {
+			if entry = strings.TrimSpace(entry); entry != "" {
+				entries = append(entries, entry)
+			}
+		}
+	}
+	if len(entries) == 0 {
+		return ""
+	}
+	// con menos entradas que proxies, la de más a la izquierda es la más lejana conocida
+	i := len(entries) - hops
+	if i < 0 {
+		i = 0
+	}
+	return entries[i]
+}
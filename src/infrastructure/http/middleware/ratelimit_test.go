@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestForwardedFor(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []string
+		hops    int
+		want    string
+	}{
+		{"sin proxies se ignora el header", []string{"1.1.1.1"}, 0, ""},
+		{"sin header", nil, 1, ""},
+		{"un proxy toma la de la derecha", []string{"6.6.6.6, 2.2.2.2"}, 1, "2.2.2.2"},
+		{"dos proxies saltan uno", []string{"6.6.6.6, 2.2.2.2, 10.0.0.1"}, 2, "2.2.2.2"},
+		{"varios headers se concatenan", []string{"6.6.6.6", "2.2.2.2"}, 1, "2.2.2.2"},
+		{"menos entradas que proxies", []string{"2.2.2.2"}, 3, "2.2.2.2"},
+		{"entradas vacías", []string{" , 2.2.2.2 ,"}, 1, "2.2.2.2"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		for _, h := range tt.headers {
+			r.Header.Add("X-Forwarded-For", h)
+		}
+		if got := forwardedFor(r, tt.hops); got != tt.want {
+			t.Errorf("%s: forwardedFor = %q, se esperaba %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseTrustProxy(t *testing.T) {
+	for val, want := range map[string]int{"": 0, "false": 0, "true": 1, "2": 2, "-1": 0} {
+		if got := parseTrustProxy(val); got != want {
+			t.Errorf("parseTrustProxy(%q) = %d, se esperaba %d", val, got, want)
+		}
+	}
+}
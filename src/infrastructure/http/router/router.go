@@ -2,10 +2,14 @@ package router
 
 import (
 	"net/http"
+	"strconv"
 
+	"apiGolan/src/domain"
 	"apiGolan/src/infrastructure/http/handler"
 	"apiGolan/src/infrastructure/http/middleware"
-	jwtutil "apiGolan/src/infrastructure/jwt"
+	"apiGolan/src/infrastructure/logger"
+	"apiGolan/src/infrastructure/metrics"
+	"apiGolan/src/infrastructure/ratelimit"
 	ws "apiGolan/src/infrastructure/websocket"
 
 	_ "apiGolan/docs"
@@ -17,44 +21,120 @@ var upgrader = websocket.Upgrader{
 	CheckOrigin: func(r *http.Request) bool { return true },
 }
 
+// Limits son los limitadores de las rutas sensibles a abuso
+type Limits struct {
+	Login    ratelimit.Limiter // por IP
+	Register ratelimit.Limiter // por IP
+	Forgot   ratelimit.Limiter // por IP
+	Answer   ratelimit.Limiter // por usuario
+}
+
 func Setup(
 	authH *handler.AuthHandler,
+	userH *handler.UserHandler,
 	roomH *handler.RoomHandler,
 	scoreH *handler.ScoreHandler,
 	questionH *handler.QuestionHandler,
+	healthH *handler.HealthHandler,
+	adminH *handler.AdminHandler,
+	oidcH *handler.OIDCHandler, // nil si el SSO no está configurado
+	apiKeyH *handler.APIKeyHandler,
+	historyH *handler.HistoryHandler,
+	leaderboardH *handler.LeaderboardHandler,
+	badgeH *handler.BadgeHandler,
+	apiKeys middleware.APIKeyAuthenticator,
+	accounts middleware.AccountChecker,
 	hub *ws.Hub,
+	limits Limits,
 ) http.Handler {
 	mux := http.NewServeMux()
 
+	// ── Salud y métricas ───────────────────────────────────
+	mux.HandleFunc("GET /healthz", healthH.Live)
+	mux.HandleFunc("GET /readyz", healthH.Ready)
+	mux.Handle("GET /metrics", metrics.Handler())
+
 	// ── Rutas públicas ─────────────────────────────────────
-	mux.HandleFunc("POST /auth/register", authH.Register)
-	mux.HandleFunc("POST /auth/login", authH.Login)
+	byIP := func(l ratelimit.Limiter, h http.HandlerFunc) http.Handler {
+		return middleware.RateLimit(l, middleware.ByIP)(h)
+	}
+	mux.Handle("POST /auth/register", byIP(limits.Register, authH.Register))
+	mux.Handle("POST /auth/login", byIP(limits.Login, authH.Login))
+	mux.Handle("POST /auth/password/forgot", byIP(limits.Forgot, authH.ForgotPassword))
+	mux.HandleFunc("POST /auth/password/reset", authH.ResetPassword)
+	mux.HandleFunc("POST /auth/verify", authH.VerifyEmail)
+	mux.HandleFunc("GET /.well-known/jwks.json", authH.JWKS)
+	if oidcH != nil {
+		mux.Handle("GET /auth/oidc/login", byIP(limits.Login, oidcH.Login))
+		mux.HandleFunc("GET /auth/oidc/callback", oidcH.Callback)
+	}
 
-	auth := middleware.Auth
+	auth := middleware.Auth(apiKeys, accounts)
 	onlyHost := func(h http.Handler) http.Handler {
-		return auth(middleware.OnlyHost(h))
+		return auth(middleware.RequireRole(domain.RoleHost)(h))
+	}
+	onlyAdmin := func(h http.Handler) http.Handler {
+		return auth(middleware.RequireRole(domain.RoleAdmin)(h))
+	}
+	// withKey además admite API keys con el scope indicado
+	withKey := func(scope domain.Scope, h http.Handler) http.Handler {
+		return middleware.AllowAPIKey(scope)(h)
 	}
 
 	// ── Cualquier usuario autenticado ──────────────────────
-	mux.Handle("GET /rooms/{code}", auth(http.HandlerFunc(roomH.GetRoom)))
+	mux.Handle("POST /auth/verify/resend", auth(http.HandlerFunc(authH.ResendVerification)))
+	mux.Handle("GET /me", auth(http.HandlerFunc(userH.GetMe)))
+	mux.Handle("PATCH /me", auth(http.HandlerFunc(userH.UpdateMe)))
+	mux.Handle("POST /me/password", auth(http.HandlerFunc(userH.ChangePassword)))
+	mux.Handle("DELETE /me", auth(http.HandlerFunc(userH.DeleteMe)))
+	mux.Handle("GET /me/history", auth(http.HandlerFunc(historyH.GetMyHistory)))
+	mux.Handle("GET /me/badges", auth(http.HandlerFunc(badgeH.GetMyBadges)))
+	mux.Handle("GET /badges", auth(http.HandlerFunc(badgeH.Catalog)))
+	mux.Handle("GET /leaderboards/{id}", withKey(domain.ScopeScoresRead, auth(http.HandlerFunc(leaderboardH.Get))))
+	mux.Handle("GET /leaderboards/{id}/ranking", withKey(domain.ScopeScoresRead, auth(http.HandlerFunc(leaderboardH.GetRanking))))
+	mux.Handle("GET /rooms/{code}", withKey(domain.ScopeRoomsRead, auth(http.HandlerFunc(roomH.GetRoom))))
 	mux.Handle("POST /rooms/{code}/join", auth(http.HandlerFunc(roomH.JoinRoom)))
-	mux.Handle("GET /rooms/{code}/ranking", auth(http.HandlerFunc(scoreH.GetRanking)))
-	mux.Handle("GET /rooms/{code}/participants", auth(http.HandlerFunc(roomH.GetParticipants)))
-	mux.Handle("GET /rooms/{code}/online", auth(http.HandlerFunc(roomH.GetOnlineUsers(hub))))
-	mux.Handle("GET /rooms/{code}/questions/current", auth(http.HandlerFunc(questionH.GetCurrentQuestion)))
-	mux.Handle("POST /rooms/{code}/answer", auth(http.HandlerFunc(questionH.SubmitAnswer)))
+	mux.Handle("GET /rooms/{code}/ranking", withKey(domain.ScopeScoresRead, auth(http.HandlerFunc(scoreH.GetRanking))))
+	mux.Handle("GET /rooms/{code}/ranking/me", withKey(domain.ScopeScoresRead, auth(http.HandlerFunc(scoreH.GetMyPosition))))
+	mux.Handle("GET /rooms/{code}/participants", withKey(domain.ScopeRoomsRead, auth(http.HandlerFunc(roomH.GetParticipants))))
+	mux.Handle("GET /rooms/{code}/online", withKey(domain.ScopeRoomsRead, auth(http.HandlerFunc(roomH.GetOnlineUsers(hub)))))
+	// SSE: mismas reglas que /ws (solo JWT), con el token en header o en ?token=
+	mux.Handle("GET /rooms/{code}/events", middleware.TokenFromQuery(auth(http.HandlerFunc(roomH.Events))))
+	mux.Handle("GET /rooms/{code}/questions/current", withKey(domain.ScopeQuestionsRead, auth(http.HandlerFunc(questionH.GetCurrentQuestion))))
+	mux.Handle("POST /rooms/{code}/answer", auth(middleware.RateLimit(limits.Answer, middleware.ByUser)(http.HandlerFunc(questionH.SubmitAnswer))))
 
 	// ── Solo host ──────────────────────────────────────────
-	mux.Handle("POST /rooms", onlyHost(http.HandlerFunc(roomH.CreateRoom)))
-	mux.Handle("PATCH /rooms/{code}/start", onlyHost(http.HandlerFunc(roomH.StartSession)))
-	mux.Handle("PATCH /rooms/{code}/end", onlyHost(http.HandlerFunc(roomH.EndSession)))
-	mux.Handle("POST /rooms/{code}/score", onlyHost(http.HandlerFunc(scoreH.AddPoints)))
-	mux.Handle("POST /rooms/{code}/score/reset", onlyHost(http.HandlerFunc(scoreH.ResetUserPoints)))
-	mux.Handle("POST /rooms/{code}/score/reset-all", onlyHost(http.HandlerFunc(scoreH.ResetAllPoints)))
-	mux.Handle("POST /rooms/{code}/kick", onlyHost(http.HandlerFunc(roomH.KickParticipant)))
-	mux.Handle("POST /rooms/{code}/questions", onlyHost(http.HandlerFunc(questionH.LaunchQuestion)))
-	mux.Handle("PATCH /rooms/{code}/questions/{question_id}/close", onlyHost(http.HandlerFunc(questionH.CloseQuestion)))
-	mux.Handle("GET /rooms/{code}/questions/{question_id}/answers", onlyHost(http.HandlerFunc(questionH.GetAnswers)))
+	mux.Handle("POST /rooms", withKey(domain.ScopeRoomsWrite, onlyHost(http.HandlerFunc(roomH.CreateRoom))))
+	mux.Handle("PATCH /rooms/{code}/start", withKey(domain.ScopeRoomsWrite, onlyHost(http.HandlerFunc(roomH.StartSession))))
+	mux.Handle("PATCH /rooms/{code}/end", withKey(domain.ScopeRoomsWrite, onlyHost(http.HandlerFunc(roomH.EndSession))))
+	mux.Handle("POST /rooms/{code}/score", withKey(domain.ScopeScoresWrite, onlyHost(http.HandlerFunc(scoreH.AddPoints))))
+	mux.Handle("POST /rooms/{code}/score/reset", withKey(domain.ScopeScoresWrite, onlyHost(http.HandlerFunc(scoreH.ResetUserPoints))))
+	mux.Handle("POST /rooms/{code}/score/reset-all", withKey(domain.ScopeScoresWrite, onlyHost(http.HandlerFunc(scoreH.ResetAllPoints))))
+	mux.Handle("GET /rooms/{code}/score/events", withKey(domain.ScopeScoresRead, onlyHost(http.HandlerFunc(scoreH.ListEvents))))
+	mux.Handle("POST /rooms/{code}/score/events/{id}/undo", withKey(domain.ScopeScoresWrite, onlyHost(http.HandlerFunc(scoreH.UndoEvent))))
+	mux.Handle("POST /rooms/{code}/score/reconcile", withKey(domain.ScopeScoresWrite, onlyHost(http.HandlerFunc(scoreH.Reconcile))))
+	mux.Handle("PATCH /rooms/{code}/ranking-mode", withKey(domain.ScopeRoomsWrite, onlyHost(http.HandlerFunc(scoreH.SetRankingMode))))
+	mux.Handle("PATCH /rooms/{code}/streak", withKey(domain.ScopeRoomsWrite, onlyHost(http.HandlerFunc(scoreH.SetStreak))))
+	mux.Handle("POST /rooms/{code}/kick", withKey(domain.ScopeRoomsWrite, onlyHost(http.HandlerFunc(roomH.KickParticipant))))
+	mux.Handle("POST /rooms/{code}/questions", withKey(domain.ScopeQuestionsWrite, onlyHost(http.HandlerFunc(questionH.LaunchQuestion))))
+	mux.Handle("PATCH /rooms/{code}/questions/{question_id}/close", withKey(domain.ScopeQuestionsWrite, onlyHost(http.HandlerFunc(questionH.CloseQuestion))))
+	mux.Handle("GET /rooms/{code}/questions/{question_id}/answers", withKey(domain.ScopeQuestionsRead, onlyHost(http.HandlerFunc(questionH.GetAnswers))))
+	mux.Handle("GET /rooms/{code}/questions/{question_id}/stats", withKey(domain.ScopeQuestionsRead, onlyHost(http.HandlerFunc(questionH.GetStats))))
+	mux.Handle("GET /users/{id}/history", withKey(domain.ScopeScoresRead, onlyHost(http.HandlerFunc(historyH.GetUserHistory))))
+	mux.Handle("GET /users/{id}/badges", withKey(domain.ScopeScoresRead, onlyHost(http.HandlerFunc(badgeH.GetUserBadges))))
+	mux.Handle("POST /leaderboards", withKey(domain.ScopeScoresWrite, onlyHost(http.HandlerFunc(leaderboardH.Create))))
+	mux.Handle("GET /leaderboards", withKey(domain.ScopeScoresRead, onlyHost(http.HandlerFunc(leaderboardH.List))))
+	mux.Handle("DELETE /leaderboards/{id}", withKey(domain.ScopeScoresWrite, onlyHost(http.HandlerFunc(leaderboardH.Delete))))
+	mux.Handle("POST /leaderboards/{id}/rooms", withKey(domain.ScopeScoresWrite, onlyHost(http.HandlerFunc(leaderboardH.AddRoom))))
+	mux.Handle("DELETE /leaderboards/{id}/rooms/{code}", withKey(domain.ScopeScoresWrite, onlyHost(http.HandlerFunc(leaderboardH.RemoveRoom))))
+	mux.Handle("POST /me/api-keys", onlyHost(http.HandlerFunc(apiKeyH.Create)))
+	mux.Handle("GET /me/api-keys", onlyHost(http.HandlerFunc(apiKeyH.List)))
+	mux.Handle("DELETE /me/api-keys/{id}", onlyHost(http.HandlerFunc(apiKeyH.Revoke)))
+
+	// ── Solo admin ─────────────────────────────────────────
+	mux.Handle("GET /admin/users", onlyAdmin(http.HandlerFunc(adminH.ListUsers)))
+	mux.Handle("PATCH /admin/users/{id}/role", onlyAdmin(http.HandlerFunc(adminH.ChangeRole)))
+	mux.Handle("POST /admin/invites", onlyAdmin(http.HandlerFunc(adminH.CreateInvite)))
 
 	// ── WebSocket ──────────────────────────────────────────
 	// ws://host:8080/ws?room=ABC123&token=<jwt>&name=Juan
@@ -66,17 +146,28 @@ func Setup(
 		tokenStr := r.URL.Query().Get("token")
 		name := r.URL.Query().Get("name") // nombre display del usuario
 
+		// Durante el apagado no se aceptan upgrades nuevos
+		if hub.IsShuttingDown() {
+			retry := int(hub.RetryAfter().Seconds())
+			if retry < 1 {
+				retry = 1
+			}
+			w.Header().Set("Retry-After", strconv.Itoa(retry))
+			middleware.WriteError(w, "servidor reiniciando, intenta de nuevo", http.StatusServiceUnavailable)
+			return
+		}
+
 		if roomCode == "" || tokenStr == "" {
-			http.Error(w, `{"error":"room y token son requeridos"}`, http.StatusBadRequest)
+			middleware.WriteError(w, "room y token son requeridos", http.StatusBadRequest)
 			return
 		}
 
 		// Validar JWT ANTES de hacer el upgrade a WebSocket
-		claims, err := jwtutil.Validate(tokenStr)
-		if err != nil {
-			http.Error(w, `{"error":"token inválido o expirado"}`, http.StatusUnauthorized)
+		claims, ok := middleware.ValidateSession(w, r, accounts, tokenStr)
+		if !ok {
 			return
 		}
+		logger.SetUserID(r.Context(), claims.UserID)
 
 		conn, err := upgrader.Upgrade(w, r, nil)
 		if err != nil {
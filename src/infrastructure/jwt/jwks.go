@@ -0,0 +1,54 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"sort"
+)
+
+// JWK es una clave pública en formato JSON Web Key (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`   // RSA: módulo
+	E   string `json:"e,omitempty"`   // RSA: exponente
+	Crv string `json:"crv,omitempty"` // OKP: curva
+	X   string `json:"x,omitempty"`   // OKP: clave pública
+}
+
+// JWKS devuelve las claves públicas de verificación, incluidas las retiradas,
+// para que otros servicios validen los tokens de QuickScore.
+// El secreto HS256 heredado nunca se publica.
+func JWKS() []JWK {
+	if keys == nil {
+		return []JWK{}
+	}
+
+	set := make([]JWK, 0, len(keys.byKid))
+	for _, k := range keys.byKid {
+		jwk := JWK{Kid: k.kid, Use: "sig", Alg: k.method.Alg()}
+		switch pub := k.public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = b64(pub.N.Bytes())
+			jwk.E = b64(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = b64(pub)
+		default:
+			continue
+		}
+		set = append(set, jwk)
+	}
+	sort.Slice(set, func(i, j int) bool { return set[i].Kid < set[j].Kid })
+	return set
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
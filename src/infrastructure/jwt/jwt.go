@@ -2,7 +2,6 @@ package jwt
 
 import (
 	"errors"
-	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -14,37 +13,54 @@ type Claims struct {
 	jwt.RegisteredClaims
 }
 
-func getSecret() []byte {
-	s := os.Getenv("JWT_SECRET")
-	if s == "" {
-		s = "secreto_dev_cambiar_en_produccion"
+// Generate crea un token JWT para el usuario dado, firmado con la clave activa
+func Generate(userID int, role string) (string, error) {
+	if keys == nil {
+		return "", errors.New("claves JWT no configuradas")
 	}
-	return []byte(s)
-}
 
-// Generate crea un token JWT para el usuario dado
-func Generate(userID int, role string) (string, error) {
 	claims := Claims{
 		UserID: userID,
 		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    keys.issuer,
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(getSecret())
+	token := jwt.NewWithClaims(keys.active.method, claims)
+	token.Header["kid"] = keys.active.kid
+	return token.SignedString(keys.active.signer)
 }
 
-// Validate valida el token y devuelve los claims si es correcto
+// Validate valida el token y devuelve los claims si es correcto.
+// La clave se elige por el kid del header y el algoritmo tiene que ser el
+// de esa clave, así un token no puede pedir HS256 con una clave pública.
 func Validate(tokenStr string) (*Claims, error) {
+	if keys == nil {
+		return nil, errors.New("claves JWT no configuradas")
+	}
+
 	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, func(t *jwt.Token) (interface{}, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+		kid, _ := t.Header["kid"].(string)
+		if kid == "" {
+			// Tokens HS256 emitidos antes de la migración a claves asimétricas
+			if keys.legacy != nil && t.Method == jwt.SigningMethodHS256 {
+				return keys.legacy, nil
+			}
+			return nil, errors.New("token sin kid")
+		}
+
+		k, ok := keys.byKid[kid]
+		if !ok {
+			return nil, errors.New("kid desconocido")
+		}
+		if t.Method.Alg() != k.method.Alg() {
 			return nil, errors.New("método de firma inválido")
 		}
-		return getSecret(), nil
-	})
+		return k.public, nil
+	}, jwt.WithValidMethods([]string{"RS256", "EdDSA", "HS256"}))
 	if err != nil {
 		return nil, err
 	}
@@ -53,6 +69,9 @@ func Validate(tokenStr string) (*Claims, error) {
 	if !ok || !token.Valid {
 		return nil, errors.New("token inválido")
 	}
+	if _, hasKid := token.Header["kid"]; hasKid && claims.Issuer != keys.issuer {
+		return nil, errors.New("emisor inválido")
+	}
 
 	return claims, nil
 }
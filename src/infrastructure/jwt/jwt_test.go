@@ -0,0 +1,329 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestMain(m *testing.M) {
+	// Setup loguea las claves que carga; en los tests sobra
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	os.Exit(m.Run())
+}
+
+var (
+	rsaOnce sync.Once
+	rsaKey  *rsa.PrivateKey
+)
+
+// testRSAKey genera una sola vez la clave RSA de 2048 bits de los tests
+func testRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	rsaOnce.Do(func() {
+		var err error
+		if rsaKey, err = rsa.GenerateKey(rand.Reader, 2048); err != nil {
+			t.Fatal(err)
+		}
+	})
+	return rsaKey
+}
+
+func testEdKey(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return priv
+}
+
+// writePrivate guarda la clave como <kid>.pem en PKCS#8
+func writePrivate(t *testing.T, dir, kid string, priv crypto.Signer) {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, filepath.Join(dir, kid+".pem"), "PRIVATE KEY", der)
+}
+
+// writePublic guarda solo la parte pública como <kid>.pub.pem
+func writePublic(t *testing.T, dir, kid string, pub crypto.PublicKey) {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, filepath.Join(dir, kid+".pub.pem"), "PUBLIC KEY", der)
+}
+
+func writePEM(t *testing.T, path, typ string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// setup llama a Setup con el entorno indicado y restaura las claves al terminar
+func setup(t *testing.T, dir, activeKid, secret, env string) error {
+	t.Helper()
+	prev := keys
+	t.Cleanup(func() { keys = prev })
+	t.Setenv("JWT_KEYS_DIR", dir)
+	t.Setenv("JWT_ACTIVE_KID", activeKid)
+	t.Setenv("JWT_SECRET", secret)
+	t.Setenv("JWT_ISSUER", "")
+	t.Setenv("APP_ENV", env)
+	return Setup()
+}
+
+func TestSetupKeys(t *testing.T) {
+	small, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		files     func(t *testing.T, dir string)
+		activeKid string
+		env       string
+		wantKid   string // "" si Setup debe fallar
+		wantAlg   string
+	}{
+		{"una RSA", func(t *testing.T, dir string) {
+			writePrivate(t, dir, "rsa-1", testRSAKey(t))
+		}, "", "production", "rsa-1", "RS256"},
+		{"una Ed25519", func(t *testing.T, dir string) {
+			writePrivate(t, dir, "ed-1", testEdKey(t))
+		}, "", "production", "ed-1", "EdDSA"},
+		{"RSA en PKCS#1", func(t *testing.T, dir string) {
+			writePEM(t, filepath.Join(dir, "rsa-1.pem"), "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(testRSAKey(t)))
+		}, "", "production", "rsa-1", "RS256"},
+		{"privada y retirada", func(t *testing.T, dir string) {
+			writePrivate(t, dir, "nueva", testEdKey(t))
+			writePublic(t, dir, "vieja", testRSAKey(t).Public())
+		}, "", "production", "nueva", "EdDSA"},
+		{"varias privadas con JWT_ACTIVE_KID", func(t *testing.T, dir string) {
+			writePrivate(t, dir, "vieja", testRSAKey(t))
+			writePrivate(t, dir, "nueva", testEdKey(t))
+		}, "nueva", "production", "nueva", "EdDSA"},
+		{"varias privadas sin JWT_ACTIVE_KID", func(t *testing.T, dir string) {
+			writePrivate(t, dir, "vieja", testRSAKey(t))
+			writePrivate(t, dir, "nueva", testEdKey(t))
+		}, "", "production", "", ""},
+		{"JWT_ACTIVE_KID sin privada", func(t *testing.T, dir string) {
+			writePrivate(t, dir, "nueva", testEdKey(t))
+			writePublic(t, dir, "vieja", testRSAKey(t).Public())
+		}, "vieja", "production", "", ""},
+		{"solo claves públicas", func(t *testing.T, dir string) {
+			writePublic(t, dir, "vieja", testRSAKey(t).Public())
+		}, "", "production", "", ""},
+		{"RSA de 1024 bits", func(t *testing.T, dir string) {
+			writePrivate(t, dir, "chica", small)
+		}, "", "production", "", ""},
+		{"directorio vacío", func(t *testing.T, dir string) {}, "", "production", "", ""},
+		{"PEM corrupto", func(t *testing.T, dir string) {
+			os.WriteFile(filepath.Join(dir, "rota.pem"), []byte("no es un PEM"), 0o600)
+		}, "", "production", "", ""},
+		{"sin directorio en producción", nil, "", "production", "", ""},
+		{"sin directorio en desarrollo", nil, "", "development", "dev", "EdDSA"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := ""
+			if tt.files != nil {
+				dir = t.TempDir()
+				tt.files(t, dir)
+			}
+			err := setup(t, dir, tt.activeKid, "", tt.env)
+			if tt.wantKid == "" {
+				if err == nil {
+					t.Errorf("Setup no falló; clave activa %q", keys.active.kid)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Setup: %v", err)
+			}
+			if keys.active.kid != tt.wantKid || keys.active.method.Alg() != tt.wantAlg {
+				t.Errorf("clave activa %s/%s, se esperaba %s/%s", keys.active.kid, keys.active.method.Alg(), tt.wantKid, tt.wantAlg)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	old, current := testRSAKey(t), testEdKey(t)
+	writePrivate(t, dir, "vieja", old)
+	writePrivate(t, dir, "nueva", current)
+
+	// tokens firmados por la clave vieja, antes de rotar
+	if err := setup(t, dir, "vieja", "", "production"); err != nil {
+		t.Fatal(err)
+	}
+	byOld, err := Generate(1, "host")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// rotación: la vieja queda solo con su pública
+	os.Remove(filepath.Join(dir, "vieja.pem"))
+	writePublic(t, dir, "vieja", old.Public())
+	if err := setup(t, dir, "", "secreto-heredado", "production"); err != nil {
+		t.Fatal(err)
+	}
+	byCurrent, err := Generate(2, "participant")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sign := func(method jwt.SigningMethod, kid string, key interface{}, claims Claims) string {
+		t.Helper()
+		token := jwt.NewWithClaims(method, claims)
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		s, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	claims := func(issuer string, expires time.Duration) Claims {
+		return Claims{UserID: 3, Role: "admin", RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    issuer,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expires)),
+		}}
+	}
+	oldPublic, _ := x509.MarshalPKIXPublicKey(old.Public())
+
+	tests := []struct {
+		name   string
+		token  string
+		wantID int // 0 si debe rechazarse
+	}{
+		{"clave activa", byCurrent, 2},
+		{"clave retirada", byOld, 1},
+		{"HS256 heredado sin kid", sign(jwt.SigningMethodHS256, "", []byte("secreto-heredado"), claims("", time.Hour)), 3},
+		{"HS256 con otro secreto", sign(jwt.SigningMethodHS256, "", []byte("otro"), claims("", time.Hour)), 0},
+		{"HS256 con la pública RSA como secreto", sign(jwt.SigningMethodHS256, "vieja", oldPublic, claims("quickscore", time.Hour)), 0},
+		{"kid desconocido", sign(jwt.SigningMethodEdDSA, "otra", current, claims("quickscore", time.Hour)), 0},
+		{"kid de otra clave", sign(jwt.SigningMethodEdDSA, "nueva", testEdKey(t), claims("quickscore", time.Hour)), 0},
+		{"otro emisor", sign(jwt.SigningMethodEdDSA, "nueva", current, claims("otro", time.Hour)), 0},
+		{"vencido", sign(jwt.SigningMethodEdDSA, "nueva", current, claims("quickscore", -time.Minute)), 0},
+		{"alg none", sign(jwt.SigningMethodNone, "nueva", jwt.UnsafeAllowNoneSignatureType, claims("quickscore", time.Hour)), 0},
+		{"basura", "no.es.token", 0},
+	}
+	for _, tt := range tests {
+		got, err := Validate(tt.token)
+		if tt.wantID == 0 {
+			if err == nil {
+				t.Errorf("%s: se aceptó el token (user %d)", tt.name, got.UserID)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got.UserID != tt.wantID {
+			t.Errorf("%s: user %d, se esperaba %d", tt.name, got.UserID, tt.wantID)
+		}
+	}
+
+	// sin la clave retirada en el directorio sus tokens dejan de valer
+	os.Remove(filepath.Join(dir, "vieja.pub.pem"))
+	if err := setup(t, dir, "", "", "production"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Validate(byOld); err == nil {
+		t.Error("se aceptó un token de una clave que ya no está en JWT_KEYS_DIR")
+	}
+}
+
+// jwkPublic reconstruye la clave pública a partir del JWK publicado
+func jwkPublic(t *testing.T, k JWK) interface{} {
+	t.Helper()
+	decode := func(s string) []byte {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	switch k.Kty {
+	case "RSA":
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(decode(k.N)), E: int(new(big.Int).SetBytes(decode(k.E)).Int64())}
+	case "OKP":
+		return ed25519.PublicKey(decode(k.X))
+	}
+	t.Fatalf("kty inesperado %q", k.Kty)
+	return nil
+}
+
+func TestJWKS(t *testing.T) {
+	dir := t.TempDir()
+	writePrivate(t, dir, "rsa", testRSAKey(t))
+	writePrivate(t, dir, "ed", testEdKey(t))
+	writePublic(t, dir, "retirada", testEdKey(t).Public())
+
+	tests := []struct {
+		active string
+		alg    string
+	}{
+		{"rsa", "RS256"},
+		{"ed", "EdDSA"},
+	}
+	for _, tt := range tests {
+		if err := setup(t, dir, tt.active, "secreto-heredado", "production"); err != nil {
+			t.Fatal(err)
+		}
+		set := JWKS()
+		byKid := map[string]JWK{}
+		for _, k := range set {
+			byKid[k.Kid] = k
+			if k.Use != "sig" {
+				t.Errorf("%s: use = %q", k.Kid, k.Use)
+			}
+		}
+		if len(set) != 3 || set[0].Kid != "ed" || set[1].Kid != "retirada" || set[2].Kid != "rsa" {
+			t.Errorf("JWKS = %+v, se esperaban ed, retirada y rsa en orden", set)
+		}
+		if byKid["rsa"].Kty != "RSA" || byKid["ed"].Crv != "Ed25519" || byKid["retirada"].Alg != "EdDSA" {
+			t.Errorf("JWKS con tipos incorrectos: %+v", set)
+		}
+
+		// un servicio externo verifica el token solo con lo publicado
+		token, err := Generate(5, "host")
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := jwt.ParseWithClaims(token, &Claims{}, func(tok *jwt.Token) (interface{}, error) {
+			k := byKid[tok.Header["kid"].(string)]
+			if tok.Method.Alg() != k.Alg {
+				t.Errorf("el token usa %s y el JWK dice %s", tok.Method.Alg(), k.Alg)
+			}
+			return jwkPublic(t, k), nil
+		})
+		if err != nil || parsed.Claims.(*Claims).UserID != 5 || parsed.Method.Alg() != tt.alg {
+			t.Errorf("activa %s: verificar con el JWKS = %v", tt.active, err)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// key es una clave de verificación identificada por su kid.
+// signer solo está presente si además tenemos la clave privada.
+type key struct {
+	kid    string
+	method jwt.SigningMethod // RS256 o EdDSA según el tipo de clave
+	public crypto.PublicKey
+	signer crypto.Signer
+}
+
+// keySet son las claves cargadas al arrancar
+type keySet struct {
+	active *key            // firma los tokens nuevos
+	byKid  map[string]*key // verifican; incluye claves retiradas
+	legacy []byte          // JWT_SECRET: solo verifica tokens HS256 anteriores a la migración
+	issuer string
+}
+
+var keys *keySet
+
+// Setup carga las claves de firma. Variables:
+//
+//	JWT_KEYS_DIR    directorio con <kid>.pem (clave privada RSA o Ed25519)
+//	                y <kid>.pub.pem (solo pública, para verificar claves retiradas)
+//	JWT_ACTIVE_KID  kid que firma los tokens nuevos (obligatorio si hay varias privadas)
+//	JWT_SECRET      opcional: sigue aceptando tokens HS256 durante la migración
+//	JWT_ISSUER      claim iss (quickscore por defecto)
+//
+// Sin JWT_KEYS_DIR solo arranca con APP_ENV=development, usando una clave
+// Ed25519 efímera: los tokens dejan de valer al reiniciar.
+func Setup() error {
+	ks := &keySet{
+		byKid:  map[string]*key{},
+		issuer: getEnv("JWT_ISSUER", "quickscore"),
+	}
+	if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		ks.legacy = []byte(secret)
+	}
+
+	dir := os.Getenv("JWT_KEYS_DIR")
+	if dir == "" {
+		if getEnv("APP_ENV", "production") != "development" {
+			return errors.New("JWT_KEYS_DIR es obligatorio fuera de APP_ENV=development")
+		}
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return err
+		}
+		k := &key{kid: "dev", method: jwt.SigningMethodEdDSA, public: priv.Public(), signer: priv}
+		ks.byKid[k.kid] = k
+		ks.active = k
+		slog.Warn("JWT: usando una clave efímera de desarrollo; los tokens no sobreviven a un reinicio")
+		keys = ks
+		return nil
+	}
+
+	if err := ks.loadDir(dir); err != nil {
+		return err
+	}
+	if err := ks.pickActive(os.Getenv("JWT_ACTIVE_KID")); err != nil {
+		return err
+	}
+
+	kids := make([]string, 0, len(ks.byKid))
+	for kid := range ks.byKid {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+	slog.Info("JWT: claves cargadas", "active_kid", ks.active.kid, "alg", ks.active.method.Alg(), "kids", kids)
+	keys = ks
+	return nil
+}
+
+// loadDir lee todas las claves PEM del directorio; el kid es el nombre del archivo
+func (ks *keySet) loadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("JWT_KEYS_DIR: %w", err)
+	}
+
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".pem") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+
+		kid := strings.TrimSuffix(name, ".pem")
+		isPublic := strings.HasSuffix(kid, ".pub")
+		kid = strings.TrimSuffix(kid, ".pub")
+
+		k, err := parseKey(kid, data, isPublic)
+		if err != nil {
+			return fmt.Errorf("clave JWT %s: %w", name, err)
+		}
+		// Si están la privada y la pública del mismo kid, gana la privada
+		if prev, ok := ks.byKid[kid]; ok && prev.signer != nil {
+			continue
+		}
+		ks.byKid[kid] = k
+	}
+
+	if len(ks.byKid) == 0 {
+		return fmt.Errorf("JWT_KEYS_DIR %s no contiene claves .pem", dir)
+	}
+	return nil
+}
+
+// pickActive elige la clave que firma: la indicada o la única privada disponible
+func (ks *keySet) pickActive(kid string) error {
+	if kid != "" {
+		k, ok := ks.byKid[kid]
+		if !ok || k.signer == nil {
+			return fmt.Errorf("JWT_ACTIVE_KID %q no tiene clave privada en JWT_KEYS_DIR", kid)
+		}
+		ks.active = k
+		return nil
+	}
+
+	for _, k := range ks.byKid {
+		if k.signer == nil {
+			continue
+		}
+		if ks.active != nil {
+			return errors.New("hay varias claves privadas: define JWT_ACTIVE_KID")
+		}
+		ks.active = k
+	}
+	if ks.active == nil {
+		return errors.New("JWT_KEYS_DIR no contiene ninguna clave privada para firmar")
+	}
+	return nil
+}
+
+// parseKey interpreta un PEM PKCS#8/PKCS#1 (privada) o PKIX (pública)
+func parseKey(kid string, data []byte, isPublic bool) (*key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("PEM inválido")
+	}
+
+	var parsed interface{}
+	var err error
+	switch {
+	case isPublic:
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case block.Type == "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	k := &key{kid: kid}
+	switch v := parsed.(type) {
+	case *rsa.PrivateKey:
+		k.method, k.public, k.signer = jwt.SigningMethodRS256, &v.PublicKey, v
+	case *rsa.PublicKey:
+		k.method, k.public = jwt.SigningMethodRS256, v
+	case ed25519.PrivateKey:
+		k.method, k.public, k.signer = jwt.SigningMethodEdDSA, v.Public(), v
+	case ed25519.PublicKey:
+		k.method, k.public = jwt.SigningMethodEdDSA, v
+	default:
+		return nil, fmt.Errorf("tipo de clave no soportado %T (usa RSA o Ed25519)", parsed)
+	}
+	if rsaKey, ok := k.public.(*rsa.PublicKey); ok && rsaKey.N.BitLen() < 2048 {
+		return nil, errors.New("las claves RSA deben tener al menos 2048 bits")
+	}
+	return k, nil
+}
+
+func getEnv(key, defaultVal string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return defaultVal
+}
@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Setup configura slog como logger por defecto según LOG_LEVEL
+// (debug, info, warn, error) y LOG_FORMAT (json o text).
+// El paquete log estándar también pasa a escribir por este logger.
+func Setup() *slog.Logger {
+	opts := &slog.HandlerOptions{Level: parseLevel(os.Getenv("LOG_LEVEL"))}
+
+	var h slog.Handler
+	if strings.ToLower(os.Getenv("LOG_FORMAT")) == "text" {
+		h = slog.NewTextHandler(os.Stdout, opts)
+	} else {
+		h = slog.NewJSONHandler(os.Stdout, opts)
+	}
+
+	l := slog.New(h)
+	slog.SetDefault(l)
+	return l
+}
+
+func parseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+type ctxKey struct{}
+
+// requestInfo son los datos de la petición que se agregan a cada log.
+// Es un puntero compartido para que los middlewares internos (por ejemplo
+// Auth) puedan completar el usuario y el access log externo lo vea.
+type requestInfo struct {
+	id     string
+	userID int
+}
+
+// WithRequestID guarda el request ID en el contexto
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, &requestInfo{id: id})
+}
+
+// RequestID devuelve el request ID del contexto ("" si no hay)
+func RequestID(ctx context.Context) string {
+	if info, ok := ctx.Value(ctxKey{}).(*requestInfo); ok {
+		return info.id
+	}
+	return ""
+}
+
+// SetUserID asocia el usuario autenticado a la petición en curso
+func SetUserID(ctx context.Context, userID int) {
+	if info, ok := ctx.Value(ctxKey{}).(*requestInfo); ok {
+		info.userID = userID
+	}
+}
+
+// UserID devuelve el usuario autenticado de la petición (0 si es anónima)
+func UserID(ctx context.Context) int {
+	if info, ok := ctx.Value(ctxKey{}).(*requestInfo); ok {
+		return info.userID
+	}
+	return 0
+}
+
+// FromContext devuelve el logger por defecto con el request ID de la petición
+func FromContext(ctx context.Context) *slog.Logger {
+	l := slog.Default()
+	if id := RequestID(ctx); id != "" {
+		l = l.With("request_id", id)
+	}
+	return l
+}
@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		level string
+		want  slog.Level
+	}{
+		{"", slog.LevelInfo},
+		{"debug", slog.LevelDebug},
+		{"DEBUG", slog.LevelDebug},
+		{"info", slog.LevelInfo},
+		{"warn", slog.LevelWarn},
+		{"warning", slog.LevelWarn},
+		{"error", slog.LevelError},
+		{"verbose", slog.LevelInfo},
+	}
+	for _, tt := range tests {
+		if got := parseLevel(tt.level); got != tt.want {
+			t.Errorf("parseLevel(%q) = %v, se esperaba %v", tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestRequestInfo(t *testing.T) {
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		userID int // lo que asigna SetUserID
+		wantID string
+		want   int
+	}{
+		{"sin request ID", context.Background(), 7, "", 0},
+		{"con request ID", WithRequestID(context.Background(), "req-1"), 7, "req-1", 7},
+		{"anónima", WithRequestID(context.Background(), "req-2"), 0, "req-2", 0},
+	}
+	for _, tt := range tests {
+		SetUserID(tt.ctx, tt.userID)
+		if got := RequestID(tt.ctx); got != tt.wantID {
+			t.Errorf("%s: RequestID = %q, se esperaba %q", tt.name, got, tt.wantID)
+		}
+		if got := UserID(tt.ctx); got != tt.want {
+			t.Errorf("%s: UserID = %d, se esperaba %d", tt.name, got, tt.want)
+		}
+	}
+}
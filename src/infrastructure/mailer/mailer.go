@@ -0,0 +1,134 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"apiGolan/src/domain"
+)
+
+// New crea el Mailer indicado en MAIL_DRIVER: "smtp" o "log". Solo con
+// APP_ENV=development se usa "log" si no se indica ninguno; en otro entorno
+// que falte MAIL_DRIVER es un error, así no se descartan correos sin avisar.
+func New() (domain.Mailer, error) {
+	from := getEnv("MAIL_FROM", "QuickScore <no-reply@quickscore.local>")
+
+	driver := os.Getenv("MAIL_DRIVER")
+	if driver == "" {
+		if getEnv("APP_ENV", "production") != "development" {
+			return nil, fmt.Errorf("MAIL_DRIVER es obligatorio fuera de APP_ENV=development")
+		}
+		driver = "log"
+	}
+
+	switch driver {
+	case "smtp":
+		host := os.Getenv("SMTP_HOST")
+		if host == "" {
+			return nil, fmt.Errorf("SMTP_HOST es requerido con MAIL_DRIVER=smtp")
+		}
+		return &SMTPMailer{
+			Addr:     net.JoinHostPort(host, getEnv("SMTP_PORT", "587")),
+			Host:     host,
+			Username: os.Getenv("SMTP_USER"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     from,
+		}, nil
+	case "log":
+		return &LogMailer{From: from, Path: os.Getenv("MAIL_LOG_FILE")}, nil
+	default:
+		return nil, fmt.Errorf("MAIL_DRIVER desconocido: %q", os.Getenv("MAIL_DRIVER"))
+	}
+}
+
+// SMTPMailer envía correos por SMTP (con STARTTLS si el servidor lo ofrece)
+type SMTPMailer struct {
+	Addr     string
+	Host     string
+	Username string
+	Password string
+	From     string
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, to, subject, body string) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	// net/smtp no acepta contexto: se corre aparte y se respeta la cancelación
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(m.Addr, auth, addressOf(m.From), []string{to}, buildMessage(m.From, to, subject, body))
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// LogMailer no envía nada: registra en el log el destinatario y el asunto y,
+// si Path no está vacío, agrega el correo completo a ese archivo. El cuerpo
+// nunca va al log porque lleva tokens de un solo uso (recuperar contraseña,
+// verificar email). Sirve para desarrollo local y pruebas.
+type LogMailer struct {
+	From string
+	Path string
+	mu   sync.Mutex
+}
+
+func (m *LogMailer) Send(ctx context.Context, to, subject, body string) error {
+	slog.Info("correo (no enviado, MAIL_DRIVER=log)", "to", to, "subject", subject)
+	if m.Path == "" {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f, err := os.OpenFile(m.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(buildMessage(m.From, to, subject, body), "\r\n\r\n"...))
+	return err
+}
+
+// buildMessage arma un mensaje RFC 5322 de texto plano
+func buildMessage(from, to, subject, body string) []byte {
+	var sb strings.Builder
+	sb.WriteString("From: " + from + "\r\n")
+	sb.WriteString("To: " + to + "\r\n")
+	sb.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n")
+	sb.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	sb.WriteString("MIME-Version: 1.0\r\n")
+	sb.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	sb.WriteString("\r\n")
+	sb.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return []byte(sb.String())
+}
+
+// addressOf extrae la dirección de "Nombre <correo>"
+func addressOf(from string) string {
+	if i := strings.LastIndex(from, "<"); i >= 0 {
+		return strings.TrimSuffix(from[i+1:], ">")
+	}
+	return from
+}
+
+func getEnv(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
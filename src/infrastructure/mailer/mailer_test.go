@@ -0,0 +1,59 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewRequiresDriverOutsideDevelopment(t *testing.T) {
+	t.Setenv("MAIL_DRIVER", "")
+	t.Setenv("APP_ENV", "production")
+	if _, err := New(); err == nil {
+		t.Error("sin MAIL_DRIVER en producción New debería fallar")
+	}
+
+	t.Setenv("APP_ENV", "development")
+	m, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.(*LogMailer); !ok {
+		t.Errorf("en desarrollo se esperaba LogMailer, se obtuvo %T", m)
+	}
+}
+
+func TestLogMailerDoesNotLogBody(t *testing.T) {
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	defer slog.SetDefault(prev)
+
+	path := filepath.Join(t.TempDir(), "mail.log")
+	m := &LogMailer{From: "QuickScore <no-reply@x.com>", Path: path}
+	body := "Abre https://app/reset-password?token=secreto123"
+	if err := m.Send(context.Background(), "ana@x.com", "Recuperar contraseña", body); err != nil {
+		t.Fatal(err)
+	}
+
+	logged := buf.String()
+	if strings.Contains(logged, "secreto123") {
+		t.Errorf("el log contiene el cuerpo del correo: %s", logged)
+	}
+	if !strings.Contains(logged, "ana@x.com") {
+		t.Errorf("el log no contiene el destinatario: %s", logged)
+	}
+
+	// el archivo explícito sí guarda el correo completo
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "secreto123") {
+		t.Error("MAIL_LOG_FILE no contiene el cuerpo del correo")
+	}
+}
@@ -0,0 +1,110 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	ws "apiGolan/src/infrastructure/websocket"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// registry propio para no mezclar con el registro global de Prometheus
+var registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "quickscore_http_requests_total",
+		Help: "Peticiones HTTP atendidas por ruta, método y código de estado.",
+	}, []string{"route", "method", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "quickscore_http_request_duration_seconds",
+		Help:    "Latencia de las peticiones HTTP por ruta.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	answersSubmitted = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "quickscore_answers_submitted_total",
+		Help: "Respuestas enviadas por los participantes.",
+	})
+
+	answersCorrect = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "quickscore_answers_correct_total",
+		Help: "Respuestas correctas enviadas por los participantes.",
+	})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests, httpDuration, answersSubmitted, answersCorrect,
+	)
+}
+
+// Register agrega las métricas que se leen en el momento del scrape:
+// el pool de conexiones de la base de datos y el estado del Hub
+func Register(db *sql.DB, hub *ws.Hub) {
+	registry.MustRegister(
+		collectors.NewDBStatsCollector(db, "quickscore"),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "quickscore_ws_connections",
+			Help: "Conexiones WebSocket activas.",
+		}, func() float64 {
+			conns, _ := hub.Stats()
+			return float64(conns)
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "quickscore_ws_rooms",
+			Help: "Salas con al menos un cliente WebSocket conectado.",
+		}, func() float64 {
+			_, rooms := hub.Stats()
+			return float64(rooms)
+		}),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "quickscore_ws_broadcast_drops_total",
+			Help: "Clientes desconectados en un broadcast por tener el buffer lleno.",
+		}, func() float64 {
+			_, _, disconnected := hub.Backpressure()
+			return float64(disconnected)
+		}),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "quickscore_ws_messages_dropped_total",
+			Help: "Mensajes descartados por buffer lleno (política drop_oldest).",
+		}, func() float64 {
+			dropped, _, _ := hub.Backpressure()
+			return float64(dropped)
+		}),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "quickscore_ws_messages_coalesced_total",
+			Help: "score_update reemplazados por uno más nuevo antes de enviarse (política coalesce).",
+		}, func() float64 {
+			_, coalesced, _ := hub.Backpressure()
+			return float64(coalesced)
+		}),
+	)
+}
+
+// Handler expone las métricas en formato Prometheus
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// ObserveRequest registra una petición HTTP atendida
+func ObserveRequest(route, method string, status int, elapsed time.Duration) {
+	httpRequests.WithLabelValues(route, method, strconv.Itoa(status)).Inc()
+	httpDuration.WithLabelValues(route, method).Observe(elapsed.Seconds())
+}
+
+// AnswerSubmitted registra una respuesta evaluada
+func AnswerSubmitted(correct bool) {
+	answersSubmitted.Inc()
+	if correct {
+		answersCorrect.Inc()
+	}
+}
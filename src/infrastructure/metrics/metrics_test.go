@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// value lee el valor actual de un contador
+func value(t *testing.T, c prometheus.Counter) float64 {
+	t.Helper()
+	var m dto.Metric
+	if err := c.Write(&m); err != nil {
+		t.Fatal(err)
+	}
+	return m.GetCounter().GetValue()
+}
+
+func TestAnswerSubmitted(t *testing.T) {
+	tests := []struct {
+		name        string
+		correct     bool
+		wantAnswers float64
+		wantCorrect float64
+	}{
+		{"correcta", true, 1, 1},
+		{"incorrecta", false, 1, 0},
+	}
+	for _, tt := range tests {
+		answers, correct := value(t, answersSubmitted), value(t, answersCorrect)
+		AnswerSubmitted(tt.correct)
+		if got := value(t, answersSubmitted) - answers; got != tt.wantAnswers {
+			t.Errorf("%s: answers_submitted sumó %v, se esperaba %v", tt.name, got, tt.wantAnswers)
+		}
+		if got := value(t, answersCorrect) - correct; got != tt.wantCorrect {
+			t.Errorf("%s: answers_correct sumó %v, se esperaba %v", tt.name, got, tt.wantCorrect)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"apiGolan/src/domain"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// Provider implementa domain.IdentityProvider contra cualquier proveedor
+// OpenID Connect con discovery (.well-known/openid-configuration).
+type Provider struct {
+	config   oauth2.Config
+	verifier *gooidc.IDTokenVerifier
+	client   *http.Client
+}
+
+// New configura el proveedor a partir de las variables OIDC_*.
+// Devuelve nil, nil si OIDC_ISSUER no está definido (SSO desactivado).
+//
+//	OIDC_ISSUER         URL del proveedor (también sirve un mock local)
+//	OIDC_CLIENT_ID      client id registrado en el proveedor
+//	OIDC_CLIENT_SECRET  opcional para clientes públicos (PKCE basta)
+//	OIDC_REDIRECT_URL   URL de /auth/oidc/callback tal como la ve el navegador
+//	OIDC_SCOPES         "openid email profile" por defecto
+func New(ctx context.Context) (*Provider, error) {
+	issuer := os.Getenv("OIDC_ISSUER")
+	if issuer == "" {
+		return nil, nil
+	}
+	clientID := os.Getenv("OIDC_CLIENT_ID")
+	redirectURL := os.Getenv("OIDC_REDIRECT_URL")
+	if clientID == "" || redirectURL == "" {
+		return nil, errors.New("OIDC_CLIENT_ID y OIDC_REDIRECT_URL son requeridos con OIDC_ISSUER")
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	provider, err := gooidc.NewProvider(gooidc.ClientContext(ctx, client), issuer)
+	if err != nil {
+		return nil, fmt.Errorf("discovery de %s: %w", issuer, err)
+	}
+
+	scopes := strings.Fields(os.Getenv("OIDC_SCOPES"))
+	if len(scopes) == 0 {
+		scopes = []string{gooidc.ScopeOpenID, "email", "profile"}
+	}
+
+	return &Provider{
+		config: oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+			Endpoint:     provider.Endpoint(),
+			RedirectURL:  redirectURL,
+			Scopes:       scopes,
+		},
+		verifier: provider.Verifier(&gooidc.Config{ClientID: clientID}),
+		client:   client,
+	}, nil
+}
+
+func (p *Provider) AuthCodeURL(state, verifier, nonce string) string {
+	return p.config.AuthCodeURL(state, gooidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier))
+}
+
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (*domain.ExternalProfile, error) {
+	ctx = gooidc.ClientContext(ctx, p.client)
+
+	token, err := p.config.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo canjear el código: %w", err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, errors.New("el proveedor no devolvió un id_token")
+	}
+
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("id_token inválido: %w", err)
+	}
+	if idToken.Nonce != nonce {
+		return nil, errors.New("id_token inválido: nonce no coincide")
+	}
+
+	var claims struct {
+		Email             string `json:"email"`
+		EmailVerified     bool   `json:"email_verified"`
+		Name              string `json:"name"`
+		PreferredUsername string `json:"preferred_username"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, err
+	}
+
+	name := claims.Name
+	if name == "" {
+		name = claims.PreferredUsername
+	}
+	return &domain.ExternalProfile{
+		Provider:      idToken.Issuer,
+		Subject:       idToken.Subject,
+		Email:         strings.ToLower(claims.Email),
+		EmailVerified: claims.EmailVerified,
+		Name:          name,
+	}, nil
+}
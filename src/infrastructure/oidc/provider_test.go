@@ -0,0 +1,111 @@
+package oidc
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	"apiGolan/src/infrastructure/oidc/oidctest"
+)
+
+// newTestProvider configura Provider contra el proveedor falso
+func newTestProvider(t *testing.T) (*Provider, *oidctest.Issuer) {
+	t.Helper()
+	iss := oidctest.NewIssuer("quickscore")
+	t.Cleanup(iss.Close)
+
+	t.Setenv("OIDC_ISSUER", iss.URL)
+	t.Setenv("OIDC_CLIENT_ID", "quickscore")
+	t.Setenv("OIDC_REDIRECT_URL", "http://app.test/auth/oidc/callback")
+	p, err := New(context.Background())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return p, iss
+}
+
+// login recorre /authorize y devuelve el código emitido
+func login(t *testing.T, p *Provider, iss *oidctest.Issuer, state, verifier, nonce string) string {
+	t.Helper()
+	callback, err := iss.Authorize(p.AuthCodeURL(state, verifier, nonce))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := callback.Query().Get("state"); got != state {
+		t.Fatalf("state en el callback = %q, se esperaba %q", got, state)
+	}
+	return callback.Query().Get("code")
+}
+
+func TestNewDisabledWithoutIssuer(t *testing.T) {
+	t.Setenv("OIDC_ISSUER", "")
+	p, err := New(context.Background())
+	if p != nil || err != nil {
+		t.Errorf("New sin OIDC_ISSUER = %v, %v; se esperaba nil, nil", p, err)
+	}
+}
+
+func TestAuthCodeURLSendsPKCEAndNonce(t *testing.T) {
+	p, _ := newTestProvider(t)
+	u, err := url.Parse(p.AuthCodeURL("st", "verifier", "nn"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" || q.Get("code_challenge") == "verifier" {
+		t.Errorf("falta el challenge S256: %s", u)
+	}
+	if q.Get("nonce") != "nn" || q.Get("state") != "st" {
+		t.Errorf("nonce o state incorrectos: %s", u)
+	}
+}
+
+func TestExchange(t *testing.T) {
+	p, iss := newTestProvider(t)
+	iss.SetUser(oidctest.User{Subject: "sub-1", Email: "Ana@Example.com", EmailVerified: true, Name: "Ana"})
+
+	code := login(t, p, iss, "st", "verifier-123", "nonce-1")
+	profile, err := p.Exchange(context.Background(), code, "verifier-123", "nonce-1")
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if profile.Provider != iss.URL || profile.Subject != "sub-1" || profile.Email != "ana@example.com" ||
+		!profile.EmailVerified || profile.Name != "Ana" {
+		t.Errorf("perfil = %+v", profile)
+	}
+
+	// el código es de un solo uso
+	if _, err := p.Exchange(context.Background(), code, "verifier-123", "nonce-1"); err == nil {
+		t.Error("se canjeó dos veces el mismo código")
+	}
+}
+
+func TestExchangeRejectsWrongVerifier(t *testing.T) {
+	p, iss := newTestProvider(t)
+	iss.SetUser(oidctest.User{Subject: "sub-1", Email: "ana@example.com"})
+
+	code := login(t, p, iss, "st", "verifier-123", "nonce-1")
+	if _, err := p.Exchange(context.Background(), code, "otro-verifier", "nonce-1"); err == nil {
+		t.Error("el canje con otro code_verifier debería fallar (PKCE)")
+	}
+}
+
+func TestExchangeRejectsNonceMismatch(t *testing.T) {
+	p, iss := newTestProvider(t)
+	iss.SetUser(oidctest.User{Subject: "sub-1", Email: "ana@example.com"})
+
+	// el nonce guardado en la cookie no coincide con el del ID token
+	code := login(t, p, iss, "st", "verifier-123", "nonce-1")
+	_, err := p.Exchange(context.Background(), code, "verifier-123", "nonce-2")
+	if err == nil || !strings.Contains(err.Error(), "nonce") {
+		t.Errorf("Exchange con otro nonce = %v, se esperaba un error de nonce", err)
+	}
+
+	// un ID token con un nonce distinto al pedido también se rechaza
+	iss.NonceOverride = "inyectado"
+	code = login(t, p, iss, "st", "verifier-123", "nonce-1")
+	if _, err := p.Exchange(context.Background(), code, "verifier-123", "nonce-1"); err == nil {
+		t.Error("se aceptó un ID token con un nonce distinto")
+	}
+}
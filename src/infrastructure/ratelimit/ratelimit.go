@@ -0,0 +1,21 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limiter decide si una acción identificada por key puede ejecutarse ahora.
+// Si no puede, retryAfter indica cuánto esperar antes de reintentar.
+type Limiter interface {
+	Allow(ctx context.Context, key string) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// Store es el subconjunto de comandos de Redis que necesita StoreLimiter
+// (INCR, PEXPIRE, PTTL). Cualquier cliente Redis puede satisfacerla; para
+// desarrollo y una sola instancia está MemoryStore.
+type Store interface {
+	Incr(ctx context.Context, key string) (int64, error)
+	PExpire(ctx context.Context, key string, ttl time.Duration) error
+	PTTL(ctx context.Context, key string) (time.Duration, error)
+}
@@ -0,0 +1,128 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// StoreLimiter limita con una ventana fija de duración window guardada en un
+// Store (Redis o MemoryStore), así varias instancias comparten el conteo.
+type StoreLimiter struct {
+	store  Store
+	limit  int64
+	window time.Duration
+	prefix string
+}
+
+func NewStoreLimiter(store Store, prefix string, limit int, window time.Duration) *StoreLimiter {
+	return &StoreLimiter{store: store, limit: int64(limit), window: window, prefix: prefix}
+}
+
+func (l *StoreLimiter) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	key = "ratelimit:" + l.prefix + ":" + key
+
+	count, err := l.store.Incr(ctx, key)
+	if err != nil {
+		return false, 0, err
+	}
+	if count == 1 {
+		if err := l.store.PExpire(ctx, key, l.window); err != nil {
+			return false, 0, err
+		}
+	}
+	if count <= l.limit {
+		return true, 0, nil
+	}
+
+	ttl, err := l.store.PTTL(ctx, key)
+	if err != nil {
+		return false, 0, err
+	}
+	if ttl <= 0 {
+		ttl = l.window
+	}
+	return false, ttl, nil
+}
+
+// MemoryStore implementa Store en memoria con la misma semántica que Redis
+type MemoryStore struct {
+	mu        sync.Mutex
+	items     map[string]*memoryItem
+	lastSweep time.Time
+}
+
+type memoryItem struct {
+	value   int64
+	expires time.Time // cero = sin expiración
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{items: make(map[string]*memoryItem), lastSweep: time.Now()}
+}
+
+// sweep elimina las keys expiradas una vez por minuto (debe llamarse con mu tomado)
+func (s *MemoryStore) sweep() {
+	now := time.Now()
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, item := range s.items {
+		if !item.expires.IsZero() && now.After(item.expires) {
+			delete(s.items, key)
+		}
+	}
+}
+
+// get devuelve la key si existe y no expiró (debe llamarse con mu tomado)
+func (s *MemoryStore) get(key string) *memoryItem {
+	item, ok := s.items[key]
+	if !ok {
+		return nil
+	}
+	if !item.expires.IsZero() && time.Now().After(item.expires) {
+		delete(s.items, key)
+		return nil
+	}
+	return item
+}
+
+func (s *MemoryStore) Incr(ctx context.Context, key string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep()
+	item := s.get(key)
+	if item == nil {
+		item = &memoryItem{}
+		s.items[key] = item
+	}
+	item.value++
+	return item.value, nil
+}
+
+func (s *MemoryStore) PExpire(ctx context.Context, key string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if item := s.get(key); item != nil {
+		item.expires = time.Now().Add(ttl)
+	}
+	return nil
+}
+
+// PTTL devuelve -2 si la key no existe y -1 si no expira, igual que Redis
+func (s *MemoryStore) PTTL(ctx context.Context, key string) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item := s.get(key)
+	if item == nil {
+		return -2, nil
+	}
+	if item.expires.IsZero() {
+		return -1, nil
+	}
+	return time.Until(item.expires), nil
+}
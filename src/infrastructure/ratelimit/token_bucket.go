@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// TokenBucket es un limitador en memoria: cada key tiene un balde de
+// capacidad limit que se rellena a razón de limit tokens cada per.
+// Solo sirve para una instancia; con varias réplicas usar StoreLimiter.
+type TokenBucket struct {
+	mu        sync.Mutex
+	limit     float64
+	rate      float64 // tokens por segundo
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func NewTokenBucket(limit int, per time.Duration) *TokenBucket {
+	return &TokenBucket{
+		limit:     float64(limit),
+		rate:      float64(limit) / per.Seconds(),
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+func (l *TokenBucket) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.limit, last: now}
+		l.buckets[key] = b
+	}
+
+	// Rellenar según el tiempo transcurrido
+	b.tokens += now.Sub(b.last).Seconds() * l.rate
+	if b.tokens > l.limit {
+		b.tokens = l.limit
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+		return false, wait, nil
+	}
+	b.tokens--
+	return true, 0, nil
+}
+
+// sweep descarta baldes llenos para que el mapa no crezca sin límite
+func (l *TokenBucket) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.limit {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"apiGolan/src/domain"
+	infradb "apiGolan/src/infrastructure/db"
+)
+
+// APIKeyRepo implementa domain.APIKeyRepository usando MySQL, PostgreSQL o SQLite.
+// Los scopes se guardan en una columna separados por comas.
+type APIKeyRepo struct {
+	db *infradb.DB
+}
+
+func NewAPIKeyRepo(db *infradb.DB) domain.APIKeyRepository {
+	return &APIKeyRepo{db: db}
+}
+
+const apiKeyColumns = `id, user_id, name, prefix, key_hash, scopes, last_used_at, revoked_at, created_at`
+
+func scanAPIKey(row interface{ Scan(...interface{}) error }) (*domain.APIKey, error) {
+	k := &domain.APIKey{}
+	var scopes string
+	var lastUsed, revoked sql.NullTime
+	err := row.Scan(&k.ID, &k.UserID, &k.Name, &k.Prefix, &k.KeyHash, &scopes, &lastUsed, &revoked, &k.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range strings.Split(scopes, ",") {
+		if s != "" {
+			k.Scopes = append(k.Scopes, domain.Scope(s))
+		}
+	}
+	if lastUsed.Valid {
+		k.LastUsedAt = &lastUsed.Time
+	}
+	if revoked.Valid {
+		k.RevokedAt = &revoked.Time
+	}
+	return k, nil
+}
+
+func (r *APIKeyRepo) Create(ctx context.Context, k *domain.APIKey) error {
+	scopes := make([]string, len(k.Scopes))
+	for i, s := range k.Scopes {
+		scopes[i] = string(s)
+	}
+	query := `INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes) VALUES (?, ?, ?, ?, ?)`
+	id, err := r.db.Insert(ctx, query, k.UserID, k.Name, k.Prefix, k.KeyHash, strings.Join(scopes, ","))
+	if err != nil {
+		return err
+	}
+	k.ID = int(id)
+	k.CreatedAt = time.Now().UTC()
+	return nil
+}
+
+func (r *APIKeyRepo) FindByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = ?`
+	return scanAPIKey(r.db.QueryRowContext(ctx, query, hash))
+}
+
+// ListByUser devuelve las keys del usuario, revocadas incluidas, de la más nueva a la más vieja
+func (r *APIKeyRepo) ListByUser(ctx context.Context, userID int) ([]domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE user_id = ? ORDER BY id DESC`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []domain.APIKey{}
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *k)
+	}
+	return keys, rows.Err()
+}
+
+func (r *APIKeyRepo) CountActive(ctx context.Context, userID int) (int, error) {
+	var n int
+	query := `SELECT COUNT(*) FROM api_keys WHERE user_id = ? AND revoked_at IS NULL`
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&n)
+	return n, err
+}
+
+func (r *APIKeyRepo) Revoke(ctx context.Context, userID, id int) (bool, error) {
+	query := `UPDATE api_keys SET revoked_at = ? WHERE id = ? AND user_id = ? AND revoked_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, time.Now().UTC(), id, userID)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+func (r *APIKeyRepo) TouchLastUsed(ctx context.Context, id int, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE api_keys SET last_used_at = ? WHERE id = ?`, at.UTC(), id)
+	return err
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"apiGolan/src/domain"
+	infradb "apiGolan/src/infrastructure/db"
+)
+
+// BadgeRepo implementa domain.BadgeRepository usando MySQL, PostgreSQL o SQLite
+type BadgeRepo struct {
+	db *infradb.DB
+}
+
+func NewBadgeRepo(db *infradb.DB) domain.BadgeRepository {
+	return &BadgeRepo{db: db}
+}
+
+// Award guarda la medalla salvo que el usuario ya la tenga en esa sala; la
+// clave única resuelve dos evaluaciones simultáneas de la misma regla
+func (r *BadgeRepo) Award(ctx context.Context, b *domain.UserBadge) (bool, error) {
+	b.EarnedAt = time.Now().UTC()
+	query := `INSERT INTO user_badges (user_id, badge, room_id, question_id, earned_at) VALUES (?, ?, ?, ?, ?) ` +
+		r.db.Ignore("user_id, badge, room_id")
+	result, err := r.db.ExecContext(ctx, query, b.UserID, b.Badge, b.RoomID, nullID(b.QuestionID), b.EarnedAt)
+	if err != nil {
+		return false, err
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+	err = r.db.QueryRowContext(ctx, `SELECT id FROM user_badges WHERE user_id = ? AND badge = ? AND room_id = ?`,
+		b.UserID, b.Badge, b.RoomID).Scan(&b.ID)
+	return err == nil, err
+}
+
+func (r *BadgeRepo) ListByUser(ctx context.Context, userID, hostID int) ([]domain.UserBadge, error) {
+	query := `
+		SELECT b.id, b.user_id, b.badge, b.room_id, r.code, b.question_id, b.earned_at
+		FROM user_badges b
+		JOIN rooms r ON r.id = b.room_id
+		WHERE b.user_id = ?`
+	args := []interface{}{userID}
+	if hostID != 0 {
+		query += ` AND r.host_id = ?`
+		args = append(args, hostID)
+	}
+	query += ` ORDER BY b.earned_at DESC, b.id DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	badges := []domain.UserBadge{}
+	for rows.Next() {
+		var b domain.UserBadge
+		var questionID sql.NullInt64
+		if err := rows.Scan(&b.ID, &b.UserID, &b.Badge, &b.RoomID, &b.RoomCode, &questionID, &b.EarnedAt); err != nil {
+			return nil, err
+		}
+		b.QuestionID = int(questionID.Int64)
+		badges = append(badges, b)
+	}
+	return badges, rows.Err()
+}
+
+func (r *BadgeRepo) FirstCorrectQuestion(ctx context.Context, userID int) (int, error) {
+	query := `SELECT question_id FROM answers WHERE user_id = ? AND is_correct ORDER BY id LIMIT 1`
+	return r.firstID(ctx, query, userID)
+}
+
+// FirstCorrectUser usa el orden de llegada: el tiempo de respuesta se mide
+// desde que se lanzó la pregunta, así que la primera correcta es la más rápida
+func (r *BadgeRepo) FirstCorrectUser(ctx context.Context, questionID int) (int, error) {
+	query := `SELECT user_id FROM answers WHERE question_id = ? AND is_correct ORDER BY id LIMIT 1`
+	return r.firstID(ctx, query, questionID)
+}
+
+// firstID devuelve el primer entero de la consulta; 0 si no hay filas
+func (r *BadgeRepo) firstID(ctx context.Context, query string, args ...interface{}) (int, error) {
+	var id int
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return id, err
+}
+
+// SessionResults cuenta las preguntas de la sala y, por cada participante
+// actual, cuántas respondió y cuántas acertó
+func (r *BadgeRepo) SessionResults(ctx context.Context, roomID int) (int, []domain.SessionResult, error) {
+	var questions int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM questions WHERE room_id = ?`, roomID).Scan(&questions); err != nil {
+		return 0, nil, err
+	}
+
+	query := `
+		SELECT p.user_id, COUNT(a.id), COALESCE(SUM(CASE WHEN a.is_correct THEN 1 ELSE 0 END), 0)
+		FROM participants p
+		LEFT JOIN questions q ON q.room_id = p.room_id
+		LEFT JOIN answers a ON a.question_id = q.id AND a.user_id = p.user_id
+		WHERE p.room_id = ?
+		GROUP BY p.user_id`
+	rows, err := r.db.QueryContext(ctx, query, roomID)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+
+	var results []domain.SessionResult
+	for rows.Next() {
+		var res domain.SessionResult
+		if err := rows.Scan(&res.UserID, &res.Answered, &res.Correct); err != nil {
+			return 0, nil, err
+		}
+		results = append(results, res)
+	}
+	return questions, results, rows.Err()
+}
+
+func (r *BadgeRepo) ScoreTimeline(ctx context.Context, roomID int) ([]domain.ScoreEvent, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT user_id, delta FROM score_events WHERE room_id = ? ORDER BY id`, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []domain.ScoreEvent
+	for rows.Next() {
+		ev := domain.ScoreEvent{RoomID: roomID}
+		if err := rows.Scan(&ev.UserID, &ev.Delta); err != nil {
+			return nil, err
+		}
+		events = append(events, ev)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"math"
+
+	"apiGolan/src/domain"
+	infradb "apiGolan/src/infrastructure/db"
+)
+
+// HistoryRepo implementa domain.HistoryRepository usando MySQL, PostgreSQL o SQLite
+type HistoryRepo struct {
+	db *infradb.DB
+}
+
+func NewHistoryRepo(db *infradb.DB) domain.HistoryRepository {
+	return &HistoryRepo{db: db}
+}
+
+// FindByUser arma una fila por sala a partir de participants (idx_participants_user),
+// con los puntos de scores y las respuestas a las preguntas de la sala
+// (idx_questions_room y uq_answer_question_user). El puesto usa idx_scores_room_points.
+func (r *HistoryRepo) FindByUser(ctx context.Context, userID, hostID int) ([]domain.SessionHistory, error) {
+	query := `
+		SELECT r.id, r.code, r.status, p.joined_at,
+		       COALESCE(s.points, 0),
+		       1 + CASE WHEN r.ranking_mode = 'dense'
+		                THEN (SELECT COUNT(DISTINCT s2.points) FROM scores s2 WHERE s2.room_id = r.id AND s2.points > COALESCE(s.points, 0))
+		                ELSE (SELECT COUNT(*) FROM scores s2 WHERE s2.room_id = r.id AND s2.points > COALESCE(s.points, 0))
+		           END,
+		       (SELECT COUNT(*) FROM participants p2 WHERE p2.room_id = r.id),
+		       (SELECT COUNT(*) FROM questions q2 WHERE q2.room_id = r.id),
+		       COUNT(a.id),
+		       COALESCE(SUM(CASE WHEN a.is_correct THEN 1 ELSE 0 END), 0),
+		       COUNT(a.response_ms),
+		       AVG(a.response_ms)
+		FROM participants p
+		JOIN rooms r ON r.id = p.room_id
+		LEFT JOIN scores s ON s.room_id = p.room_id AND s.user_id = p.user_id
+		LEFT JOIN questions q ON q.room_id = p.room_id
+		LEFT JOIN answers a ON a.question_id = q.id AND a.user_id = p.user_id
+		WHERE p.user_id = ?`
+	args := []interface{}{userID}
+	if hostID != 0 {
+		query += ` AND r.host_id = ?`
+		args = append(args, hostID)
+	}
+	query += `
+		GROUP BY r.id, r.code, r.status, r.ranking_mode, p.joined_at, s.points
+		ORDER BY p.joined_at DESC, r.id DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []domain.SessionHistory{}
+	for rows.Next() {
+		var h domain.SessionHistory
+		var avgResponse sql.NullFloat64
+		if err := rows.Scan(
+			&h.RoomID, &h.RoomCode, &h.RoomStatus, &h.JoinedAt,
+			&h.Points, &h.Rank, &h.Participants, &h.Questions,
+			&h.Answered, &h.Correct, &h.TimedAnswers, &avgResponse,
+		); err != nil {
+			return nil, err
+		}
+		h.Accuracy = percent(h.Correct, h.Answered)
+		h.AvgResponseMS = int64(math.Round(avgResponse.Float64))
+		sessions = append(sessions, h)
+	}
+	return sessions, rows.Err()
+}
+
+// percent devuelve part/total en porcentaje con un decimal (0 si total es 0)
+func percent(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)*1000/float64(total)) / 10
+}
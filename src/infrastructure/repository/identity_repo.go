@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"apiGolan/src/domain"
+	infradb "apiGolan/src/infrastructure/db"
+)
+
+// IdentityRepo implementa domain.IdentityRepository usando MySQL, PostgreSQL o SQLite
+type IdentityRepo struct {
+	db *infradb.DB
+}
+
+func NewIdentityRepo(db *infradb.DB) domain.IdentityRepository {
+	return &IdentityRepo{db: db}
+}
+
+func (r *IdentityRepo) FindBySubject(ctx context.Context, provider, subject string) (*domain.ExternalIdentity, error) {
+	id := &domain.ExternalIdentity{}
+	query := `SELECT id, user_id, provider, subject, email, created_at FROM user_identities WHERE provider = ? AND subject = ?`
+	err := r.db.QueryRowContext(ctx, query, provider, subject).Scan(
+		&id.ID, &id.UserID, &id.Provider, &id.Subject, &id.Email, &id.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return id, nil
+}
+
+func (r *IdentityRepo) Create(ctx context.Context, identity *domain.ExternalIdentity) error {
+	query := `INSERT INTO user_identities (user_id, provider, subject, email) VALUES (?, ?, ?, ?)`
+	id, err := r.db.Insert(ctx, query, identity.UserID, identity.Provider, identity.Subject, identity.Email)
+	if err != nil {
+		return err
+	}
+	identity.ID = int(id)
+	return nil
+}
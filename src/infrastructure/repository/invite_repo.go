@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"apiGolan/src/domain"
+	infradb "apiGolan/src/infrastructure/db"
+)
+
+// InviteRepo implementa domain.InviteRepository usando MySQL, PostgreSQL o SQLite
+type InviteRepo struct {
+	db *infradb.DB
+}
+
+func NewInviteRepo(db *infradb.DB) domain.InviteRepository {
+	return &InviteRepo{db: db}
+}
+
+func (r *InviteRepo) Create(ctx context.Context, inv *domain.InviteCode) error {
+	var expiresAt interface{}
+	if inv.ExpiresAt != nil {
+		expiresAt = inv.ExpiresAt.UTC()
+	}
+	query := `INSERT INTO invite_codes (code, role, uses_left, expires_at, created_by) VALUES (?, ?, ?, ?, ?)`
+	id, err := r.db.Insert(ctx, query, inv.Code, inv.Role, inv.UsesLeft, expiresAt, inv.CreatedBy)
+	if err != nil {
+		return err
+	}
+	inv.ID = int(id)
+	inv.CreatedAt = time.Now().UTC()
+	return nil
+}
+
+// Consume descuenta un uso del código si sigue vigente.
+// El UPDATE condicional hace que dos registros simultáneos no puedan
+// gastar el mismo último uso.
+func (r *InviteRepo) Consume(ctx context.Context, code string) (*domain.InviteCode, error) {
+	query := `
+		UPDATE invite_codes SET uses_left = uses_left - 1
+		WHERE code = ? AND uses_left > 0 AND (expires_at IS NULL OR expires_at > ?)`
+	result, err := r.db.ExecContext(ctx, query, code, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, nil
+	}
+
+	inv := &domain.InviteCode{}
+	var expiresAt sql.NullTime
+	query = `SELECT id, code, role, uses_left, expires_at, created_by, created_at FROM invite_codes WHERE code = ?`
+	err = r.db.QueryRowContext(ctx, query, code).Scan(
+		&inv.ID, &inv.Code, &inv.Role, &inv.UsesLeft, &expiresAt, &inv.CreatedBy, &inv.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if expiresAt.Valid {
+		inv.ExpiresAt = &expiresAt.Time
+	}
+	return inv, nil
+}
@@ -0,0 +1,402 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"apiGolan/src/domain"
+	infradb "apiGolan/src/infrastructure/db"
+)
+
+// LeaderboardRepo implementa domain.LeaderboardRepository usando MySQL, PostgreSQL o SQLite.
+// leaderboard_points guarda una fila por leaderboard, periodo y usuario; se
+// actualiza con cada score_event (applyLeaderboards) en vez de recalcularse.
+type LeaderboardRepo struct {
+	db *infradb.DB
+}
+
+func NewLeaderboardRepo(db *infradb.DB) domain.LeaderboardRepository {
+	return &LeaderboardRepo{db: db}
+}
+
+const leaderboardColumns = `id, host_id, name, starts_at, ends_at, created_at`
+
+func scanLeaderboard(row interface{ Scan(...interface{}) error }) (*domain.Leaderboard, error) {
+	lb := &domain.Leaderboard{Rooms: []string{}}
+	var startsAt, endsAt sql.NullTime
+	err := row.Scan(&lb.ID, &lb.HostID, &lb.Name, &startsAt, &endsAt, &lb.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if startsAt.Valid {
+		lb.StartsAt = &startsAt.Time
+	}
+	if endsAt.Valid {
+		lb.EndsAt = &endsAt.Time
+	}
+	return lb, nil
+}
+
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
+
+func (r *LeaderboardRepo) Create(ctx context.Context, lb *domain.Leaderboard) error {
+	query := `INSERT INTO leaderboards (host_id, name, starts_at, ends_at) VALUES (?, ?, ?, ?)`
+	id, err := r.db.Insert(ctx, query, lb.HostID, lb.Name, nullTime(lb.StartsAt), nullTime(lb.EndsAt))
+	if err != nil {
+		return err
+	}
+	lb.ID = int(id)
+	lb.CreatedAt = time.Now().UTC()
+	if lb.Rooms == nil {
+		lb.Rooms = []string{}
+	}
+	return nil
+}
+
+func (r *LeaderboardRepo) FindByID(ctx context.Context, id int) (*domain.Leaderboard, error) {
+	query := `SELECT ` + leaderboardColumns + ` FROM leaderboards WHERE id = ?`
+	lb, err := scanLeaderboard(r.db.QueryRowContext(ctx, query, id))
+	if err != nil || lb == nil {
+		return lb, err
+	}
+	rooms, err := r.roomCodes(ctx, `WHERE lr.leaderboard_id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	lb.Rooms = append(lb.Rooms, rooms[id]...)
+	return lb, nil
+}
+
+// ListByHost devuelve los leaderboards del host, del más nuevo al más viejo
+func (r *LeaderboardRepo) ListByHost(ctx context.Context, hostID int) ([]domain.Leaderboard, error) {
+	query := `SELECT ` + leaderboardColumns + ` FROM leaderboards WHERE host_id = ? ORDER BY id DESC`
+	rows, err := r.db.QueryContext(ctx, query, hostID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	boards := []domain.Leaderboard{}
+	for rows.Next() {
+		lb, err := scanLeaderboard(rows)
+		if err != nil {
+			return nil, err
+		}
+		boards = append(boards, *lb)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rooms, err := r.roomCodes(ctx, `JOIN leaderboards l ON l.id = lr.leaderboard_id WHERE l.host_id = ?`, hostID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range boards {
+		boards[i].Rooms = append(boards[i].Rooms, rooms[boards[i].ID]...)
+	}
+	return boards, nil
+}
+
+// roomCodes devuelve los códigos de sala agrupados por leaderboard
+func (r *LeaderboardRepo) roomCodes(ctx context.Context, where string, args ...interface{}) (map[int][]string, error) {
+	query := `SELECT lr.leaderboard_id, rm.code FROM leaderboard_rooms lr
+	          JOIN rooms rm ON rm.id = lr.room_id ` + where + ` ORDER BY lr.added_at, rm.id`
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	codes := map[int][]string{}
+	for rows.Next() {
+		var id int
+		var code string
+		if err := rows.Scan(&id, &code); err != nil {
+			return nil, err
+		}
+		codes[id] = append(codes[id], code)
+	}
+	return codes, rows.Err()
+}
+
+// Delete borra el leaderboard; sus salas y puntos se borran en cascada
+func (r *LeaderboardRepo) Delete(ctx context.Context, id int) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM leaderboards WHERE id = ?`, id)
+	return err
+}
+
+// AddRoom incluye la sala y suma al leaderboard lo que ya tiene en el ledger.
+// Los scores de la sala se bloquean primero: un score_event simultáneo o ya
+// ve la sala en el leaderboard o queda en el ledger antes de sumarlo, nunca
+// las dos cosas ni ninguna.
+func (r *LeaderboardRepo) AddRoom(ctx context.Context, lb *domain.Leaderboard, roomID int) (bool, error) {
+	added := false
+	err := r.db.InTx(ctx, func(ctx context.Context, tx *infradb.Tx) error {
+		if _, err := lockScores(ctx, tx, roomID, 0); err != nil {
+			return err
+		}
+		var count int
+		query := `SELECT COUNT(*) FROM leaderboard_rooms WHERE leaderboard_id = ? AND room_id = ?`
+		if err := tx.QueryRowContext(ctx, query, lb.ID, roomID).Scan(&count); err != nil || count > 0 {
+			return err
+		}
+		query = `INSERT INTO leaderboard_rooms (leaderboard_id, room_id) VALUES (?, ?)`
+		if _, err := tx.ExecContext(ctx, query, lb.ID, roomID); err != nil {
+			return err
+		}
+		added = true
+		_, err := backfillRoom(ctx, tx, lb, roomID, 1)
+		return err
+	})
+	return added && err == nil, err
+}
+
+// RemoveRoom quita la sala y resta del leaderboard lo que aportó
+func (r *LeaderboardRepo) RemoveRoom(ctx context.Context, lb *domain.Leaderboard, roomID int) (bool, error) {
+	removed := false
+	err := r.db.InTx(ctx, func(ctx context.Context, tx *infradb.Tx) error {
+		if _, err := lockScores(ctx, tx, roomID, 0); err != nil {
+			return err
+		}
+		query := `DELETE FROM leaderboard_rooms WHERE leaderboard_id = ? AND room_id = ?`
+		result, err := tx.ExecContext(ctx, query, lb.ID, roomID)
+		if err != nil {
+			return err
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return nil
+		}
+		removed = true
+		keys, err := backfillRoom(ctx, tx, lb, roomID, -1)
+		if err != nil {
+			return err
+		}
+		return pruneLeaderboardPoints(ctx, tx, lb, keys)
+	})
+	return removed && err == nil, err
+}
+
+// pruneLeaderboardPoints borra las filas de keys cuyo usuario ya no tiene
+// eventos en ese periodo en ninguna de las salas que quedan en el leaderboard.
+// Quien sigue jugando en otra sala conserva su fila aunque sume 0.
+func pruneLeaderboardPoints(ctx context.Context, tx *infradb.Tx, lb *domain.Leaderboard, keys map[periodKey]bool) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	users := map[int]bool{}
+	args := []interface{}{lb.ID}
+	for key := range keys {
+		if !users[key.userID] {
+			users[key.userID] = true
+			args = append(args, key.userID)
+		}
+	}
+
+	query := `SELECT e.user_id, e.created_at FROM score_events e
+	          JOIN leaderboard_rooms lr ON lr.room_id = e.room_id
+	          WHERE lr.leaderboard_id = ? AND e.user_id IN (?` + strings.Repeat(", ?", len(users)-1) + `)`
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	remaining := map[periodKey]bool{}
+	for rows.Next() {
+		var userID int
+		var at time.Time
+		if err := rows.Scan(&userID, &at); err != nil {
+			rows.Close()
+			return err
+		}
+		if !lb.Covers(at) {
+			continue
+		}
+		for _, w := range domain.LeaderboardWindows {
+			remaining[periodKey{period: w.Period(at), userID: userID}] = true
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for key := range keys {
+		if remaining[key] {
+			continue
+		}
+		query := `DELETE FROM leaderboard_points WHERE leaderboard_id = ? AND period = ? AND user_id = ?`
+		if _, err := tx.ExecContext(ctx, query, lb.ID, key.period, key.userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// periodKey identifica una fila de leaderboard_points
+type periodKey struct {
+	period string
+	userID int
+}
+
+// backfillRoom suma (sign = 1) o resta (sign = -1) al leaderboard los eventos
+// del ledger de la sala que caen en su rango de fechas. Devuelve las filas
+// (periodo y usuario) a las que la sala aporta algún evento.
+func backfillRoom(ctx context.Context, tx *infradb.Tx, lb *domain.Leaderboard, roomID, sign int) (map[periodKey]bool, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT user_id, delta, created_at FROM score_events WHERE room_id = ?`, roomID)
+	if err != nil {
+		return nil, err
+	}
+	totals := map[periodKey]int{}
+	latest := map[periodKey]time.Time{}
+	for rows.Next() {
+		var userID, delta int
+		var at time.Time
+		if err := rows.Scan(&userID, &delta, &at); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if !lb.Covers(at) {
+			continue
+		}
+		for _, w := range domain.LeaderboardWindows {
+			key := periodKey{period: w.Period(at), userID: userID}
+			totals[key] += delta
+			if at.After(latest[key]) {
+				latest[key] = at
+			}
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[periodKey]bool, len(totals))
+	for key, total := range totals {
+		keys[key] = true
+		if total == 0 {
+			continue
+		}
+		if sign > 0 {
+			err = addLeaderboardPoints(ctx, tx, lb.ID, key.period, key.userID, total, latest[key])
+		} else {
+			query := `UPDATE leaderboard_points SET points = points - ? WHERE leaderboard_id = ? AND period = ? AND user_id = ?`
+			_, err = tx.ExecContext(ctx, query, total, lb.ID, key.period, key.userID)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// applyLeaderboards suma el evento a cada leaderboard que incluye su sala y
+// cubre su fecha, en todas las ventanas. Se llama dentro de la transacción
+// que registra el evento, después de actualizar scores.
+func applyLeaderboards(ctx context.Context, tx *infradb.Tx, ev *domain.ScoreEvent) error {
+	query := `SELECT l.id, l.host_id, l.name, l.starts_at, l.ends_at, l.created_at
+	          FROM leaderboard_rooms lr
+	          JOIN leaderboards l ON l.id = lr.leaderboard_id
+	          WHERE lr.room_id = ?`
+	rows, err := tx.QueryContext(ctx, query, ev.RoomID)
+	if err != nil {
+		return err
+	}
+	var boards []int
+	for rows.Next() {
+		lb, err := scanLeaderboard(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		if lb.Covers(ev.CreatedAt) {
+			boards = append(boards, lb.ID)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range boards {
+		for _, w := range domain.LeaderboardWindows {
+			if err := addLeaderboardPoints(ctx, tx, id, w.Period(ev.CreatedAt), ev.UserID, ev.Delta, ev.CreatedAt); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// addLeaderboardPoints suma delta a la fila del periodo, creándola si no existe
+func addLeaderboardPoints(ctx context.Context, tx *infradb.Tx, leaderboardID int, period string, userID, delta int, at time.Time) error {
+	query := `
+		INSERT INTO leaderboard_points (leaderboard_id, period, user_id, points, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+	` + tx.Upsert("leaderboard_id, period, user_id", "points = leaderboard_points.points + excluded.points, updated_at = excluded.updated_at")
+	_, err := tx.ExecContext(ctx, query, leaderboardID, period, userID, delta, at)
+	return err
+}
+
+// leaderboardRanking numera el periodo con RANK: los empatados comparten
+// puesto y, dentro del empate, va primero quien llegó antes a esos puntos
+const leaderboardRanking = `
+	SELECT user_id, name, points, position FROM (
+		SELECT lp.user_id, u.name, lp.points, lp.updated_at,
+		       RANK() OVER (ORDER BY lp.points DESC) AS position
+		FROM leaderboard_points lp
+		JOIN users u ON u.id = lp.user_id
+		WHERE lp.leaderboard_id = ? AND lp.period = ?
+	) ranked`
+
+func (r *LeaderboardRepo) Ranking(ctx context.Context, q domain.LeaderboardQuery) ([]domain.LeaderboardEntry, error) {
+	query := leaderboardRanking + ` ORDER BY position, updated_at, user_id LIMIT ? OFFSET ?`
+	rows, err := r.db.QueryContext(ctx, query, q.LeaderboardID, q.Period, q.Limit, q.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []domain.LeaderboardEntry{}
+	for rows.Next() {
+		var e domain.LeaderboardEntry
+		if err := rows.Scan(&e.UserID, &e.UserName, &e.Points, &e.Position); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func (r *LeaderboardRepo) Entry(ctx context.Context, q domain.LeaderboardQuery, userID int) (*domain.LeaderboardEntry, error) {
+	e := &domain.LeaderboardEntry{}
+	err := r.db.QueryRowContext(ctx, leaderboardRanking+` WHERE user_id = ?`, q.LeaderboardID, q.Period, userID).Scan(
+		&e.UserID, &e.UserName, &e.Points, &e.Position,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (r *LeaderboardRepo) IsMember(ctx context.Context, id, userID int) (bool, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM leaderboard_rooms lr
+	          JOIN participants p ON p.room_id = lr.room_id
+	          WHERE lr.leaderboard_id = ? AND p.user_id = ?`
+	err := r.db.QueryRowContext(ctx, query, id, userID).Scan(&count)
+	return count > 0, err
+}
@@ -1,27 +1,30 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"math"
+	"time"
 
 	"apiGolan/src/domain"
+	infradb "apiGolan/src/infrastructure/db"
 )
 
-// QuestionRepo implementa domain.QuestionRepository usando MySQL
+// QuestionRepo implementa domain.QuestionRepository usando MySQL, PostgreSQL o SQLite
 type QuestionRepo struct {
-	db *sql.DB
+	db *infradb.DB
 }
 
-func NewQuestionRepo(db *sql.DB) domain.QuestionRepository {
+func NewQuestionRepo(db *infradb.DB) domain.QuestionRepository {
 	return &QuestionRepo{db: db}
 }
 
-func (r *QuestionRepo) Create(q *domain.Question) error {
-	query := `INSERT INTO questions (room_id, text, correct_answer, points, status) VALUES (?, ?, ?, ?, ?)`
-	result, err := r.db.Exec(query, q.RoomID, q.Text, q.CorrectAnswer, q.Points, q.Status)
-	if err != nil {
-		return err
-	}
-	id, err := result.LastInsertId()
+func (r *QuestionRepo) Create(ctx context.Context, q *domain.Question) error {
+	// created_at se fija aquí con milisegundos: los tiempos de respuesta se miden desde él
+	q.CreatedAt = time.Now().UTC()
+	query := `INSERT INTO questions (room_id, text, correct_answer, points, status, created_at) VALUES (?, ?, ?, ?, ?, ?)`
+	id, err := r.db.Insert(ctx, query, q.RoomID, q.Text, q.CorrectAnswer, q.Points, q.Status, q.CreatedAt)
 	if err != nil {
 		return err
 	}
@@ -29,34 +32,34 @@ func (r *QuestionRepo) Create(q *domain.Question) error {
 	return nil
 }
 
-func (r *QuestionRepo) FindByID(id int) (*domain.Question, error) {
+func (r *QuestionRepo) FindByID(ctx context.Context, id int) (*domain.Question, error) {
 	q := &domain.Question{}
 	query := `SELECT id, room_id, text, correct_answer, points, status, created_at FROM questions WHERE id = ?`
-	err := r.db.QueryRow(query, id).Scan(&q.ID, &q.RoomID, &q.Text, &q.CorrectAnswer, &q.Points, &q.Status, &q.CreatedAt)
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&q.ID, &q.RoomID, &q.Text, &q.CorrectAnswer, &q.Points, &q.Status, &q.CreatedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	return q, err
 }
 
-func (r *QuestionRepo) FindOpenByRoom(roomID int) (*domain.Question, error) {
+func (r *QuestionRepo) FindOpenByRoom(ctx context.Context, roomID int) (*domain.Question, error) {
 	q := &domain.Question{}
 	query := `SELECT id, room_id, text, correct_answer, points, status, created_at FROM questions WHERE room_id = ? AND status = 'open' LIMIT 1`
-	err := r.db.QueryRow(query, roomID).Scan(&q.ID, &q.RoomID, &q.Text, &q.CorrectAnswer, &q.Points, &q.Status, &q.CreatedAt)
+	err := r.db.QueryRowContext(ctx, query, roomID).Scan(&q.ID, &q.RoomID, &q.Text, &q.CorrectAnswer, &q.Points, &q.Status, &q.CreatedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	return q, err
 }
 
-func (r *QuestionRepo) CloseQuestion(id int) error {
-	_, err := r.db.Exec(`UPDATE questions SET status = 'closed' WHERE id = ?`, id)
+func (r *QuestionRepo) CloseQuestion(ctx context.Context, id int) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE questions SET status = 'closed' WHERE id = ?`, id)
 	return err
 }
 
-func (r *QuestionRepo) FindByRoom(roomID int) ([]domain.Question, error) {
+func (r *QuestionRepo) FindByRoom(ctx context.Context, roomID int) ([]domain.Question, error) {
 	query := `SELECT id, room_id, text, correct_answer, points, status, created_at FROM questions WHERE room_id = ? ORDER BY created_at DESC`
-	rows, err := r.db.Query(query, roomID)
+	rows, err := r.db.QueryContext(ctx, query, roomID)
 	if err != nil {
 		return nil, err
 	}
@@ -73,35 +76,35 @@ func (r *QuestionRepo) FindByRoom(roomID int) ([]domain.Question, error) {
 	return questions, nil
 }
 
-// AnswerRepo implementa domain.AnswerRepository usando MySQL
+// AnswerRepo implementa domain.AnswerRepository usando MySQL, PostgreSQL o SQLite
 type AnswerRepo struct {
-	db *sql.DB
+	db *infradb.DB
 }
 
-func NewAnswerRepo(db *sql.DB) domain.AnswerRepository {
+func NewAnswerRepo(db *infradb.DB) domain.AnswerRepository {
 	return &AnswerRepo{db: db}
 }
 
-func (r *AnswerRepo) Create(a *domain.Answer) error {
-	query := `INSERT INTO answers (question_id, user_id, text, is_correct) VALUES (?, ?, ?, ?)`
-	result, err := r.db.Exec(query, a.QuestionID, a.UserID, a.Text, a.IsCorrect)
+func (r *AnswerRepo) Create(ctx context.Context, a *domain.Answer) error {
+	responseMS := sql.NullInt64{Int64: a.ResponseMS, Valid: a.ResponseMS > 0}
+	query := `INSERT INTO answers (question_id, user_id, text, is_correct, response_ms) VALUES (?, ?, ?, ?, ?)`
+	id, err := r.db.Insert(ctx, query, a.QuestionID, a.UserID, a.Text, a.IsCorrect, responseMS)
 	if err != nil {
 		return err
 	}
-	id, _ := result.LastInsertId()
 	a.ID = int(id)
 	return nil
 }
 
-func (r *AnswerRepo) HasAnswered(questionID, userID int) (bool, error) {
+func (r *AnswerRepo) HasAnswered(ctx context.Context, questionID, userID int) (bool, error) {
 	var count int
-	err := r.db.QueryRow(`SELECT COUNT(*) FROM answers WHERE question_id = ? AND user_id = ?`, questionID, userID).Scan(&count)
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM answers WHERE question_id = ? AND user_id = ?`, questionID, userID).Scan(&count)
 	return count > 0, err
 }
 
-func (r *AnswerRepo) FindByQuestion(questionID int) ([]domain.Answer, error) {
-	query := `SELECT id, question_id, user_id, text, is_correct, answered_at FROM answers WHERE question_id = ?`
-	rows, err := r.db.Query(query, questionID)
+func (r *AnswerRepo) FindByQuestion(ctx context.Context, questionID int) ([]domain.Answer, error) {
+	query := `SELECT id, question_id, user_id, text, is_correct, response_ms, answered_at FROM answers WHERE question_id = ?`
+	rows, err := r.db.QueryContext(ctx, query, questionID)
 	if err != nil {
 		return nil, err
 	}
@@ -110,10 +113,117 @@ func (r *AnswerRepo) FindByQuestion(questionID int) ([]domain.Answer, error) {
 	var answers []domain.Answer
 	for rows.Next() {
 		var a domain.Answer
-		if err := rows.Scan(&a.ID, &a.QuestionID, &a.UserID, &a.Text, &a.IsCorrect, &a.AnsweredAt); err != nil {
+		var responseMS sql.NullInt64
+		if err := rows.Scan(&a.ID, &a.QuestionID, &a.UserID, &a.Text, &a.IsCorrect, &responseMS, &a.AnsweredAt); err != nil {
 			return nil, err
 		}
+		a.ResponseMS = responseMS.Int64
 		answers = append(answers, a)
 	}
 	return answers, nil
 }
+
+func (r *AnswerRepo) CountByQuestion(ctx context.Context, questionID int) (total, correct int, err error) {
+	query := `SELECT COUNT(*), COALESCE(SUM(CASE WHEN is_correct THEN 1 ELSE 0 END), 0)
+	          FROM answers WHERE question_id = ?`
+	err = r.db.QueryRowContext(ctx, query, questionID).Scan(&total, &correct)
+	return total, correct, err
+}
+
+// Cuántos textos distintos devuelven la distribución y la lista de incorrectas
+const (
+	statsDistributionLimit = 20
+	statsTopWrongLimit     = 5
+)
+
+// participantAnswers limita las respuestas de una pregunta a las de quienes
+// siguen en la sala: las de expulsados o de quien ya salió no cuentan en
+// ninguna estadística. Recibe room_id y question_id, en ese orden.
+const participantAnswers = `answers a
+	          JOIN participants p ON p.user_id = a.user_id AND p.room_id = ?
+	          WHERE a.question_id = ?`
+
+func (r *AnswerRepo) StatsByQuestion(ctx context.Context, questionID, roomID int) (*domain.QuestionStats, error) {
+	stats := &domain.QuestionStats{QuestionID: questionID}
+
+	// Participantes, respondidas y correctas salen de la misma consulta para
+	// que answered + not_answered sea siempre participants
+	query := `SELECT COUNT(*),
+	                 COALESCE(SUM(CASE WHEN a.id IS NULL THEN 0 ELSE 1 END), 0),
+	                 COALESCE(SUM(CASE WHEN a.is_correct THEN 1 ELSE 0 END), 0)
+	          FROM participants p
+	          LEFT JOIN answers a ON a.question_id = ? AND a.user_id = p.user_id
+	          WHERE p.room_id = ?`
+	if err := r.db.QueryRowContext(ctx, query, questionID, roomID).Scan(&stats.Participants, &stats.Answered, &stats.Correct); err != nil {
+		return nil, err
+	}
+	stats.NotAnswered = stats.Participants - stats.Answered
+	stats.PercentCorrect = percent(stats.Correct, stats.Answered)
+
+	var err error
+	if stats.Distribution, err = r.groupAnswers(ctx, questionID, roomID, "", statsDistributionLimit); err != nil {
+		return nil, err
+	}
+	if stats.TopWrong, err = r.groupAnswers(ctx, questionID, roomID, "AND NOT a.is_correct", statsTopWrongLimit); err != nil {
+		return nil, err
+	}
+	if stats.ResponseTimes, err = r.responseTimes(ctx, questionID, roomID); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// groupAnswers cuenta las respuestas por texto normalizado, de la más a la
+// menos frecuente. filter se agrega tal cual al WHERE.
+func (r *AnswerRepo) groupAnswers(ctx context.Context, questionID, roomID int, filter string, limit int) ([]domain.AnswerCount, error) {
+	query := fmt.Sprintf(`SELECT MIN(TRIM(a.text)), COUNT(*), MAX(CASE WHEN a.is_correct THEN 1 ELSE 0 END)
+	          FROM %s %s
+	          GROUP BY LOWER(TRIM(a.text))
+	          ORDER BY COUNT(*) DESC, MIN(TRIM(a.text))
+	          LIMIT ?`, participantAnswers, filter)
+	rows, err := r.db.QueryContext(ctx, query, roomID, questionID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []domain.AnswerCount{}
+	for rows.Next() {
+		var c domain.AnswerCount
+		var isCorrect int
+		if err := rows.Scan(&c.Answer, &c.Count, &isCorrect); err != nil {
+			return nil, err
+		}
+		c.IsCorrect = isCorrect > 0
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
+
+// responseTimes calcula la mediana y los percentiles 75 y 90 por rango más
+// cercano: el valor en la posición ceil(p·n) de los tiempos ordenados
+func (r *AnswerRepo) responseTimes(ctx context.Context, questionID, roomID int) (domain.ResponseTimes, error) {
+	var rt domain.ResponseTimes
+	query := `SELECT COUNT(*) FROM ` + participantAnswers + ` AND a.response_ms IS NOT NULL`
+	if err := r.db.QueryRowContext(ctx, query, roomID, questionID).Scan(&rt.Count); err != nil || rt.Count == 0 {
+		return rt, err
+	}
+
+	percentiles := []struct {
+		p    float64
+		dest *int64
+	}{
+		{0.50, &rt.MedianMS},
+		{0.75, &rt.P75MS},
+		{0.90, &rt.P90MS},
+	}
+	query = `SELECT a.response_ms FROM ` + participantAnswers + ` AND a.response_ms IS NOT NULL
+	         ORDER BY a.response_ms LIMIT 1 OFFSET ?`
+	for _, pc := range percentiles {
+		offset := int(math.Ceil(pc.p*float64(rt.Count))) - 1
+		if err := r.db.QueryRowContext(ctx, query, roomID, questionID, offset).Scan(pc.dest); err != nil {
+			return rt, err
+		}
+	}
+	return rt, nil
+}
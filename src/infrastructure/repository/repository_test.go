@@ -0,0 +1,674 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"apiGolan/src/domain"
+	infradb "apiGolan/src/infrastructure/db"
+)
+
+// newTestDB abre una base SQLite en memoria con schema_sqlite.sql aplicado
+func newTestDB(t *testing.T) *infradb.DB {
+	t.Helper()
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DB_PATH", ":memory:")
+	db, err := infradb.Connect()
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// seedUser crea un usuario con el rol indicado
+func seedUser(t *testing.T, db *infradb.DB, name string, role domain.Role) *domain.User {
+	t.Helper()
+	u := &domain.User{Name: name, Email: name + "@test.com", Password: "hash", Role: role, EmailVerified: true}
+	if err := NewUserRepo(db).Create(context.Background(), u); err != nil {
+		t.Fatalf("crear usuario %s: %v", name, err)
+	}
+	return u
+}
+
+// seedRoom crea una sala del host con los participantes indicados
+func seedRoom(t *testing.T, db *infradb.DB, host *domain.User, participants ...*domain.User) *domain.Room {
+	t.Helper()
+	ctx := context.Background()
+	room := &domain.Room{
+		Code:        fmt.Sprintf("R%05d", host.ID*100+len(participants)),
+		HostID:      host.ID,
+		Status:      domain.RoomStatusActive,
+		RankingMode: domain.RankingCompetition,
+		Streak:      domain.StreakConfig{Mode: domain.StreakNone},
+	}
+	if err := NewRoomRepo(db).Create(ctx, room); err != nil {
+		t.Fatalf("crear sala: %v", err)
+	}
+	for _, u := range participants {
+		if err := NewParticipantRepo(db).Add(ctx, &domain.Participant{RoomID: room.ID, UserID: u.ID}); err != nil {
+			t.Fatalf("unir %s: %v", u.Name, err)
+		}
+	}
+	return room
+}
+
+func TestUserRepo(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	repo := NewUserRepo(db)
+
+	ana := seedUser(t, db, "ana", domain.RoleHost)
+
+	got, err := repo.FindByEmail(ctx, "ana@test.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || got.ID != ana.ID || got.Role != domain.RoleHost || !got.EmailVerified {
+		t.Fatalf("FindByEmail = %+v", got)
+	}
+
+	if missing, err := repo.FindByID(ctx, 999); err != nil || missing != nil {
+		t.Errorf("FindByID de un id inexistente = %v, %v; se esperaba nil, nil", missing, err)
+	}
+
+	dup := &domain.User{Name: "otra", Email: "ana@test.com", Password: "x", Role: domain.RoleParticipant}
+	if err := repo.Create(ctx, dup); err == nil {
+		t.Error("se creó un usuario con el email repetido")
+	}
+
+	if err := repo.UpdateRole(ctx, ana.ID, domain.RoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = repo.FindByID(ctx, ana.ID)
+	if got.Role != domain.RoleAdmin {
+		t.Errorf("rol = %s, se esperaba admin", got.Role)
+	}
+}
+
+func TestRoomAndParticipantRepo(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	host := seedUser(t, db, "host", domain.RoleHost)
+	p1 := seedUser(t, db, "p1", domain.RoleParticipant)
+	room := seedRoom(t, db, host, p1)
+
+	got, err := NewRoomRepo(db).FindByCode(ctx, room.Code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || got.ID != room.ID || got.HostID != host.ID || got.Streak.Mode != domain.StreakNone {
+		t.Fatalf("FindByCode = %+v", got)
+	}
+
+	participants := NewParticipantRepo(db)
+	if ok, err := participants.ExistsInRoom(ctx, room.ID, p1.ID); err != nil || !ok {
+		t.Errorf("ExistsInRoom = %v, %v; se esperaba true", ok, err)
+	}
+	if err := participants.Add(ctx, &domain.Participant{RoomID: room.ID, UserID: p1.ID}); err == nil {
+		t.Error("se unió dos veces el mismo participante")
+	}
+	if err := participants.Remove(ctx, room.ID, p1.ID); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := participants.ExistsInRoom(ctx, room.ID, p1.ID); ok {
+		t.Error("el participante sigue en la sala después de Remove")
+	}
+}
+
+func TestScoreRepoAddPoints(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	repo := &ScoreRepo{db: db}
+
+	host := seedUser(t, db, "host", domain.RoleHost)
+	p1 := seedUser(t, db, "p1", domain.RoleParticipant)
+	room := seedRoom(t, db, host, p1)
+
+	for _, delta := range []int{10, -3} {
+		ev := &domain.ScoreEvent{RoomID: room.ID, UserID: p1.ID, ActorID: host.ID, Source: domain.ScoreSourceManual, Delta: delta}
+		if err := repo.AddPoints(ctx, ev); err != nil {
+			t.Fatal(err)
+		}
+		if ev.ID == 0 {
+			t.Error("AddPoints no asignó el id del evento")
+		}
+	}
+
+	score, err := repo.GetByRoomAndUser(ctx, room.ID, p1.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if score == nil || score.Points != 7 {
+		t.Fatalf("score = %+v, se esperaban 7 puntos", score)
+	}
+
+	events, err := repo.ListEvents(ctx, domain.ScoreEventFilter{RoomID: room.ID, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Errorf("el ledger tiene %d eventos, se esperaban 2", len(events))
+	}
+}
+
+func TestScoreRepoStreak(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	repo := &ScoreRepo{db: db}
+
+	host := seedUser(t, db, "host", domain.RoleHost)
+	p1 := seedUser(t, db, "p1", domain.RoleParticipant)
+	room := seedRoom(t, db, host, p1)
+
+	// un error sin score previo no crea la fila
+	if streak, err := repo.UpdateStreak(ctx, room.ID, p1.ID, false); err != nil || streak != 0 {
+		t.Fatalf("UpdateStreak(false) = %d, %v", streak, err)
+	}
+	if score, _ := repo.GetByRoomAndUser(ctx, room.ID, p1.ID); score != nil {
+		t.Error("una respuesta incorrecta creó el score")
+	}
+
+	for want := 1; want <= 3; want++ {
+		streak, err := repo.UpdateStreak(ctx, room.ID, p1.ID, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if streak != want {
+			t.Errorf("racha = %d, se esperaba %d", streak, want)
+		}
+	}
+	if streak, _ := repo.UpdateStreak(ctx, room.ID, p1.ID, false); streak != 0 {
+		t.Errorf("racha tras un error = %d, se esperaba 0", streak)
+	}
+}
+
+func TestScoreRepoRevert(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	repo := &ScoreRepo{db: db}
+
+	host := seedUser(t, db, "host", domain.RoleHost)
+	p1 := seedUser(t, db, "p1", domain.RoleParticipant)
+	room := seedRoom(t, db, host, p1)
+
+	ev := &domain.ScoreEvent{RoomID: room.ID, UserID: p1.ID, ActorID: host.ID, Source: domain.ScoreSourceManual, Delta: 10}
+	if err := repo.AddPoints(ctx, ev); err != nil {
+		t.Fatal(err)
+	}
+
+	undo := func() (bool, error) {
+		return repo.Revert(ctx, &domain.ScoreEvent{
+			RoomID: room.ID, UserID: p1.ID, ActorID: host.ID,
+			Source: domain.ScoreSourceUndo, Delta: -ev.Delta, RevertsID: ev.ID,
+		})
+	}
+	if ok, err := undo(); !ok || err != nil {
+		t.Fatalf("primer Revert = %v, %v", ok, err)
+	}
+	// el segundo no registra nada ni vuelve a restar
+	if ok, err := undo(); ok || err != nil {
+		t.Errorf("segundo Revert = %v, %v; se esperaba false, nil", ok, err)
+	}
+
+	score, _ := repo.GetByRoomAndUser(ctx, room.ID, p1.ID)
+	if score == nil || score.Points != 0 {
+		t.Errorf("score tras deshacer = %+v, se esperaban 0 puntos", score)
+	}
+	found, err := repo.FindEvent(ctx, ev.ID)
+	if err != nil || found == nil || found.RevertedBy == 0 {
+		t.Errorf("FindEvent = %+v, %v; se esperaba reverted_by", found, err)
+	}
+}
+
+func TestScoreRepoReconcile(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	repo := &ScoreRepo{db: db}
+
+	host := seedUser(t, db, "host", domain.RoleHost)
+	p1 := seedUser(t, db, "p1", domain.RoleParticipant)
+	p2 := seedUser(t, db, "p2", domain.RoleParticipant)
+	room := seedRoom(t, db, host, p1, p2)
+
+	for _, u := range []*domain.User{p1, p2} {
+		ev := &domain.ScoreEvent{RoomID: room.ID, UserID: u.ID, ActorID: host.ID, Source: domain.ScoreSourceManual, Delta: 10}
+		if err := repo.AddPoints(ctx, ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if drifts, err := repo.Reconcile(ctx, room.ID); err != nil || len(drifts) != 0 {
+		t.Fatalf("Reconcile sin desfase = %+v, %v", drifts, err)
+	}
+
+	// scores.points se desfasa del ledger por fuera de AddPoints
+	if _, err := db.ExecContext(ctx, `UPDATE scores SET points = 25 WHERE room_id = ? AND user_id = ?`, room.ID, p2.ID); err != nil {
+		t.Fatal(err)
+	}
+	drifts, err := repo.Reconcile(ctx, room.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drifts) != 1 || drifts[0].UserID != p2.ID || drifts[0].Points != 25 || drifts[0].Ledger != 10 {
+		t.Errorf("Reconcile = %+v, se esperaba p2 con 25 puntos y 10 en el ledger", drifts)
+	}
+	for _, u := range []*domain.User{p1, p2} {
+		if score, _ := repo.GetByRoomAndUser(ctx, room.ID, u.ID); score == nil || score.Points != 10 {
+			t.Errorf("score de %s tras Reconcile = %+v, se esperaban 10", u.Name, score)
+		}
+	}
+}
+
+func TestScoreRepoGetRanking(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	repo := &ScoreRepo{db: db}
+
+	host := seedUser(t, db, "host", domain.RoleHost)
+	names := []string{"p1", "p2", "p3", "p4", "p5", "p6", "p7", "p8"}
+	players := map[string]*domain.User{}
+	ordered := make([]*domain.User, 0, len(names))
+	for _, name := range names {
+		players[name] = seedUser(t, db, name, domain.RoleParticipant)
+		ordered = append(ordered, players[name])
+	}
+	room := seedRoom(t, db, host, ordered...)
+
+	// unión a la sala en el orden de los nombres salvo p6, que entró primero
+	for i, u := range ordered {
+		joined := time.Date(2026, 1, 1, 10, i+1, 0, 0, time.UTC)
+		if u.Name == "p6" {
+			joined = time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+		}
+		if _, err := db.ExecContext(ctx, `UPDATE participants SET joined_at = ? WHERE room_id = ? AND user_id = ?`, joined, room.ID, u.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	points := map[string]int{"p1": 30, "p2": 20, "p3": 20, "p4": 20, "p5": 10, "p6": 10, "p7": 5, "p8": 5}
+	for name, pts := range points {
+		ev := &domain.ScoreEvent{RoomID: room.ID, UserID: players[name].ID, Source: domain.ScoreSourceManual, Delta: pts}
+		if err := repo.AddPoints(ctx, ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	questions, answers := NewQuestionRepo(db), NewAnswerRepo(db)
+	q1 := &domain.Question{RoomID: room.ID, Text: "q1", CorrectAnswer: "a", Points: 10, Status: domain.QuestionStatusClosed}
+	q2 := &domain.Question{RoomID: room.ID, Text: "q2", CorrectAnswer: "a", Points: 10, Status: domain.QuestionStatusClosed}
+	for _, q := range []*domain.Question{q1, q2} {
+		if err := questions.Create(ctx, q); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, a := range []struct {
+		q        *domain.Question
+		name     string
+		correct  bool
+		ms       int64
+		answered time.Time
+	}{
+		// p2, p3 y p4 empatan en 20: desempata el tiempo total y luego tener un acierto
+		{q1, "p2", true, 500, time.Date(2026, 1, 1, 11, 0, 0, 0, time.UTC)},
+		{q1, "p3", true, 900, time.Date(2026, 1, 1, 11, 0, 0, 0, time.UTC)},
+		{q1, "p4", false, 500, time.Date(2026, 1, 1, 11, 0, 0, 0, time.UTC)},
+		// p7 y p8 empatan en puntos y tiempo: va primero quien acertó antes
+		{q2, "p7", true, 300, time.Date(2026, 1, 1, 11, 6, 0, 0, time.UTC)},
+		{q2, "p8", true, 300, time.Date(2026, 1, 1, 11, 5, 0, 0, time.UTC)},
+	} {
+		answer := &domain.Answer{QuestionID: a.q.ID, UserID: players[a.name].ID, Text: "a", IsCorrect: a.correct, ResponseMS: a.ms}
+		if err := answers.Create(ctx, answer); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.ExecContext(ctx, `UPDATE answers SET answered_at = ? WHERE id = ?`, a.answered, answer.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// p5 y p6 no respondieron nada: desempata quién se unió antes
+	wantOrder := []string{"p1", "p2", "p4", "p3", "p6", "p5", "p8", "p7"}
+	tests := []struct {
+		mode      domain.RankingMode
+		positions []int
+	}{
+		{domain.RankingCompetition, []int{1, 2, 2, 2, 5, 5, 7, 7}},
+		{domain.RankingDense, []int{1, 2, 2, 2, 3, 3, 4, 4}},
+	}
+	for _, tt := range tests {
+		ranking, err := repo.GetRanking(ctx, domain.RankingQuery{RoomID: room.ID, Mode: tt.mode})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ranking) != len(wantOrder) {
+			t.Fatalf("%s: el ranking tiene %d filas", tt.mode, len(ranking))
+		}
+		for i, entry := range ranking {
+			if entry.UserName != wantOrder[i] || entry.Position != tt.positions[i] {
+				t.Errorf("%s fila %d = %s puesto %d, se esperaba %s puesto %d",
+					tt.mode, i, entry.UserName, entry.Position, wantOrder[i], tt.positions[i])
+			}
+		}
+
+		// la página y la fila individual respetan el mismo orden y puesto
+		page, err := repo.GetRanking(ctx, domain.RankingQuery{RoomID: room.ID, Mode: tt.mode, Limit: 2, Offset: 2})
+		if err != nil || len(page) != 2 || page[0].UserName != "p4" || page[1].UserName != "p3" {
+			t.Errorf("%s: página = %+v, %v", tt.mode, page, err)
+		}
+		entry, err := repo.GetRankingEntry(ctx, domain.RankingQuery{RoomID: room.ID, Mode: tt.mode}, players["p7"].ID)
+		if err != nil || entry == nil || entry.Position != tt.positions[7] || entry.TotalResponseMS != 300 {
+			t.Errorf("%s: GetRankingEntry(p7) = %+v, %v", tt.mode, entry, err)
+		}
+	}
+
+	if entry, err := repo.GetRankingEntry(ctx, domain.RankingQuery{RoomID: room.ID}, host.ID); entry != nil || err != nil {
+		t.Errorf("GetRankingEntry de quien no tiene score = %+v, %v", entry, err)
+	}
+}
+
+func TestLeaderboardRepoRemoveRoom(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	scores := &ScoreRepo{db: db}
+	repo := NewLeaderboardRepo(db)
+
+	host := seedUser(t, db, "host", domain.RoleHost)
+	ana := seedUser(t, db, "ana", domain.RoleParticipant)
+	beto := seedUser(t, db, "beto", domain.RoleParticipant)
+	carl := seedUser(t, db, "carl", domain.RoleParticipant)
+	room1 := seedRoom(t, db, host, ana, beto, carl)
+	room2 := seedRoom(t, db, host, ana, carl)
+
+	for _, ev := range []domain.ScoreEvent{
+		{RoomID: room1.ID, UserID: ana.ID, Delta: 10},
+		{RoomID: room1.ID, UserID: beto.ID, Delta: 7},
+		{RoomID: room1.ID, UserID: carl.ID, Delta: 10},
+		{RoomID: room2.ID, UserID: ana.ID, Delta: 5},
+		{RoomID: room2.ID, UserID: ana.ID, Delta: -5}, // ana suma 0 en room2
+		{RoomID: room2.ID, UserID: carl.ID, Delta: -10},
+	} {
+		ev.Source = domain.ScoreSourceManual
+		if err := scores.AddPoints(ctx, &ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lb := &domain.Leaderboard{HostID: host.ID, Name: "liga"}
+	if err := repo.Create(ctx, lb); err != nil {
+		t.Fatal(err)
+	}
+	for _, room := range []*domain.Room{room1, room2} {
+		if ok, err := repo.AddRoom(ctx, lb, room.ID); !ok || err != nil {
+			t.Fatalf("AddRoom = %v, %v", ok, err)
+		}
+	}
+
+	ranking := func() map[string]int {
+		t.Helper()
+		got := map[string]int{}
+		for _, w := range domain.LeaderboardWindows {
+			entries, err := repo.Ranking(ctx, domain.LeaderboardQuery{LeaderboardID: lb.ID, Period: w.Period(time.Now()), Limit: 10})
+			if err != nil {
+				t.Fatal(err)
+			}
+			window := map[string]int{}
+			for _, e := range entries {
+				window[e.UserName] = e.Points
+			}
+			if len(got) > 0 && fmt.Sprint(window) != fmt.Sprint(got) {
+				t.Errorf("la ventana %s = %v, las demás %v", w, window, got)
+			}
+			got = window
+		}
+		return got
+	}
+	if got := fmt.Sprint(ranking()); got != "map[ana:10 beto:7 carl:0]" {
+		t.Fatalf("con las dos salas = %s", got)
+	}
+
+	if ok, err := repo.RemoveRoom(ctx, lb, room1.ID); !ok || err != nil {
+		t.Fatalf("RemoveRoom = %v, %v", ok, err)
+	}
+	// ana sigue en room2 aunque allí sume 0; beto solo jugaba en room1
+	if got := fmt.Sprint(ranking()); got != "map[ana:0 carl:-10]" {
+		t.Errorf("tras quitar room1 = %s, se esperaba map[ana:0 carl:-10]", got)
+	}
+
+	if ok, err := repo.RemoveRoom(ctx, lb, room1.ID); ok || err != nil {
+		t.Errorf("quitar una sala que ya no está = %v, %v", ok, err)
+	}
+	if ok, err := repo.RemoveRoom(ctx, lb, room2.ID); !ok || err != nil {
+		t.Fatalf("RemoveRoom = %v, %v", ok, err)
+	}
+	if got := ranking(); len(got) != 0 {
+		t.Errorf("sin salas el leaderboard tiene %v", got)
+	}
+}
+
+func TestUserRepoAnonymize(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	users := NewUserRepo(db)
+	identities := NewIdentityRepo(db)
+
+	ana := seedUser(t, db, "ana", domain.RoleParticipant)
+	if err := identities.Create(ctx, &domain.ExternalIdentity{UserID: ana.ID, Provider: "https://idp", Subject: "sub-1", Email: "ana@test.com"}); err != nil {
+		t.Fatal(err)
+	}
+	keys := NewAPIKeyRepo(db)
+	if err := keys.Create(ctx, &domain.APIKey{UserID: ana.ID, Name: "script", Prefix: "qs_ana", KeyHash: "hash-ana", Scopes: []domain.Scope{domain.ScopeScoresRead}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := users.Anonymize(ctx, ana.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := users.FindByID(ctx, ana.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.DeletedAt == nil || got.Email == "ana@test.com" || got.Password != "" {
+		t.Errorf("usuario sin anonimizar: %+v", got)
+	}
+	if id, err := identities.FindBySubject(ctx, "https://idp", "sub-1"); err != nil || id != nil {
+		t.Errorf("la identidad externa sigue vinculada: %+v, %v", id, err)
+	}
+	if k, err := keys.FindByHash(ctx, "hash-ana"); err != nil || k != nil {
+		t.Errorf("la API key sigue activa: %+v, %v", k, err)
+	}
+}
+
+func TestAnswerRepoStatsByQuestion(t *testing.T) {
+	type answer struct {
+		user    string
+		text    string
+		correct bool
+		ms      int64
+	}
+	answers := []answer{
+		{"ana", "París", true, 100},
+		{"beto", "Lyon", false, 300},
+		{"caro", "Roma", false, 200},
+	}
+
+	tests := []struct {
+		name          string
+		answers       []answer
+		kick          []string
+		outsider      bool // alguien que nunca se unió responde igual
+		participants  int
+		answered      int
+		correct       int
+		wrong         []string
+		responseCount int
+		median        int64
+	}{
+		{"todos responden", answers, nil, false, 3, 3, 1, []string{"Lyon", "Roma"}, 3, 200},
+		{"uno sin responder", answers[:2], nil, false, 3, 2, 1, []string{"Lyon"}, 2, 100},
+		{"expulsado que respondió", answers, []string{"beto"}, false, 2, 2, 1, []string{"Roma"}, 2, 100},
+		{"expulsado sin responder", answers[:2], []string{"caro"}, false, 2, 2, 1, []string{"Lyon"}, 2, 100},
+		{"respuesta de alguien fuera de la sala", answers[:1], nil, true, 3, 1, 1, []string{}, 1, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			ctx := context.Background()
+
+			host := seedUser(t, db, "host", domain.RoleHost)
+			users := map[string]*domain.User{}
+			for _, name := range []string{"ana", "beto", "caro"} {
+				users[name] = seedUser(t, db, name, domain.RoleParticipant)
+			}
+			room := seedRoom(t, db, host, users["ana"], users["beto"], users["caro"])
+
+			q := &domain.Question{RoomID: room.ID, Text: "¿Capital de Francia?", CorrectAnswer: "París", Points: 10, Status: domain.QuestionStatusOpen}
+			if err := NewQuestionRepo(db).Create(ctx, q); err != nil {
+				t.Fatal(err)
+			}
+			repo := NewAnswerRepo(db)
+			for _, a := range tt.answers {
+				if err := repo.Create(ctx, &domain.Answer{QuestionID: q.ID, UserID: users[a.user].ID, Text: a.text, IsCorrect: a.correct, ResponseMS: a.ms}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.outsider {
+				dani := seedUser(t, db, "dani", domain.RoleParticipant)
+				if err := repo.Create(ctx, &domain.Answer{QuestionID: q.ID, UserID: dani.ID, Text: "Berlín", ResponseMS: 50}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, name := range tt.kick {
+				if err := NewParticipantRepo(db).Remove(ctx, room.ID, users[name].ID); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			stats, err := repo.StatsByQuestion(ctx, q.ID, room.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stats.Participants != tt.participants || stats.Answered != tt.answered || stats.Correct != tt.correct {
+				t.Errorf("participants/answered/correct = %d/%d/%d, want %d/%d/%d",
+					stats.Participants, stats.Answered, stats.Correct, tt.participants, tt.answered, tt.correct)
+			}
+			if stats.Answered+stats.NotAnswered != stats.Participants {
+				t.Errorf("answered %d + not_answered %d != participants %d", stats.Answered, stats.NotAnswered, stats.Participants)
+			}
+			total := 0
+			for _, c := range stats.Distribution {
+				total += c.Count
+			}
+			if total != stats.Answered {
+				t.Errorf("la distribución suma %d, answered = %d", total, stats.Answered)
+			}
+			wrong := []string{}
+			for _, c := range stats.TopWrong {
+				wrong = append(wrong, c.Answer)
+			}
+			if fmt.Sprint(wrong) != fmt.Sprint(tt.wrong) {
+				t.Errorf("top_wrong = %v, want %v", wrong, tt.wrong)
+			}
+			if stats.ResponseTimes.Count != tt.responseCount || stats.ResponseTimes.MedianMS != tt.median {
+				t.Errorf("response_times = %+v, want count %d, mediana %d", stats.ResponseTimes, tt.responseCount, tt.median)
+			}
+		})
+	}
+}
+
+func TestHistoryRepoFindByUser(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	scores := NewScoreRepo(db)
+	answers := NewAnswerRepo(db)
+
+	host1 := seedUser(t, db, "host1", domain.RoleHost)
+	host2 := seedUser(t, db, "host2", domain.RoleHost)
+	ana := seedUser(t, db, "ana", domain.RoleParticipant)
+	beto := seedUser(t, db, "beto", domain.RoleParticipant)
+	caro := seedUser(t, db, "caro", domain.RoleParticipant)
+	dani := seedUser(t, db, "dani", domain.RoleParticipant) // nunca se unió a una sala
+	points := func(room *domain.Room, u *domain.User, delta int) {
+		t.Helper()
+		if err := scores.AddPoints(ctx, &domain.ScoreEvent{RoomID: room.ID, UserID: u.ID, Source: domain.ScoreSourceManual, Delta: delta}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// primera sala (competition): tres preguntas; ana responde dos, beto una sin tiempo
+	first := seedRoom(t, db, host1, ana, beto, caro)
+	var qs []*domain.Question
+	for i := 0; i < 3; i++ {
+		q := &domain.Question{RoomID: first.ID, Text: fmt.Sprint("¿", i, "?"), CorrectAnswer: "sí", Points: 10, Status: domain.QuestionStatusClosed}
+		if err := NewQuestionRepo(db).Create(ctx, q); err != nil {
+			t.Fatal(err)
+		}
+		qs = append(qs, q)
+	}
+	for _, a := range []*domain.Answer{
+		{QuestionID: qs[0].ID, UserID: ana.ID, Text: "sí", IsCorrect: true, ResponseMS: 100},
+		{QuestionID: qs[1].ID, UserID: ana.ID, Text: "no", ResponseMS: 301},
+		{QuestionID: qs[0].ID, UserID: beto.ID, Text: "sí", IsCorrect: true},
+	} {
+		if err := answers.Create(ctx, a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	points(first, ana, 10)
+	points(first, beto, 20)
+	points(first, caro, 20)
+
+	// segunda sala (dense) de otro host: sin preguntas y ana sin puntos
+	second := seedRoom(t, db, host2, ana, beto, caro)
+	if err := NewRoomRepo(db).UpdateRankingMode(ctx, second.Code, domain.RankingDense); err != nil {
+		t.Fatal(err)
+	}
+	points(second, beto, 5)
+	points(second, caro, 5)
+
+	type session struct {
+		code                                  string
+		points, rank, participants, questions int
+		answered, correct                     int
+		accuracy                              float64
+		avgMS                                 int64
+		timed                                 int
+	}
+	firstAna := session{first.Code, 10, 3, 3, 3, 2, 1, 50, 201, 2}
+	secondAna := session{second.Code, 0, 2, 3, 0, 0, 0, 0, 0, 0}
+
+	tests := []struct {
+		name   string
+		user   *domain.User
+		hostID int
+		want   []session
+	}{
+		{"todas las salas, la más reciente primero", ana, 0, []session{secondAna, firstAna}},
+		{"solo las del host1", ana, host1.ID, []session{firstAna}},
+		{"solo las del host2", ana, host2.ID, []session{secondAna}},
+		{"host sin salas del usuario", ana, beto.ID, []session{}},
+		{"respuesta sin tiempo", beto, host1.ID, []session{{first.Code, 20, 1, 3, 3, 1, 1, 100, 0, 0}}},
+		{"usuario sin salas", dani, 0, []session{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewHistoryRepo(db).FindByUser(ctx, tt.user.ID, tt.hostID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got == nil {
+				t.Fatal("FindByUser devolvió nil en vez de una lista vacía")
+			}
+			sessions := []session{}
+			for _, h := range got {
+				sessions = append(sessions, session{h.RoomCode, h.Points, h.Rank, h.Participants, h.Questions,
+					h.Answered, h.Correct, h.Accuracy, h.AvgResponseMS, h.TimedAnswers})
+			}
+			if fmt.Sprint(sessions) != fmt.Sprint(tt.want) {
+				t.Errorf("sesiones = %+v\nse esperaba  %+v", sessions, tt.want)
+			}
+		})
+	}
+}
@@ -1,27 +1,26 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 
 	"apiGolan/src/domain"
+	infradb "apiGolan/src/infrastructure/db"
 )
 
-// RoomRepo implementa domain.RoomRepository usando MySQL
+// RoomRepo implementa domain.RoomRepository usando MySQL, PostgreSQL o SQLite
 type RoomRepo struct {
-	db *sql.DB
+	db *infradb.DB
 }
 
-func NewRoomRepo(db *sql.DB) domain.RoomRepository {
+func NewRoomRepo(db *infradb.DB) domain.RoomRepository {
 	return &RoomRepo{db: db}
 }
 
-func (r *RoomRepo) Create(room *domain.Room) error {
-	query := `INSERT INTO rooms (code, host_id, status) VALUES (?, ?, ?)`
-	result, err := r.db.Exec(query, room.Code, room.HostID, room.Status)
-	if err != nil {
-		return err
-	}
-	id, err := result.LastInsertId()
+func (r *RoomRepo) Create(ctx context.Context, room *domain.Room) error {
+	query := `INSERT INTO rooms (code, host_id, status, ranking_mode, streak_mode, streak_step, streak_max) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	id, err := r.db.Insert(ctx, query, room.Code, room.HostID, room.Status, room.RankingMode,
+		room.Streak.Mode, room.Streak.Step, room.Streak.Max)
 	if err != nil {
 		return err
 	}
@@ -29,11 +28,12 @@ func (r *RoomRepo) Create(room *domain.Room) error {
 	return nil
 }
 
-func (r *RoomRepo) FindByCode(code string) (*domain.Room, error) {
+func (r *RoomRepo) FindByCode(ctx context.Context, code string) (*domain.Room, error) {
 	room := &domain.Room{}
-	query := `SELECT id, code, host_id, status, created_at FROM rooms WHERE code = ?`
-	err := r.db.QueryRow(query, code).Scan(
-		&room.ID, &room.Code, &room.HostID, &room.Status, &room.CreatedAt,
+	query := `SELECT id, code, host_id, status, ranking_mode, streak_mode, streak_step, streak_max, created_at FROM rooms WHERE code = ?`
+	err := r.db.QueryRowContext(ctx, query, code).Scan(
+		&room.ID, &room.Code, &room.HostID, &room.Status, &room.RankingMode,
+		&room.Streak.Mode, &room.Streak.Step, &room.Streak.Max, &room.CreatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -44,42 +44,52 @@ func (r *RoomRepo) FindByCode(code string) (*domain.Room, error) {
 	return room, nil
 }
 
-func (r *RoomRepo) UpdateStatus(code string, status domain.RoomStatus) error {
+func (r *RoomRepo) UpdateStatus(ctx context.Context, code string, status domain.RoomStatus) error {
 	query := `UPDATE rooms SET status = ? WHERE code = ?`
-	_, err := r.db.Exec(query, status, code)
+	_, err := r.db.ExecContext(ctx, query, status, code)
+	return err
+}
+
+func (r *RoomRepo) UpdateRankingMode(ctx context.Context, code string, mode domain.RankingMode) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE rooms SET ranking_mode = ? WHERE code = ?`, mode, code)
 	return err
 }
 
-// ParticipantRepo implementa domain.ParticipantRepository usando MySQL
+func (r *RoomRepo) UpdateStreak(ctx context.Context, code string, streak domain.StreakConfig) error {
+	query := `UPDATE rooms SET streak_mode = ?, streak_step = ?, streak_max = ? WHERE code = ?`
+	_, err := r.db.ExecContext(ctx, query, streak.Mode, streak.Step, streak.Max, code)
+	return err
+}
+
+// ParticipantRepo implementa domain.ParticipantRepository usando MySQL, PostgreSQL o SQLite
 type ParticipantRepo struct {
-	db *sql.DB
+	db *infradb.DB
 }
 
-func NewParticipantRepo(db *sql.DB) domain.ParticipantRepository {
+func NewParticipantRepo(db *infradb.DB) domain.ParticipantRepository {
 	return &ParticipantRepo{db: db}
 }
 
-func (r *ParticipantRepo) Add(p *domain.Participant) error {
+func (r *ParticipantRepo) Add(ctx context.Context, p *domain.Participant) error {
 	query := `INSERT INTO participants (room_id, user_id) VALUES (?, ?)`
-	result, err := r.db.Exec(query, p.RoomID, p.UserID)
+	id, err := r.db.Insert(ctx, query, p.RoomID, p.UserID)
 	if err != nil {
 		return err
 	}
-	id, _ := result.LastInsertId()
 	p.ID = int(id)
 	return nil
 }
 
-func (r *ParticipantRepo) ExistsInRoom(roomID, userID int) (bool, error) {
+func (r *ParticipantRepo) ExistsInRoom(ctx context.Context, roomID, userID int) (bool, error) {
 	var count int
 	query := `SELECT COUNT(*) FROM participants WHERE room_id = ? AND user_id = ?`
-	err := r.db.QueryRow(query, roomID, userID).Scan(&count)
+	err := r.db.QueryRowContext(ctx, query, roomID, userID).Scan(&count)
 	return count > 0, err
 }
 
-func (r *ParticipantRepo) FindByRoom(roomID int) ([]domain.Participant, error) {
+func (r *ParticipantRepo) FindByRoom(ctx context.Context, roomID int) ([]domain.Participant, error) {
 	query := `SELECT id, room_id, user_id, joined_at FROM participants WHERE room_id = ?`
-	rows, err := r.db.Query(query, roomID)
+	rows, err := r.db.QueryContext(ctx, query, roomID)
 	if err != nil {
 		return nil, err
 	}
@@ -97,7 +107,7 @@ func (r *ParticipantRepo) FindByRoom(roomID int) ([]domain.Participant, error) {
 }
 
 // FindByRoomWithUsers devuelve participantes con datos del usuario
-func (r *ParticipantRepo) FindByRoomWithUsers(roomID int) ([]domain.ParticipantWithUser, error) {
+func (r *ParticipantRepo) FindByRoomWithUsers(ctx context.Context, roomID int) ([]domain.ParticipantWithUser, error) {
 	query := `
 		SELECT u.id, u.name, u.email, p.joined_at
 		FROM participants p
@@ -105,7 +115,7 @@ func (r *ParticipantRepo) FindByRoomWithUsers(roomID int) ([]domain.ParticipantW
 		WHERE p.room_id = ?
 		ORDER BY p.joined_at ASC
 	`
-	rows, err := r.db.Query(query, roomID)
+	rows, err := r.db.QueryContext(ctx, query, roomID)
 	if err != nil {
 		return nil, err
 	}
@@ -123,7 +133,7 @@ func (r *ParticipantRepo) FindByRoomWithUsers(roomID int) ([]domain.ParticipantW
 }
 
 // Remove expulsa a un participante de la sala
-func (r *ParticipantRepo) Remove(roomID, userID int) error {
-	_, err := r.db.Exec(`DELETE FROM participants WHERE room_id = ? AND user_id = ?`, roomID, userID)
+func (r *ParticipantRepo) Remove(ctx context.Context, roomID, userID int) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM participants WHERE room_id = ? AND user_id = ?`, roomID, userID)
 	return err
-}
\ No newline at end of file
+}
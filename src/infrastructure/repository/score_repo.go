@@ -1,77 +1,162 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"time"
 
 	"apiGolan/src/domain"
+	infradb "apiGolan/src/infrastructure/db"
 )
 
-// ScoreRepo implementa domain.ScoreRepository usando MySQL
+// ScoreRepo implementa domain.ScoreRepository usando MySQL, PostgreSQL o SQLite
 type ScoreRepo struct {
-	db *sql.DB
+	db *infradb.DB
 }
 
-func NewScoreRepo(db *sql.DB) domain.ScoreRepository {
+func NewScoreRepo(db *infradb.DB) domain.ScoreRepository {
 	return &ScoreRepo{db: db}
 }
 
 // Upsert crea el registro de puntos si no existe, o lo actualiza si ya existe
-func (r *ScoreRepo) Upsert(roomID, userID, points int) error {
+func (r *ScoreRepo) Upsert(ctx context.Context, roomID, userID, points int) error {
 	query := `
 		INSERT INTO scores (room_id, user_id, points)
 		VALUES (?, ?, ?)
-		ON DUPLICATE KEY UPDATE points = VALUES(points)
-	`
-	_, err := r.db.Exec(query, roomID, userID, points)
+	` + r.db.Upsert("room_id, user_id", "points = excluded.points, updated_at = CURRENT_TIMESTAMP")
+	_, err := r.db.ExecContext(ctx, query, roomID, userID, points)
 	return err
 }
 
-// AddPoints suma o resta puntos al score actual del participante
-func (r *ScoreRepo) AddPoints(roomID, userID, delta int) error {
-	query := `
-		INSERT INTO scores (room_id, user_id, points)
-		VALUES (?, ?, ?)
-		ON DUPLICATE KEY UPDATE points = points + VALUES(points)
-	`
-	_, err := r.db.Exec(query, roomID, userID, delta)
-	return err
+// AddPoints registra el evento en el ledger y suma su Delta al score actual
+func (r *ScoreRepo) AddPoints(ctx context.Context, ev *domain.ScoreEvent) error {
+	return r.db.InTx(ctx, func(ctx context.Context, tx *infradb.Tx) error {
+		return recordScoreEvent(ctx, tx, ev)
+	})
 }
 
-// GetRanking devuelve los participantes ordenados por puntos de mayor a menor
-// Incluye el nombre del usuario haciendo JOIN con la tabla users
-func (r *ScoreRepo) GetRanking(roomID int) ([]domain.RankingEntry, error) {
-	query := `
-		SELECT s.user_id, u.name, s.points
+// rankingQuery calcula el puesto de cada participante con RANK o DENSE_RANK
+// sobre los puntos y las columnas de desempate: tiempo total de respuesta,
+// última respuesta correcta (las salas sin ninguna van al final) y unión a la
+// sala. Las respuestas se agregan una vez por usuario en la subconsulta t.
+func rankingQuery(mode domain.RankingMode) string {
+	rank := "RANK()"
+	if mode == domain.RankingDense {
+		rank = "DENSE_RANK()"
+	}
+	return `
+		SELECT s.user_id, u.name, s.points, s.streak,
+		       ` + rank + ` OVER (ORDER BY s.points DESC) AS position,
+		       COALESCE(t.total_ms, 0) AS total_ms,
+		       CASE WHEN t.last_correct IS NULL THEN 1 ELSE 0 END AS no_correct,
+		       t.last_correct,
+		       p.joined_at
 		FROM scores s
 		JOIN users u ON u.id = s.user_id
-		WHERE s.room_id = ?
-		ORDER BY s.points DESC
-	`
-	rows, err := r.db.Query(query, roomID)
+		LEFT JOIN participants p ON p.room_id = s.room_id AND p.user_id = s.user_id
+		LEFT JOIN (
+			SELECT a.user_id,
+			       SUM(a.response_ms) AS total_ms,
+			       MAX(CASE WHEN a.is_correct THEN a.answered_at END) AS last_correct
+			FROM answers a
+			JOIN questions q ON q.id = a.question_id
+			WHERE q.room_id = ?
+			GROUP BY a.user_id
+		) t ON t.user_id = s.user_id
+		WHERE s.room_id = ?`
+}
+
+// rankingOrder desempata dentro de un mismo puesto
+const rankingOrder = ` ORDER BY position, total_ms, no_correct, last_correct, joined_at, user_id`
+
+// GetRanking devuelve una página del ranking ordenada por puesto y desempates
+func (r *ScoreRepo) GetRanking(ctx context.Context, q domain.RankingQuery) ([]domain.RankingEntry, error) {
+	query := `SELECT user_id, name, points, position, total_ms, streak FROM (` + rankingQuery(q.Mode) + `) ranked` + rankingOrder
+	args := []interface{}{q.RoomID, q.RoomID}
+	if q.Limit > 0 {
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, q.Limit, q.Offset)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var ranking []domain.RankingEntry
-	position := 1
+	ranking := []domain.RankingEntry{}
 	for rows.Next() {
 		var entry domain.RankingEntry
-		if err := rows.Scan(&entry.UserID, &entry.UserName, &entry.Points); err != nil {
+		if err := rows.Scan(&entry.UserID, &entry.UserName, &entry.Points, &entry.Position, &entry.TotalResponseMS, &entry.Streak); err != nil {
 			return nil, err
 		}
-		entry.Position = position
-		position++
 		ranking = append(ranking, entry)
 	}
-	return ranking, nil
+	return ranking, rows.Err()
+}
+
+// GetRankingEntry calcula el ranking completo de la sala y devuelve solo la fila del usuario
+func (r *ScoreRepo) GetRankingEntry(ctx context.Context, q domain.RankingQuery, userID int) (*domain.RankingEntry, error) {
+	query := `SELECT user_id, name, points, position, total_ms, streak FROM (` + rankingQuery(q.Mode) + `) ranked WHERE user_id = ?`
+	entry := &domain.RankingEntry{}
+	err := r.db.QueryRowContext(ctx, query, q.RoomID, q.RoomID, userID).Scan(
+		&entry.UserID, &entry.UserName, &entry.Points, &entry.Position, &entry.TotalResponseMS, &entry.Streak,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// UpdateStreak actualiza la racha tras una respuesta. Un acierto crea el
+// score si no existe (como lo haría sumar sus puntos); un error solo corta la
+// racha de quien ya tiene score, así no aparece en el ranking quien no suma.
+func (r *ScoreRepo) UpdateStreak(ctx context.Context, roomID, userID int, correct bool) (int, error) {
+	var streak int
+	err := r.db.InTx(ctx, func(ctx context.Context, tx *infradb.Tx) error {
+		if correct {
+			query := `
+				INSERT INTO scores (room_id, user_id, points, streak)
+				VALUES (?, ?, 0, 1)
+			` + tx.Upsert("room_id, user_id", "streak = scores.streak + 1")
+			if _, err := tx.ExecContext(ctx, query, roomID, userID); err != nil {
+				return err
+			}
+		} else {
+			query := `UPDATE scores SET streak = 0 WHERE room_id = ? AND user_id = ?`
+			if _, err := tx.ExecContext(ctx, query, roomID, userID); err != nil {
+				return err
+			}
+		}
+
+		err := tx.QueryRowContext(ctx, `SELECT streak FROM scores WHERE room_id = ? AND user_id = ?`, roomID, userID).Scan(&streak)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return err
+	})
+	return streak, err
+}
+
+// ResetMissedStreaks corta la racha de los participantes de la sala que no
+// respondieron la pregunta; se llama al cerrarla
+func (r *ScoreRepo) ResetMissedStreaks(ctx context.Context, roomID, questionID int) error {
+	query := `
+		UPDATE scores SET streak = 0
+		WHERE room_id = ? AND streak > 0
+		  AND user_id NOT IN (SELECT user_id FROM answers WHERE question_id = ?)`
+	_, err := r.db.ExecContext(ctx, query, roomID, questionID)
+	return err
 }
 
 // GetByRoomAndUser devuelve el score de un usuario específico en una sala
-func (r *ScoreRepo) GetByRoomAndUser(roomID, userID int) (*domain.Score, error) {
+func (r *ScoreRepo) GetByRoomAndUser(ctx context.Context, roomID, userID int) (*domain.Score, error) {
 	score := &domain.Score{}
 	query := `SELECT id, room_id, user_id, points, updated_at FROM scores WHERE room_id = ? AND user_id = ?`
-	err := r.db.QueryRow(query, roomID, userID).Scan(
+	err := r.db.QueryRowContext(ctx, query, roomID, userID).Scan(
 		&score.ID, &score.RoomID, &score.UserID, &score.Points, &score.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
@@ -80,14 +165,241 @@ func (r *ScoreRepo) GetByRoomAndUser(roomID, userID int) (*domain.Score, error)
 	return score, err
 }
 
-// ResetPoints resetea los puntos de un participante específico a 0
-func (r *ScoreRepo) ResetPoints(roomID, userID int) error {
-	_, err := r.db.Exec(`UPDATE scores SET points = 0 WHERE room_id = ? AND user_id = ?`, roomID, userID)
-	return err
+// ResetPoints deja en 0 los puntos de un participante. El evento reset lleva
+// lo que tenía en negativo; si ya estaba en 0 no se registra nada.
+func (r *ScoreRepo) ResetPoints(ctx context.Context, ev domain.ScoreEvent) error {
+	return r.resetScores(ctx, ev.RoomID, ev.UserID, ev.ActorID, ev.Reason)
+}
+
+// ResetAllPoints deja en 0 los puntos de todos los participantes de una sala
+func (r *ScoreRepo) ResetAllPoints(ctx context.Context, roomID, actorID int, reason string) error {
+	return r.resetScores(ctx, roomID, 0, actorID, reason)
+}
+
+// resetScores pone en 0 los scores de la sala (o solo el de userID si no es 0)
+// con un evento reset por cada uno que no estaba en 0
+func (r *ScoreRepo) resetScores(ctx context.Context, roomID, userID, actorID int, reason string) error {
+	return r.db.InTx(ctx, func(ctx context.Context, tx *infradb.Tx) error {
+		current, err := lockScores(ctx, tx, roomID, userID)
+		if err != nil {
+			return err
+		}
+		for _, sc := range current {
+			if sc.Points == 0 {
+				continue
+			}
+			ev := &domain.ScoreEvent{
+				RoomID:  roomID,
+				UserID:  sc.UserID,
+				ActorID: actorID,
+				Source:  domain.ScoreSourceReset,
+				Delta:   -sc.Points,
+				Reason:  reason,
+			}
+			if err := recordScoreEvent(ctx, tx, ev); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+const scoreEventSelect = `
+	SELECT e.id, e.room_id, e.user_id, u.name, e.actor_id, COALESCE(a.name, ''), e.source, e.delta,
+	       e.reason, e.question_id, e.reverts_id, rv.id, e.created_at
+	FROM score_events e
+	JOIN users u ON u.id = e.user_id
+	LEFT JOIN users a ON a.id = e.actor_id
+	LEFT JOIN score_events rv ON rv.reverts_id = e.id`
+
+func scanScoreEvent(row interface{ Scan(...interface{}) error }) (*domain.ScoreEvent, error) {
+	ev := &domain.ScoreEvent{}
+	var actorID, questionID, revertsID, revertedBy sql.NullInt64
+	err := row.Scan(&ev.ID, &ev.RoomID, &ev.UserID, &ev.UserName, &actorID, &ev.ActorName, &ev.Source, &ev.Delta,
+		&ev.Reason, &questionID, &revertsID, &revertedBy, &ev.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	ev.ActorID = int(actorID.Int64)
+	ev.QuestionID = int(questionID.Int64)
+	ev.RevertsID = int(revertsID.Int64)
+	ev.RevertedBy = int(revertedBy.Int64)
+	return ev, nil
 }
 
-// ResetAllPoints resetea los puntos de todos los participantes de una sala
-func (r *ScoreRepo) ResetAllPoints(roomID int) error {
-	_, err := r.db.Exec(`UPDATE scores SET points = 0 WHERE room_id = ?`, roomID)
+func (r *ScoreRepo) ListEvents(ctx context.Context, filter domain.ScoreEventFilter) ([]domain.ScoreEvent, error) {
+	query := scoreEventSelect + ` WHERE e.room_id = ?`
+	args := []interface{}{filter.RoomID}
+	if filter.UserID != 0 {
+		query += ` AND e.user_id = ?`
+		args = append(args, filter.UserID)
+	}
+	query += ` ORDER BY e.id DESC LIMIT ? OFFSET ?`
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []domain.ScoreEvent{}
+	for rows.Next() {
+		ev, err := scanScoreEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, *ev)
+	}
+	return events, rows.Err()
+}
+
+func (r *ScoreRepo) FindEvent(ctx context.Context, id int) (*domain.ScoreEvent, error) {
+	return scanScoreEvent(r.db.QueryRowContext(ctx, scoreEventSelect+` WHERE e.id = ?`, id))
+}
+
+// Revert registra el evento undo y aplica su Delta. El score del participante
+// se bloquea antes de comprobar si el evento ya fue deshecho, así dos undo
+// simultáneos del mismo evento no pasan los dos (reverts_id además es UNIQUE).
+func (r *ScoreRepo) Revert(ctx context.Context, undo *domain.ScoreEvent) (bool, error) {
+	reverted := false
+	err := r.db.InTx(ctx, func(ctx context.Context, tx *infradb.Tx) error {
+		if _, err := lockScores(ctx, tx, undo.RoomID, undo.UserID); err != nil {
+			return err
+		}
+		var count int
+		query := `SELECT COUNT(*) FROM score_events WHERE reverts_id = ?`
+		if err := tx.QueryRowContext(ctx, query, undo.RevertsID).Scan(&count); err != nil || count > 0 {
+			return err
+		}
+		if err := recordScoreEvent(ctx, tx, undo); err != nil {
+			return err
+		}
+		reverted = true
+		return nil
+	})
+	return reverted && err == nil, err
+}
+
+// Reconcile recalcula scores.points como la suma del ledger de cada participante
+func (r *ScoreRepo) Reconcile(ctx context.Context, roomID int) ([]domain.ScoreDrift, error) {
+	drifts := []domain.ScoreDrift{}
+	err := r.db.InTx(ctx, func(ctx context.Context, tx *infradb.Tx) error {
+		if _, err := lockScores(ctx, tx, roomID, 0); err != nil {
+			return err
+		}
+		query := `
+			SELECT s.user_id, s.points,
+			       COALESCE((SELECT SUM(e.delta) FROM score_events e WHERE e.room_id = s.room_id AND e.user_id = s.user_id), 0)
+			FROM scores s
+			WHERE s.room_id = ?`
+		rows, err := tx.QueryContext(ctx, query, roomID)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var d domain.ScoreDrift
+			if err := rows.Scan(&d.UserID, &d.Points, &d.Ledger); err != nil {
+				rows.Close()
+				return err
+			}
+			if d.Points != d.Ledger {
+				drifts = append(drifts, d)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, d := range drifts {
+			query := `UPDATE scores SET points = ?, updated_at = CURRENT_TIMESTAMP WHERE room_id = ? AND user_id = ?`
+			if _, err := tx.ExecContext(ctx, query, d.Ledger, roomID, d.UserID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return drifts, nil
+}
+
+// recordScoreEvent registra un cambio de puntos: agrega el evento al ledger,
+// aplica su Delta al score y lo suma a los leaderboards que incluyen la sala
+func recordScoreEvent(ctx context.Context, tx *infradb.Tx, ev *domain.ScoreEvent) error {
+	if err := appendScoreEvent(ctx, tx, ev); err != nil {
+		return err
+	}
+	if err := applyDelta(ctx, tx, ev.RoomID, ev.UserID, ev.Delta); err != nil {
+		return err
+	}
+	return applyLeaderboards(ctx, tx, ev)
+}
+
+// appendScoreEvent agrega el evento al ledger y completa su ID y CreatedAt
+func appendScoreEvent(ctx context.Context, tx *infradb.Tx, ev *domain.ScoreEvent) error {
+	ev.CreatedAt = time.Now().UTC()
+	query := `INSERT INTO score_events (room_id, user_id, actor_id, source, delta, reason, question_id, reverts_id, created_at)
+	          VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	id, err := tx.Insert(ctx, query, ev.RoomID, ev.UserID, nullID(ev.ActorID), ev.Source, ev.Delta, ev.Reason,
+		nullID(ev.QuestionID), nullID(ev.RevertsID), ev.CreatedAt)
+	if err != nil {
+		return err
+	}
+	ev.ID = int(id)
+	return nil
+}
+
+// applyDelta suma delta al score del participante, creándolo si no existe
+func applyDelta(ctx context.Context, tx *infradb.Tx, roomID, userID, delta int) error {
+	query := `
+		INSERT INTO scores (room_id, user_id, points)
+		VALUES (?, ?, ?)
+	` + tx.Upsert("room_id, user_id", "points = scores.points + excluded.points, updated_at = CURRENT_TIMESTAMP")
+	_, err := tx.ExecContext(ctx, query, roomID, userID, delta)
 	return err
-}
\ No newline at end of file
+}
+
+// lockScores bloquea los scores de la sala (o solo el de userID si no es 0)
+// escribiendo en ellos antes de leerlos, y devuelve sus puntos. Escribir
+// primero funciona en los tres motores (SQLite no tiene SELECT ... FOR UPDATE)
+// y evita que otro cambio se cuele entre la lectura y el evento del ledger.
+func lockScores(ctx context.Context, tx *infradb.Tx, roomID, userID int) ([]domain.Score, error) {
+	where := ` WHERE room_id = ?`
+	args := []interface{}{roomID}
+	if userID != 0 {
+		where += ` AND user_id = ?`
+		args = append(args, userID)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE scores SET updated_at = CURRENT_TIMESTAMP`+where, args...); err != nil {
+		return nil, err
+	}
+
+	// en orden de usuario, así dos transacciones que tocan los mismos
+	// leaderboards actualizan sus filas en el mismo orden
+	rows, err := tx.QueryContext(ctx, `SELECT user_id, points FROM scores`+where+` ORDER BY user_id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var scores []domain.Score
+	for rows.Next() {
+		sc := domain.Score{RoomID: roomID}
+		if err := rows.Scan(&sc.UserID, &sc.Points); err != nil {
+			return nil, err
+		}
+		scores = append(scores, sc)
+	}
+	return scores, rows.Err()
+}
+
+// nullID guarda NULL en lugar de 0 para referencias opcionales
+func nullID(id int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(id), Valid: id != 0}
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"apiGolan/src/domain"
+	infradb "apiGolan/src/infrastructure/db"
+)
+
+// TokenRepo implementa domain.TokenRepository usando MySQL, PostgreSQL o SQLite
+type TokenRepo struct {
+	db *infradb.DB
+}
+
+func NewTokenRepo(db *infradb.DB) domain.TokenRepository {
+	return &TokenRepo{db: db}
+}
+
+func (r *TokenRepo) Create(ctx context.Context, t *domain.UserToken) error {
+	query := `INSERT INTO user_tokens (user_id, purpose, token_hash, expires_at) VALUES (?, ?, ?, ?)`
+	id, err := r.db.Insert(ctx, query, t.UserID, t.Purpose, t.TokenHash, t.ExpiresAt.UTC())
+	if err != nil {
+		return err
+	}
+	t.ID = int(id)
+	return nil
+}
+
+func (r *TokenRepo) FindByHash(ctx context.Context, purpose domain.TokenPurpose, hash string) (*domain.UserToken, error) {
+	t := &domain.UserToken{}
+	var usedAt sql.NullTime
+	query := `SELECT id, user_id, purpose, token_hash, expires_at, used_at, created_at FROM user_tokens WHERE purpose = ? AND token_hash = ?`
+	err := r.db.QueryRowContext(ctx, query, purpose, hash).Scan(
+		&t.ID, &t.UserID, &t.Purpose, &t.TokenHash, &t.ExpiresAt, &usedAt, &t.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if usedAt.Valid {
+		t.UsedAt = &usedAt.Time
+	}
+	return t, nil
+}
+
+// MarkUsed marca el token como usado; devuelve false si otro request lo usó antes
+func (r *TokenRepo) MarkUsed(ctx context.Context, id int) (bool, error) {
+	query := `UPDATE user_tokens SET used_at = ? WHERE id = ? AND used_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, time.Now().UTC(), id)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n == 1, err
+}
+
+func (r *TokenRepo) InvalidateAll(ctx context.Context, userID int, purpose domain.TokenPurpose) error {
+	query := `UPDATE user_tokens SET used_at = ? WHERE user_id = ? AND purpose = ? AND used_at IS NULL`
+	_, err := r.db.ExecContext(ctx, query, time.Now().UTC(), userID, purpose)
+	return err
+}
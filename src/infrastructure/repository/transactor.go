@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+
+	"apiGolan/src/domain"
+	infradb "apiGolan/src/infrastructure/db"
+)
+
+// Transactor implementa domain.Transactor con infradb.DB.InTx: los
+// repositorios que reciben el ctx de fn usan la transacción abierta
+type Transactor struct {
+	db *infradb.DB
+}
+
+func NewTransactor(db *infradb.DB) domain.Transactor {
+	return &Transactor{db: db}
+}
+
+func (t *Transactor) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return t.db.InTx(ctx, func(ctx context.Context, _ *infradb.Tx) error {
+		return fn(ctx)
+	})
+}
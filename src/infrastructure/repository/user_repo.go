@@ -1,26 +1,26 @@
 package repository
 
 import (
-"database/sql"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
 
-"apiGolan/src/domain"
+	"apiGolan/src/domain"
+	infradb "apiGolan/src/infrastructure/db"
 )
 
 type UserRepo struct {
-	db *sql.DB
+	db *infradb.DB
 }
 
-func NewUserRepo(db *sql.DB) domain.UserRepository {
+func NewUserRepo(db *infradb.DB) domain.UserRepository {
 	return &UserRepo{db: db}
 }
 
-func (r *UserRepo) Create(user *domain.User) error {
-	query := `INSERT INTO users (name, email, password, role) VALUES (?, ?, ?, ?)`
-	result, err := r.db.Exec(query, user.Name, user.Email, user.Password, user.Role)
-	if err != nil {
-		return err
-	}
-	id, err := result.LastInsertId()
+func (r *UserRepo) Create(ctx context.Context, user *domain.User) error {
+	query := `INSERT INTO users (name, email, password, role, email_verified, host_requested) VALUES (?, ?, ?, ?, ?, ?)`
+	id, err := r.db.Insert(ctx, query, user.Name, user.Email, user.Password, user.Role, user.EmailVerified, user.HostRequested)
 	if err != nil {
 		return err
 	}
@@ -28,11 +28,15 @@ func (r *UserRepo) Create(user *domain.User) error {
 	return nil
 }
 
-func (r *UserRepo) FindByEmail(email string) (*domain.User, error) {
+// userColumns son las columnas que lee scanUser, en el mismo orden
+const userColumns = `id, name, email, password, role, created_at, failed_logins, locked_until, email_verified, deleted_at, host_requested`
+
+func scanUser(row interface{ Scan(...interface{}) error }) (*domain.User, error) {
 	user := &domain.User{}
-	query := `SELECT id, name, email, password, role, created_at FROM users WHERE email = ?`
-	err := r.db.QueryRow(query, email).Scan(
-&user.ID, &user.Name, &user.Email, &user.Password, &user.Role, &user.CreatedAt,
+	var lockedUntil, deletedAt sql.NullTime
+	err := row.Scan(
+		&user.ID, &user.Name, &user.Email, &user.Password, &user.Role, &user.CreatedAt,
+		&user.FailedLogins, &lockedUntil, &user.EmailVerified, &deletedAt, &user.HostRequested,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -40,20 +44,117 @@ func (r *UserRepo) FindByEmail(email string) (*domain.User, error) {
 	if err != nil {
 		return nil, err
 	}
+	if lockedUntil.Valid {
+		user.LockedUntil = &lockedUntil.Time
+	}
+	if deletedAt.Valid {
+		user.DeletedAt = &deletedAt.Time
+	}
 	return user, nil
 }
 
-func (r *UserRepo) FindByID(id int) (*domain.User, error) {
-	user := &domain.User{}
-	query := `SELECT id, name, email, password, role, created_at FROM users WHERE id = ?`
-	err := r.db.QueryRow(query, id).Scan(
-&user.ID, &user.Name, &user.Email, &user.Password, &user.Role, &user.CreatedAt,
-	)
-	if err == sql.ErrNoRows {
-		return nil, nil
+func (r *UserRepo) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = ?`
+	return scanUser(r.db.QueryRowContext(ctx, query, email))
+}
+
+func (r *UserRepo) FindByID(ctx context.Context, id int) (*domain.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = ?`
+	return scanUser(r.db.QueryRowContext(ctx, query, id))
+}
+
+// RecordLoginFailure suma un intento fallido y guarda el bloqueo (nil = sin bloqueo)
+func (r *UserRepo) RecordLoginFailure(ctx context.Context, id int, lockedUntil *time.Time) error {
+	query := `UPDATE users SET failed_logins = failed_logins + 1, locked_until = ? WHERE id = ?`
+	var until interface{}
+	if lockedUntil != nil {
+		until = lockedUntil.UTC()
+	}
+	_, err := r.db.ExecContext(ctx, query, until, id)
+	return err
+}
+
+// ResetLoginFailures limpia los intentos fallidos tras un login correcto
+func (r *UserRepo) ResetLoginFailures(ctx context.Context, id int) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE users SET failed_logins = 0, locked_until = NULL WHERE id = ?`, id)
+	return err
+}
+
+// UpdatePassword reemplaza el hash de la contraseña y limpia el bloqueo
+func (r *UserRepo) UpdatePassword(ctx context.Context, id int, hashed string) error {
+	query := `UPDATE users SET password = ?, failed_logins = 0, locked_until = NULL WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, hashed, id)
+	return err
+}
+
+func (r *UserRepo) MarkEmailVerified(ctx context.Context, id int) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE users SET email_verified = TRUE WHERE id = ?`, id)
+	return err
+}
+
+func (r *UserRepo) UpdateName(ctx context.Context, id int, name string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE users SET name = ? WHERE id = ?`, name, id)
+	return err
+}
+
+// Anonymize elimina la cuenta sin borrar la fila: las respuestas, puntos y
+// salas siguen apuntando al mismo id (los ON DELETE CASCADE no se disparan),
+// pero nombre, email y contraseña dejan de identificar a la persona.
+// El email queda único y la contraseña vacía no coincide con ningún bcrypt.
+// También se desvinculan las cuentas externas para que el SSO no vuelva a
+// entrar y se borran sus API keys, que si no seguirían autenticando.
+func (r *UserRepo) Anonymize(ctx context.Context, id int) error {
+	return r.db.InTx(ctx, func(ctx context.Context, tx *infradb.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM user_identities WHERE user_id = ?`, id); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM api_keys WHERE user_id = ?`, id); err != nil {
+			return err
+		}
+
+		query := `
+			UPDATE users
+			SET name = 'Usuario eliminado', email = ?, password = '',
+			    email_verified = FALSE, host_requested = FALSE, failed_logins = 0, locked_until = NULL, deleted_at = ?
+			WHERE id = ?`
+		email := fmt.Sprintf("deleted-%d@deleted.invalid", id)
+		_, err := tx.ExecContext(ctx, query, email, time.Now().UTC(), id)
+		return err
+	})
+}
+
+// List devuelve los usuarios no eliminados, del más reciente al más antiguo
+func (r *UserRepo) List(ctx context.Context, filter domain.UserFilter) ([]domain.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE deleted_at IS NULL`
+	var args []interface{}
+	if filter.Role != "" {
+		query += ` AND role = ?`
+		args = append(args, filter.Role)
+	}
+	if filter.HostRequested {
+		query += ` AND host_requested = TRUE`
 	}
+	query += ` ORDER BY id DESC LIMIT ? OFFSET ?`
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
-	return user, nil
+	defer rows.Close()
+
+	users := []domain.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *user)
+	}
+	return users, rows.Err()
+}
+
+func (r *UserRepo) UpdateRole(ctx context.Context, id int, role domain.Role) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE users SET role = ?, host_requested = FALSE WHERE id = ?`, role, id)
+	return err
 }
@@ -0,0 +1,115 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// PresenceRefresh es cada cuánto el Hub vuelve a publicar su presencia en el
+// broker aunque no haya cambios. Los brokers compartidos deben expirar la
+// presencia de una instancia si no la refresca en varias vueltas (así una
+// instancia caída no deja usuarios fantasma).
+const PresenceRefresh = 20 * time.Second
+
+// Event es un mensaje de sala ya serializado, tal como viaja por el broker
+type Event struct {
+	ID     uint64          `json:"id"` // orden de emisión; SSE lo usa para reanudar
+	Room   string          `json:"room"`
+	Name   string          `json:"event"`
+	Data   json.RawMessage `json:"data"`              // Message serializado
+	UserID int             `json:"user_id,omitempty"` // si no es 0, solo para ese usuario
+	Roles  []string        `json:"roles,omitempty"`   // si no está vacío, solo para esos roles
+}
+
+// visibleTo indica si el evento va dirigido al cliente
+func (ev Event) visibleTo(info ClientInfo) bool {
+	if ev.UserID != 0 && ev.UserID != info.UserID {
+		return false
+	}
+	if len(ev.Roles) == 0 {
+		return true
+	}
+	for _, role := range ev.Roles {
+		if role == info.Role {
+			return true
+		}
+	}
+	return false
+}
+
+// lastEventID es el último id asignado por esta instancia
+var lastEventID atomic.Uint64
+
+// nextEventID devuelve un id creciente basado en el reloj, para que los ids de
+// distintas instancias queden aproximadamente en el orden en que se emitieron
+func nextEventID() uint64 {
+	for {
+		prev := lastEventID.Load()
+		id := uint64(time.Now().UnixNano())
+		if id <= prev {
+			id = prev + 1
+		}
+		if lastEventID.CompareAndSwap(prev, id) {
+			return id
+		}
+	}
+}
+
+// Broker reparte los mensajes de una sala entre todas las instancias de la API.
+// Hub.Broadcast publica en el broker y cada instancia entrega a sus clientes
+// locales lo que recibe de su suscripción, incluido lo que publicó ella misma.
+type Broker interface {
+	// Publish envía un evento a todas las instancias
+	Publish(ctx context.Context, ev Event) error
+	// Subscribe registra la función que entrega los eventos a los clientes locales
+	Subscribe(deliver func(ev Event))
+	// SetPresence reemplaza la presencia de esta instancia en la sala (vacía = ninguna)
+	SetPresence(ctx context.Context, room string, users []ClientInfo) error
+	// RemotePresence devuelve la presencia publicada por las demás instancias
+	RemotePresence(ctx context.Context, room string) ([]ClientInfo, error)
+	// Close libera la suscripción y retira la presencia de esta instancia
+	Close() error
+}
+
+// LocalBroker es el broker de una sola instancia: entrega en el mismo proceso
+// y no hay presencia remota. Es el que se usa si no se configura Redis.
+type LocalBroker struct {
+	mu      sync.RWMutex
+	deliver func(ev Event)
+}
+
+func NewLocalBroker() *LocalBroker {
+	return &LocalBroker{}
+}
+
+func (b *LocalBroker) Publish(ctx context.Context, ev Event) error {
+	b.mu.RLock()
+	deliver := b.deliver
+	b.mu.RUnlock()
+
+	if deliver != nil {
+		deliver(ev)
+	}
+	return nil
+}
+
+func (b *LocalBroker) Subscribe(deliver func(ev Event)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.deliver = deliver
+}
+
+func (b *LocalBroker) SetPresence(ctx context.Context, room string, users []ClientInfo) error {
+	return nil
+}
+
+func (b *LocalBroker) RemotePresence(ctx context.Context, room string) ([]ClientInfo, error) {
+	return nil, nil
+}
+
+func (b *LocalBroker) Close() error {
+	return nil
+}
@@ -0,0 +1,344 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryNetwork simula el Redis compartido por varias instancias: reparte
+// los eventos a todas las suscripciones y guarda la presencia de cada
+// instancia con vencimiento, como el broker de Redis
+type memoryNetwork struct {
+	mu       sync.Mutex
+	now      time.Time
+	ttl      time.Duration
+	brokers  []*memoryBroker
+	presence map[string]map[*memoryBroker]memoryPresence // sala → instancia → presencia
+}
+
+type memoryPresence struct {
+	users     []ClientInfo
+	expiresAt time.Time
+}
+
+func newMemoryNetwork() *memoryNetwork {
+	return &memoryNetwork{
+		now:      time.Unix(0, 0),
+		ttl:      3 * PresenceRefresh,
+		presence: make(map[string]map[*memoryBroker]memoryPresence),
+	}
+}
+
+// advance adelanta el reloj con el que vence la presencia
+func (n *memoryNetwork) advance(d time.Duration) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.now = n.now.Add(d)
+}
+
+// instance devuelve el broker de una instancia nueva conectada a la red
+func (n *memoryNetwork) instance() *memoryBroker {
+	b := &memoryBroker{net: n}
+	n.mu.Lock()
+	n.brokers = append(n.brokers, b)
+	n.mu.Unlock()
+	return b
+}
+
+// memoryBroker implementa Broker sobre memoryNetwork
+type memoryBroker struct {
+	net     *memoryNetwork
+	deliver func(ev Event)
+	fail    error // si no es nil, Publish devuelve este error
+}
+
+func (b *memoryBroker) Publish(ctx context.Context, ev Event) error {
+	if b.fail != nil {
+		return b.fail
+	}
+	b.net.mu.Lock()
+	subscribers := make([]func(Event), 0, len(b.net.brokers))
+	for _, other := range b.net.brokers {
+		if other.deliver != nil {
+			subscribers = append(subscribers, other.deliver)
+		}
+	}
+	b.net.mu.Unlock()
+
+	for _, deliver := range subscribers {
+		deliver(ev)
+	}
+	return nil
+}
+
+func (b *memoryBroker) Subscribe(deliver func(ev Event)) {
+	b.net.mu.Lock()
+	defer b.net.mu.Unlock()
+	b.deliver = deliver
+}
+
+func (b *memoryBroker) SetPresence(ctx context.Context, room string, users []ClientInfo) error {
+	b.net.mu.Lock()
+	defer b.net.mu.Unlock()
+
+	if len(users) == 0 {
+		delete(b.net.presence[room], b)
+		return nil
+	}
+	if b.net.presence[room] == nil {
+		b.net.presence[room] = make(map[*memoryBroker]memoryPresence)
+	}
+	b.net.presence[room][b] = memoryPresence{
+		users:     append([]ClientInfo(nil), users...),
+		expiresAt: b.net.now.Add(b.net.ttl),
+	}
+	return nil
+}
+
+func (b *memoryBroker) RemotePresence(ctx context.Context, room string) ([]ClientInfo, error) {
+	b.net.mu.Lock()
+	defer b.net.mu.Unlock()
+
+	var users []ClientInfo
+	for instance, p := range b.net.presence[room] {
+		if instance == b || !b.net.now.Before(p.expiresAt) {
+			continue
+		}
+		users = append(users, p.users...)
+	}
+	return users, nil
+}
+
+func (b *memoryBroker) Close() error {
+	b.net.mu.Lock()
+	defer b.net.mu.Unlock()
+
+	b.deliver = nil
+	for _, instances := range b.net.presence {
+		delete(instances, b)
+	}
+	return nil
+}
+
+// eventually reintenta cond hasta que se cumpla; la presencia se publica en
+// el broker desde syncPresence, en otra goroutine
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("no se cumplió: %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// statuses resume la presencia como userID → estado
+func statuses(users []ClientInfo) map[int]string {
+	out := make(map[int]string, len(users))
+	for _, u := range users {
+		out[u.UserID] = u.Status
+	}
+	return out
+}
+
+func newInstance(t *testing.T, net *memoryNetwork) *Hub {
+	t.Helper()
+	h := NewHub(testConfig(32, PolicyDropOldest), net.instance())
+	t.Cleanup(func() { shutdown(t, h) })
+	return h
+}
+
+func subscribe(t *testing.T, h *Hub, userID int, role string) *Client {
+	t.Helper()
+	c := h.Subscribe("ABC123", ClientInfo{UserID: userID, Role: role}, "")
+	if c == nil {
+		t.Fatal("Subscribe devolvió nil")
+	}
+	// antes que el Shutdown de newInstance, que espera a los clientes
+	t.Cleanup(func() { h.Unsubscribe(c) })
+	return c
+}
+
+// received devuelve los nombres de los eventos pendientes del cliente, en orden
+func received(c *Client) []string {
+	events, _ := c.Take()
+	out := make([]string, 0, len(events))
+	for _, e := range events {
+		out = append(out, e.Name)
+	}
+	return out
+}
+
+func TestBrokerFanOutAcrossInstances(t *testing.T) {
+	net := newMemoryNetwork()
+	a, b := newInstance(t, net), newInstance(t, net)
+
+	host := subscribe(t, a, 1, "host")
+	ana := subscribe(t, b, 2, "participant")
+	beto := subscribe(t, b, 3, "participant")
+	received(host)
+	received(ana)
+	received(beto)
+
+	a.Broadcast("ABC123", Message{Event: "question_started"})
+	b.SendToUser("ABC123", 2, Message{Event: "answer_result"})
+	b.BroadcastToRoles("ABC123", []string{"host"}, Message{Event: "answer_received"})
+	a.Broadcast("OTRA01", Message{Event: "question_started"})
+
+	tests := []struct {
+		name   string
+		client *Client
+		want   []string
+	}{
+		{"host en la instancia A", host, []string{"question_started", "answer_received"}},
+		{"ana en la instancia B", ana, []string{"question_started", "answer_result"}},
+		{"beto en la instancia B", beto, []string{"question_started"}},
+	}
+	for _, tt := range tests {
+		if got := received(tt.client); !equal(got, tt.want) {
+			t.Errorf("%s recibió %v, se esperaba %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBrokerPublishFailureDeliversLocally(t *testing.T) {
+	net := newMemoryNetwork()
+	broker := net.instance()
+	a := NewHub(testConfig(32, PolicyDropOldest), broker)
+	t.Cleanup(func() { shutdown(t, a) })
+	b := newInstance(t, net)
+
+	local := subscribe(t, a, 1, "host")
+	remote := subscribe(t, b, 2, "participant")
+	received(local)
+	received(remote)
+
+	broker.fail = errors.New("redis caído")
+	a.Broadcast("ABC123", Message{Event: "question_started"})
+	if got := received(local); !equal(got, []string{"question_started"}) {
+		t.Errorf("el cliente local recibió %v", got)
+	}
+	if got := received(remote); len(got) != 0 {
+		t.Errorf("sin broker el cliente remoto recibió %v", got)
+	}
+}
+
+func TestBrokerPresenceMergesInstances(t *testing.T) {
+	net := newMemoryNetwork()
+	a, b := newInstance(t, net), newInstance(t, net)
+
+	subscribe(t, a, 1, "host")
+	anaA := a.Subscribe("ABC123", ClientInfo{UserID: 2, Role: "participant"}, "")
+	anaB := subscribe(t, b, 2, "participant") // ana con una pestaña en cada instancia
+	subscribe(t, b, 3, "participant")
+
+	eventually(t, "cada instancia ve a los usuarios de la otra", func() bool {
+		return len(a.GetOnlineUsers("ABC123")) == 3 && len(b.GetOnlineUsers("ABC123")) == 3
+	})
+	for _, h := range []*Hub{a, b} {
+		users := h.GetOnlineUsers("ABC123")
+		ids := make([]int, 0, len(users))
+		for _, u := range users {
+			ids = append(ids, u.UserID)
+			if u.Status != StatusOnline {
+				t.Errorf("usuario %d con estado %q", u.UserID, u.Status)
+			}
+		}
+		sort.Ints(ids)
+		if len(ids) != 3 || ids[0] != 1 || ids[1] != 2 || ids[2] != 3 {
+			t.Errorf("presencia combinada = %v, se esperaba un usuario por id", ids)
+		}
+	}
+
+	// ana cierra la pestaña de A: sigue online porque tiene la de B
+	received(anaB)
+	a.Unsubscribe(anaA)
+	if got := statuses(a.GetOnlineUsers("ABC123"))[2]; got != StatusOnline {
+		t.Errorf("ana con una pestaña abierta en B figura %q en A", got)
+	}
+	for _, name := range received(anaB) {
+		if name == "presence_changed" {
+			t.Error("se anunció la desconexión de ana aunque sigue conectada en B")
+		}
+	}
+}
+
+func TestBrokerPresenceExpiresWithoutRefresh(t *testing.T) {
+	net := newMemoryNetwork()
+	a, b := newInstance(t, net), newInstance(t, net)
+
+	subscribe(t, a, 1, "host")
+	subscribe(t, b, 2, "participant")
+	eventually(t, "A ve a los usuarios de B", func() bool {
+		return len(a.GetOnlineUsers("ABC123")) == 2
+	})
+
+	// B deja de refrescar (se cayó sin cerrar el broker): pasado el TTL sus
+	// usuarios dejan de figurar en A
+	net.advance(2 * PresenceRefresh)
+	if got := len(a.GetOnlineUsers("ABC123")); got != 2 {
+		t.Errorf("antes del TTL A ve %d usuarios, se esperaban 2", got)
+	}
+	net.advance(PresenceRefresh)
+	if got := statuses(a.GetOnlineUsers("ABC123")); len(got) != 1 || got[1] != StatusOnline {
+		t.Errorf("tras el TTL A ve %v, se esperaba solo al usuario 1", got)
+	}
+}
+
+func TestBrokerPresenceRemovedOnShutdown(t *testing.T) {
+	net := newMemoryNetwork()
+	a := newInstance(t, net)
+	b := NewHub(testConfig(32, PolicyDropOldest), net.instance())
+
+	subscribe(t, a, 1, "host")
+	c := b.Subscribe("ABC123", ClientInfo{UserID: 2, Role: "participant"}, "")
+	eventually(t, "A ve a los usuarios de B", func() bool {
+		return len(a.GetOnlineUsers("ABC123")) == 2
+	})
+
+	go func() {
+		for {
+			<-c.Ready()
+			if _, closed := c.Take(); closed {
+				b.Unsubscribe(c)
+				return
+			}
+		}
+	}()
+	shutdown(t, b)
+	if got := statuses(a.GetOnlineUsers("ABC123")); len(got) != 1 {
+		t.Errorf("tras apagar B, A ve %v", got)
+	}
+}
+
+func TestEventVisibleTo(t *testing.T) {
+	host := ClientInfo{UserID: 1, Role: "host"}
+	ana := ClientInfo{UserID: 2, Role: "participant"}
+	admin := ClientInfo{UserID: 3, Role: "admin"}
+
+	tests := []struct {
+		name string
+		ev   Event
+		want map[int]bool // userID → lo recibe
+	}{
+		{"a toda la sala", Event{}, map[int]bool{1: true, 2: true, 3: true}},
+		{"a un usuario", Event{UserID: 2}, map[int]bool{2: true}},
+		{"a un rol, sin admins si no se listan", Event{Roles: []string{"host"}}, map[int]bool{1: true}},
+		{"a varios roles", Event{Roles: []string{"host", "admin"}}, map[int]bool{1: true, 3: true}},
+		{"usuario y rol a la vez", Event{UserID: 2, Roles: []string{"host"}}, map[int]bool{}},
+		{"usuario con su rol", Event{UserID: 1, Roles: []string{"host"}}, map[int]bool{1: true}},
+		{"usuario que no está", Event{UserID: 9}, map[int]bool{}},
+	}
+	for _, tt := range tests {
+		for _, info := range []ClientInfo{host, ana, admin} {
+			if got := tt.ev.visibleTo(info); got != tt.want[info.UserID] {
+				t.Errorf("%s: visibleTo(%s %d) = %v", tt.name, info.Role, info.UserID, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package websocket
+
+import "time"
+
+// Config ajusta los tiempos de las conexiones y de la presencia
+type Config struct {
+	PingInterval    time.Duration // cada cuánto se envía un ping
+	PongWait        time.Duration // sin pong (ni mensaje) en este plazo la conexión se da por muerta
+	WriteWait       time.Duration // plazo máximo para escribir un mensaje
+	MaxMessageSize  int64         // tamaño máximo de un mensaje del cliente, en bytes
+	IdleAfter       time.Duration // sin actividad del cliente pasa a "idle"
+	DisconnectGrace time.Duration // espera antes de anunciar participant_disconnected
+
+	SendBuffer       int    // mensajes pendientes por cliente antes de aplicar la política
+	SlowClientPolicy Policy // qué hacer con un cliente cuyo buffer está lleno
+}
+
+// DefaultConfig son los valores usados si no se configura nada
+func DefaultConfig() Config {
+	return Config{
+		PingInterval:    25 * time.Second,
+		PongWait:        60 * time.Second,
+		WriteWait:       10 * time.Second,
+		MaxMessageSize:  4096,
+		IdleAfter:       2 * time.Minute,
+		DisconnectGrace: 10 * time.Second,
+
+		SendBuffer:       64,
+		SlowClientPolicy: PolicyCoalesce,
+	}
+}
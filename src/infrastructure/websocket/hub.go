@@ -1,9 +1,12 @@
 package websocket
 
 import (
+	"context"
 	"encoding/json"
-	"log"
+	"log/slog"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/gorilla/websocket"
 )
@@ -13,6 +16,7 @@ type Message struct {
 	Event    string      `json:"event"` // "score_update", "participant_connected", etc.
 	RoomCode string      `json:"room"`
 	Payload  interface{} `json:"payload"`
+	Resync   bool        `json:"resync,omitempty"` // room_state que reemplaza eventos que no se pudieron reenviar
 }
 
 // ClientInfo contiene los datos públicos de un cliente conectado
@@ -20,69 +24,183 @@ type ClientInfo struct {
 	UserID int    `json:"user_id"`
 	Name   string `json:"name"`
 	Role   string `json:"role"`
+	Status string `json:"status,omitempty"` // online, idle o disconnected
 }
 
 // Client representa una conexión WebSocket activa con identidad conocida
 type Client struct {
 	conn     *websocket.Conn
 	roomCode string
-	send     chan []byte
+	out      *outbox    // mensajes pendientes de enviar
 	Info     ClientInfo // quién es este cliente
 }
 
 // Hub gestiona todas las conexiones activas agrupadas por sala
 type Hub struct {
-	mu    sync.RWMutex
-	rooms map[string]map[*Client]bool // roomCode → set de clientes
+	mu           sync.RWMutex
+	cfg          Config
+	broker       Broker
+	state        StateFunc                    // arma room_state para cada cliente nuevo (opcional)
+	rooms        map[string]map[*Client]bool  // roomCode → set de clientes
+	presence     map[string]map[int]*presence // roomCode → userID → presencia
+	shuttingDown bool                         // ya no se aceptan conexiones nuevas
+	retryAfter   time.Duration                // cuándo deben reintentar los clientes
+	pumps        sync.WaitGroup               // writePumps en curso
+	dropped      atomic.Int64                 // mensajes descartados por buffer lleno
+	coalesced    atomic.Int64                 // score_update reemplazados por uno más nuevo
+	disconnected atomic.Int64                 // clientes desconectados por buffer lleno
+
+	histMu  sync.Mutex         // ver deliver; se toma antes que mu
+	history map[string][]Event // roomCode → últimos eventos (para reanudar SSE)
+
+	dirtyMu  sync.Mutex
+	dirty    map[string]bool // salas cuya presencia hay que publicar en el broker
+	dirtyC   chan struct{}
+	stopSync chan struct{}
+	synced   chan struct{}
 }
 
-func NewHub() *Hub {
-	return &Hub{
-		rooms: make(map[string]map[*Client]bool),
+// NewHub crea el Hub. Con un broker compartido (Redis) los broadcasts llegan a
+// los clientes de todas las instancias; con nil se usa un LocalBroker.
+func NewHub(cfg Config, broker Broker) *Hub {
+	if broker == nil {
+		broker = NewLocalBroker()
 	}
+	h := &Hub{
+		cfg:      cfg,
+		broker:   broker,
+		rooms:    make(map[string]map[*Client]bool),
+		presence: make(map[string]map[int]*presence),
+		history:  make(map[string][]Event),
+		dirty:    make(map[string]bool),
+		dirtyC:   make(chan struct{}, 1),
+		stopSync: make(chan struct{}),
+		synced:   make(chan struct{}),
+	}
+	broker.Subscribe(h.deliver)
+	go h.syncPresence()
+	return h
 }
 
-// Register agrega un cliente identificado a una sala y notifica a todos
+// StateFunc devuelve el estado completo de una sala visto por un usuario.
+// Lo provee la capa de aplicación; el Hub solo lo envía como room_state.
+type StateFunc func(ctx context.Context, roomCode string, userID int) (interface{}, error)
+
+// SetRoomState configura cómo armar el room_state que recibe cada cliente al
+// conectarse. Debe llamarse antes de aceptar conexiones.
+func (h *Hub) SetRoomState(fn StateFunc) {
+	h.state = fn
+}
+
+// Register agrega un cliente WebSocket identificado a una sala y notifica a todos
 func (h *Hub) Register(conn *websocket.Conn, roomCode string, info ClientInfo) *Client {
-	client := &Client{
+	client := h.newClient(conn, roomCode, info)
+	if !h.add(client, nil) {
+		conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseServiceRestart, "servidor reiniciando"),
+			time.Now().Add(time.Second))
+		conn.Close()
+		return nil
+	}
+
+	go client.writePump(h)
+	go client.readPump(h)
+
+	return client
+}
+
+func (h *Hub) newClient(conn *websocket.Conn, roomCode string, info ClientInfo) *Client {
+	return &Client{
 		conn:     conn,
 		roomCode: roomCode,
-		send:     make(chan []byte, 64),
+		out:      newOutbox(h.cfg.SendBuffer, h.cfg.SlowClientPolicy),
 		Info:     info,
 	}
+}
+
+// add suma el cliente a la sala y a la presencia, anuncia su llegada y le
+// envía la lista de presentes. Devuelve false si el hub se está apagando.
+// Con resumeAfter se le reenvían primero los eventos del historial posteriores
+// a ese id; se hace con histMu tomado para no perder ni duplicar ninguno. Si
+// el id no está en el historial de esta instancia no se puede saber qué se
+// perdió: en vez de un reenvío parcial recibe room_state marcado como resync.
+func (h *Hub) add(client *Client, resumeAfter *uint64) bool {
+	roomCode := client.roomCode
 
+	h.histMu.Lock()
 	h.mu.Lock()
+	if h.shuttingDown {
+		h.mu.Unlock()
+		h.histMu.Unlock()
+		return false
+	}
 	if h.rooms[roomCode] == nil {
 		h.rooms[roomCode] = make(map[*Client]bool)
 	}
 	h.rooms[roomCode][client] = true
+	h.pumps.Add(1)
+	event := h.join(client)
+	current := h.presence[roomCode][client.Info.UserID].withStatus()
+	resync := false
+	if resumeAfter != nil {
+		replay, found := h.since(roomCode, *resumeAfter)
+		if !found && h.state != nil {
+			resync = true
+			replay = nil
+		}
+		for _, ev := range replay {
+			if ev.visibleTo(client.Info) {
+				h.enqueue(client, ev)
+			}
+		}
+	}
+	// Lo que llegue mientras se arma room_state queda retenido para ir después
+	// de la foto: así el cliente nunca aplica un evento más viejo que ella
+	if h.state != nil {
+		client.out.hold()
+	}
 	h.mu.Unlock()
+	h.histMu.Unlock()
 
-	// Notificar a todos en la sala que este usuario se conectó
-	h.Broadcast(roomCode, Message{
-		Event:    "participant_connected",
-		RoomCode: roomCode,
-		Payload:  info,
-	})
+	// Notificar a todos en la sala que este usuario se conectó (o que volvió
+	// dentro del periodo de gracia); una pestaña más del mismo usuario no se anuncia
+	if event != "" {
+		h.broadcastPresence(roomCode, event, current)
+	}
 
 	// Enviarle al recién conectado la lista de quiénes ya están en la sala
 	h.sendOnlineList(client)
 
-	go client.writePump()
-	go client.readPump(h)
+	if h.state != nil {
+		client.out.release(h.roomState(client, resync))
+	}
+	return true
+}
 
-	return client
+// roomState arma el evento room_state del cliente; nil si no se pudo
+func (h *Hub) roomState(client *Client, resync bool) *Event {
+	ctx, cancel := context.WithTimeout(context.Background(), h.cfg.WriteWait)
+	defer cancel()
+
+	state, err := h.state(ctx, client.roomCode, client.Info.UserID)
+	if err != nil {
+		slog.Warn("no se pudo armar room_state", "room", client.roomCode, "user_id", client.Info.UserID, "error", err)
+		return nil
+	}
+	msg := Message{Event: "room_state", RoomCode: client.roomCode, Payload: state, Resync: resync}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil
+	}
+	return &Event{Room: client.roomCode, Name: msg.Event, Data: data}
 }
 
 // sendOnlineList envía al cliente recién conectado la lista de presentes
 func (h *Hub) sendOnlineList(target *Client) {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
-
 	online := make([]ClientInfo, 0)
-	for c := range h.rooms[target.roomCode] {
-		if c != target { // excluirse a sí mismo, él ya sabe que está
-			online = append(online, c.Info)
+	for _, info := range h.GetOnlineUsers(target.roomCode) {
+		if info.UserID != target.Info.UserID { // excluirse a sí mismo, él ya sabe que está
+			online = append(online, info)
 		}
 	}
 
@@ -95,86 +213,273 @@ func (h *Hub) sendOnlineList(target *Client) {
 	if err != nil {
 		return
 	}
-	select {
-	case target.send <- data:
-	default:
-	}
+	h.enqueue(target, Event{Room: target.roomCode, Name: msg.Event, Data: data})
 }
 
-// GetOnlineUsers devuelve los usuarios conectados actualmente en una sala
+// GetOnlineUsers devuelve los usuarios presentes en una sala en todas las
+// instancias (uno por usuario aunque tenga varias pestañas), incluidos los que
+// están en periodo de gracia. Si el broker no responde devuelve solo los locales.
 func (h *Hub) GetOnlineUsers(roomCode string) []ClientInfo {
+	users := h.localPresence(roomCode)
+
+	ctx, cancel := context.WithTimeout(context.Background(), brokerTimeout)
+	defer cancel()
+	remote, err := h.broker.RemotePresence(ctx, roomCode)
+	if err != nil {
+		slog.Error("error al leer la presencia remota", "room", roomCode, "error", err)
+	}
+
+	return mergePresence(users, remote)
+}
+
+// Stats devuelve cuántas conexiones y salas con clientes hay ahora mismo
+func (h *Hub) Stats() (connections, rooms int) {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
-	online := make([]ClientInfo, 0)
-	for c := range h.rooms[roomCode] {
-		online = append(online, c.Info)
+	for _, clients := range h.rooms {
+		connections += len(clients)
 	}
-	return online
+	return connections, len(h.rooms)
+}
+
+// Backpressure devuelve cuántos mensajes se descartaron o se reemplazaron y
+// cuántos clientes se desconectaron por tener el buffer de envío lleno
+func (h *Hub) Backpressure() (dropped, coalesced, disconnected int64) {
+	return h.dropped.Load(), h.coalesced.Load(), h.disconnected.Load()
 }
 
-// Broadcast envía un mensaje a todos los clientes de una sala
+// Broadcast envía un mensaje a todos los clientes de una sala, en todas las
+// instancias. Si el broker falla se entrega al menos a los clientes locales.
 func (h *Hub) Broadcast(roomCode string, msg Message) {
+	h.publish(Event{Room: roomCode}, msg)
+}
+
+// SendToUser envía un mensaje solo a las conexiones de un usuario en la sala
+// (todas sus pestañas, en cualquier instancia)
+func (h *Hub) SendToUser(roomCode string, userID int, msg Message) {
+	h.publish(Event{Room: roomCode, UserID: userID}, msg)
+}
+
+// BroadcastToRoles envía un mensaje solo a los clientes de la sala cuyo rol
+// esté en roles. La comparación es exacta: para incluir admins hay que listarlos.
+func (h *Hub) BroadcastToRoles(roomCode string, roles []string, msg Message) {
+	h.publish(Event{Room: roomCode, Roles: roles}, msg)
+}
+
+// publish serializa msg y lo publica con el destino indicado en ev
+func (h *Hub) publish(ev Event, msg Message) {
 	data, err := json.Marshal(msg)
 	if err != nil {
-		log.Println("error al serializar mensaje ws:", err)
+		slog.Error("error al serializar mensaje ws", "event", msg.Event, "room", ev.Room, "error", err)
 		return
 	}
 
+	ev.ID = nextEventID()
+	ev.Name = msg.Event
+	ev.Data = data
+	ctx, cancel := context.WithTimeout(context.Background(), brokerTimeout)
+	defer cancel()
+	if err := h.broker.Publish(ctx, ev); err != nil {
+		slog.Error("error al publicar mensaje ws en el broker", "event", msg.Event, "room", ev.Room, "error", err)
+		h.deliver(ev)
+	}
+}
+
+// deliver entrega un evento a los clientes de esta instancia y lo guarda en
+// el historial de la sala para que SSE pueda reanudar
+func (h *Hub) deliver(ev Event) {
+	// histMu ordena las entregas con el alta de clientes (add): un cliente
+	// nuevo recibe cada evento una sola vez, o por el historial o por aquí
+	h.histMu.Lock()
+	defer h.histMu.Unlock()
+
+	// Con RLock basta: deliver no modifica el mapa de salas. Si un cliente
+	// se desconecta por lento, su writePump cierra la conexión y readPump lo
+	// quita de la sala con unregister, que sí toma el lock de escritura.
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
-	for client := range h.rooms[roomCode] {
-		select {
-		case client.send <- data:
-		default:
-			close(client.send)
-			delete(h.rooms[roomCode], client)
+	h.remember(ev)
+	for client := range h.rooms[ev.Room] {
+		if ev.visibleTo(client.Info) {
+			h.enqueue(client, ev)
 		}
 	}
 }
 
-// unregister elimina un cliente de su sala y notifica la desconexión
+// enqueue encola un evento para el cliente y cuenta qué hizo la política
+func (h *Hub) enqueue(client *Client, ev Event) {
+	switch client.out.push(ev) {
+	case pushDropped:
+		h.dropped.Add(1)
+	case pushCoalesced:
+		h.coalesced.Add(1)
+	case pushOverflow:
+		h.disconnected.Add(1)
+		slog.Warn("cliente ws desconectado por no leer a tiempo",
+			"room", client.roomCode, "user_id", client.Info.UserID, "policy", client.out.policy)
+	}
+}
+
+// unregister elimina un cliente de su sala. Si era la última conexión del
+// usuario se anuncia como "disconnected"; participant_disconnected llega
+// después, si no vuelve durante DisconnectGrace.
 func (h *Hub) unregister(client *Client) {
 	h.mu.Lock()
 	if clients, ok := h.rooms[client.roomCode]; ok {
-		if _, exists := clients[client]; exists {
-			delete(clients, client)
-			close(client.send)
-		}
+		delete(clients, client)
 		if len(clients) == 0 {
 			delete(h.rooms, client.roomCode)
 		}
 	}
+	var gone bool
+	var info ClientInfo
+	if !h.shuttingDown {
+		gone = h.leave(client)
+		if gone {
+			info = h.presence[client.roomCode][client.Info.UserID].withStatus()
+		}
+	}
 	h.mu.Unlock()
+	client.out.close(nil, false)
 
-	// Notificar a los demás que este usuario se desconectó
-	h.Broadcast(client.roomCode, Message{
-		Event:    "participant_disconnected",
-		RoomCode: client.roomCode,
-		Payload:  client.Info,
-	})
+	if gone {
+		h.broadcastPresence(client.roomCode, "presence_changed", info)
+	}
+}
+
+// IsShuttingDown indica si el hub está cerrando y ya no acepta conexiones
+func (h *Hub) IsShuttingDown() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.shuttingDown
+}
+
+// RetryAfter devuelve cuánto deben esperar los clientes para reconectar
+func (h *Hub) RetryAfter() time.Duration {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.retryAfter
+}
+
+// Shutdown deja de aceptar conexiones, avisa a todas las salas con
+// "server_restarting" (incluyendo cuándo reintentar) y cierra cada cliente
+// con un frame de cierre. Espera a que se vacíen los envíos o a que venza ctx.
+func (h *Hub) Shutdown(ctx context.Context, retryAfter time.Duration) {
+	h.mu.Lock()
+	h.shuttingDown = true
+	h.retryAfter = retryAfter
+	rooms := make([]string, 0, len(h.rooms))
+	for code := range h.rooms {
+		rooms = append(rooms, code)
+	}
+	h.mu.Unlock()
+
+	// Solo se reinicia esta instancia: el aviso no pasa por el broker
+	for _, code := range rooms {
+		msg := Message{
+			Event:    "server_restarting",
+			RoomCode: code,
+			Payload:  map[string]int64{"retry_after_ms": retryAfter.Milliseconds()},
+		}
+		if data, err := json.Marshal(msg); err == nil {
+			h.deliver(Event{ID: nextEventID(), Room: code, Name: msg.Event, Data: data})
+		}
+	}
+
+	closeFrame := websocket.FormatCloseMessage(websocket.CloseServiceRestart, "servidor reiniciando")
+	h.mu.Lock()
+	for code, clients := range h.rooms {
+		for client := range clients {
+			client.out.close(closeFrame, false)
+		}
+		delete(h.rooms, code)
+	}
+	for code, users := range h.presence {
+		for _, p := range users {
+			p.idle.Stop()
+			if p.grace != nil {
+				p.grace.Stop()
+			}
+		}
+		delete(h.presence, code)
+	}
+	h.mu.Unlock()
+
+	close(h.stopSync)
+	<-h.synced
+	if err := h.broker.Close(); err != nil {
+		slog.Error("error al cerrar el broker ws", "error", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		h.pumps.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
 }
 
-// writePump envía mensajes pendientes al cliente
-func (c *Client) writePump() {
-	defer c.conn.Close()
-	for msg := range c.send {
-		if err := c.conn.WriteMessage(websocket.TextMessage, msg); err != nil {
-			return
+// writePump envía mensajes pendientes al cliente y un ping cada PingInterval.
+// Si una escritura no termina en WriteWait la conexión se da por muerta.
+func (c *Client) writePump(h *Hub) {
+	ticker := time.NewTicker(h.cfg.PingInterval)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+		h.pumps.Done()
+	}()
+
+	for {
+		select {
+		case <-c.out.ready:
+			events, closed, closeFrame := c.out.take()
+			for _, ev := range events {
+				c.conn.SetWriteDeadline(time.Now().Add(h.cfg.WriteWait))
+				if err := c.conn.WriteMessage(websocket.TextMessage, ev.Data); err != nil {
+					return
+				}
+			}
+			if closed {
+				if closeFrame != nil {
+					c.conn.WriteControl(websocket.CloseMessage, closeFrame, time.Now().Add(time.Second))
+				}
+				return
+			}
+		case <-ticker.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(h.cfg.WriteWait)); err != nil {
+				return
+			}
 		}
 	}
 }
 
-// readPump lee del cliente para detectar desconexiones
+// readPump lee del cliente para detectar desconexiones. Cada pong o mensaje
+// extiende el plazo de lectura; sin ellos durante PongWait la lectura falla y
+// el cliente se elimina aunque el TCP siga medio abierto. Los mensajes del
+// cliente (p. ej. {"event":"activity"}) además cuentan como actividad para
+// la presencia; los pongs no, porque los responde el navegador solo.
 func (c *Client) readPump(h *Hub) {
 	defer func() {
 		h.unregister(c)
 		c.conn.Close()
 	}()
+
+	c.conn.SetReadLimit(h.cfg.MaxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(h.cfg.PongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(h.cfg.PongWait))
+	})
+
 	for {
 		if _, _, err := c.conn.ReadMessage(); err != nil {
 			break
 		}
+		c.conn.SetReadDeadline(time.Now().Add(h.cfg.PongWait))
+		h.touch(c)
 	}
 }
@@ -0,0 +1,404 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestMain(m *testing.M) {
+	// las desconexiones por cliente lento se loguean; en los tests sobran
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	os.Exit(m.Run())
+}
+
+func testConfig(buffer int, policy Policy) Config {
+	cfg := DefaultConfig()
+	cfg.PingInterval = time.Second
+	cfg.PongWait = 2 * time.Second
+	cfg.WriteWait = time.Second
+	cfg.DisconnectGrace = 10 * time.Millisecond
+	cfg.SendBuffer = buffer
+	cfg.SlowClientPolicy = policy
+	return cfg
+}
+
+func shutdown(t *testing.T, h *Hub) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	h.Shutdown(ctx, time.Second)
+	if ctx.Err() != nil {
+		t.Error("Shutdown no terminó a tiempo")
+	}
+}
+
+// payloads devuelve el payload (como texto) de los eventos llamados name
+func payloads(t *testing.T, events []Event, name string) []string {
+	t.Helper()
+	var out []string
+	for _, e := range events {
+		if e.Name != name {
+			continue
+		}
+		var msg struct {
+			Payload json.RawMessage `json:"payload"`
+		}
+		if err := json.Unmarshal(e.Data, &msg); err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, string(msg.Payload))
+	}
+	return out
+}
+
+// slowClient suscribe un cliente que no lee y descarta lo recibido al entrar
+func slowClient(t *testing.T, h *Hub) *Client {
+	t.Helper()
+	c := h.Subscribe("ABC123", ClientInfo{UserID: 1, Name: "Ana", Role: "participant"}, "")
+	if c == nil {
+		t.Fatal("Subscribe devolvió nil")
+	}
+	c.Take()
+	t.Cleanup(func() { h.Unsubscribe(c) })
+	return c
+}
+
+func TestHubSlowClientDisconnect(t *testing.T) {
+	h := NewHub(testConfig(3, PolicyDisconnect), nil)
+	c := slowClient(t, h)
+
+	for i := 1; i <= 4; i++ {
+		h.Broadcast("ABC123", Message{Event: "score_update", Payload: i})
+	}
+
+	events, closed := c.Take()
+	if len(events) != 0 || !closed {
+		t.Errorf("Take = %d eventos, closed = %v; se esperaba la cola cerrada y vacía", len(events), closed)
+	}
+	if dropped, coalesced, disconnected := h.Backpressure(); dropped != 0 || coalesced != 0 || disconnected != 1 {
+		t.Errorf("Backpressure = %d, %d, %d", dropped, coalesced, disconnected)
+	}
+}
+
+func TestHubSlowClientDropOldest(t *testing.T) {
+	h := NewHub(testConfig(3, PolicyDropOldest), nil)
+	c := slowClient(t, h)
+
+	for i := 1; i <= 5; i++ {
+		h.Broadcast("ABC123", Message{Event: "answer_received", Payload: i})
+	}
+
+	events, closed := c.Take()
+	if got := payloads(t, events, "answer_received"); closed || strings.Join(got, ",") != "3,4,5" {
+		t.Errorf("Take = %v, closed = %v", got, closed)
+	}
+	if dropped, _, disconnected := h.Backpressure(); dropped != 2 || disconnected != 0 {
+		t.Errorf("Backpressure: dropped = %d, disconnected = %d", dropped, disconnected)
+	}
+}
+
+func TestHubSlowClientCoalesce(t *testing.T) {
+	h := NewHub(testConfig(2, PolicyCoalesce), nil)
+	c := slowClient(t, h)
+
+	h.Broadcast("ABC123", Message{Event: "question_started", Payload: 1})
+	for i := 1; i <= 3; i++ {
+		h.Broadcast("ABC123", Message{Event: "score_update", Payload: i})
+	}
+
+	// el cliente recibe solo el ranking más nuevo
+	events, closed := c.Take()
+	if closed || len(events) != 2 || strings.Join(payloads(t, events, "score_update"), ",") != "3" {
+		t.Errorf("Take = %v, closed = %v", names(events), closed)
+	}
+	if _, coalesced, disconnected := h.Backpressure(); coalesced != 2 || disconnected != 0 {
+		t.Errorf("Backpressure: coalesced = %d, disconnected = %d", coalesced, disconnected)
+	}
+}
+
+// TestHubConcurrent altera salas, entregas, bajas y el apagado a la vez; con
+// -race detecta accesos a rooms, presence o history sin el lock correcto
+func TestHubConcurrent(t *testing.T) {
+	for _, policy := range []Policy{PolicyDisconnect, PolicyDropOldest, PolicyCoalesce} {
+		t.Run(string(policy), func(t *testing.T) {
+			h := NewHub(testConfig(8, policy), nil)
+			rooms := []string{"ROOM01", "ROOM02", "ROOM03"}
+			var wg sync.WaitGroup
+
+			// clientes que leen hasta que el hub los cierra
+			for i := 0; i < 6; i++ {
+				c := h.Subscribe(rooms[i%3], ClientInfo{UserID: 100 + i, Role: "participant"}, "")
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for {
+						<-c.Ready()
+						if _, closed := c.Take(); closed {
+							h.Unsubscribe(c)
+							return
+						}
+					}
+				}()
+			}
+
+			// clientes que entran y salen (a veces sin leer nada)
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					for j := 0; j < 50; j++ {
+						c := h.Subscribe(rooms[j%3], ClientInfo{UserID: i%4 + 1, Role: "host"}, fmt.Sprint(j))
+						if c == nil {
+							return // el hub ya se apagó
+						}
+						if j%2 == 0 {
+							c.Take()
+						}
+						h.Unsubscribe(c)
+					}
+				}(i)
+			}
+
+			// entregas a salas, usuarios y roles
+			stop := make(chan struct{})
+			var senders sync.WaitGroup
+			for i := 0; i < 4; i++ {
+				senders.Add(1)
+				go func(i int) {
+					defer senders.Done()
+					for j := 0; ; j++ {
+						select {
+						case <-stop:
+							return
+						default:
+						}
+						room := rooms[j%3]
+						switch i {
+						case 0:
+							h.Broadcast(room, Message{Event: "score_update", Payload: j})
+						case 1:
+							h.SendToUser(room, 100+j%6, Message{Event: "answer_result", Payload: j})
+						case 2:
+							h.BroadcastToRoles(room, []string{"host"}, Message{Event: "answer_received", Payload: j})
+						default:
+							h.GetOnlineUsers(room)
+							h.Stats()
+						}
+					}
+				}(i)
+			}
+
+			time.Sleep(50 * time.Millisecond)
+			shutdown(t, h)
+			close(stop)
+			senders.Wait()
+			wg.Wait()
+
+			if conns, n := h.Stats(); conns != 0 || n != 0 {
+				t.Errorf("tras Shutdown quedan %d conexiones en %d salas", conns, n)
+			}
+			if h.Subscribe("ROOM01", ClientInfo{UserID: 1}, "") != nil {
+				t.Error("Subscribe aceptó un cliente después de Shutdown")
+			}
+		})
+	}
+}
+
+// TestHubWebSocket conecta clientes reales y comprueba la entrega y el
+// frame de cierre que reciben al apagar
+func TestHubWebSocket(t *testing.T) {
+	h := NewHub(testConfig(16, PolicyCoalesce), nil)
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		var userID int
+		fmt.Sscan(r.URL.Query().Get("user"), &userID)
+		h.Register(conn, "ABC123", ClientInfo{UserID: userID, Role: "participant"})
+	}))
+	defer srv.Close()
+
+	dial := func(userID int) *websocket.Conn {
+		url := "ws" + strings.TrimPrefix(srv.URL, "http") + fmt.Sprintf("/?user=%d", userID)
+		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		return conn
+	}
+	// next lee hasta el próximo mensaje llamado event
+	next := func(conn *websocket.Conn, event string) Message {
+		t.Helper()
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		for {
+			var msg Message
+			if err := conn.ReadJSON(&msg); err != nil {
+				t.Fatalf("esperando %s: %v", event, err)
+			}
+			if msg.Event == event {
+				return msg
+			}
+		}
+	}
+
+	ana := dial(1)
+	next(ana, "online_list")
+	beto := dial(2)
+	next(beto, "online_list")
+	if msg := next(ana, "participant_connected"); msg.Payload.(map[string]interface{})["user_id"] != float64(2) {
+		t.Errorf("participant_connected = %+v", msg)
+	}
+
+	h.SendToUser("ABC123", 2, Message{Event: "answer_result", RoomCode: "ABC123", Payload: "solo beto"})
+	h.Broadcast("ABC123", Message{Event: "score_update", RoomCode: "ABC123", Payload: "todos"})
+	if msg := next(beto, "answer_result"); msg.Payload != "solo beto" {
+		t.Errorf("answer_result = %+v", msg)
+	}
+	for _, conn := range []*websocket.Conn{ana, beto} {
+		// ana no recibe answer_result: lo siguiente es el score_update
+		if msg := next(conn, "score_update"); msg.Payload != "todos" {
+			t.Errorf("score_update = %+v", msg)
+		}
+	}
+
+	shutdown(t, h)
+	for _, conn := range []*websocket.Conn{ana, beto} {
+		next(conn, "server_restarting")
+		_, _, err := conn.ReadMessage()
+		if !websocket.IsCloseError(err, websocket.CloseServiceRestart) {
+			t.Errorf("tras server_restarting se recibió %v, se esperaba el cierre 1012", err)
+		}
+	}
+}
+
+func TestHubTargetedMessages(t *testing.T) {
+	h := NewHub(testConfig(32, PolicyDropOldest), nil)
+	t.Cleanup(func() { shutdown(t, h) })
+
+	// el host de ABC123 es el usuario 1; el 2 también es host, pero de otra sala
+	clients := map[string]*Client{
+		"host":           subscribe(t, h, 1, "host"),
+		"otro host":      subscribe(t, h, 2, "host"),
+		"admin":          subscribe(t, h, 3, "admin"),
+		"ana":            subscribe(t, h, 4, "participant"),
+		"ana, pestaña 2": subscribe(t, h, 4, "participant"),
+	}
+	elsewhere := h.Subscribe("XYZ789", ClientInfo{UserID: 1, Role: "host"}, "")
+	t.Cleanup(func() { h.Unsubscribe(elsewhere) })
+	clients["host en otra sala"] = elsewhere
+
+	msg := Message{Event: "answer_received", RoomCode: "ABC123"}
+	tests := []struct {
+		name string
+		send func()
+		want []string
+	}{
+		{"a toda la sala", func() { h.Broadcast("ABC123", msg) }, []string{"admin", "ana", "ana, pestaña 2", "host", "otro host"}},
+		{"al host de la sala", func() { h.SendToUser("ABC123", 1, msg) }, []string{"host"}},
+		{"a todas las pestañas de un usuario", func() { h.SendToUser("ABC123", 4, msg) }, []string{"ana", "ana, pestaña 2"}},
+		{"a un rol", func() { h.BroadcastToRoles("ABC123", []string{"host"}, msg) }, []string{"host", "otro host"}},
+		{"a varios roles", func() { h.BroadcastToRoles("ABC123", []string{"host", "admin"}, msg) }, []string{"admin", "host", "otro host"}},
+		{"a un rol sin clientes", func() { h.BroadcastToRoles("ABC123", []string{"viewer"}, msg) }, nil},
+	}
+	for _, tt := range tests {
+		for _, c := range clients {
+			received(c)
+		}
+		tt.send()
+
+		var got []string
+		for name, c := range clients {
+			for _, ev := range received(c) {
+				if ev == msg.Event {
+					got = append(got, name)
+				}
+			}
+		}
+		sort.Strings(got)
+		if !equal(got, tt.want) {
+			t.Errorf("%s: lo recibieron %v, se esperaba %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHubShutdown(t *testing.T) {
+	tests := []struct {
+		name      string
+		release   bool // el cliente termina su stream al ver el cierre
+		wantDrain bool // Shutdown vuelve antes de que venza el plazo
+	}{
+		{"los clientes terminan a tiempo", true, true},
+		{"un cliente no suelta la conexión", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHub(testConfig(8, PolicyDropOldest), nil)
+			clients := []*Client{
+				h.Subscribe("ABC123", ClientInfo{UserID: 1, Role: "host"}, ""),
+				h.Subscribe("XYZ789", ClientInfo{UserID: 2, Role: "participant"}, ""),
+			}
+			got := make([][]Event, len(clients))
+			var wg sync.WaitGroup
+			for i, c := range clients {
+				c.Take()
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for {
+						<-c.Ready()
+						events, closed := c.Take()
+						got[i] = append(got[i], events...)
+						if closed {
+							if tt.release {
+								h.Unsubscribe(c)
+							}
+							return
+						}
+					}
+				}()
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			h.Shutdown(ctx, 3*time.Second)
+			if drained := ctx.Err() == nil; drained != tt.wantDrain {
+				t.Errorf("Shutdown vació los clientes antes del plazo = %v, se esperaba %v", drained, tt.wantDrain)
+			}
+			wg.Wait()
+
+			for i, events := range got {
+				restarting := payloads(t, events, "server_restarting")
+				if len(restarting) != 1 || restarting[0] != `{"retry_after_ms":3000}` {
+					t.Errorf("cliente %d recibió server_restarting %v", i, restarting)
+				}
+			}
+			if h.RetryAfter() != 3*time.Second {
+				t.Errorf("RetryAfter = %v", h.RetryAfter())
+			}
+			if h.Subscribe("ABC123", ClientInfo{UserID: 3}, "") != nil {
+				t.Error("Subscribe aceptó un cliente durante el apagado")
+			}
+			if !tt.release {
+				for _, c := range clients {
+					h.Unsubscribe(c)
+				}
+			}
+		})
+	}
+}
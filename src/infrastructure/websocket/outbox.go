@@ -0,0 +1,197 @@
+package websocket
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// Policy decide qué hacer cuando un cliente no lee a tiempo y su buffer de
+// envío está lleno
+type Policy string
+
+const (
+	// PolicyDisconnect cierra la conexión; al reconectar el cliente recibe el estado actual
+	PolicyDisconnect Policy = "disconnect"
+	// PolicyDropOldest descarta el mensaje pendiente más viejo para hacer lugar
+	PolicyDropOldest Policy = "drop_oldest"
+	// PolicyCoalesce reemplaza el score_update pendiente por el nuevo (cada uno
+	// trae el ranking actualizado); si no hay ninguno que reemplazar, desconecta
+	PolicyCoalesce Policy = "coalesce"
+)
+
+// overflowFrame cierra la conexión de un cliente que no leía a tiempo.
+// 1013 (Try Again Later) le indica que puede reconectar.
+var overflowFrame = websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "cliente demasiado lento")
+
+// coalescable son los eventos cuyo último mensaje deja obsoletos a los anteriores
+var coalescable = map[string]bool{
+	"score_update": true,
+}
+
+// ParsePolicy valida el nombre de una política (WS_SLOW_CLIENT_POLICY)
+func ParsePolicy(s string) (Policy, error) {
+	switch p := Policy(s); p {
+	case PolicyDisconnect, PolicyDropOldest, PolicyCoalesce:
+		return p, nil
+	}
+	return "", fmt.Errorf("política de cliente lento desconocida: %q", s)
+}
+
+// pushResult indica qué pasó al encolar un mensaje
+type pushResult int
+
+const (
+	pushed        pushResult = iota
+	pushDropped              // se descartó el mensaje más viejo
+	pushCoalesced            // reemplazó a un score_update pendiente
+	pushOverflow             // no había lugar: se cerró la cola
+	pushClosed               // la cola ya estaba cerrada
+)
+
+// outbox es la cola de salida de un cliente. La escriben Broadcast y el Hub
+// (con su propio mutex, así que no hace falta el lock de escritura del Hub) y
+// la vacía únicamente writePump. Cerrarla es idempotente.
+type outbox struct {
+	mu         sync.Mutex
+	queue      []Event
+	size       int
+	policy     Policy
+	closed     bool
+	closeFrame []byte        // frame de cierre a enviar al vaciar la cola cerrada
+	ready      chan struct{} // señal (buffer 1) de que hay mensajes o se cerró
+
+	held   bool // retenida hasta release: no se entrega nada todavía
+	holdAt int  // dónde va el evento de release (tras lo encolado antes de hold)
+}
+
+func newOutbox(size int, policy Policy) *outbox {
+	return &outbox{
+		queue:  make([]Event, 0, size),
+		size:   size,
+		policy: policy,
+		ready:  make(chan struct{}, 1),
+	}
+}
+
+// push encola un evento aplicando la política si la cola está llena
+func (o *outbox) push(ev Event) pushResult {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.closed {
+		return pushClosed
+	}
+
+	result := pushed
+	if len(o.queue) >= o.size {
+		switch o.policy {
+		case PolicyDropOldest:
+			o.remove(0)
+			result = pushDropped
+		case PolicyCoalesce:
+			i := o.pending(ev.Name)
+			if i < 0 {
+				o.closeLocked(overflowFrame, true)
+				return pushOverflow
+			}
+			o.remove(i)
+			result = pushCoalesced
+		default:
+			o.closeLocked(overflowFrame, true)
+			return pushOverflow
+		}
+	}
+
+	o.queue = append(o.queue, ev)
+	o.signal()
+	return result
+}
+
+// remove quita el evento i de la cola manteniendo holdAt en su lugar
+func (o *outbox) remove(i int) {
+	o.queue = append(o.queue[:i], o.queue[i+1:]...)
+	if i < o.holdAt {
+		o.holdAt--
+	}
+}
+
+// hold retiene la cola: lo que llegue desde ahora se acumula y no se entrega
+// hasta release, que pone su evento antes de eso
+func (o *outbox) hold() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.held = true
+	o.holdAt = len(o.queue)
+}
+
+// release libera la cola retenida. Si first no es nil se entrega antes que lo
+// acumulado durante la retención (y después de lo encolado antes de hold).
+func (o *outbox) release(first *Event) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if first != nil && !o.closed {
+		o.queue = append(o.queue, Event{})
+		copy(o.queue[o.holdAt+1:], o.queue[o.holdAt:])
+		o.queue[o.holdAt] = *first
+	}
+	o.held = false
+	o.holdAt = 0
+	o.signal()
+}
+
+// pending devuelve la posición del mensaje pendiente que event deja obsoleto, o -1
+func (o *outbox) pending(event string) int {
+	if !coalescable[event] {
+		return -1
+	}
+	for i, m := range o.queue {
+		if m.Name == event {
+			return i
+		}
+	}
+	return -1
+}
+
+// take devuelve los mensajes pendientes y, si la cola está cerrada, el frame
+// de cierre a enviar después de ellos
+func (o *outbox) take() (events []Event, closed bool, closeFrame []byte) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.held && !o.closed {
+		return nil, false, nil
+	}
+	events = o.queue
+	o.queue = make([]Event, 0, o.size)
+	return events, o.closed, o.closeFrame
+}
+
+// close cierra la cola. Los mensajes pendientes se envían antes del frame de
+// cierre salvo que discard sea true. Llamarla de nuevo no hace nada.
+func (o *outbox) close(closeFrame []byte, discard bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.closeLocked(closeFrame, discard)
+}
+
+func (o *outbox) closeLocked(closeFrame []byte, discard bool) {
+	if o.closed {
+		return
+	}
+	o.closed = true
+	o.closeFrame = closeFrame
+	if discard {
+		o.queue = o.queue[:0]
+	}
+	o.signal()
+}
+
+func (o *outbox) signal() {
+	select {
+	case o.ready <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,185 @@
+package websocket
+
+import (
+	"bytes"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func ev(name string, n int) Event {
+	return Event{Room: "ABC123", Name: name, Data: []byte(strconv.Itoa(n))}
+}
+
+// names resume los eventos como "nombre:dato" para comparar órdenes
+func names(events []Event) []string {
+	out := make([]string, len(events))
+	for i, e := range events {
+		out[i] = e.Name + ":" + string(e.Data)
+	}
+	return out
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestParsePolicy(t *testing.T) {
+	for _, s := range []string{"disconnect", "drop_oldest", "coalesce"} {
+		if p, err := ParsePolicy(s); err != nil || string(p) != s {
+			t.Errorf("ParsePolicy(%q) = %q, %v", s, p, err)
+		}
+	}
+	if _, err := ParsePolicy("block"); err == nil {
+		t.Error("ParsePolicy aceptó una política desconocida")
+	}
+}
+
+func TestOutboxDisconnectPolicy(t *testing.T) {
+	o := newOutbox(2, PolicyDisconnect)
+	o.push(ev("score_update", 1))
+	o.push(ev("score_update", 2))
+
+	if got := o.push(ev("score_update", 3)); got != pushOverflow {
+		t.Fatalf("push con la cola llena = %v, se esperaba pushOverflow", got)
+	}
+	if got := o.push(ev("score_update", 4)); got != pushClosed {
+		t.Errorf("push tras desbordar = %v, se esperaba pushClosed", got)
+	}
+
+	// lo pendiente se descarta: el cliente recibe el estado actual al reconectar
+	events, closed, frame := o.take()
+	if len(events) != 0 || !closed || !bytes.Equal(frame, overflowFrame) {
+		t.Errorf("take = %v, %v, %q", names(events), closed, frame)
+	}
+}
+
+func TestOutboxDropOldestPolicy(t *testing.T) {
+	o := newOutbox(3, PolicyDropOldest)
+	results := make([]pushResult, 0, 5)
+	for i := 1; i <= 5; i++ {
+		results = append(results, o.push(ev("answer_received", i)))
+	}
+
+	want := []pushResult{pushed, pushed, pushed, pushDropped, pushDropped}
+	for i := range want {
+		if results[i] != want[i] {
+			t.Errorf("push %d = %v, se esperaba %v", i+1, results[i], want[i])
+		}
+	}
+	events, closed, _ := o.take()
+	if got := names(events); closed || !equal(got, []string{"answer_received:3", "answer_received:4", "answer_received:5"}) {
+		t.Errorf("take = %v, closed = %v", got, closed)
+	}
+}
+
+func TestOutboxCoalescePolicy(t *testing.T) {
+	o := newOutbox(3, PolicyCoalesce)
+	o.push(ev("score_update", 1))
+	o.push(ev("question_started", 1))
+	o.push(ev("score_update", 2))
+
+	// reemplaza al score_update pendiente más viejo
+	if got := o.push(ev("score_update", 3)); got != pushCoalesced {
+		t.Fatalf("push de score_update con la cola llena = %v, se esperaba pushCoalesced", got)
+	}
+	events, _, _ := o.take()
+	if got := names(events); !equal(got, []string{"question_started:1", "score_update:2", "score_update:3"}) {
+		t.Errorf("take = %v", got)
+	}
+
+	// un evento que no se puede combinar desconecta
+	o.push(ev("score_update", 4))
+	o.push(ev("question_started", 2))
+	o.push(ev("question_ended", 2))
+	if got := o.push(ev("question_ended", 3)); got != pushOverflow {
+		t.Errorf("push de question_ended con la cola llena = %v, se esperaba pushOverflow", got)
+	}
+	if events, closed, frame := o.take(); len(events) != 0 || !closed || !bytes.Equal(frame, overflowFrame) {
+		t.Errorf("take = %v, %v, %q", names(events), closed, frame)
+	}
+}
+
+func TestOutboxHoldRelease(t *testing.T) {
+	o := newOutbox(8, PolicyDropOldest)
+	o.push(ev("online_list", 1))
+	o.hold()
+	o.push(ev("score_update", 1))
+
+	if events, _, _ := o.take(); len(events) != 0 {
+		t.Fatalf("una cola retenida entregó %v", names(events))
+	}
+
+	state := ev("room_state", 1)
+	o.release(&state)
+	events, _, _ := o.take()
+	if got := names(events); !equal(got, []string{"online_list:1", "room_state:1", "score_update:1"}) {
+		t.Errorf("take = %v", got)
+	}
+}
+
+func TestOutboxCloseKeepsPending(t *testing.T) {
+	o := newOutbox(4, PolicyDisconnect)
+	o.push(ev("score_update", 1))
+	o.close([]byte("bye"), false)
+	o.close(nil, true) // cerrar de nuevo no cambia nada
+
+	events, closed, frame := o.take()
+	if got := names(events); !closed || string(frame) != "bye" || !equal(got, []string{"score_update:1"}) {
+		t.Errorf("take = %v, %v, %q", got, closed, frame)
+	}
+}
+
+// TestOutboxConcurrent encola desde varias goroutines mientras otra vacía la
+// cola y una tercera la cierra; con -race detecta accesos sin el mutex
+func TestOutboxConcurrent(t *testing.T) {
+	for _, policy := range []Policy{PolicyDisconnect, PolicyDropOldest, PolicyCoalesce} {
+		t.Run(string(policy), func(t *testing.T) {
+			o := newOutbox(4, policy)
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for {
+					<-o.ready
+					if _, closed, _ := o.take(); closed {
+						return
+					}
+				}
+			}()
+
+			var wg sync.WaitGroup
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					for j := 0; j < 200; j++ {
+						name := "score_update"
+						if j%3 == 0 {
+							name = "answer_received"
+						}
+						o.push(ev(name, i*1000+j))
+						if j == 100 && i == 0 {
+							o.hold()
+							o.release(nil)
+						}
+					}
+				}(i)
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				o.close(nil, false)
+			}()
+			wg.Wait()
+			<-done
+		})
+	}
+}
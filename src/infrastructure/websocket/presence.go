@@ -0,0 +1,266 @@
+package websocket
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Estados de presencia de un usuario en una sala
+const (
+	StatusOnline       = "online"
+	StatusIdle         = "idle"         // conectado pero sin actividad desde hace IdleAfter
+	StatusDisconnected = "disconnected" // sin conexiones; dentro del periodo de gracia
+)
+
+// statusRank ordena los estados para combinar la presencia de varias instancias
+var statusRank = map[string]int{
+	StatusDisconnected: 0,
+	StatusIdle:         1,
+	StatusOnline:       2,
+}
+
+// brokerTimeout es el plazo de cada operación contra el broker
+const brokerTimeout = 2 * time.Second
+
+// presence es el estado de un usuario en una sala. Un usuario puede tener
+// varias conexiones (pestañas); se da por desconectado cuando cierra la
+// última y pasa DisconnectGrace sin que vuelva.
+type presence struct {
+	info   ClientInfo
+	conns  int
+	status string
+	idle   *time.Timer // pasa a idle si no hay actividad
+	grace  *time.Timer // anuncia la desconexión al vencer
+}
+
+// join registra una conexión del usuario. Devuelve el evento a anunciar:
+// participant_connected si es nuevo, presence_changed si volvía dentro del
+// periodo de gracia o "" si ya tenía otra conexión abierta.
+// Se llama con h.mu tomado.
+func (h *Hub) join(client *Client) string {
+	room := client.roomCode
+	if h.presence[room] == nil {
+		h.presence[room] = make(map[int]*presence)
+	}
+
+	p, ok := h.presence[room][client.Info.UserID]
+	if !ok {
+		p = &presence{info: client.Info, status: StatusOnline}
+		p.idle = time.AfterFunc(h.cfg.IdleAfter, func() { h.setIdle(room, p) })
+		h.presence[room][client.Info.UserID] = p
+		p.conns++
+		return "participant_connected"
+	}
+
+	p.conns++
+	p.info = client.Info
+	if p.grace != nil {
+		p.grace.Stop()
+		p.grace = nil
+	}
+	p.idle.Reset(h.cfg.IdleAfter)
+	if p.status != StatusOnline {
+		p.status = StatusOnline
+		return "presence_changed"
+	}
+	return ""
+}
+
+// leave descuenta una conexión; al cerrar la última arranca el periodo de gracia.
+// Devuelve true si el usuario pasó a "disconnected". Se llama con h.mu tomado.
+func (h *Hub) leave(client *Client) bool {
+	room := client.roomCode
+	p, ok := h.presence[room][client.Info.UserID]
+	if !ok {
+		return false
+	}
+	p.conns--
+	if p.conns > 0 {
+		return false
+	}
+
+	p.status = StatusDisconnected
+	p.idle.Stop()
+	p.grace = time.AfterFunc(h.cfg.DisconnectGrace, func() { h.expire(room, p) })
+	return true
+}
+
+// touch marca actividad del cliente: reinicia el contador de idle y, si
+// estaba idle, lo vuelve a poner online
+func (h *Hub) touch(client *Client) {
+	h.mu.Lock()
+	p, ok := h.presence[client.roomCode][client.Info.UserID]
+	if !ok || p.conns == 0 {
+		h.mu.Unlock()
+		return
+	}
+	p.idle.Reset(h.cfg.IdleAfter)
+	changed := p.status == StatusIdle
+	if changed {
+		p.status = StatusOnline
+	}
+	info := p.withStatus()
+	h.mu.Unlock()
+
+	if changed {
+		h.broadcastPresence(client.roomCode, "presence_changed", info)
+	}
+}
+
+func (h *Hub) setIdle(room string, p *presence) {
+	h.mu.Lock()
+	if h.presence[room][p.info.UserID] != p || p.status != StatusOnline {
+		h.mu.Unlock()
+		return
+	}
+	p.status = StatusIdle
+	info := p.withStatus()
+	h.mu.Unlock()
+
+	h.broadcastPresence(room, "presence_changed", info)
+}
+
+// expire anuncia la desconexión si el usuario no volvió durante la gracia
+func (h *Hub) expire(room string, p *presence) {
+	h.mu.Lock()
+	if h.presence[room][p.info.UserID] != p || p.conns > 0 {
+		h.mu.Unlock()
+		return
+	}
+	delete(h.presence[room], p.info.UserID)
+	if len(h.presence[room]) == 0 {
+		delete(h.presence, room)
+	}
+	info := p.withStatus()
+	h.mu.Unlock()
+
+	h.forget(room)
+	h.broadcastPresence(room, "participant_disconnected", info)
+}
+
+func (p *presence) withStatus() ClientInfo {
+	info := p.info
+	info.Status = p.status
+	return info
+}
+
+// broadcastPresence anuncia un cambio de presencia y lo publica en el broker.
+// Si el usuario está conectado en otra instancia no se repite su llegada ni
+// se anuncia un estado peor que el que tiene allí (p. ej. cerró la pestaña
+// de esta instancia pero sigue en la de otra).
+func (h *Hub) broadcastPresence(room, event string, info ClientInfo) {
+	h.markDirty(room)
+
+	if remote, ok := h.remoteStatus(room, info.UserID); ok {
+		switch {
+		case event == "participant_connected" && remote != StatusDisconnected:
+			return
+		case statusRank[remote] > statusRank[info.Status]:
+			return
+		}
+	}
+	h.Broadcast(room, Message{Event: event, RoomCode: room, Payload: info})
+}
+
+// remoteStatus devuelve el estado del usuario en las demás instancias
+func (h *Hub) remoteStatus(room string, userID int) (string, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), brokerTimeout)
+	defer cancel()
+	remote, err := h.broker.RemotePresence(ctx, room)
+	if err != nil {
+		return "", false
+	}
+	for _, info := range mergePresence(nil, remote) {
+		if info.UserID == userID {
+			return info.Status, true
+		}
+	}
+	return "", false
+}
+
+// localPresence devuelve la presencia de la sala en esta instancia
+func (h *Hub) localPresence(room string) []ClientInfo {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	users := make([]ClientInfo, 0, len(h.presence[room]))
+	for _, p := range h.presence[room] {
+		users = append(users, p.withStatus())
+	}
+	return users
+}
+
+// mergePresence combina la presencia de varias instancias dejando una entrada
+// por usuario con su mejor estado (online > idle > disconnected)
+func mergePresence(local, remote []ClientInfo) []ClientInfo {
+	best := make(map[int]ClientInfo, len(local)+len(remote))
+	order := make([]int, 0, len(local)+len(remote))
+	for _, list := range [][]ClientInfo{local, remote} {
+		for _, info := range list {
+			current, ok := best[info.UserID]
+			if !ok {
+				order = append(order, info.UserID)
+			}
+			if !ok || statusRank[info.Status] > statusRank[current.Status] {
+				best[info.UserID] = info
+			}
+		}
+	}
+
+	merged := make([]ClientInfo, 0, len(order))
+	for _, userID := range order {
+		merged = append(merged, best[userID])
+	}
+	return merged
+}
+
+// markDirty pide publicar la presencia de la sala en el broker
+func (h *Hub) markDirty(room string) {
+	h.dirtyMu.Lock()
+	h.dirty[room] = true
+	h.dirtyMu.Unlock()
+
+	select {
+	case h.dirtyC <- struct{}{}:
+	default:
+	}
+}
+
+// syncPresence publica en el broker la presencia de las salas que cambiaron y,
+// cada PresenceRefresh, la de todas para que no expire. Se publica siempre el
+// estado actual leído al momento, así un cambio viejo no pisa a uno nuevo.
+func (h *Hub) syncPresence() {
+	defer close(h.synced)
+	ticker := time.NewTicker(PresenceRefresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-h.stopSync:
+			return
+		case <-h.dirtyC:
+		case <-ticker.C:
+			h.mu.RLock()
+			for room := range h.presence {
+				h.dirtyMu.Lock()
+				h.dirty[room] = true
+				h.dirtyMu.Unlock()
+			}
+			h.mu.RUnlock()
+		}
+
+		h.dirtyMu.Lock()
+		rooms := h.dirty
+		h.dirty = make(map[string]bool)
+		h.dirtyMu.Unlock()
+
+		for room := range rooms {
+			ctx, cancel := context.WithTimeout(context.Background(), brokerTimeout)
+			if err := h.broker.SetPresence(ctx, room, h.localPresence(room)); err != nil {
+				slog.Error("error al publicar la presencia", "room", room, "error", err)
+			}
+			cancel()
+		}
+	}
+}
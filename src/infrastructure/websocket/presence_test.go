@@ -0,0 +1,151 @@
+package websocket
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// presenceOf devuelve los eventos de presencia del usuario como "evento:estado"
+func presenceOf(t *testing.T, c *Client, userID int) []string {
+	t.Helper()
+	events, _ := c.Take()
+	var out []string
+	for _, ev := range events {
+		switch ev.Name {
+		case "participant_connected", "presence_changed", "participant_disconnected":
+		default:
+			continue
+		}
+		var msg struct {
+			Payload ClientInfo `json:"payload"`
+		}
+		if err := json.Unmarshal(ev.Data, &msg); err != nil {
+			t.Fatal(err)
+		}
+		if msg.Payload.UserID == userID {
+			out = append(out, ev.Name+":"+msg.Payload.Status)
+		}
+	}
+	return out
+}
+
+func TestPresenceLifecycle(t *testing.T) {
+	tests := []struct {
+		name  string
+		grace time.Duration
+		idle  time.Duration
+		steps func(t *testing.T, h *Hub)
+		want  []string
+		final string // estado de ana al terminar; "" si ya no figura
+	}{
+		{"cierra y no vuelve", 20 * time.Millisecond, time.Second, func(t *testing.T, h *Hub) {
+			ana := h.Subscribe("ABC123", ClientInfo{UserID: 2, Role: "participant"}, "")
+			h.Unsubscribe(ana)
+			time.Sleep(60 * time.Millisecond)
+		}, []string{"participant_connected:online", "presence_changed:disconnected", "participant_disconnected:disconnected"}, ""},
+
+		{"vuelve dentro de la gracia", time.Second, time.Second, func(t *testing.T, h *Hub) {
+			h.Unsubscribe(h.Subscribe("ABC123", ClientInfo{UserID: 2, Role: "participant"}, ""))
+			subscribe(t, h, 2, "participant")
+		}, []string{"participant_connected:online", "presence_changed:disconnected", "presence_changed:online"}, StatusOnline},
+
+		{"una segunda pestaña no se anuncia", 20 * time.Millisecond, time.Second, func(t *testing.T, h *Hub) {
+			subscribe(t, h, 2, "participant")
+			h.Unsubscribe(h.Subscribe("ABC123", ClientInfo{UserID: 2, Role: "participant"}, ""))
+			time.Sleep(60 * time.Millisecond)
+		}, []string{"participant_connected:online"}, StatusOnline},
+
+		{"pasa a idle y vuelve con actividad", time.Second, 30 * time.Millisecond, func(t *testing.T, h *Hub) {
+			ana := subscribe(t, h, 2, "participant")
+			eventually(t, "ana pasa a idle", func() bool {
+				return statuses(h.GetOnlineUsers("ABC123"))[2] == StatusIdle
+			})
+			h.touch(ana)
+		}, []string{"participant_connected:online", "presence_changed:idle", "presence_changed:online"}, StatusOnline},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(32, PolicyDropOldest)
+			cfg.DisconnectGrace = tt.grace
+			cfg.IdleAfter = tt.idle
+			h := NewHub(cfg, nil)
+			t.Cleanup(func() { shutdown(t, h) })
+
+			host := subscribe(t, h, 1, "host")
+			host.Take()
+			tt.steps(t, h)
+
+			if got := presenceOf(t, host, 2); !equal(got, tt.want) {
+				t.Errorf("el host vio %v, se esperaba %v", got, tt.want)
+			}
+			if got := statuses(h.GetOnlineUsers("ABC123"))[2]; got != tt.final {
+				t.Errorf("ana figura %q, se esperaba %q", got, tt.final)
+			}
+		})
+	}
+}
+
+func TestHeartbeatClosesDeadConnections(t *testing.T) {
+	cfg := testConfig(16, PolicyDropOldest)
+	cfg.PingInterval = 20 * time.Millisecond
+	cfg.PongWait = 80 * time.Millisecond
+	h := NewHub(cfg, nil)
+
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		var userID int
+		fmt.Sscan(r.URL.Query().Get("user"), &userID)
+		h.Register(conn, "ABC123", ClientInfo{UserID: userID, Role: "participant"})
+	}))
+	defer srv.Close()
+	defer shutdown(t, h)
+
+	dial := func(userID int) *websocket.Conn {
+		url := "ws" + strings.TrimPrefix(srv.URL, "http") + fmt.Sprintf("/?user=%d", userID)
+		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		return conn
+	}
+
+	// ana lee, así gorilla responde los pings; beto nunca lee y no hay pongs
+	ana := dial(2)
+	go func() {
+		for {
+			if _, _, err := ana.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	dial(3)
+	eventually(t, "las dos conexiones registradas", func() bool {
+		conns, _ := h.Stats()
+		return conns == 2
+	})
+
+	eventually(t, "se cierra la conexión que no responde pings", func() bool {
+		conns, _ := h.Stats()
+		return conns == 1
+	})
+	time.Sleep(3 * cfg.PongWait)
+	if conns, _ := h.Stats(); conns != 1 {
+		t.Errorf("quedan %d conexiones; la que responde pings no debía cerrarse", conns)
+	}
+	// pasada la gracia beto deja de figurar
+	if got := statuses(h.GetOnlineUsers("ABC123")); len(got) != 1 || got[2] != StatusOnline {
+		t.Errorf("presencia tras el heartbeat = %v, se esperaba solo a ana online", got)
+	}
+}
@@ -0,0 +1,102 @@
+package websocket
+
+import (
+	"strconv"
+	"time"
+)
+
+// historySize es cuántos eventos recientes se guardan por sala para que un
+// suscriptor SSE que reconecta con Last-Event-ID reciba lo que se perdió.
+// El historial es de cada instancia y empieza cuando la sala tiene presencia
+// en ella: con varias instancias, reconectar a otra no encuentra el id y el
+// cliente se resincroniza con room_state (ver add).
+const historySize = 100
+
+// Subscribe agrega a la sala un cliente sin WebSocket (SSE). Recibe los mismos
+// eventos y cuenta en la presencia igual que uno de /ws. Si lastEventID no está
+// vacío, antes que nada se le reenvían los eventos posteriores a ese id que
+// sigan en el historial, o room_state con resync si el id ya no está en él.
+// Devuelve nil si el hub se está apagando; si no, hay
+// que llamar a Unsubscribe al terminar.
+func (h *Hub) Subscribe(roomCode string, info ClientInfo, lastEventID string) *Client {
+	client := h.newClient(nil, roomCode, info)
+
+	var resumeAfter *uint64
+	if last, err := strconv.ParseUint(lastEventID, 10, 64); err == nil {
+		resumeAfter = &last
+	}
+	if !h.add(client, resumeAfter) {
+		return nil
+	}
+	return client
+}
+
+// Unsubscribe quita de la sala un cliente creado con Subscribe
+func (h *Hub) Unsubscribe(client *Client) {
+	h.unregister(client)
+	h.pumps.Done()
+}
+
+// KeepAlive es cada cuánto un stream sin eventos debe enviar algo para que
+// proxies y balanceadores no lo corten; es el mismo intervalo que los pings de /ws
+func (h *Hub) KeepAlive() time.Duration {
+	return h.cfg.PingInterval
+}
+
+// Ready avisa cuando hay eventos pendientes o el cliente se cerró
+func (c *Client) Ready() <-chan struct{} {
+	return c.out.ready
+}
+
+// Take devuelve los eventos pendientes y si el cliente se cerró (por apagado
+// del servidor o por no leer a tiempo); en ese caso hay que terminar el stream
+func (c *Client) Take() (events []Event, closed bool) {
+	events, closed, _ = c.out.take()
+	return events, closed
+}
+
+// remember guarda el evento en el historial de la sala. Solo se guardan salas
+// con presencia en esta instancia, así las que solo llegan por el broker no
+// ocupan memoria. Se llama con histMu y mu tomados.
+func (h *Hub) remember(ev Event) {
+	if _, ok := h.presence[ev.Room]; !ok {
+		return
+	}
+	events := append(h.history[ev.Room], ev)
+	if len(events) > historySize {
+		events = events[len(events)-historySize:]
+	}
+	h.history[ev.Room] = events
+}
+
+// since devuelve los eventos del historial posteriores a last y si last sigue
+// en él. Si no está (salió del historial, o lo emitió otra instancia antes de
+// que esta tuviera la sala) se devuelve lo que tenga id mayor, que puede no ser
+// todo lo que el cliente se perdió. Se llama con histMu tomado.
+func (h *Hub) since(room string, last uint64) (replay []Event, found bool) {
+	events := h.history[room]
+	for i, ev := range events {
+		if ev.ID == last {
+			return append([]Event(nil), events[i+1:]...), true
+		}
+	}
+
+	for _, ev := range events {
+		if ev.ID > last {
+			replay = append(replay, ev)
+		}
+	}
+	return replay, false
+}
+
+// forget borra el historial de una sala que quedó sin presencia
+func (h *Hub) forget(room string) {
+	h.histMu.Lock()
+	defer h.histMu.Unlock()
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if _, ok := h.presence[room]; !ok {
+		delete(h.history, room)
+	}
+}
@@ -0,0 +1,142 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+// roomStateFunc devuelve siempre la misma foto de la sala
+func roomStateFunc(ctx context.Context, roomCode string, userID int) (interface{}, error) {
+	return "foto", nil
+}
+
+// emit publica en ABC123 los eventos de prueba y devuelve sus ids, leídos
+// del cliente que los recibe
+func emit(t *testing.T, h *Hub, watcher *Client) []uint64 {
+	t.Helper()
+	watcher.Take()
+	h.Broadcast("ABC123", Message{Event: "question_started"})
+	h.SendToUser("ABC123", 3, Message{Event: "answer_result"}) // para beto, no para quien reanuda
+	h.Broadcast("ABC123", Message{Event: "score_update"})
+	h.Broadcast("ABC123", Message{Event: "question_closed"})
+
+	events, _ := watcher.Take()
+	var ids []uint64
+	for _, ev := range events {
+		ids = append(ids, ev.ID)
+	}
+	if len(ids) != 3 {
+		t.Fatalf("el cliente que observa recibió %d eventos, se esperaban 3", len(ids))
+	}
+	return ids
+}
+
+// resumed devuelve los eventos de sala que recibió el cliente al conectarse y
+// si room_state vino marcado como resync
+func resumed(t *testing.T, c *Client) (names []string, resync bool) {
+	t.Helper()
+	events, _ := c.Take()
+	for _, ev := range events {
+		switch ev.Name {
+		case "question_started", "answer_result", "score_update", "question_closed":
+			names = append(names, ev.Name)
+		case "room_state":
+			names = append(names, ev.Name)
+			var msg Message
+			if err := json.Unmarshal(ev.Data, &msg); err != nil {
+				t.Fatal(err)
+			}
+			resync = msg.Resync
+		}
+	}
+	return names, resync
+}
+
+func TestSubscribeResume(t *testing.T) {
+	// lastEventID elige a partir de qué evento de emit reanudar
+	tests := []struct {
+		name        string
+		withState   bool
+		lastEventID func(ids []uint64) string
+		want        []string
+		resync      bool
+	}{
+		{"sin Last-Event-ID", true, func([]uint64) string { return "" }, []string{"room_state"}, false},
+		{"id no numérico", true, func([]uint64) string { return "abc" }, []string{"room_state"}, false},
+		{"desde el primero", true, func(ids []uint64) string { return fmt.Sprint(ids[0]) },
+			[]string{"score_update", "question_closed", "room_state"}, false},
+		{"desde el último", true, func(ids []uint64) string { return fmt.Sprint(ids[2]) }, []string{"room_state"}, false},
+		{"id que no está en el historial", true, func(ids []uint64) string { return fmt.Sprint(ids[0] - 1) },
+			[]string{"room_state"}, true},
+		{"id desconocido sin room_state", false, func(ids []uint64) string { return fmt.Sprint(ids[0] - 1) },
+			[]string{"question_started", "score_update", "question_closed"}, false},
+		{"desde el primero sin room_state", false, func(ids []uint64) string { return fmt.Sprint(ids[0]) },
+			[]string{"score_update", "question_closed"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHub(testConfig(32, PolicyDropOldest), nil)
+			if tt.withState {
+				h.SetRoomState(roomStateFunc)
+			}
+			t.Cleanup(func() { shutdown(t, h) })
+
+			// ana mantiene la sala con presencia, así esta instancia guarda el historial
+			ids := emit(t, h, subscribe(t, h, 2, "participant"))
+
+			c := h.Subscribe("ABC123", ClientInfo{UserID: 4, Role: "participant"}, tt.lastEventID(ids))
+			if c == nil {
+				t.Fatal("Subscribe devolvió nil")
+			}
+			t.Cleanup(func() { h.Unsubscribe(c) })
+
+			got, resync := resumed(t, c)
+			if !equal(got, tt.want) {
+				t.Errorf("recibió %v, se esperaba %v", got, tt.want)
+			}
+			if resync != tt.resync {
+				t.Errorf("resync = %v, se esperaba %v", resync, tt.resync)
+			}
+		})
+	}
+}
+
+func TestSubscribeResumeOnAnotherInstance(t *testing.T) {
+	tests := []struct {
+		name       string
+		bHasRoom   bool // B ya tenía clientes de la sala cuando se emitieron los eventos
+		want       []string
+		wantResync bool
+	}{
+		{"B ya tenía la sala", true, []string{"score_update", "question_closed", "room_state"}, false},
+		{"B no tenía la sala", false, []string{"room_state"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			net := newMemoryNetwork()
+			a, b := newInstance(t, net), newInstance(t, net)
+			b.SetRoomState(roomStateFunc)
+			if tt.bHasRoom {
+				subscribe(t, b, 5, "participant")
+			}
+
+			// ana recibe los eventos en A y reconecta a B con el primer id
+			ids := emit(t, a, subscribe(t, a, 2, "participant"))
+			c := b.Subscribe("ABC123", ClientInfo{UserID: 2, Role: "participant"}, fmt.Sprint(ids[0]))
+			if c == nil {
+				t.Fatal("Subscribe devolvió nil")
+			}
+			t.Cleanup(func() { b.Unsubscribe(c) })
+
+			got, resync := resumed(t, c)
+			if !equal(got, tt.want) {
+				t.Errorf("recibió %v, se esperaba %v", got, tt.want)
+			}
+			if resync != tt.wantResync {
+				t.Errorf("resync = %v, se esperaba %v", resync, tt.wantResync)
+			}
+		})
+	}
+}